@@ -0,0 +1,145 @@
+// path: chessTest/cmd/capturebug/main.go
+// capturebug replays a battle log against the engine and writes the observed
+// outcome as a regression corpus entry under internal/game/testdata/regressions.
+//
+// Log format (one directive per line, '#' starts a comment):
+//
+//	name: doover-capture-rewind
+//	note: capture after DoOver left the client on a stale board
+//	white: DoOver / Light
+//	black: DoOver / Shadow
+//	placement: rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR   (optional)
+//	turn: white                                              (optional)
+//	e2 e4
+//	e4xd5 N
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+)
+
+func main() {
+	in := flag.String("in", "", "battle log to convert (default: stdin)")
+	out := flag.String("out", filepath.Join("internal", "game", "testdata", "regressions"), "corpus directory")
+	name := flag.String("name", "", "override the entry name from the log")
+	flag.Parse()
+
+	var src io.Reader = os.Stdin
+	if *in != "" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("open log: %v", err)
+		}
+		defer f.Close()
+		src = f
+	}
+	entry, err := parseLog(src)
+	if err != nil {
+		log.Fatalf("parse log: %v", err)
+	}
+	if *name != "" {
+		entry.Name = *name
+	}
+	if entry.Name == "" {
+		log.Fatal("entry needs a name (log 'name:' line or -name)")
+	}
+	entry, err = entry.Replay()
+	if err != nil {
+		log.Fatalf("replay: %v", err)
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		log.Fatalf("encode entry: %v", err)
+	}
+	data = append(data, '\n')
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("create corpus dir: %v", err)
+	}
+	path := filepath.Join(*out, entry.Name+".json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		log.Fatalf("write entry: %v", err)
+	}
+	log.Printf("wrote %s (%d moves)", path, len(entry.Moves))
+}
+
+func parseLog(r io.Reader) (game.CorpusEntry, error) {
+	var entry game.CorpusEntry
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if key, val, ok := strings.Cut(line, ":"); ok {
+			val = strings.TrimSpace(val)
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "name":
+				entry.Name = val
+			case "note", "bug":
+				entry.Note = val
+			case "placement":
+				entry.Placement = val
+			case "turn":
+				entry.Turn = strings.ToLower(val)
+			case "white":
+				entry.White = parseSide(val)
+			case "black":
+				entry.Black = parseSide(val)
+			default:
+				return entry, fmt.Errorf("line %d: unknown directive %q", lineNo, key)
+			}
+			continue
+		}
+		mv, err := parseMove(line)
+		if err != nil {
+			return entry, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entry.Moves = append(entry.Moves, mv)
+	}
+	return entry, sc.Err()
+}
+
+func parseSide(val string) game.CorpusSide {
+	abilities, element, _ := strings.Cut(val, "/")
+	var side game.CorpusSide
+	for _, a := range strings.Split(abilities, ",") {
+		if a = strings.TrimSpace(a); a != "" {
+			side.Abilities = append(side.Abilities, a)
+		}
+	}
+	side.Element = strings.TrimSpace(element)
+	return side
+}
+
+// parseMove accepts "e2 e4", "e2e4", "e2-e4" or "e4xd5", optionally followed
+// by a BlockPath direction.
+func parseMove(line string) (game.CorpusMove, error) {
+	fields := strings.Fields(strings.NewReplacer("-", " ", "x", " ").Replace(strings.ToLower(line)))
+	if len(fields) > 0 && len(fields[0]) == 4 {
+		fields = append([]string{fields[0][:2], fields[0][2:]}, fields[1:]...)
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return game.CorpusMove{}, fmt.Errorf("malformed move %q", line)
+	}
+	mv := game.CorpusMove{From: fields[0], To: fields[1]}
+	if len(fields) == 3 {
+		mv.Dir = strings.ToUpper(fields[2])
+	}
+	return mv, nil
+}
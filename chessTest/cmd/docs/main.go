@@ -0,0 +1,222 @@
+// path: chessTest/cmd/docs/main.go
+// docs generates the ability interaction reference from the resolver's own
+// metadata, so the published document cannot drift from the code:
+//
+//	go run ./cmd/docs -out ../docs/ability_interactions.md
+//	go run ./cmd/docs -format html -out interactions.html
+//	go run ./cmd/docs -out ../docs/ability_interactions.md -check
+//
+// -check exits non-zero when the file on disk is stale instead of writing it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+)
+
+type phaseRow struct {
+	Name      string
+	Abilities []string
+}
+
+type interactionRow struct {
+	Ability, Other, Kind, Note string
+}
+
+// reference is everything the document shows, in display order.
+type reference struct {
+	Abilities    []game.AbilityInfo
+	Phases       []phaseRow
+	Names        []string
+	Order        [][]string
+	Interactions []interactionRow
+}
+
+func main() {
+	format := flag.String("format", "md", "output format: md or html")
+	out := flag.String("out", "", "file to write (default: stdout)")
+	check := flag.Bool("check", false, "report whether -out is up to date instead of writing it")
+	flag.Parse()
+
+	var render func(io.Writer, reference) error
+	switch *format {
+	case "md":
+		render = renderMarkdown
+	case "html":
+		render = renderHTML
+	default:
+		log.Fatalf("unknown format %q", *format)
+	}
+	var buf bytes.Buffer
+	if err := render(&buf, build()); err != nil {
+		log.Fatalf("render: %v", err)
+	}
+	switch {
+	case *out == "":
+		if *check {
+			log.Fatal("-check needs -out")
+		}
+		if _, err := os.Stdout.Write(buf.Bytes()); err != nil {
+			log.Fatalf("write: %v", err)
+		}
+	case *check:
+		have, err := os.ReadFile(*out)
+		if err != nil {
+			log.Fatalf("read %s: %v", *out, err)
+		}
+		if !bytes.Equal(have, buf.Bytes()) {
+			log.Fatalf("%s is stale; rerun go run ./cmd/docs", *out)
+		}
+	default:
+		if err := os.WriteFile(*out, buf.Bytes(), 0o644); err != nil {
+			log.Fatalf("write %s: %v", *out, err)
+		}
+		log.Printf("wrote %s", *out)
+	}
+}
+
+func build() reference {
+	var ref reference
+	ref.Abilities = game.AbilityInfos()
+	byPhase := make(map[string][]string)
+	// LightSpeed flattens every priority of its owner, so the table order is
+	// the order without it.
+	var queued game.AbilityList
+	for _, info := range ref.Abilities {
+		ref.Names = append(ref.Names, info.Ability.String())
+		if info.Ability != game.AbilityLightSpeed {
+			queued = append(queued, info.Ability)
+		}
+	}
+	for _, step := range game.ResolutionOrder(game.White, queued, nil) {
+		byPhase[step.Phase] = append(byPhase[step.Phase], fmt.Sprintf("%s (%d)", step.Ability, step.Priority))
+	}
+	for _, phase := range game.ResolutionPhases() {
+		ref.Phases = append(ref.Phases, phaseRow{Name: phase, Abilities: byPhase[phase]})
+	}
+	for _, row := range ref.Abilities {
+		cells := make([]string, len(ref.Abilities))
+		for j, col := range ref.Abilities {
+			if row.Ability == col.Ability {
+				continue
+			}
+			cells[j] = "after"
+			if order := game.ResolutionOrder(game.White, game.AbilityList{row.Ability, col.Ability}, nil); len(order) == 2 && order[0].Ability == row.Ability {
+				cells[j] = "before"
+			}
+		}
+		ref.Order = append(ref.Order, cells)
+	}
+	for _, in := range game.AbilityInteractions() {
+		other := in.Other.String()
+		if in.Other == game.AbilityNone {
+			other = "all of its owner's"
+		}
+		ref.Interactions = append(ref.Interactions, interactionRow{Ability: in.Ability.String(), Other: other, Kind: in.Kind, Note: in.Note})
+	}
+	return ref
+}
+
+const intro = "The resolver runs each turn's abilities in phases: %s. " +
+	"Within a phase, lower priority runs first. Ties keep queue order: the mover's abilities before the defender's, " +
+	"then the order abilities are declared in."
+
+func renderMarkdown(w io.Writer, ref reference) error {
+	var b strings.Builder
+	b.WriteString("<!-- path: docs/ability_interactions.md -->\n")
+	b.WriteString("<!-- Generated by go run ./cmd/docs. Do not edit; change the game package and regenerate. -->\n")
+	b.WriteString("# Ability Interactions\n\n")
+	fmt.Fprintf(&b, intro+"\n\n", strings.Join(ref.phaseNames(), ", "))
+	b.WriteString("## Abilities\n\n| Ability | Phase | Priority | Cost | Effect |\n| --- | --- | --- | --- | --- |\n")
+	for _, info := range ref.Abilities {
+		fmt.Fprintf(&b, "| %s | %s | %d | %d | %s |\n", info.Ability, info.Phase, info.Priority, info.Cost, info.Description)
+	}
+	b.WriteString("\nCost is the default charge price, paid only when charges are enabled.\n\n")
+	b.WriteString("## Resolution order\n\nOne side holding every ability except LightSpeed resolves them in this order:\n\n")
+	for i, phase := range ref.Phases {
+		list := strings.Join(phase.Abilities, ", ")
+		if list == "" {
+			list = "none"
+		}
+		fmt.Fprintf(&b, "%d. %s: %s\n", i+1, phase.Name, list)
+	}
+	b.WriteString("\n## Who runs first\n\nEach cell says whether the row ability runs before or after the column ability when one side holds both.\n\n")
+	b.WriteString("| |")
+	for _, name := range ref.Names {
+		fmt.Fprintf(&b, " %s |", name)
+	}
+	b.WriteString("\n| --- |" + strings.Repeat(" --- |", len(ref.Names)) + "\n")
+	for i, cells := range ref.Order {
+		fmt.Fprintf(&b, "| %s |", ref.Names[i])
+		for _, cell := range cells {
+			fmt.Fprintf(&b, " %s |", cell)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n## Interactions\n\n| Ability | Other | Kind | Note |\n| --- | --- | --- | --- |\n")
+	for _, in := range ref.Interactions {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", in.Ability, in.Other, in.Kind, in.Note)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// phaseNames lists the phase names in run order.
+func (ref reference) phaseNames() []string {
+	out := make([]string, len(ref.Phases))
+	for i, p := range ref.Phases {
+		out[i] = p.Name
+	}
+	return out
+}
+
+var htmlPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<!-- Generated by go run ./cmd/docs. Do not edit; change the game package and regenerate. -->
+<html lang="en">
+<head><meta charset="utf-8"><title>Ability Interactions</title></head>
+<body>
+<h1>Ability Interactions</h1>
+<p>{{.Intro}}</p>
+<h2>Abilities</h2>
+<table>
+<tr><th>Ability</th><th>Phase</th><th>Priority</th><th>Cost</th><th>Effect</th></tr>
+{{range .Ref.Abilities}}<tr><td>{{.Ability}}</td><td>{{.Phase}}</td><td>{{.Priority}}</td><td>{{.Cost}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+<p>Cost is the default charge price, paid only when charges are enabled.</p>
+<h2>Resolution order</h2>
+<p>One side holding every ability except LightSpeed resolves them in this order:</p>
+<ol>
+{{range .Ref.Phases}}<li>{{.Name}}: {{range $i, $a := .Abilities}}{{if $i}}, {{end}}{{$a}}{{else}}none{{end}}</li>
+{{end}}</ol>
+<h2>Who runs first</h2>
+<p>Each cell says whether the row ability runs before or after the column ability when one side holds both.</p>
+<table>
+<tr><th></th>{{range .Ref.Names}}<th>{{.}}</th>{{end}}</tr>
+{{range $i, $cells := .Ref.Order}}<tr><th>{{index $.Ref.Names $i}}</th>{{range $cells}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</table>
+<h2>Interactions</h2>
+<table>
+<tr><th>Ability</th><th>Other</th><th>Kind</th><th>Note</th></tr>
+{{range .Ref.Interactions}}<tr><td>{{.Ability}}</td><td>{{.Other}}</td><td>{{.Kind}}</td><td>{{.Note}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, ref reference) error {
+	return htmlPage.Execute(w, struct {
+		Intro string
+		Ref   reference
+	}{
+		Intro: fmt.Sprintf(intro, strings.Join(ref.phaseNames(), ", ")),
+		Ref:   ref,
+	})
+}
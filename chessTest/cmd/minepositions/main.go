@@ -0,0 +1,194 @@
+// path: chessTest/cmd/minepositions/main.go
+// minepositions plays greedy self-play games between random loadouts and
+// keeps the positions where the most ability state is in play at once, as
+// XFEN fixtures with their legal move counts. The fixtures are checked by
+// TestMinedPositions, so each run can grow CI coverage of rare interaction
+// states:
+//
+//	go run ./cmd/minepositions -games 200 -keep 20
+//
+// A position's activity is the number of distinct abilities ready to fire on
+// either side, plus the BlockPath facings held, plus the captures open to the
+// side to move. New positions are appended to the fixture file; positions
+// already in it are skipped.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/selfplay"
+)
+
+type mined struct {
+	xfen     string
+	moves    int
+	activity int
+}
+
+func main() {
+	games := flag.Int("games", 100, "self-play games to mine")
+	plies := flag.Int("plies", 60, "plies per game at most")
+	keep := flag.Int("keep", 10, "positions to add, most active first")
+	minActivity := flag.Int("min-activity", 4, "least activity a position needs to be kept")
+	maxAbilities := flag.Int("abilities", 3, "most abilities per random loadout")
+	noise := flag.Float64("noise", 0.2, "chance a player picks a random legal move instead of the best one")
+	seed := flag.Int64("seed", 1, "random seed")
+	out := flag.String("out", filepath.Join("internal", "game", "testdata", "positions.txt"), "fixture file to extend")
+	flag.Parse()
+
+	if *games < 1 || *plies < 1 || *keep < 1 || *maxAbilities < 1 {
+		log.Fatal("games, plies, keep and abilities must be positive")
+	}
+	known, err := readKnown(*out)
+	if err != nil {
+		log.Fatalf("read fixtures: %v", err)
+	}
+	rng := rand.New(rand.NewSource(*seed))
+	found := make(map[string]mined)
+	for g := 0; g < *games; g++ {
+		for _, m := range playGame(rng, *plies, *maxAbilities, *noise) {
+			if m.activity < *minActivity || known[m.xfen] {
+				continue
+			}
+			if cur, ok := found[m.xfen]; !ok || m.activity > cur.activity {
+				found[m.xfen] = m
+			}
+		}
+	}
+	list := make([]mined, 0, len(found))
+	for _, m := range found {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].activity != list[j].activity {
+			return list[i].activity > list[j].activity
+		}
+		return list[i].xfen < list[j].xfen
+	})
+	if len(list) > *keep {
+		list = list[:*keep]
+	}
+	if err := appendFixtures(*out, list); err != nil {
+		log.Fatalf("write fixtures: %v", err)
+	}
+	log.Printf("added %d positions to %s (%d already there)", len(list), *out, len(known))
+}
+
+// playGame plays one game between random loadouts and returns every position
+// reached, scored.
+func playGame(rng *rand.Rand, plies, maxAbilities int, noise float64) []mined {
+	eng := game.NewEngine()
+	for {
+		if eng.SetSidesConfig(randomSetup(rng, maxAbilities), randomSetup(rng, maxAbilities)) == nil {
+			break
+		}
+	}
+	scratch := game.NewEngine()
+	var out []mined
+	for ply := 0; ply < plies && eng.State(game.StateOptions{Detail: game.StateSummary}).Status == game.StatusActive; ply++ {
+		moves := eng.LegalMoves()
+		out = append(out, mined{xfen: eng.XFEN(), moves: len(moves), activity: activity(eng, moves)})
+		move, ok := selfplay.PickMove(eng, scratch, moves, rng, noise)
+		if !ok {
+			break
+		}
+		if err := eng.Move(move); err != nil && !errors.Is(err, game.ErrDoOverActivated) {
+			break
+		}
+	}
+	return out
+}
+
+func randomSetup(rng *rand.Rand, maxAbilities int) game.SideSetup {
+	var setup game.SideSetup
+	for _, i := range rng.Perm(len(game.AllAbilities))[:1+rng.Intn(maxAbilities)] {
+		setup.Abilities = append(setup.Abilities, game.AllAbilities[i])
+	}
+	elements := game.ElementStrings()
+	setup.Element, _ = game.ParseElement(elements[rng.Intn(len(elements))])
+	return setup
+}
+
+func activity(eng *game.Engine, moves []game.MoveRequest) int {
+	st := eng.State()
+	ready := make(map[game.Ability]bool)
+	occupied := make(map[game.Square]bool, len(st.Pieces))
+	for _, pc := range st.Pieces {
+		occupied[pc.Square] = true
+		detail, ok := eng.Piece(pc.ID)
+		if !ok {
+			continue
+		}
+		for _, r := range detail.Readiness {
+			if r.Ready {
+				ready[r.Ability] = true
+			}
+		}
+	}
+	captures := 0
+	for _, mv := range moves {
+		if occupied[mv.To] {
+			captures++
+		}
+	}
+	return len(ready) + len(st.BlockFacing) + captures
+}
+
+// readKnown returns the XFENs already in the fixture file.
+func readKnown(path string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return known, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		xfen, _, _ := strings.Cut(line, ";")
+		known[strings.TrimSpace(xfen)] = true
+	}
+	return known, sc.Err()
+}
+
+func appendFixtures(path string, list []mined) error {
+	if len(list) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	_, statErr := os.Stat(path)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if os.IsNotExist(statErr) {
+		fmt.Fprintln(w, "# Positions mined by cmd/minepositions: <xfen> ; moves=<legal move count>")
+	}
+	for _, m := range list {
+		fmt.Fprintf(w, "%s ; moves=%d\n", m.xfen, m.moves)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,298 @@
+// path: chessTest/cmd/optimize/main.go
+// optimize searches for strong loadouts against a fixed opponent by simulated
+// annealing. Each candidate is scored by greedy self-play: both sides pick
+// the legal move that leaves the best static evaluation, with a little noise
+// so games differ. For example:
+//
+//	go run ./cmd/optimize -opponent "ScatterShot,DoOver/Fire" -budget 4
+//
+// A loadout costs two points per offense or temporal ability and one per
+// other ability, the same weighting Evaluate uses for ability potential.
+// Conflicting abilities, as listed by game.AbilityInteractions, are never
+// combined.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/selfplay"
+)
+
+type loadout struct {
+	abilities game.AbilitySet
+	element   game.Element
+}
+
+func (l loadout) list() game.AbilityList {
+	var out game.AbilityList
+	for _, a := range game.AllAbilities {
+		if l.abilities.Has(a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+func (l loadout) String() string {
+	names := l.list().Strings()
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	return strings.Join(names, ",") + " / " + l.element.String()
+}
+
+// record is a candidate's self-play tally, from the candidate's side.
+type record struct {
+	wins, draws, losses int
+}
+
+func (r record) score() float64 {
+	n := r.wins + r.draws + r.losses
+	if n == 0 {
+		return 0
+	}
+	return (float64(r.wins) + 0.5*float64(r.draws)) / float64(n)
+}
+
+type optimizer struct {
+	opponent loadout
+	budget   int
+	games    int
+	plies    int
+	noise    float64
+	seed     int64
+	points   map[game.Ability]int
+	conflict map[[2]game.Ability]bool
+	seen     map[loadout]record
+}
+
+func main() {
+	opponentSpec := flag.String("opponent", "", "fixed opponent loadout as abilities/element, e.g. \"ScatterShot,DoOver/Fire\"")
+	budget := flag.Int("budget", 4, "loadout point budget")
+	iterations := flag.Int("iterations", 60, "annealing steps")
+	games := flag.Int("games", 6, "self-play games per candidate, split between colours")
+	plies := flag.Int("plies", 60, "plies before an unfinished game counts as a draw")
+	temp := flag.Float64("temp", 0.2, "starting temperature, in win-rate units")
+	noise := flag.Float64("noise", 0.1, "chance a player picks a random legal move instead of the best one")
+	top := flag.Int("top", 5, "candidates to report")
+	seed := flag.Int64("seed", 1, "random seed")
+	flag.Parse()
+
+	opponent, err := parseLoadout(*opponentSpec)
+	if err != nil {
+		log.Fatalf("opponent: %v", err)
+	}
+	if *budget < 1 || *iterations < 1 || *games < 1 || *plies < 1 {
+		log.Fatal("budget, iterations, games and plies must be positive")
+	}
+	o := newOptimizer(opponent, *budget, *games, *plies, *noise, *seed)
+	rng := rand.New(rand.NewSource(*seed))
+	o.anneal(rng, *iterations, *temp)
+	o.report(*top)
+}
+
+func parseLoadout(spec string) (loadout, error) {
+	abilities, element, _ := strings.Cut(spec, "/")
+	var out loadout
+	for _, name := range strings.Split(abilities, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		a, ok := game.ParseAbility(name)
+		if !ok {
+			return loadout{}, fmt.Errorf("invalid ability %q; valid: %v", name, game.AbilityStrings())
+		}
+		out.abilities = out.abilities.With(a)
+	}
+	el, ok := game.ParseElement(strings.TrimSpace(element))
+	if !ok {
+		return loadout{}, fmt.Errorf("invalid element %q; valid: %v", element, game.ElementStrings())
+	}
+	out.element = el
+	return out, nil
+}
+
+func newOptimizer(opponent loadout, budget, games, plies int, noise float64, seed int64) *optimizer {
+	o := &optimizer{
+		opponent: opponent,
+		budget:   budget,
+		games:    games,
+		plies:    plies,
+		noise:    noise,
+		seed:     seed,
+		points:   make(map[game.Ability]int),
+		conflict: make(map[[2]game.Ability]bool),
+		seen:     make(map[loadout]record),
+	}
+	for _, info := range game.AbilityInfos() {
+		o.points[info.Ability] = 1
+		if info.Phase == "offense" || info.Phase == "temporal" {
+			o.points[info.Ability] = 2
+		}
+	}
+	for _, in := range game.AbilityInteractions() {
+		if in.Kind == game.InteractionConflicts {
+			o.conflict[[2]game.Ability{in.Ability, in.Other}] = true
+			o.conflict[[2]game.Ability{in.Other, in.Ability}] = true
+		}
+	}
+	return o
+}
+
+func (o *optimizer) cost(l loadout) int {
+	total := 0
+	for _, a := range l.list() {
+		total += o.points[a]
+	}
+	return total
+}
+
+func (o *optimizer) allowed(l loadout) bool {
+	if o.cost(l) > o.budget {
+		return false
+	}
+	list := l.list()
+	for i, a := range list {
+		for _, b := range list[i+1:] {
+			if o.conflict[[2]game.Ability{a, b}] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// neighbour adds, drops or swaps one ability, or changes the element, and
+// retries until the result fits the budget.
+func (o *optimizer) neighbour(rng *rand.Rand, l loadout) loadout {
+	for {
+		next := l
+		if rng.Intn(4) == 0 {
+			next.element = game.AllElements[rng.Intn(len(game.AllElements))]
+		} else {
+			a := game.AllAbilities[rng.Intn(len(game.AllAbilities))]
+			if next.abilities.Has(a) {
+				next.abilities &^= game.NewAbilitySet(a)
+			} else {
+				next.abilities = next.abilities.With(a)
+			}
+		}
+		if next != l && o.allowed(next) {
+			return next
+		}
+	}
+}
+
+// anneal walks the loadout space from an empty loadout, accepting a worse
+// neighbour with probability exp(-drop/T) while T cools linearly to zero.
+func (o *optimizer) anneal(rng *rand.Rand, iterations int, temp float64) {
+	current := loadout{element: game.AllElements[rng.Intn(len(game.AllElements))]}
+	currentScore := o.evaluate(current).score()
+	for i := 0; i < iterations; i++ {
+		t := temp * (1 - float64(i)/float64(iterations))
+		next := o.neighbour(rng, current)
+		nextScore := o.evaluate(next).score()
+		if nextScore >= currentScore || (t > 0 && rng.Float64() < math.Exp((nextScore-currentScore)/t)) {
+			current, currentScore = next, nextScore
+		}
+	}
+}
+
+// evaluate plays the candidate against the opponent, alternating colours.
+// Results are cached, so revisiting a loadout costs nothing.
+func (o *optimizer) evaluate(l loadout) record {
+	if r, ok := o.seen[l]; ok {
+		return r
+	}
+	var r record
+	for g := 0; g < o.games; g++ {
+		side := game.White
+		if g%2 == 1 {
+			side = game.Black
+		}
+		rng := rand.New(rand.NewSource(o.seed ^ int64(g+1)*7919))
+		switch o.play(l, side, rng) {
+		case 1:
+			r.wins++
+		case 0:
+			r.draws++
+		default:
+			r.losses++
+		}
+	}
+	o.seen[l] = r
+	return r
+}
+
+// play runs one greedy self-play game and returns 1, 0 or -1 for a win, draw
+// or loss by the candidate playing side.
+func (o *optimizer) play(l loadout, side game.Color, rng *rand.Rand) int {
+	setups := [2]game.SideSetup{}
+	setups[side.Index()] = game.SideSetup{Abilities: l.list(), Element: l.element}
+	setups[side.Opposite().Index()] = game.SideSetup{Abilities: o.opponent.list(), Element: o.opponent.element}
+	eng := game.NewEngine()
+	if err := eng.SetSidesConfig(setups[game.White.Index()], setups[game.Black.Index()]); err != nil {
+		// A rejected loadout cannot be fielded, which is a loss.
+		return -1
+	}
+	scratch := game.NewEngine()
+	for ply := 0; ply < o.plies && eng.State(game.StateOptions{Detail: game.StateSummary}).Status == game.StatusActive; ply++ {
+		move, ok := selfplay.PickMove(eng, scratch, eng.LegalMoves(), rng, o.noise)
+		if !ok {
+			break
+		}
+		if err := eng.Move(move); err != nil && !errors.Is(err, game.ErrDoOverActivated) {
+			break
+		}
+	}
+	switch eng.State(game.StateOptions{Detail: game.StateSummary}).Status {
+	case game.StatusWhiteWins:
+		if side == game.White {
+			return 1
+		}
+		return -1
+	case game.StatusBlackWins:
+		if side == game.Black {
+			return 1
+		}
+		return -1
+	}
+	return 0
+}
+
+func (o *optimizer) report(top int) {
+	type ranked struct {
+		loadout
+		record
+	}
+	all := make([]ranked, 0, len(o.seen))
+	for l, r := range o.seen {
+		all = append(all, ranked{l, r})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if si, sj := all[i].score(), all[j].score(); si != sj {
+			return si > sj
+		}
+		return all[i].loadout.String() < all[j].loadout.String()
+	})
+	fmt.Printf("opponent %s, budget %d, %d loadouts evaluated\n\n", o.opponent, o.budget, len(all))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\tscore\tW/D/L\tcost\tloadout")
+	for i, c := range all {
+		if i == top {
+			break
+		}
+		fmt.Fprintf(tw, "%d\t%.2f\t%d/%d/%d\t%d\t%s\n", i+1, c.score(), c.wins, c.draws, c.losses, o.cost(c.loadout), c.loadout)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,120 @@
+// path: chessTest/cmd/peer/main.go
+// Headless peer: plays battle_chess matches against another peer over TCP.
+// Run one side with -listen and point the other at it with -challenge.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/peer"
+)
+
+func main() {
+	listen := flag.String("listen", getenv("BCHESS_PEER_LISTEN", ""), "accept challenges on this address")
+	challenge := flag.String("challenge", getenv("BCHESS_PEER_CHALLENGE", ""), "challenge the peer at this address")
+	name := flag.String("name", getenv("BCHESS_PEER_NAME", "battle_chess"), "name announced to the opponent")
+	color := flag.String("color", getenv("BCHESS_PEER_COLOR", "white"), "color to play when challenging (white or black)")
+	abils := flag.String("abilities", getenv("BCHESS_PEER_ABILITIES", ""), "comma-separated abilities for our side")
+	elem := flag.String("element", getenv("BCHESS_PEER_ELEMENT", "Light"), "element for our side")
+	strategy := flag.String("strategy", getenv("BCHESS_PEER_STRATEGY", "random"), "move strategy: first or random")
+	maxPlies := flag.Uint("max-plies", 200, "declare a draw after this many plies (0 = no limit)")
+	flag.Parse()
+
+	cfg := peer.Config{Name: *name, MaxPlies: uint32(*maxPlies)}
+	switch strings.ToLower(*color) {
+	case "white":
+		cfg.Color = game.White
+	case "black":
+		cfg.Color = game.Black
+	default:
+		log.Fatalf("invalid color %q", *color)
+	}
+	var err error
+	if cfg.Abilities, err = parseAbilitiesCSV(*abils); err != nil {
+		log.Fatalf("abilities: %v", err)
+	}
+	var ok bool
+	if cfg.Element, ok = game.ParseElement(*elem); !ok {
+		log.Fatalf("invalid element %q; valid: %v", *elem, game.ElementStrings())
+	}
+	switch *strategy {
+	case "first":
+		cfg.Strategy = peer.FirstLegal
+	case "random":
+		cfg.Strategy = peer.RandomLegal(rand.New(rand.NewSource(time.Now().UnixNano())))
+	default:
+		log.Fatalf("invalid strategy %q", *strategy)
+	}
+
+	switch {
+	case *listen != "" && *challenge != "":
+		log.Fatal("choose either -listen or -challenge, not both")
+	case *challenge != "":
+		conn, err := net.Dial("tcp", *challenge)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer conn.Close()
+		res, err := peer.Challenge(conn, game.NewEngine(), cfg)
+		report(res, err)
+	case *listen != "":
+		ln, err := net.Listen("tcp", *listen)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Peer listening on %s", ln.Addr())
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				log.Fatal(err)
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				res, err := peer.Accept(conn, game.NewEngine(), cfg)
+				report(res, err)
+			}(conn)
+		}
+	default:
+		log.Fatal("one of -listen or -challenge is required")
+	}
+}
+
+func report(res peer.Result, err error) {
+	if err != nil {
+		log.Printf("match failed: %v", err)
+		return
+	}
+	log.Printf("match vs %s as %s: %s after %d plies (%s) hash=%x", res.Opponent, res.Color, res.Status, res.Plies, res.Reason, res.Hash)
+}
+
+func parseAbilitiesCSV(s string) (game.AbilityList, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	parts := strings.Split(s, ",")
+	out := make(game.AbilityList, 0, len(parts))
+	for _, p := range parts {
+		a, ok := game.ParseAbility(strings.TrimSpace(p))
+		if !ok {
+			return nil, fmt.Errorf("invalid ability %q; valid: %v", p, game.AbilityStrings())
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func getenv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -9,10 +9,15 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	"battle_chess_poc/internal/flags"
 	// Adjust these imports to your actual module paths if different.
 	"battle_chess_poc/internal/game"  // your engine: NewEngine(), SetSideConfig(...), etc.
 	"battle_chess_poc/internal/httpx" // your HTTP server wrapper exposing Listen(engine) or similar
+	"battle_chess_poc/internal/notify"
+	"battle_chess_poc/internal/persist"
+	"battle_chess_poc/internal/telemetry"
 )
 
 func main() {
@@ -23,11 +28,51 @@ func main() {
 	bAbils := flag.String("black-abilities", getenv("BCHESS_BLACK_ABILITIES", ""), "comma-separated abilities for Black (used only if -preconfig)")
 	wElem := flag.String("white-element", getenv("BCHESS_WHITE_ELEMENT", ""), "element for White (used only if -preconfig)")
 	bElem := flag.String("black-element", getenv("BCHESS_BLACK_ELEMENT", ""), "element for Black (used only if -preconfig)")
+	authTokens := flag.String("auth-tokens", getenv("BCHESS_AUTH_TOKENS", ""), "static bearer tokens as token:subject:role[|role],... (player/admin endpoints stay open when unset)")
+	oidcIssuer := flag.String("oidc-issuer", getenv("BCHESS_OIDC_ISSUER", ""), "OIDC issuer URL for bearer token validation")
+	oidcAudience := flag.String("oidc-audience", getenv("BCHESS_OIDC_AUDIENCE", ""), "expected audience of OIDC bearer tokens")
+	dataDir := flag.String("data-dir", getenv("BCHESS_DATA_DIR", ""), "directory for game snapshots and write-ahead logs (games are not persisted when unset)")
+	walSync := flag.String("wal-sync", getenv("BCHESS_WAL_SYNC", "entry"), "when the write-ahead log is fsynced: entry, checkpoint or never")
+	abilityFlags := flag.String("ability-flags", getenv("BCHESS_ABILITY_FLAGS", ""), "feature-flagged abilities as ability[:on|off|N%|optin|games=id|id],... (flagged abilities are disabled where the flag does not reach)")
+	idleAfter := flag.Duration("idle-after", getdur("BCHESS_IDLE_AFTER", httpx.DefaultSeatPolicy().IdleAfter), "mark a player's seat idle after this long without a heartbeat (0 disables)")
+	forfeitAfter := flag.Duration("forfeit-after", getdur("BCHESS_FORFEIT_AFTER", 0), "forfeit the game of a seat silent this long (0 never forfeits)")
+	telemetryURL := flag.String("telemetry-endpoint", getenv("BCHESS_TELEMETRY_ENDPOINT", ""), "opt in to anonymous gameplay telemetry, POSTed to this URL (off when unset)")
+	telemetryDir := flag.String("telemetry-dir", getenv("BCHESS_TELEMETRY_DIR", ""), "opt in to local-only telemetry, appended to telemetry.jsonl in this directory")
+	telemetryEvery := flag.Duration("telemetry-interval", getdur("BCHESS_TELEMETRY_INTERVAL", telemetry.DefaultInterval), "time between telemetry batches")
+	puzzlePack := flag.String("puzzles", getenv("BCHESS_PUZZLES", ""), "puzzle pack JSON file served by /api/puzzles (built-in pack when unset)")
+	hintQuotas := flag.String("hint-quotas", getenv("BCHESS_HINT_QUOTAS", ""), "advisor hints per seat as variant=N[/cooldown],... e.g. battle=5/30s (unlimited when unset)")
+	presetFile := flag.String("presets", getenv("BCHESS_PRESETS", ""), "game-speed presets JSON file offered by /api/presets (built-in blitz, rapid and correspondence when unset)")
+	notifyOn := flag.Bool("notify", getenb("BCHESS_NOTIFY", false), "send turn notifications to players of games created from a notifying preset")
+	notifySMTP := flag.String("notify-smtp", getenv("BCHESS_NOTIFY_SMTP", ""), "host:port of the SMTP relay for email notifications (webhooks only when unset)")
+	notifyFrom := flag.String("notify-from", getenv("BCHESS_NOTIFY_FROM", ""), "From address of notification emails")
+	notifyAllow := flag.String("notify-allow", getenv("BCHESS_NOTIFY_ALLOW", ""), "internal networks webhooks may reach as CIDR,... (loopback, private and link-local addresses are refused when unset)")
+	slowHandler := flag.Duration("slow-handler", getdur("BCHESS_SLOW_HANDLER", 50*time.Millisecond), "log ability handler runs at least this slow; per-ability timings are in /api/metrics (0 logs none)")
+	shareKey := flag.String("share-key", getenv("BCHESS_SHARE_KEY", ""), "secret of at least 16 bytes signing /g/ share links, so they survive restarts (random per run when unset)")
+	chaosSpec := flag.String("chaos", getenv("BCHESS_CHAOS", ""), "testing only: inject faults into the API as latency=D,jitter=D,fail=P,lose=P,drop=P,seed=N")
 	flag.Parse()
 
-	eng := game.NewEngine()
+	flagList, err := flags.ParseSpec(*abilityFlags)
+	fatalIf(err, "ability flags")
+	flagSet, err := flags.NewSet(flagList...)
+	fatalIf(err, "ability flags")
+
+	var store *persist.Store
+	var eng *game.Engine
+	if *dataDir != "" {
+		policy, ok := persist.ParseSyncPolicy(*walSync)
+		fatalIfBool(!ok, fmt.Errorf("invalid wal sync policy %q; valid: entry, checkpoint, never", *walSync))
+		store, err = persist.Open(*dataDir, persist.Options{Sync: policy})
+		fatalIf(err, "data dir")
+		defer store.Close()
+		eng, err = httpx.RecoverEngine(store)
+		fatalIf(err, "recover game")
+	}
 
-	if *preconfig {
+	switch {
+	case eng != nil:
+		log.Printf("Resuming persisted game; preconfig skipped.")
+	case *preconfig:
+		eng = newDefaultEngine(flagSet)
 		wa, err := parseAbilitiesCSV(*wAbils)
 		fatalIf(err, "white abilities")
 		ba, err := parseAbilitiesCSV(*bAbils)
@@ -45,18 +90,104 @@ func main() {
 			log.Fatalf("config black: %v", err)
 		}
 		log.Printf("Preconfig ON: White[%v,%s] Black[%v,%s]", wa.Strings(), we, ba.Strings(), be)
-	} else {
+	default:
 		// No defaults, nothing selected. UI/API must set both sides before first move.
+		eng = newDefaultEngine(flagSet)
 		log.Printf("No preconfig. Both players must select Ability/Element before the match starts.")
 	}
 
 	srv := httpx.NewServer(eng)
+	srv.SetFlags(flagSet)
+	srv.SetSeatPolicy(httpx.SeatPolicy{IdleAfter: *idleAfter, ForfeitAfter: *forfeitAfter})
+	srv.SetHandlerMetrics(*slowHandler)
+	if *shareKey != "" {
+		if len(*shareKey) < 16 {
+			log.Fatal("share key must be at least 16 bytes")
+		}
+		srv.SetShareKey([]byte(*shareKey))
+	}
+	if *forfeitAfter > 0 {
+		log.Printf("Idle forfeit ON after %s without a heartbeat", *forfeitAfter)
+	}
+	if *telemetryURL != "" || *telemetryDir != "" {
+		fatalIf(srv.SetTelemetry(telemetry.Config{Endpoint: *telemetryURL, Dir: *telemetryDir, Interval: *telemetryEvery}), "telemetry")
+		if *telemetryDir != "" {
+			log.Printf("Telemetry ON, local only in %s (every %s)", *telemetryDir, *telemetryEvery)
+		} else {
+			log.Printf("Telemetry ON, reporting to %s (every %s)", *telemetryURL, *telemetryEvery)
+		}
+	}
+	if *puzzlePack != "" {
+		data, err := os.ReadFile(*puzzlePack)
+		fatalIf(err, "puzzles")
+		pack, err := game.ParsePuzzlePack(data)
+		fatalIf(err, "puzzles")
+		fatalIf(srv.SetPuzzles(pack), "puzzles")
+		log.Printf("Puzzle pack %q loaded (%d puzzles)", pack.Name, len(pack.Puzzles))
+	}
+	if *hintQuotas != "" {
+		quotas, err := httpx.ParseHintQuotas(*hintQuotas)
+		fatalIf(err, "hint quotas")
+		srv.SetHintQuotas(quotas)
+		log.Printf("Hint quotas ON (%s)", *hintQuotas)
+	}
+	if *presetFile != "" {
+		data, err := os.ReadFile(*presetFile)
+		fatalIf(err, "presets")
+		presets, err := httpx.ParsePresets(data)
+		fatalIf(err, "presets")
+		fatalIf(srv.SetPresets(presets), "presets")
+		log.Printf("Presets loaded (%d)", len(presets))
+	}
+	if *notifyOn {
+		allow, err := notify.ParseNetworks(*notifyAllow)
+		fatalIf(err, "notify-allow")
+		fatalIf(srv.SetNotifier(notify.Config{SMTPAddr: *notifySMTP, From: *notifyFrom, AllowNetworks: allow}), "notifications")
+		log.Printf("Turn notifications ON")
+	}
+	chaosCfg, err := httpx.ParseChaos(*chaosSpec)
+	fatalIf(err, "chaos")
+	if chaosCfg.Enabled() {
+		srv.SetChaos(chaosCfg)
+		log.Printf("WARNING: chaos mode ON (%s); the API will misbehave on purpose", *chaosSpec)
+	}
+	if len(flagList) > 0 {
+		log.Printf("Ability flags ON (%d flags)", len(flagList))
+	}
+	if store != nil {
+		fatalIf(srv.SetStore(store), "recover games")
+		log.Printf("Persistence ON in %s (wal sync: %s)", *dataDir, *walSync)
+	}
+	switch {
+	case *authTokens != "" && *oidcIssuer != "":
+		log.Fatal("choose either -auth-tokens or -oidc-issuer, not both")
+	case *authTokens != "":
+		tokens, err := httpx.ParseStaticTokens(*authTokens)
+		fatalIf(err, "auth tokens")
+		srv.SetAuthenticator(tokens)
+		log.Printf("Static token auth ON (%d tokens)", len(tokens))
+	case *oidcIssuer != "":
+		srv.SetAuthenticator(httpx.NewOIDCAuthenticator(*oidcIssuer, *oidcAudience))
+		log.Printf("OIDC auth ON (issuer %s)", *oidcIssuer)
+	}
 	log.Printf("HTTP listening on %s", *addr)
 	if err := srv.Listen(*addr); err != nil {
 		log.Fatal(err)
 	}
 }
 
+// newDefaultEngine starts the unscoped game with the abilities flagSet holds
+// back from it disabled.
+func newDefaultEngine(flagSet *flags.Set) *game.Engine {
+	rules := game.DefaultRules()
+	rules.DisabledAbilities = flagSet.Disabled(httpx.DefaultGameID, nil)
+	eng := game.NewEngine()
+	if err := eng.Apply(game.WithRules(rules), game.WithLogger(log.Default())); err != nil {
+		log.Fatalf("ability flags: %v", err)
+	}
+	return eng
+}
+
 func parseAbilitiesCSV(s string) (game.AbilityList, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
@@ -81,6 +212,15 @@ func getenv(key, def string) string {
 	return def
 }
 
+func getdur(key string, def time.Duration) time.Duration {
+	if v := os.Getenv(key); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
 func getenb(key string, def bool) bool {
 	if v := os.Getenv(key); v != "" {
 		switch strings.ToLower(strings.TrimSpace(v)) {
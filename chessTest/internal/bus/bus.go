@@ -0,0 +1,125 @@
+// path: chessTest/internal/bus/bus.go
+// Package bus fans game events out to independent subscribers. Engines
+// publish through their event sink; streaming, persistence, metrics and other
+// consumers subscribe without knowing about each other or about httpx.
+//
+// Publish never blocks: each subscriber has its own buffer, and a subscriber
+// that falls behind loses events rather than stalling the move that produced
+// them. Dropped reports how many it lost, and Event.Seq gaps show where.
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Message is one event from one game. Seat status changes travel on the same
+// bus with Seat set; their Event is the zero value and carries no Seq.
+type Message struct {
+	Game  string
+	Event game.Event
+	Seat  *SeatStatus
+}
+
+// SeatStatus reports that a player's seat changed state, such as going idle
+// after missed heartbeats.
+type SeatStatus struct {
+	Color    game.Color
+	Status   string
+	LastSeen time.Time
+}
+
+// Bus is a concurrency-safe publish/subscribe hub. The zero value is not
+// usable; call New.
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the messages a subscriber asked for on C until it is
+// closed.
+type Subscription struct {
+	C <-chan Message
+
+	bus     *Bus
+	ch      chan Message
+	game    string
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Subscribe registers a subscriber for game id's events, or every game's when
+// id is "". buffer is how many messages may queue before new ones are
+// dropped; values below one use one.
+func (b *Bus) Subscribe(id string, buffer int) *Subscription {
+	ch := make(chan Message, max(buffer, 1))
+	sub := &Subscription{C: ch, bus: b, ch: ch, game: id}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		sub.once.Do(func() { close(ch) })
+		return sub
+	}
+	b.subs[sub] = struct{}{}
+	return sub
+}
+
+// Publish delivers msg to every matching subscriber with room for it.
+func (b *Bus) Publish(msg Message) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for sub := range b.subs {
+		if sub.game != "" && sub.game != msg.Game {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			sub.dropped.Add(1)
+		}
+	}
+}
+
+// Sink adapts the bus to game.Engine.SetEventSink for game id.
+func (b *Bus) Sink(id string) func(game.Event) {
+	return func(ev game.Event) {
+		b.Publish(Message{Game: id, Event: ev})
+	}
+}
+
+// Close ends every subscription. Later publishes are discarded and later
+// subscriptions start closed.
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for sub := range b.subs {
+		sub.once.Do(func() { close(sub.ch) })
+		delete(b.subs, sub)
+	}
+}
+
+// Dropped counts the messages this subscriber missed because its buffer was
+// full.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes and closes C.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subs, s)
+	s.once.Do(func() { close(s.ch) })
+}
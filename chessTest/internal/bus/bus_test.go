@@ -0,0 +1,74 @@
+// path: chessTest/internal/bus/bus_test.go
+package bus
+
+import (
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestSubscribersReceiveIndependently(t *testing.T) {
+	b := New()
+	all := b.Subscribe("", 8)
+	one := b.Subscribe("g1", 8)
+	slow := b.Subscribe("", 1)
+
+	b.Publish(Message{Game: "g1", Event: game.Event{Seq: 1}})
+	b.Publish(Message{Game: "g2", Event: game.Event{Seq: 1}})
+	b.Publish(Message{Game: "g1", Event: game.Event{Seq: 2}})
+
+	cases := []struct {
+		name    string
+		sub     *Subscription
+		want    []string
+		dropped uint64
+	}{
+		{name: "all games", sub: all, want: []string{"g1", "g2", "g1"}},
+		{name: "one game", sub: one, want: []string{"g1", "g1"}},
+		{name: "full buffer drops", sub: slow, want: []string{"g1"}, dropped: 2},
+	}
+	for _, tc := range cases {
+		if got := len(tc.sub.C); got != len(tc.want) {
+			t.Fatalf("%s: queued %d want %d", tc.name, got, len(tc.want))
+		}
+		for i, want := range tc.want {
+			if msg := <-tc.sub.C; msg.Game != want {
+				t.Fatalf("%s: message %d from %q want %q", tc.name, i, msg.Game, want)
+			}
+		}
+		if got := tc.sub.Dropped(); got != tc.dropped {
+			t.Fatalf("%s: dropped %d want %d", tc.name, got, tc.dropped)
+		}
+	}
+
+	one.Close()
+	if _, ok := <-one.C; ok {
+		t.Fatalf("closed subscription still open")
+	}
+	b.Publish(Message{Game: "g1"})
+	b.Close()
+	if _, ok := <-all.C; !ok {
+		t.Fatalf("message published before Close was lost")
+	}
+	if _, ok := <-all.C; ok {
+		t.Fatalf("subscription open after bus Close")
+	}
+	if _, ok := <-b.Subscribe("", 1).C; ok {
+		t.Fatalf("subscription on closed bus is open")
+	}
+	all.Close()
+}
+
+func TestEngineSinkPublishes(t *testing.T) {
+	b := New()
+	sub := b.Subscribe("default", 16)
+	eng := game.NewEngine()
+	eng.SetEventSink(b.Sink("default"))
+	if err := eng.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareE4}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	msg := <-sub.C
+	if msg.Event.Kind != game.EventMove || msg.Event.Seq != eng.EventSeq() {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+}
@@ -0,0 +1,190 @@
+// path: chessTest/internal/controller/controller.go
+// Package controller runs battle-chess games without any transport: it owns
+// the engines, the lock that serialises each one, their write-ahead journals
+// and the publishing of their events. httpx is one front end over it; a chat
+// bot or a desktop app can embed a Controller and drive the same games
+// directly, with the persistence and event stream the server has.
+//
+// A Controller holds no policy. Rules limits, feature flags, seats, advisors
+// and authentication stay with the front end that needs them.
+package controller
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/persist"
+)
+
+// ErrNoFreeID is returned by Create when no unused game id could be drawn.
+var ErrNoFreeID = errors.New("no free game id")
+
+// Controller is a set of games keyed by id. It is safe for concurrent use.
+type Controller struct {
+	mu    sync.Mutex
+	games map[string]*Game
+	bus   *bus.Bus
+	// store is read by games under their own lock, so it is not guarded by
+	// mu: games lock mu only after their own.
+	store atomic.Pointer[persist.Store]
+	// hooks run on every game as it is added, and on the games already
+	// present when the hook is installed; see OnAdd.
+	hooks []func(*Game)
+}
+
+// New returns an empty Controller that keeps nothing on disk and publishes
+// no events until SetStore and SetBus are called.
+func New() *Controller {
+	return &Controller{games: make(map[string]*Game)}
+}
+
+// SetStore makes every game crash-recoverable through store, first loading
+// the games it already holds, except those named in skip. Call it before the
+// games are played.
+func (c *Controller) SetStore(store *persist.Store, skip ...string) error {
+	ids, err := store.Games()
+	if err != nil {
+		return err
+	}
+	c.store.Store(store)
+	for _, id := range ids {
+		if contains(skip, id) {
+			continue
+		}
+		eng, replayed, err := store.Recover(id)
+		if err != nil {
+			return err
+		}
+		c.Add(id, eng)
+		log.Printf("recovered game %s (%d logged moves replayed)", id, replayed)
+	}
+	return nil
+}
+
+// SetBus publishes the events of every game, present and future, to b.
+func (c *Controller) SetBus(b *bus.Bus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.bus = b
+	for id, g := range c.games {
+		g.mu.Lock()
+		g.engine.SetEventSink(b.Sink(id))
+		g.mu.Unlock()
+	}
+}
+
+// OnAdd runs fn on every game added from now on and on those already added,
+// each under the game's lock, so a front end can install engine options such
+// as metrics on all of them.
+func (c *Controller) OnAdd(fn func(*Game)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hooks = append(c.hooks, fn)
+	for _, g := range c.games {
+		g.mu.Lock()
+		fn(g)
+		g.mu.Unlock()
+	}
+}
+
+// Create starts a game under rules with a fresh id and checkpoints it, so it
+// survives a restart before its first move.
+func (c *Controller) Create(rules game.RulesConfig) (*Game, error) {
+	return c.CreateFor(func(string) game.RulesConfig { return rules })
+}
+
+// CreateFor is Create for rules that depend on the new game's id, such as
+// feature flags rolled out to a share of games.
+func (c *Controller) CreateFor(rules func(id string) game.RulesConfig) (*Game, error) {
+	id, err := c.newID()
+	if err != nil {
+		return nil, err
+	}
+	eng := game.NewEngine()
+	if err := eng.Apply(game.WithRules(rules(id))); err != nil {
+		return nil, err
+	}
+	if store := c.store.Load(); store != nil {
+		if err := store.Checkpoint(id, eng); err != nil {
+			return nil, err
+		}
+	}
+	return c.Add(id, eng), nil
+}
+
+// Add registers eng as game id, replacing any game of that id.
+func (c *Controller) Add(id string, eng *game.Engine) *Game {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bus != nil {
+		eng.SetEventSink(c.bus.Sink(id))
+	}
+	g := &Game{ID: id, Created: time.Now(), mu: new(sync.Mutex), engine: eng, ctl: c}
+	for _, fn := range c.hooks {
+		fn(g)
+	}
+	c.games[id] = g
+	return g
+}
+
+// Get finds game id.
+func (c *Controller) Get(id string) (*Game, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.games[id]
+	return g, ok
+}
+
+// IDs lists the games' ids in order.
+func (c *Controller) IDs() []string {
+	c.mu.Lock()
+	ids := make([]string, 0, len(c.games))
+	for id := range c.games {
+		ids = append(ids, id)
+	}
+	c.mu.Unlock()
+	sort.Strings(ids)
+	return ids
+}
+
+// idEncoding spells game ids in lowercase base32 without padding: 64 random
+// bits in 13 characters that survive being read aloud, typed into a URL or
+// used as a file name by the store.
+var idEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// newID draws a game id not already in use. A clash is astronomically
+// unlikely, but a handful of draws costs nothing against handing out a live
+// game to a second table.
+func (c *Controller) newID() (string, error) {
+	for range 4 {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		id := idEncoding.EncodeToString(b[:])
+		c.mu.Lock()
+		_, taken := c.games[id]
+		c.mu.Unlock()
+		if !taken {
+			return id, nil
+		}
+	}
+	return "", ErrNoFreeID
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// path: chessTest/internal/controller/controller_test.go
+package controller
+
+import (
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/persist"
+)
+
+func TestControllerRecoversGames(t *testing.T) {
+	dir := t.TempDir()
+	store, err := persist.Open(dir, persist.Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	ctl := New()
+	if err := ctl.SetStore(store); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	g, err := ctl.Create(game.DefaultRules())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !regexp.MustCompile(`^[a-z2-7]{13}$`).MatchString(g.ID) {
+		t.Fatalf("unexpected id %q", g.ID)
+	}
+	moves := []game.MoveRequest{
+		{From: game.SquareE2, To: game.SquareE4},
+		{From: game.SquareE7, To: game.SquareE5},
+	}
+	for _, mv := range moves {
+		if _, err := g.Move(mv, MoveOptions{}); err != nil {
+			t.Fatalf("move: %v", err)
+		}
+	}
+	out, err := g.Move(game.MoveRequest{From: game.SquareG1, To: game.SquareF3}, MoveOptions{Explain: true})
+	if !errors.Is(err, game.ErrInvalidMove) || out.Explanation == "" {
+		t.Fatalf("knight move: err %v, explanation %q", err, out.Explanation)
+	}
+	want := g.State(game.StateOptions{}).Hash
+	if err := store.Close(); err != nil {
+		t.Fatalf("close store: %v", err)
+	}
+
+	reopened, err := persist.Open(dir, persist.Options{})
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer reopened.Close()
+	recovered := New()
+	if err := recovered.SetStore(reopened); err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	got, ok := recovered.Get(g.ID)
+	if !ok || got.State(game.StateOptions{}).Hash != want {
+		t.Fatalf("game %s not recovered as played", g.ID)
+	}
+	if ids := recovered.IDs(); len(ids) != 1 {
+		t.Fatalf("recovered %v", ids)
+	}
+	skipped := New()
+	if err := skipped.SetStore(reopened, g.ID); err != nil {
+		t.Fatalf("recover with skip: %v", err)
+	}
+	if _, ok := skipped.Get(g.ID); ok {
+		t.Fatalf("skipped game was recovered")
+	}
+}
+
+func TestMovePrepare(t *testing.T) {
+	var mu sync.Mutex
+	g := Attach("local", &mu, game.NewEngine(), nil)
+	stop := errors.New("stop")
+	out, err := g.Move(game.MoveRequest{}, MoveOptions{Prepare: func(*game.Engine, *game.MoveRequest) error { return stop }})
+	if err != stop || out.State.Turn != game.White {
+		t.Fatalf("rejected prepare: err %v, turn %s", err, out.State.Turn)
+	}
+	_, err = g.Move(game.MoveRequest{}, MoveOptions{Prepare: func(eng *game.Engine, req *game.MoveRequest) error {
+		*req = eng.LegalMoves()[0]
+		return nil
+	}})
+	if err != nil || g.Engine().Turn() != game.Black {
+		t.Fatalf("prepared move: err %v, turn %s", err, g.Engine().Turn())
+	}
+}
+
+func TestOnAddReachesEveryGame(t *testing.T) {
+	ctl := New()
+	first := ctl.Add("first", game.NewEngine())
+	seen := map[string]bool{}
+	ctl.OnAdd(func(g *Game) { seen[g.ID] = true })
+	ctl.Add("second", game.NewEngine())
+	if !seen[first.ID] || !seen["second"] || len(seen) != 2 {
+		t.Fatalf("hook saw %v", seen)
+	}
+}
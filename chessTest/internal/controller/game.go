@@ -0,0 +1,173 @@
+// path: chessTest/internal/controller/game.go
+package controller
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/persist"
+)
+
+// ErrNotRecorded is returned, wrapping the store's error, when a move could
+// not be written ahead to the journal and was therefore not played.
+var ErrNotRecorded = errors.New("could not record move")
+
+// Game is one game: an engine, the lock every use of it takes, and the
+// journal that makes it recoverable. Its methods lock for themselves; Do
+// runs anything else under the same lock.
+type Game struct {
+	ID string
+	// Created is when the game was added to its Controller; zero for games
+	// made with Attach.
+	Created time.Time
+
+	mu     *sync.Mutex
+	engine *game.Engine
+	// store journals an attached game; a Controller's games use the
+	// Controller's store, whenever it is set.
+	store *persist.Store
+	ctl   *Controller
+}
+
+func (g *Game) journal() *persist.Store {
+	if g.ctl == nil {
+		return g.store
+	}
+	return g.ctl.store.Load()
+}
+
+// Attach wraps an engine the caller already owns, with the lock the caller
+// already guards it with, as a Game journaled to store; store may be nil.
+// The game is not added to any Controller. It lets a front end drive an
+// engine it created itself, such as a server's default game, through the
+// same calls as the rest.
+func Attach(id string, mu *sync.Mutex, eng *game.Engine, store *persist.Store) *Game {
+	return &Game{ID: id, mu: mu, engine: eng, store: store}
+}
+
+// Do runs fn with the engine under the game's lock. fn must not keep the
+// engine past its return.
+func (g *Game) Do(fn func(*game.Engine)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fn(g.engine)
+}
+
+// Lock exposes the game's lock, for callers that must hold it across several
+// steps of their own.
+func (g *Game) Lock() *sync.Mutex { return g.mu }
+
+// Engine is the engine the game's lock guards.
+func (g *Game) Engine() *game.Engine { return g.engine }
+
+// MoveOptions tune one Move.
+type MoveOptions struct {
+	// Prepare runs under the lock before the move is recorded. It may
+	// rewrite the request, say to resolve relative directions for the side
+	// to move, and an error from it rejects the move untouched.
+	Prepare func(eng *game.Engine, req *game.MoveRequest) error
+	// Explain asks for a coach-mode explanation of a rejected move.
+	Explain bool
+	// State selects the detail of the returned state.
+	State game.StateOptions
+}
+
+// MoveOutcome is what a Move did and the position it left.
+type MoveOutcome struct {
+	Request game.MoveRequest
+	Result  game.MoveResult
+	State   game.BoardState
+	// Explanation says why a rejected move was rejected, when asked for.
+	Explanation string
+}
+
+// Move plays req: it writes the move ahead to the journal, applies it, and
+// checkpoints the game when the store asks for one. A move the engine rejects
+// returns its error alongside the outcome, whose State is the position after
+// the attempt; soft outcomes such as game.ErrDoOverActivated come back the
+// same way.
+func (g *Game) Move(req game.MoveRequest, opts MoveOptions) (MoveOutcome, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	eng := g.engine
+	if opts.Prepare != nil {
+		if err := opts.Prepare(eng, &req); err != nil {
+			return MoveOutcome{Request: req, State: eng.State(opts.State)}, err
+		}
+	}
+	out := MoveOutcome{Request: req}
+	due, err := g.record(req)
+	if err != nil {
+		out.State = eng.State(opts.State)
+		return out, errors.Join(ErrNotRecorded, err)
+	}
+	out.Result, err = eng.MoveEx(req)
+	if due {
+		g.checkpoint()
+	}
+	if err != nil && opts.Explain {
+		out.Explanation = eng.ExplainMove(req, err)
+	}
+	out.State = eng.State(opts.State)
+	return out, err
+}
+
+// Configure sets one side's loadout.
+func (g *Game) Configure(color game.Color, setup game.SideSetup, opts game.StateOptions) (game.BoardState, error) {
+	return g.Update(func(eng *game.Engine) error { return eng.SetSideSetup(color, setup) }, opts)
+}
+
+// ConfigureBoth sets both loadouts together: either both take effect or
+// neither does.
+func (g *Game) ConfigureBoth(white, black game.SideSetup, opts game.StateOptions) (game.BoardState, error) {
+	return g.Update(func(eng *game.Engine) error { return eng.SetSidesConfig(white, black) }, opts)
+}
+
+// Update applies a change other than a move, such as a reset or a restored
+// snapshot, and checkpoints the game when it succeeds: the journal only
+// replays moves, so anything else must be captured whole.
+func (g *Game) Update(change func(*game.Engine) error, opts game.StateOptions) (game.BoardState, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	err := change(g.engine)
+	if err == nil {
+		g.checkpoint()
+	}
+	return g.engine.State(opts), err
+}
+
+// State reads the game's state.
+func (g *Game) State(opts game.StateOptions) game.BoardState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.engine.State(opts)
+}
+
+// Checkpoint snapshots the game now. The caller holds the game's lock, as
+// it does around a change made outside Update.
+func (g *Game) Checkpoint() { g.checkpoint() }
+
+// record logs req ahead of applying it and reports whether a checkpoint is
+// due afterwards.
+func (g *Game) record(req game.MoveRequest) (bool, error) {
+	store := g.journal()
+	if store == nil {
+		return false, nil
+	}
+	return store.Append(g.ID, g.engine, req)
+}
+
+// checkpoint snapshots the engine. A failure is logged rather than returned:
+// the log still holds every move, so recovery only gets slower.
+func (g *Game) checkpoint() {
+	store := g.journal()
+	if store == nil {
+		return
+	}
+	if err := store.Checkpoint(g.ID, g.engine); err != nil {
+		log.Printf("checkpoint %s: %v", g.ID, err)
+	}
+}
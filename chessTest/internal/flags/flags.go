@@ -0,0 +1,192 @@
+// path: chessTest/internal/flags/flags.go
+// Package flags gates abilities per game so new handlers can ship dark. A
+// flagged ability is disabled in every game the flag does not reach; a flag
+// reaches a game when it is fully on, lists the game, rolls out to the game's
+// percentage bucket, or allows opt-in and the game asked for the feature.
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"battle_chess_poc/internal/game"
+)
+
+var ErrInvalidFlag = errors.New("invalid feature flag")
+
+// Flag gates one ability.
+type Flag struct {
+	Ability game.Ability
+	// Enabled turns the ability on for every game.
+	Enabled bool
+	// Rollout is the percentage of games, 0-100, that get the ability. A
+	// game's bucket is a hash of the ability and game id, so the same games
+	// stay in as the percentage grows.
+	Rollout int
+	// Games always get the ability.
+	Games []string
+	// OptIn lets a game enable the ability by naming it when created.
+	OptIn bool
+}
+
+func (f Flag) validate() error {
+	if !f.Ability.Valid() {
+		return fmt.Errorf("%w: unknown ability", ErrInvalidFlag)
+	}
+	if f.Rollout < 0 || f.Rollout > 100 {
+		return fmt.Errorf("%w: rollout %d outside 0-100", ErrInvalidFlag, f.Rollout)
+	}
+	return nil
+}
+
+// Reaches reports whether f enables its ability for game id; optIn lists the
+// features the game asked for.
+func (f Flag) Reaches(id string, optIn []game.Ability) bool {
+	if f.Enabled {
+		return true
+	}
+	for _, g := range f.Games {
+		if g == id {
+			return true
+		}
+	}
+	if f.OptIn {
+		for _, a := range optIn {
+			if a == f.Ability {
+				return true
+			}
+		}
+	}
+	return f.Rollout > 0 && bucket(f.Ability, id) < f.Rollout
+}
+
+// bucket places a game in 0-99 for f's rollout.
+func bucket(a game.Ability, id string) int {
+	h := fnv.New32a()
+	h.Write([]byte(a.String()))
+	h.Write([]byte{':'})
+	h.Write([]byte(id))
+	return int(h.Sum32() % 100)
+}
+
+// Set is a concurrency-safe collection of flags, one per ability.
+type Set struct {
+	mu    sync.RWMutex
+	flags map[game.Ability]Flag
+}
+
+// NewSet validates and loads flags.
+func NewSet(flags ...Flag) (*Set, error) {
+	s := &Set{flags: make(map[game.Ability]Flag, len(flags))}
+	for _, f := range flags {
+		if err := s.Put(f); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Put adds or replaces the flag for f.Ability.
+func (s *Set) Put(f Flag) error {
+	if err := f.validate(); err != nil {
+		return err
+	}
+	f.Games = append([]string(nil), f.Games...)
+	s.mu.Lock()
+	s.flags[f.Ability] = f
+	s.mu.Unlock()
+	return nil
+}
+
+// Delete removes the flag for a, ungating the ability everywhere. It reports
+// whether a flag existed.
+func (s *Set) Delete(a game.Ability) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.flags[a]
+	delete(s.flags, a)
+	return ok
+}
+
+// List returns the flags ordered by ability.
+func (s *Set) List() []Flag {
+	s.mu.RLock()
+	out := make([]Flag, 0, len(s.flags))
+	for _, f := range s.flags {
+		f.Games = append([]string(nil), f.Games...)
+		out = append(out, f)
+	}
+	s.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Ability < out[j].Ability })
+	return out
+}
+
+// Disabled lists the flagged abilities that do not reach game id, ready for
+// RulesConfig.DisabledAbilities. A nil Set disables nothing.
+func (s *Set) Disabled(id string, optIn []game.Ability) game.AbilityList {
+	if s == nil {
+		return nil
+	}
+	var out game.AbilityList
+	for _, f := range s.List() {
+		if !f.Reaches(id, optIn) {
+			out = append(out, f.Ability)
+		}
+	}
+	return out
+}
+
+// ParseSpec reads flags from a config string: comma-separated entries of
+// ability[:setting...], where each setting is "on", "off", a rollout such as
+// "25%", "optin", or "games=id|id". "DoOver:10%:optin" rolls DoOver out to a
+// tenth of games and any game that opts in.
+func ParseSpec(spec string) ([]Flag, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+	var out []Flag
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		a, ok := game.ParseAbility(strings.TrimSpace(parts[0]))
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown ability %q", ErrInvalidFlag, parts[0])
+		}
+		f := Flag{Ability: a}
+		for _, setting := range parts[1:] {
+			setting = strings.TrimSpace(setting)
+			switch {
+			case strings.EqualFold(setting, "on"):
+				f.Enabled = true
+			case strings.EqualFold(setting, "off"):
+				f.Enabled = false
+			case strings.EqualFold(setting, "optin"):
+				f.OptIn = true
+			case strings.HasSuffix(setting, "%"):
+				pct, err := strconv.Atoi(strings.TrimSuffix(setting, "%"))
+				if err != nil {
+					return nil, fmt.Errorf("%w: bad rollout %q", ErrInvalidFlag, setting)
+				}
+				f.Rollout = pct
+			case strings.HasPrefix(setting, "games="):
+				for _, id := range strings.Split(strings.TrimPrefix(setting, "games="), "|") {
+					if id = strings.TrimSpace(id); id != "" {
+						f.Games = append(f.Games, id)
+					}
+				}
+			default:
+				return nil, fmt.Errorf("%w: unknown setting %q", ErrInvalidFlag, setting)
+			}
+		}
+		if err := f.validate(); err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+// path: chessTest/internal/flags/flags_test.go
+package flags
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestParseSpec(t *testing.T) {
+	cases := []struct {
+		spec string
+		want []Flag
+		err  bool
+	}{
+		{spec: "", want: nil},
+		{spec: "DoOver", want: []Flag{{Ability: game.AbilityDoOver}}},
+		{spec: "DoOver:25%:optin, ScatterShot:on", want: []Flag{
+			{Ability: game.AbilityDoOver, Rollout: 25, OptIn: true},
+			{Ability: game.AbilityScatterShot, Enabled: true},
+		}},
+		{spec: "BlockPath:games=a|b", want: []Flag{{Ability: game.AbilityBlockPath, Games: []string{"a", "b"}}}},
+		{spec: "Teleport", err: true},
+		{spec: "DoOver:150%", err: true},
+		{spec: "DoOver:maybe", err: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseSpec(tc.spec)
+		if tc.err {
+			if !errors.Is(err, ErrInvalidFlag) {
+				t.Fatalf("%q: expected ErrInvalidFlag, got %v", tc.spec, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", tc.spec, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Fatalf("%q: got %+v want %+v", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func TestFlagReach(t *testing.T) {
+	optIn := []game.Ability{game.AbilityDoOver}
+	cases := []struct {
+		name string
+		flag Flag
+		id   string
+		opt  []game.Ability
+		want bool
+	}{
+		{name: "dark", flag: Flag{Ability: game.AbilityDoOver}, id: "g1", want: false},
+		{name: "on", flag: Flag{Ability: game.AbilityDoOver, Enabled: true}, id: "g1", want: true},
+		{name: "listed game", flag: Flag{Ability: game.AbilityDoOver, Games: []string{"g1"}}, id: "g1", want: true},
+		{name: "opt-in honoured", flag: Flag{Ability: game.AbilityDoOver, OptIn: true}, id: "g1", opt: optIn, want: true},
+		{name: "opt-in not offered", flag: Flag{Ability: game.AbilityDoOver}, id: "g1", opt: optIn, want: false},
+		{name: "full rollout", flag: Flag{Ability: game.AbilityDoOver, Rollout: 100}, id: "g1", want: true},
+	}
+	for _, tc := range cases {
+		if got := tc.flag.Reaches(tc.id, tc.opt); got != tc.want {
+			t.Fatalf("%s: reaches = %v want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRolloutIsStableAndProportional(t *testing.T) {
+	set, err := NewSet(Flag{Ability: game.AbilityScatterShot, Rollout: 30})
+	if err != nil {
+		t.Fatalf("new set: %v", err)
+	}
+	reached := 0
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("game-%d", i)
+		disabled := set.Disabled(id, nil)
+		if len(disabled) == 0 {
+			reached++
+		}
+		// Growing the rollout never drops a game that was already in.
+		wider := Flag{Ability: game.AbilityScatterShot, Rollout: 60}
+		if len(disabled) == 0 && !wider.Reaches(id, nil) {
+			t.Fatalf("%s left the rollout when it grew", id)
+		}
+	}
+	if reached < 250 || reached > 350 {
+		t.Fatalf("30%% rollout reached %d of 1000 games", reached)
+	}
+	if !set.Delete(game.AbilityScatterShot) || len(set.Disabled("game-1", nil)) != 0 {
+		t.Fatal("deleting the flag should ungate the ability")
+	}
+}
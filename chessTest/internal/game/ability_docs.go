@@ -0,0 +1,108 @@
+// path: chessTest/internal/game/ability_docs.go
+package game
+
+// Interaction kinds reported by AbilityInteractions.
+const (
+	// InteractionConflicts: the mover may not carry both; the move is
+	// rejected with ErrConflictingAugmentors.
+	InteractionConflicts = "conflicts"
+	// InteractionRequires: the ability only takes effect when the other one
+	// has already run for the same side this turn.
+	InteractionRequires = "requires"
+	// InteractionModifies: the ability changes what the other one does.
+	InteractionModifies = "modifies"
+)
+
+// AbilityInfo is an ability's resolver metadata, for documentation.
+type AbilityInfo struct {
+	Ability  Ability
+	Phase    string
+	Priority int
+	// Cost is the default charge price; see DefaultChargeCosts.
+	Cost        int
+	Description string
+}
+
+// AbilityInteraction records how one ability affects another. Other is
+// AbilityNone when the ability affects every ability of its owner.
+type AbilityInteraction struct {
+	Ability Ability
+	Other   Ability
+	Kind    string
+	Note    string
+}
+
+// abilityDescriptions say what each handler in abilityMetaTable does. Keep
+// them in step with the handlers; cmd/docs builds the published reference
+// from them. "Telemetry only" marks handlers whose effect is recorded for
+// the turn but does not change the board or the move's outcome.
+var abilityDescriptions = [abilityCountInt]string{
+	AbilityDoOver:        "When the opponent captures one of the owner's pieces, rewinds that move. Once per side per game.",
+	AbilityBlockPath:     "Turns the moving piece to face the requested direction. Captures arriving from that direction or either neighbour are refused.",
+	AbilityMistShroud:    "Shrouds its side for the turn. Telemetry only.",
+	AbilityTailwind:      "Raises its side's tailwind for the turn. Telemetry only.",
+	AbilityScatterShot:   "Removes enemy pieces orthogonally next to the target square, sweeping from a random side, within the turn's removal budget.",
+	AbilityOverload:      "Reports the first ability of every piece on its side, up to 16. Telemetry only. The mover needs an ability of its own, not only the side loadout.",
+	AbilityRadiantVision: "Grants its side radiant vision for the turn. Telemetry only.",
+	AbilityLightSpeed:    "Runs every handler of its owner at priority 0 within its phase.",
+	AbilityScorch:        "Marks the four squares orthogonal to the target square as firewall squares. Telemetry only.",
+	AbilityBlazeRush:     "Counts a blaze rush for the moving piece, up to three per turn. Telemetry only.",
+	AbilityFloodWake:     "Keeps its side's wake after a DoOver rewind and holds an enemy Water tide drift.",
+	AbilityBastion:       "Raises its side's bastion for the turn. Telemetry only.",
+	AbilitySturdy:        "Makes its side sturdy for the turn. Telemetry only.",
+	AbilityGaleLift:      "Lifts its side when Bastion and Sturdy have both run for it this turn. Telemetry only.",
+	AbilityRaijin:        "Flags a follow-up strike. Telemetry only.",
+	AbilityBlinding:      "Flags the turn as blinded. Telemetry only.",
+	AbilityAnarchist:     "Names ScatterShot as the turn's override. Telemetry only.",
+	AbilitySadist:        "Reports the override Anarchist chose, or itself when there is none. Telemetry only.",
+}
+
+var abilityInteractions = []AbilityInteraction{
+	{AbilityMistShroud, AbilityRadiantVision, InteractionConflicts, "A mover cannot be both shrouded and radiant."},
+	{AbilityGaleLift, AbilityBastion, InteractionRequires, "Bastion runs first in the augmentor phase."},
+	{AbilityGaleLift, AbilitySturdy, InteractionRequires, "Sturdy runs first in the augmentor phase."},
+	{AbilityTailwind, AbilityMistShroud, InteractionModifies, "With MistShroud on the same side, the tailwind bridges the mist."},
+	{AbilityFloodWake, AbilityDoOver, InteractionModifies, "The wake persists through the rewind."},
+	{AbilityAnarchist, AbilitySadist, InteractionModifies, "Sadist reports ScatterShot instead of itself."},
+	{AbilityLightSpeed, AbilityNone, InteractionModifies, "Flattens its owner's priorities, so queue order decides within each phase."},
+}
+
+// Describe says what a resolves to, in one or two sentences.
+func (a Ability) Describe() string {
+	if int(a) <= 0 || int(a) >= abilityCountInt {
+		return ""
+	}
+	return abilityDescriptions[a]
+}
+
+// AbilityInfos lists every ability in AllAbilities order.
+func AbilityInfos() []AbilityInfo {
+	costs := DefaultChargeCosts()
+	out := make([]AbilityInfo, 0, len(AllAbilities))
+	for _, a := range AllAbilities {
+		meta := abilityMetaTable[a]
+		out = append(out, AbilityInfo{
+			Ability:     a,
+			Phase:       meta.phase.String(),
+			Priority:    int(meta.basePriority),
+			Cost:        costs[a],
+			Description: a.Describe(),
+		})
+	}
+	return out
+}
+
+// AbilityInteractions lists the pairs of abilities that change each other's
+// effect. Order alone is not an interaction; see ResolutionOrder for that.
+func AbilityInteractions() []AbilityInteraction {
+	return append([]AbilityInteraction(nil), abilityInteractions...)
+}
+
+// ResolutionPhases names the resolver's phases in the order they run.
+func ResolutionPhases() []string {
+	out := make([]string, phaseCount)
+	for i := range out {
+		out[i] = abilityPhase(i).String()
+	}
+	return out
+}
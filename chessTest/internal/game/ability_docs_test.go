@@ -0,0 +1,51 @@
+// path: chessTest/internal/game/ability_docs_test.go
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAbilityInfosCoverEveryAbility(t *testing.T) {
+	infos := AbilityInfos()
+	if len(infos) != len(AllAbilities) {
+		t.Fatalf("got %d infos for %d abilities", len(infos), len(AllAbilities))
+	}
+	for _, info := range infos {
+		if info.Description == "" {
+			t.Fatalf("%s has no description", info.Ability)
+		}
+		if abilityMetaTable[info.Ability].handler == nil || info.Phase == "unknown" {
+			t.Fatalf("%s has no handler metadata", info.Ability)
+		}
+	}
+	if (Ability(0)).Describe() != "" || abilityCount.Describe() != "" {
+		t.Fatalf("out-of-range abilities should have no description")
+	}
+}
+
+func TestAbilityInteractionsMatchResolver(t *testing.T) {
+	for _, in := range AbilityInteractions() {
+		if in.Ability.Describe() == "" || (in.Other != AbilityNone && in.Other.Describe() == "") {
+			t.Fatalf("interaction %+v names an unknown ability", in)
+		}
+		switch in.Kind {
+		case InteractionConflicts:
+			eng := NewEngine()
+			if err := eng.SetSideConfig(White, AbilityList{in.Ability, in.Other}, ElementLight); err != nil {
+				t.Fatalf("configure %+v: %v", in, err)
+			}
+			if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); !errors.Is(err, ErrConflictingAugmentors) {
+				t.Fatalf("%s with %s: move = %v", in.Ability, in.Other, err)
+			}
+		case InteractionRequires:
+			order := ResolutionOrder(White, AbilityList{in.Ability, in.Other}, nil)
+			if len(order) != 2 || order[0].Ability != in.Other {
+				t.Fatalf("%s should run after %s: %+v", in.Ability, in.Other, order)
+			}
+		case InteractionModifies:
+		default:
+			t.Fatalf("unknown interaction kind %q", in.Kind)
+		}
+	}
+}
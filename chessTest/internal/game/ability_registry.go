@@ -26,6 +26,18 @@ func (c Color) String() string {
 	return "black"
 }
 
+// ParseColor reads "white" or "black", or their first letters.
+func ParseColor(s string) (Color, bool) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "white", "w":
+		return White, true
+	case "black", "b":
+		return Black, true
+	default:
+		return White, false
+	}
+}
+
 type PieceType uint8
 
 const (
@@ -121,6 +133,24 @@ const (
 	DirNW
 )
 
+var directionNames = [...]string{
+	DirN:  "N",
+	DirNE: "NE",
+	DirE:  "E",
+	DirSE: "SE",
+	DirS:  "S",
+	DirSW: "SW",
+	DirW:  "W",
+	DirNW: "NW",
+}
+
+func (d Direction) String() string {
+	if int(d) < len(directionNames) && directionNames[d] != "" {
+		return directionNames[d]
+	}
+	return ""
+}
+
 func ParseDirection(s string) Direction {
 	switch strings.ToUpper(strings.TrimSpace(s)) {
 	case "N":
@@ -144,6 +174,89 @@ func ParseDirection(s string) Direction {
 	}
 }
 
+// RelativeDirection is a direction as seen by one side: Forward points at the
+// opponent's back rank and Right is the mover's right hand.
+type RelativeDirection uint8
+
+const (
+	RelNone RelativeDirection = iota
+	RelForward
+	RelForwardRight
+	RelRight
+	RelBackRight
+	RelBack
+	RelBackLeft
+	RelLeft
+	RelForwardLeft
+)
+
+var relativeNames = [...]string{
+	RelForward:      "FORWARD",
+	RelForwardRight: "FORWARD_RIGHT",
+	RelRight:        "RIGHT",
+	RelBackRight:    "BACK_RIGHT",
+	RelBack:         "BACK",
+	RelBackLeft:     "BACK_LEFT",
+	RelLeft:         "LEFT",
+	RelForwardLeft:  "FORWARD_LEFT",
+}
+
+func (r RelativeDirection) String() string {
+	if int(r) < len(relativeNames) && relativeNames[r] != "" {
+		return relativeNames[r]
+	}
+	return ""
+}
+
+// Absolute converts r to the board direction for a piece of color c. Both
+// enums run clockwise, so White's view matches the board and Black's is the
+// board turned half way round.
+func (r RelativeDirection) Absolute(c Color) Direction {
+	if r == RelNone || int(r) >= len(relativeNames) {
+		return DirNone
+	}
+	if c == Black {
+		return Direction((int(r)+3)%8 + 1)
+	}
+	return Direction(r)
+}
+
+// Relative is the inverse of RelativeDirection.Absolute.
+func (d Direction) Relative(c Color) RelativeDirection {
+	if d == DirNone || int(d) >= len(directionNames) {
+		return RelNone
+	}
+	if c == Black {
+		return RelativeDirection((int(d)+3)%8 + 1)
+	}
+	return RelativeDirection(d)
+}
+
+// ParseRelativeDirection reads names such as "FORWARD" or "back-left",
+// accepting spaces, dashes or underscores between the parts.
+func ParseRelativeDirection(s string) (RelativeDirection, bool) {
+	normalized := strings.ToUpper(strings.TrimSpace(s))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	for r, name := range relativeNames {
+		if name != "" && name == normalized {
+			return RelativeDirection(r), true
+		}
+	}
+	return RelNone, false
+}
+
+// ParseDirectionFor accepts an absolute compass name or a relative name,
+// resolving the latter from color's point of view.
+func ParseDirectionFor(s string, color Color) Direction {
+	if r, ok := ParseRelativeDirection(s); ok {
+		return r.Absolute(color)
+	}
+	return ParseDirection(s)
+}
+
+// Element is a side's alignment. It changes how abilities resolve and adds
+// the Earth shove and the Water tide drift; it never changes what a move
+// costs.
 type Element uint8
 
 const (
@@ -277,6 +390,12 @@ func (a Ability) String() string {
 	return "Unknown"
 }
 
+// Valid reports whether a names a catalogued ability.
+func (a Ability) Valid() bool {
+	_, ok := abilityNameByID[a]
+	return ok
+}
+
 func ParseAbility(s string) (Ability, bool) {
 	normalized := strings.ToLower(strings.TrimSpace(s))
 	if normalized == "" {
@@ -0,0 +1,34 @@
+// path: chessTest/internal/game/ability_registry_test.go
+package game
+
+import "testing"
+
+func TestRelativeDirections(t *testing.T) {
+	cases := []struct {
+		input string
+		color Color
+		want  Direction
+	}{
+		{input: "FORWARD", color: White, want: DirN},
+		{input: "forward", color: Black, want: DirS},
+		{input: "right", color: White, want: DirE},
+		{input: "RIGHT", color: Black, want: DirW},
+		{input: "forward-left", color: Black, want: DirSE},
+		{input: "Back Right", color: White, want: DirSE},
+		{input: "back_left", color: Black, want: DirNE},
+		{input: "NW", color: Black, want: DirNW},
+		{input: "sideways", color: White, want: DirNone},
+	}
+	for _, tc := range cases {
+		if got := ParseDirectionFor(tc.input, tc.color); got != tc.want {
+			t.Fatalf("ParseDirectionFor(%q, %s) = %s want %s", tc.input, tc.color, got, tc.want)
+		}
+	}
+	for _, color := range []Color{White, Black} {
+		for d := DirN; d <= DirNW; d++ {
+			if back := d.Relative(color).Absolute(color); back != d {
+				t.Fatalf("%s round trip for %s gave %s", d, color, back)
+			}
+		}
+	}
+}
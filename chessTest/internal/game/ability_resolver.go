@@ -1,13 +1,18 @@
 // path: chessTest/internal/game/ability_resolver.go
 package game
 
-import "math/bits"
+import (
+	"math/bits"
+	"time"
+)
 
 const (
 	abilityCountInt  = int(abilityCount)
 	phaseCount       = 5
 	maxPhaseEntries  = 16
 	overloadCapacity = 16
+	maxRemovalBudget = 8
+	maxRecordedDraws = 8
 )
 
 type abilityPhase uint8
@@ -20,7 +25,72 @@ const (
 	phaseResolution
 )
 
-type rngState struct{ seed uint64 }
+var phaseNames = [phaseCount]string{
+	phaseElemental:  "elemental",
+	phaseAugmentor:  "augmentor",
+	phaseOffense:    "offense",
+	phaseTemporal:   "temporal",
+	phaseResolution: "resolution",
+}
+
+func (p abilityPhase) String() string {
+	if int(p) < len(phaseNames) {
+		return phaseNames[p]
+	}
+	return "unknown"
+}
+
+// ResolutionStep is one handler invocation in resolution order.
+type ResolutionStep struct {
+	Phase    string
+	Priority int
+	Ability  Ability
+	Owner    Color
+}
+
+// ResolutionOrder lists the handlers the resolver would run, in order, when
+// mover's side moves with moverAbilities against defenderAbilities. It uses
+// the resolver's own queueing, so abilityMetaTable phases and priorities and
+// the LightSpeed override apply exactly as in play. Loadout legality, charges
+// and piece eligibility are not checked.
+func ResolutionOrder(mover Color, moverAbilities, defenderAbilities AbilityList) []ResolutionStep {
+	defender := mover.Opposite()
+	var masks [2]AbilitySet
+	masks[mover.Index()] = NewAbilitySet(moverAbilities...)
+	masks[defender.Index()] = NewAbilitySet(defenderAbilities...)
+	var ctx resolveContext
+	var state resolveState
+	state.moverColor, state.enemyColor = mover, defender
+	for i, mask := range masks {
+		state.sides[i].combined = mask
+		state.lightSpeed[i] = mask.Has(AbilityLightSpeed)
+	}
+	abilityResolverQueue(&ctx, &state, mover, -1, masks[mover.Index()])
+	abilityResolverQueue(&ctx, &state, defender, -1, masks[defender.Index()])
+	var out []ResolutionStep
+	for phase, scratch := range [phaseCount]*phaseScratch{&ctx.elemental, &ctx.augmentor, &ctx.offense, &ctx.temporal, &ctx.resolution} {
+		scratch.sort()
+		for i := uint8(0); i < scratch.count; i++ {
+			out = append(out, ResolutionStep{
+				Phase:    abilityPhase(phase).String(),
+				Priority: int(scratch.priority[i]),
+				Ability:  scratch.ability[i],
+				Owner:    scratch.owner[i],
+			})
+		}
+	}
+	return out
+}
+
+// rngState is the resolver's random source. Every value it hands out is
+// recorded so the turn can be replayed exactly; values queued in replay are
+// handed out first, in order, before the generator is consulted.
+type rngState struct {
+	seed   uint64
+	replay []uint32
+	draws  [maxRecordedDraws]uint32
+	count  uint8
+}
 
 func newRNG(seed uint64) rngState {
 	if seed == 0 {
@@ -30,12 +100,31 @@ func newRNG(seed uint64) rngState {
 }
 
 func (r *rngState) next() uint32 {
-	s := r.seed
-	s ^= s << 7
-	s ^= s >> 9
-	s ^= s << 8
-	r.seed = s
-	return uint32(s)
+	var v uint32
+	if len(r.replay) > 0 {
+		v = r.replay[0]
+		r.replay = r.replay[1:]
+	} else {
+		s := r.seed
+		s ^= s << 7
+		s ^= s >> 9
+		s ^= s << 8
+		r.seed = s
+		v = uint32(s)
+	}
+	if r.count < maxRecordedDraws {
+		r.draws[r.count] = v
+		r.count++
+	}
+	return v
+}
+
+// recorded returns the values drawn so far, or nil when there were none.
+func (r *rngState) recorded() []uint32 {
+	if r.count == 0 {
+		return nil
+	}
+	return append([]uint32(nil), r.draws[:r.count]...)
 }
 
 type phaseScratch struct {
@@ -121,6 +210,16 @@ type resolveTelemetry struct {
 	ck                  PieceType
 	qk                  PieceType
 	dk                  PieceType
+	removals            [maxRemovalBudget]removalClaim
+	removalCount        uint8
+	removalDenied       Ability
+}
+
+// removalClaim records which ability spent a unit of the turn's removal
+// budget, and on which board slot.
+type removalClaim struct {
+	ability Ability
+	piece   int8
 }
 
 type resolveContext struct {
@@ -135,18 +234,33 @@ type resolveContext struct {
 	sideElement  Element
 	enemyElement Element
 	seed         uint64
-	elemental    phaseScratch
-	augmentor    phaseScratch
-	offense      phaseScratch
-	temporal     phaseScratch
-	resolution   phaseScratch
-	rng          rngState
+	charges      *[2]uint16
+	chargeCosts  [abilityCountInt]uint8
+	// removalBudget caps ability removals this turn, shared by every
+	// removing handler in phase and priority order.
+	removalBudget uint8
+	// replayDraws replaces the first random draws of the turn.
+	replayDraws []uint32
+	// ineligible holds, per piece type, the abilities that piece may not use.
+	ineligible ineligibleTable
+	// timings, when set, receives a HandlerTiming per handler dispatched.
+	timings    *[]HandlerTiming
+	elemental  phaseScratch
+	augmentor  phaseScratch
+	offense    phaseScratch
+	temporal   phaseScratch
+	resolution phaseScratch
+	rng        rngState
 }
 
 type resolveResult struct {
-	doOver    bool
-	blockDir  Direction
-	setBlock  bool
+	// draws are the random values the handlers consumed, in order.
+	draws    []uint32
+	doOver   bool
+	blockDir Direction
+	setBlock bool
+	// tideHeld reports an enemy FloodWake in play, which cancels a drift.
+	tideHeld  bool
 	telemetry resolveTelemetry
 }
 
@@ -174,8 +288,15 @@ type resolveState struct {
 	bastion    [2]bool
 	sturdy     [2]bool
 	override   Ability
+	removals   uint8
 }
 
+// abilityHandler runs one ability during resolution. Handlers are listed in
+// abilityMetaTable and read and write the turn through resolveContext,
+// resolveState and resolveResult, all unexported: the table is closed, so
+// there is no outside handler for a public move-state contract to serve.
+// This is the engine's only ability pipeline; ScatterShot's removals, for
+// one, happen here and nowhere else.
 type abilityHandler func(*resolveContext, *resolveResult, *resolveState, abilitySource)
 
 type abilityMeta struct {
@@ -205,7 +326,45 @@ var abilityMetaTable = [abilityCountInt]abilityMeta{
 	AbilitySadist:        {phaseResolution, 1, handleSadist},
 }
 
-type abilityResolver struct{}
+// handlerCaps is what a handler lets its holder do beyond an ordinary move,
+// as reported by CapabilitiesOf. Abilities missing from abilityCapsTable
+// declare nothing.
+type handlerCaps struct {
+	specialMoves  []string
+	captureVetoes []string
+}
+
+var abilityCapsTable = [abilityCountInt]handlerCaps{
+	AbilityBlockPath: {specialMoves: []string{SpecialMoveFacing}, captureVetoes: []string{BlockRuleFacing}},
+}
+
+// maxGuardedHandlers bounds the handlers one engine may have running under a
+// deadline at once: the one in flight plus any abandoned past their deadline
+// and still running. Once that many are out, dispatch refuses with
+// ErrHandlerBusy instead of starting another goroutine.
+const maxGuardedHandlers = 4
+
+// handlerGuard configures optional execution guards around handler dispatch.
+// The zero value dispatches directly with no allocation.
+type handlerGuard struct {
+	timeout time.Duration
+	isolate bool
+	// slots holds a token per handler running under timeout; a handler
+	// returns its token when it finishes, not when it is abandoned.
+	slots chan struct{}
+}
+
+func newHandlerGuard(timeout time.Duration, isolate bool) handlerGuard {
+	g := handlerGuard{timeout: timeout, isolate: isolate || timeout > 0}
+	if timeout > 0 {
+		g.slots = make(chan struct{}, maxGuardedHandlers)
+	}
+	return g
+}
+
+type abilityResolver struct {
+	guard handlerGuard
+}
 
 func newAbilityResolver() abilityResolver { return abilityResolver{} }
 
@@ -216,12 +375,23 @@ func (r abilityResolver) resolve(ctx resolveContext) (resolveResult, error) {
 		return res, err
 	}
 	r.collect(&ctx, &state)
-	r.runPhase(&ctx, &state, phaseElemental, &ctx.elemental, &res)
-	r.runPhase(&ctx, &state, phaseAugmentor, &ctx.augmentor, &res)
-	r.runPhase(&ctx, &state, phaseOffense, &ctx.offense, &res)
-	r.runPhase(&ctx, &state, phaseTemporal, &ctx.temporal, &res)
-	r.runPhase(&ctx, &state, phaseResolution, &ctx.resolution, &res)
+	if err := r.runPhase(&ctx, &state, phaseElemental, &ctx.elemental, &res); err != nil {
+		return res, err
+	}
+	if err := r.runPhase(&ctx, &state, phaseAugmentor, &ctx.augmentor, &res); err != nil {
+		return res, err
+	}
+	if err := r.runPhase(&ctx, &state, phaseOffense, &ctx.offense, &res); err != nil {
+		return res, err
+	}
+	if err := r.runPhase(&ctx, &state, phaseTemporal, &ctx.temporal, &res); err != nil {
+		return res, err
+	}
+	if err := r.runPhase(&ctx, &state, phaseResolution, &ctx.resolution, &res); err != nil {
+		return res, err
+	}
 	r.finalize(&ctx, &state, &res)
+	res.draws = ctx.rng.recorded()
 	return res, nil
 }
 
@@ -243,19 +413,23 @@ func (abilityResolver) initState(ctx *resolveContext) (resolveState, error) {
 	if enemyPieceMask == 0 {
 		enemyPieceMask = ctx.enemyMask
 	}
+	moverCombined := (moverPieceMask | ctx.sideMask) &^ ctx.ineligible[ctx.board.types[ctx.mover]]
+	enemyCombined := enemyPieceMask | ctx.enemyMask
+	if ctx.captureIdx >= 0 {
+		enemyCombined &^= ctx.ineligible[ctx.board.types[ctx.captureIdx]]
+	}
 	state.sides[moverIdx] = sideState{
 		mask:     ctx.sideMask,
 		piece:    moverPieceMask,
-		combined: moverPieceMask | ctx.sideMask,
+		combined: moverCombined,
 		element:  ctx.sideElement,
 	}
 	state.sides[enemyIdx] = sideState{
 		mask:     ctx.enemyMask,
 		piece:    enemyPieceMask,
-		combined: enemyPieceMask | ctx.enemyMask,
+		combined: enemyCombined,
 		element:  ctx.enemyElement,
 	}
-	moverCombined := state.sides[moverIdx].combined
 	if moverCombined.Has(AbilityMistShroud) && moverCombined.Has(AbilityRadiantVision) {
 		return state, ErrConflictingAugmentors
 	}
@@ -263,6 +437,8 @@ func (abilityResolver) initState(ctx *resolveContext) (resolveState, error) {
 		return state, ErrInvalidOverload
 	}
 	ctx.rng = newRNG(ctx.seed ^ uint64(ctx.board.ids[ctx.mover])<<1 ^ uint64(ctx.target))
+	ctx.rng.replay = ctx.replayDraws
+	state.removals = ctx.removalBudget
 	state.floodWake[moverIdx] = moverCombined.Has(AbilityFloodWake)
 	state.floodWake[enemyIdx] = state.sides[enemyIdx].combined.Has(AbilityFloodWake)
 	state.tailwind[moverIdx] = moverCombined.Has(AbilityTailwind)
@@ -321,9 +497,9 @@ func abilityResolverQueue(ctx *resolveContext, state *resolveState, color Color,
 	}
 }
 
-func (r abilityResolver) runPhase(ctx *resolveContext, state *resolveState, phase abilityPhase, scratch *phaseScratch, res *resolveResult) {
+func (r abilityResolver) runPhase(ctx *resolveContext, state *resolveState, phase abilityPhase, scratch *phaseScratch, res *resolveResult) error {
 	if scratch.count == 0 {
-		return
+		return nil
 	}
 	scratch.sort()
 	for i := uint8(0); i < scratch.count; i++ {
@@ -334,10 +510,92 @@ func (r abilityResolver) runPhase(ctx *resolveContext, state *resolveState, phas
 		meta := abilityMetaTable[int(ability)]
 		if meta.handler != nil {
 			src := abilitySource{color: owner, mask: state.sides[idx].combined, piece: piece}
-			meta.handler(ctx, res, state, src)
+			var started time.Time
+			if ctx.timings != nil {
+				started = time.Now()
+			}
+			err := r.dispatch(ability, meta.handler, ctx, res, state, src)
+			if ctx.timings != nil {
+				*ctx.timings = append(*ctx.timings, HandlerTiming{Ability: ability, Owner: owner, Elapsed: time.Since(started), Err: err})
+			}
+			if err != nil {
+				return err
+			}
 		}
 		res.telemetry.phaseLogs[int(phase)].record(ability, owner)
 	}
+	return nil
+}
+
+// dispatch invokes a single handler under the configured guard.
+func (r abilityResolver) dispatch(ability Ability, h abilityHandler, ctx *resolveContext, res *resolveResult, state *resolveState, src abilitySource) error {
+	if r.guard.timeout <= 0 {
+		if !r.guard.isolate {
+			h(ctx, res, state, src)
+			return nil
+		}
+		return callIsolated(ability, h, ctx, res, state, src)
+	}
+	select {
+	case r.guard.slots <- struct{}{}:
+	default:
+		return &HandlerError{Ability: ability, Err: ErrHandlerBusy}
+	}
+	// Run on private copies, slices included, so a handler abandoned past its
+	// deadline cannot race with the engine; results are copied back only on
+	// success.
+	board := *ctx.board
+	doOver := *ctx.doOverUsed
+	local := *ctx
+	local.board = &board
+	local.doOverUsed = &doOver
+	var charges [2]uint16
+	if ctx.charges != nil {
+		charges = *ctx.charges
+		local.charges = &charges
+	}
+	local.replayDraws = append([]uint32(nil), ctx.replayDraws...)
+	local.rng.replay = append([]uint32(nil), ctx.rng.replay...)
+	local.timings = nil
+	localRes := *res
+	localRes.draws = append([]uint32(nil), res.draws...)
+	localState := *state
+	done := make(chan error, 1)
+	go func() {
+		defer func() { <-r.guard.slots }()
+		done <- callIsolated(ability, h, &local, &localRes, &localState, src)
+	}()
+	timer := time.NewTimer(r.guard.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+	case <-timer.C:
+		return &HandlerError{Ability: ability, Err: ErrHandlerTimeout}
+	}
+	boardPtr, doOverPtr, chargesPtr, timings := ctx.board, ctx.doOverUsed, ctx.charges, ctx.timings
+	*boardPtr = board
+	*doOverPtr = doOver
+	if chargesPtr != nil {
+		*chargesPtr = charges
+	}
+	*ctx = local
+	ctx.board, ctx.doOverUsed, ctx.charges, ctx.timings = boardPtr, doOverPtr, chargesPtr, timings
+	*res = localRes
+	*state = localState
+	return nil
+}
+
+func callIsolated(ability Ability, h abilityHandler, ctx *resolveContext, res *resolveResult, state *resolveState, src abilitySource) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = &HandlerError{Ability: ability, Err: ErrHandlerPanic}
+		}
+	}()
+	h(ctx, res, state, src)
+	return nil
 }
 
 func (abilityResolver) finalize(ctx *resolveContext, state *resolveState, res *resolveResult) {
@@ -364,6 +622,7 @@ func (abilityResolver) finalize(ctx *resolveContext, state *resolveState, res *r
 	if res.doOver {
 		res.telemetry.floodWakePersistent = res.telemetry.floodWakePersistent || state.floodWake[enemyIdx]
 	}
+	res.tideHeld = state.floodWake[enemyIdx]
 }
 
 func handleDoOver(ctx *resolveContext, res *resolveResult, state *resolveState, src abilitySource) {
@@ -371,10 +630,17 @@ func handleDoOver(ctx *resolveContext, res *resolveResult, state *resolveState,
 		return
 	}
 	color := ctx.board.colors[ctx.captureIdx]
+	if color != src.color {
+		// DoOver only protects its owner's pieces.
+		return
+	}
 	idx := color.Index()
 	if (*ctx.doOverUsed)[idx] {
 		return
 	}
+	if !ctx.spendCharge(color, AbilityDoOver) {
+		return
+	}
 	(*ctx.doOverUsed)[idx] = true
 	res.doOver = true
 	if state.floodWake[idx] {
@@ -408,6 +674,9 @@ func handleTailwind(_ *resolveContext, res *resolveResult, state *resolveState,
 }
 
 func handleScatterShot(ctx *resolveContext, res *resolveResult, state *resolveState, src abilitySource) {
+	if !ctx.spendCharge(src.color, AbilityScatterShot) {
+		return
+	}
 	enemy := src.color.Opposite()
 	dirs := [][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
 	start := int(ctx.rng.next() % uint32(len(dirs)))
@@ -421,12 +690,32 @@ func handleScatterShot(ctx *resolveContext, res *resolveResult, state *resolveSt
 		if idx < 0 || ctx.board.colors[idx] != enemy {
 			continue
 		}
-		ctx.board.removePiece(idx)
+		if !state.claimRemoval(ctx, res, AbilityScatterShot, idx) {
+			return
+		}
 		if res.telemetry.scatterHits < 4 {
 			res.telemetry.scatterHits++
 		}
 	}
-	_ = state
+}
+
+// claimRemoval removes the piece in slot idx on behalf of ability if the
+// turn's removal budget allows it. Handlers run in phase and priority order,
+// so abilityMetaTable decides who gets the budget first; the first ability
+// turned away is recorded in telemetry.
+func (state *resolveState) claimRemoval(ctx *resolveContext, res *resolveResult, ability Ability, idx int) bool {
+	t := &res.telemetry
+	if state.removals == 0 {
+		if t.removalDenied == AbilityNone {
+			t.removalDenied = ability
+		}
+		return false
+	}
+	state.removals--
+	ctx.board.removePiece(idx)
+	t.removals[t.removalCount] = removalClaim{ability: ability, piece: int8(idx)}
+	t.removalCount++
+	return true
 }
 
 func handleOverload(ctx *resolveContext, res *resolveResult, state *resolveState, src abilitySource) {
@@ -438,7 +727,7 @@ func handleOverload(ctx *resolveContext, res *resolveResult, state *resolveState
 		}
 		mask := ctx.board.ability[i]
 		if mask == 0 {
-			mask = state.sides[idx].mask
+			mask = state.sides[idx].mask &^ ctx.ineligible[ctx.board.types[i]]
 		}
 		ability := firstAbility(mask)
 		if ability == AbilityNone {
@@ -1,7 +1,11 @@
 // path: chessTest/internal/game/ability_resolver_test.go
 package game
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+)
 
 func newEmptyBoard() boardSoA {
 	var b boardSoA
@@ -101,6 +105,23 @@ func TestAbilityResolverPhases(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "do-over ignores the owner's own captures",
+			side: NewAbilitySet(AbilityDoOver),
+			setup: func(b *boardSoA, ctx *resolveContext) {
+				addPiece(b, 1, 2, Black, Pawn, ctx.target)
+				ctx.captureIdx = 1
+				b.removePiece(1)
+			},
+			expect: func(t *testing.T, res resolveResult, err error, _ *boardSoA, ctx *resolveContext) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if res.doOver || ctx.doOverUsed[Black.Index()] {
+					t.Fatalf("white's do-over must not protect black's piece")
+				}
+			},
+		},
 		{
 			name: "mist tailwind synergy",
 			side: NewAbilitySet(AbilityMistShroud, AbilityTailwind),
@@ -147,6 +168,59 @@ func TestAbilityResolverPhases(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "scorch and scatter share the removal budget",
+			side: NewAbilitySet(AbilityScorch, AbilityScatterShot),
+			setup: func(b *boardSoA, ctx *resolveContext) {
+				addPiece(b, 1, 3, Black, Pawn, SquareF4)
+				addPiece(b, 2, 4, Black, Pawn, SquareE5)
+				addPiece(b, 3, 5, Black, Pawn, SquareD4)
+				ctx.removalBudget = 1
+			},
+			expect: func(t *testing.T, res resolveResult, err error, b *boardSoA, _ *resolveContext) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				log := res.telemetry.phaseLogs[phaseElemental]
+				if log.count != 1 || log.abilities[0] != AbilityScorch {
+					t.Fatalf("expected scorch to resolve in the elemental phase, got %+v", log)
+				}
+				if res.telemetry.firewallCount != 4 {
+					t.Fatalf("expected scorch firewall, got %d squares", res.telemetry.firewallCount)
+				}
+				if res.telemetry.removalCount != 1 || res.telemetry.removals[0].ability != AbilityScatterShot {
+					t.Fatalf("expected scatter shot to consume the single removal, got %+v", res.telemetry.removals[:res.telemetry.removalCount])
+				}
+				if res.telemetry.removalDenied != AbilityScatterShot {
+					t.Fatalf("expected denied removal recorded, got %s", res.telemetry.removalDenied)
+				}
+				alive := 0
+				for i := 1; i <= 3; i++ {
+					if b.alive[i] {
+						alive++
+					}
+				}
+				if alive != 2 {
+					t.Fatalf("expected exactly one removal, %d enemies left", 3-alive)
+				}
+			},
+		},
+		{
+			name: "removals disabled",
+			side: NewAbilitySet(AbilityScatterShot),
+			setup: func(b *boardSoA, ctx *resolveContext) {
+				addPiece(b, 1, 3, Black, Pawn, SquareF4)
+				ctx.removalBudget = 0
+			},
+			expect: func(t *testing.T, res resolveResult, err error, b *boardSoA, _ *resolveContext) {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !b.alive[1] || res.telemetry.removalCount != 0 || res.telemetry.scatterHits != 0 {
+					t.Fatalf("expected no removal without budget")
+				}
+			},
+		},
 		{
 			name: "overload assignments",
 			side: NewAbilitySet(AbilityOverload),
@@ -304,17 +378,18 @@ func TestAbilityResolverPhases(t *testing.T) {
 			board.ability[0] = tc.side
 			doOver := [2]bool{}
 			ctx := resolveContext{
-				board:        &board,
-				mover:        0,
-				target:       SquareE4,
-				captureIdx:   -1,
-				sideMask:     tc.side,
-				enemyMask:    tc.enemy,
-				doOverUsed:   &doOver,
-				requestedDir: DirE,
-				sideElement:  ElementFire,
-				enemyElement: ElementWater,
-				seed:         0xACE0FACE,
+				board:         &board,
+				mover:         0,
+				target:        SquareE4,
+				captureIdx:    -1,
+				sideMask:      tc.side,
+				enemyMask:     tc.enemy,
+				doOverUsed:    &doOver,
+				requestedDir:  DirE,
+				sideElement:   ElementFire,
+				enemyElement:  ElementWater,
+				seed:          0xACE0FACE,
+				removalBudget: DefaultExtraRemovals,
 			}
 			if tc.setup != nil {
 				tc.setup(&board, &ctx)
@@ -325,3 +400,180 @@ func TestAbilityResolverPhases(t *testing.T) {
 		})
 	}
 }
+
+// swapHandler runs h in place of ability's handler until the test ends. The
+// handler table is fixed at compile time, so this is the only way to stand in
+// a handler; tests that use it must not run in parallel.
+func swapHandler(t *testing.T, ability Ability, h abilityHandler) {
+	t.Helper()
+	orig := abilityMetaTable[ability].handler
+	abilityMetaTable[ability].handler = h
+	t.Cleanup(func() { abilityMetaTable[ability].handler = orig })
+}
+
+func TestResolverHandlerGuards(t *testing.T) {
+	cases := []struct {
+		name    string
+		guard   handlerGuard
+		handler abilityHandler
+		want    error
+	}{
+		{
+			name:  "slow handler times out",
+			guard: newHandlerGuard(5*time.Millisecond, true),
+			handler: func(ctx *resolveContext, _ *resolveResult, _ *resolveState, _ abilitySource) {
+				time.Sleep(200 * time.Millisecond)
+				ctx.board.removePiece(ctx.mover)
+			},
+			want: ErrHandlerTimeout,
+		},
+		{
+			name:  "panic isolated",
+			guard: handlerGuard{isolate: true},
+			handler: func(*resolveContext, *resolveResult, *resolveState, abilitySource) {
+				panic("boom")
+			},
+			want: ErrHandlerPanic,
+		},
+		{
+			name:  "panic isolated under deadline",
+			guard: newHandlerGuard(time.Second, true),
+			handler: func(*resolveContext, *resolveResult, *resolveState, abilitySource) {
+				panic("boom")
+			},
+			want: ErrHandlerPanic,
+		},
+		{
+			name:  "fast handler passes",
+			guard: newHandlerGuard(time.Second, true),
+			handler: func(_ *resolveContext, res *resolveResult, _ *resolveState, _ abilitySource) {
+				res.telemetry.raijinFollow = true
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			swapHandler(t, AbilityRaijin, tc.handler)
+			board := newEmptyBoard()
+			addPiece(&board, 0, 1, White, Knight, SquareD4)
+			board.ability[0] = NewAbilitySet(AbilityRaijin)
+			doOver := [2]bool{}
+			ctx := resolveContext{
+				board:         &board,
+				mover:         0,
+				target:        SquareE4,
+				captureIdx:    -1,
+				sideMask:      NewAbilitySet(AbilityRaijin),
+				doOverUsed:    &doOver,
+				seed:          1,
+				removalBudget: DefaultExtraRemovals,
+			}
+			resolver := abilityResolver{guard: tc.guard}
+			res, err := resolver.resolve(ctx)
+			if tc.want == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !res.telemetry.raijinFollow {
+					t.Fatalf("expected handler result copied back")
+				}
+				return
+			}
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+			var herr *HandlerError
+			if !errors.As(err, &herr) || herr.Ability != AbilityRaijin {
+				t.Fatalf("expected HandlerError for Raijin, got %v", err)
+			}
+			if !board.alive[0] {
+				t.Fatalf("abandoned handler mutated the live board")
+			}
+		})
+	}
+}
+
+func TestResolverBoundsAbandonedHandlers(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	swapHandler(t, AbilityRaijin, func(ctx *resolveContext, _ *resolveResult, _ *resolveState, _ abilitySource) {
+		<-release
+		ctx.replayDraws[0] = 99
+	})
+	guard := newHandlerGuard(time.Millisecond, true)
+	replay := []uint32{7}
+	resolve := func() error {
+		board := newEmptyBoard()
+		addPiece(&board, 0, 1, White, Knight, SquareD4)
+		board.ability[0] = NewAbilitySet(AbilityRaijin)
+		doOver := [2]bool{}
+		_, err := abilityResolver{guard: guard}.resolve(resolveContext{
+			board:         &board,
+			mover:         0,
+			target:        SquareE4,
+			captureIdx:    -1,
+			sideMask:      NewAbilitySet(AbilityRaijin),
+			doOverUsed:    &doOver,
+			seed:          1,
+			removalBudget: DefaultExtraRemovals,
+			replayDraws:   replay,
+		})
+		return err
+	}
+	cases := []struct {
+		name string
+		runs int
+		want error
+	}{
+		{name: "each handler abandoned", runs: maxGuardedHandlers, want: ErrHandlerTimeout},
+		{name: "no slot left", runs: 1, want: ErrHandlerBusy},
+	}
+	for _, tc := range cases {
+		for i := 0; i < tc.runs; i++ {
+			if err := resolve(); !errors.Is(err, tc.want) {
+				t.Fatalf("%s: run %d: expected %v, got %v", tc.name, i+1, tc.want, err)
+			}
+		}
+	}
+	if len(guard.slots) != maxGuardedHandlers {
+		t.Fatalf("slots in use = %d want %d", len(guard.slots), maxGuardedHandlers)
+	}
+	release <- struct{}{}
+	for deadline := time.Now().Add(time.Second); len(guard.slots) == maxGuardedHandlers; {
+		if time.Now().After(deadline) {
+			t.Fatalf("finished handler kept its slot")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if replay[0] != 7 {
+		t.Fatalf("abandoned handler wrote the caller's replay draws: %v", replay)
+	}
+}
+
+func TestEngineRollsBackOnHandlerTimeout(t *testing.T) {
+	swapHandler(t, AbilityRaijin, func(*resolveContext, *resolveResult, *resolveState, abilitySource) {
+		time.Sleep(200 * time.Millisecond)
+	})
+
+	eng := NewEngine()
+	if err := eng.SetRules(RulesConfig{HandlerTimeout: 5 * time.Millisecond}); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	if err := eng.SetSideConfig(White, AbilityList{AbilityRaijin}, ElementLightning); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	before := eng.Placement()
+	err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4})
+	if !errors.Is(err, ErrHandlerTimeout) {
+		t.Fatalf("expected handler timeout, got %v", err)
+	}
+	if got := eng.Placement(); got != before {
+		t.Fatalf("board not rolled back: %s", got)
+	}
+	if eng.State().Turn != White {
+		t.Fatalf("turn advanced after aborted move")
+	}
+	if err := eng.SetRules(RulesConfig{HandlerTimeout: -time.Second}); err != ErrInvalidRules {
+		t.Fatalf("expected ErrInvalidRules, got %v", err)
+	}
+}
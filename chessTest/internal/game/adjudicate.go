@@ -0,0 +1,258 @@
+// path: chessTest/internal/game/adjudicate.go
+package game
+
+import (
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// AdjudicationRules end a game early once its result is no longer in doubt,
+// so bot tournaments do not play out trivially won endings. The zero value
+// never adjudicates.
+type AdjudicationRules struct {
+	// Threshold is the Evaluate score, in centipawns, one side must lead by
+	// for Plies consecutive plies to be awarded the game. Both must be set
+	// for score adjudication.
+	Threshold int
+	Plies     int
+	// Tablebase solves endings with at most TablebasePawns pawns and no
+	// abilities loaded, and ends the game as soon as the result with best
+	// play is known.
+	Tablebase bool
+}
+
+// Enabled reports whether r can ever adjudicate a game.
+func (r AdjudicationRules) Enabled() bool {
+	return (r.Threshold > 0 && r.Plies > 0) || r.Tablebase
+}
+
+// maxAdjudicationPlies bounds AdjudicationRules.Plies.
+const maxAdjudicationPlies = 1000
+
+func (r AdjudicationRules) validate() error {
+	if r.Threshold < 0 || r.Plies < 0 || r.Plies > maxAdjudicationPlies {
+		return ErrInvalidRules
+	}
+	return nil
+}
+
+// AdjudicationKind says what decided an adjudicated game.
+type AdjudicationKind uint8
+
+const (
+	// AdjudicatedScore: one side led by the threshold for the required plies.
+	AdjudicatedScore AdjudicationKind = iota + 1
+	// AdjudicatedTablebase: the ending was solved.
+	AdjudicatedTablebase
+)
+
+var adjudicationKindNames = [...]string{
+	AdjudicatedScore:     "score",
+	AdjudicatedTablebase: "tablebase",
+}
+
+func (k AdjudicationKind) String() string {
+	if int(k) < len(adjudicationKindNames) && adjudicationKindNames[k] != "" {
+		return adjudicationKindNames[k]
+	}
+	return "unknown"
+}
+
+// Adjudication records why the engine ended a game early.
+type Adjudication struct {
+	Kind   AdjudicationKind
+	Result GameStatus
+	// Ply is the ply the game was adjudicated after.
+	Ply uint32
+	// Score is the Evaluate score at that ply, from White's point of view.
+	Score int
+	// Streak is how many consecutive plies the winner led by the threshold;
+	// zero for tablebase results.
+	Streak int
+	Reason string
+}
+
+// Adjudicated reports how the game was adjudicated, if it was.
+func (e *Engine) Adjudicated() (Adjudication, bool) {
+	if e.adjudication == nil {
+		return Adjudication{}, false
+	}
+	return *e.adjudication, true
+}
+
+// adjudicate runs after by's completed turn. The score streak is signed:
+// positive while White leads by the threshold, negative while Black does.
+func (e *Engine) adjudicate(by Color, ply uint32) {
+	rules := e.rules.Adjudication
+	if e.status != StatusActive || !rules.Enabled() {
+		return
+	}
+	if rules.Tablebase {
+		if result, ok := e.solveEnding(); ok {
+			reason := "adjudicated by tablebase: " + result.verdict() + " with best play"
+			e.adjudicateAs(by, Adjudication{Kind: AdjudicatedTablebase, Result: result, Ply: ply, Score: e.Evaluate().Score, Reason: reason})
+			return
+		}
+	}
+	if rules.Threshold == 0 || rules.Plies == 0 {
+		return
+	}
+	score := e.Evaluate().Score
+	switch {
+	case score >= rules.Threshold:
+		e.adjStreak = max(e.adjStreak, 0) + 1
+	case score <= -rules.Threshold:
+		e.adjStreak = min(e.adjStreak, 0) - 1
+	default:
+		e.adjStreak = 0
+	}
+	if abs(e.adjStreak) < rules.Plies {
+		return
+	}
+	winner, result := White, StatusWhiteWins
+	if e.adjStreak < 0 {
+		winner, result = Black, StatusBlackWins
+	}
+	reason := fmt.Sprintf("adjudicated: %s led by at least %d centipawns for %d plies (score %+d)", winner, rules.Threshold, abs(e.adjStreak), score)
+	e.adjudicateAs(by, Adjudication{Kind: AdjudicatedScore, Result: result, Ply: ply, Score: score, Streak: abs(e.adjStreak), Reason: reason})
+}
+
+func (e *Engine) adjudicateAs(by Color, a Adjudication) {
+	e.adjudication = &a
+	e.finish(a.Result, a.Reason, by, a.Ply)
+}
+
+func (s GameStatus) verdict() string {
+	switch s {
+	case StatusWhiteWins:
+		return "white wins"
+	case StatusBlackWins:
+		return "black wins"
+	}
+	return "drawn"
+}
+
+// TablebasePawns is the most pawns an ending may hold for the tablebase to
+// solve it. Only pawns move, and only forward, so such an ending has a small,
+// finite game tree.
+const TablebasePawns = 4
+
+// tablebaseNodes bounds one solve; an ending that needs more is left unknown.
+const tablebaseNodes = 20000
+
+// solveEnding reports the result of the position with best play, when the
+// position is small enough to solve and every line of it ends. Abilities
+// are out of scope: their facings and drifts are choices LegalMoves does not
+// enumerate, so endings with a loadout are never solved.
+func (e *Engine) solveEnding() (GameStatus, bool) {
+	pawns := 0
+	for c := range e.board.pieceMask {
+		pawns += bits.OnesCount64(e.board.pieceMask[c][Pawn])
+	}
+	if pawns > TablebasePawns || pawns == 0 || e.locked || e.rules.Standard() {
+		return 0, false
+	}
+	for i := range e.abilityLists {
+		if len(e.abilityLists[i]) > 0 || len(e.typeAbilities[i]) > 0 {
+			return 0, false
+		}
+	}
+	snap := e.Snapshot()
+	// Time is irrelevant to the solved result; a flag falling mid-search
+	// would only cut lines short.
+	snap.Rules.TimeControl = TimeControl{}
+	snap.Rules.Adjudication = AdjudicationRules{}
+	snap.MoveLog, snap.Events = nil, nil
+	scratch := NewEngine(WithClock(func() time.Time { return snap.TurnStart }))
+	if err := scratch.Restore(snap); err != nil {
+		return 0, false
+	}
+	s := endingSolver{eng: scratch, memo: make(map[uint64]endingValue)}
+	v := s.solve()
+	switch v {
+	case endingWhite:
+		return StatusWhiteWins, true
+	case endingBlack:
+		return StatusBlackWins, true
+	case endingDraw:
+		return StatusDraw, true
+	}
+	return 0, false
+}
+
+type endingValue uint8
+
+const (
+	endingUnknown endingValue = iota
+	endingWhite
+	endingBlack
+	endingDraw
+)
+
+type endingSolver struct {
+	eng   *Engine
+	memo  map[uint64]endingValue
+	nodes int
+}
+
+// solve is a minimax over the solver's engine. A line is unknown when it
+// reaches a side with no legal move, which the rules leave undecided, or runs
+// past the node budget; the side to move wins if any line wins for it, and
+// otherwise the result is known only when every line is.
+func (s *endingSolver) solve() endingValue {
+	e := s.eng
+	switch e.status {
+	case StatusWhiteWins:
+		return endingWhite
+	case StatusBlackWins:
+		return endingBlack
+	case StatusDraw:
+		return endingDraw
+	}
+	key := e.PositionKey()
+	if v, ok := s.memo[key]; ok {
+		return v
+	}
+	s.nodes++
+	if s.nodes > tablebaseNodes {
+		return endingUnknown
+	}
+	mover, win := e.Turn(), endingWhite
+	if mover == Black {
+		win = endingBlack
+	}
+	moves := e.LegalMoves()
+	best := endingUnknown
+	known := len(moves) > 0
+	snap := e.Snapshot()
+	for _, mv := range moves {
+		if err := e.Move(mv); err != nil {
+			known = false
+			_ = e.Restore(snap)
+			continue
+		}
+		v := s.solve()
+		_ = e.Restore(snap)
+		switch {
+		case v == win:
+			best, known = win, true
+		case v == endingUnknown:
+			known = false
+		case v == endingDraw || best == endingUnknown:
+			if best != win {
+				best = v
+			}
+		}
+		if best == win {
+			break
+		}
+	}
+	if !known {
+		best = endingUnknown
+	}
+	if best != endingUnknown || s.nodes <= tablebaseNodes {
+		s.memo[key] = best
+	}
+	return best
+}
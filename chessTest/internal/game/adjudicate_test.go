@@ -0,0 +1,110 @@
+// path: chessTest/internal/game/adjudicate_test.go
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdjudication(t *testing.T) {
+	cases := []struct {
+		name       string
+		rules      AdjudicationRules
+		placement  string
+		moves      []MoveRequest
+		wantStatus GameStatus
+		wantKind   AdjudicationKind
+		wantReason string
+	}{
+		{
+			name:      "score streak",
+			rules:     AdjudicationRules{Threshold: 300, Plies: 3},
+			placement: "4k3/7p/8/8/8/8/PPPPPPPP/4K3",
+			moves: []MoveRequest{
+				{From: SquareA2, To: SquareA3},
+				{From: SquareH7, To: SquareH6},
+				{From: SquareB2, To: SquareB3},
+			},
+			wantStatus: StatusWhiteWins,
+			wantKind:   AdjudicatedScore,
+			wantReason: "white led by at least 300 centipawns for 3 plies",
+		},
+		{
+			name:      "streak not reached",
+			rules:     AdjudicationRules{Threshold: 300, Plies: 4},
+			placement: "4k3/7p/8/8/8/8/PPPPPPPP/4K3",
+			moves: []MoveRequest{
+				{From: SquareA2, To: SquareA3},
+				{From: SquareH7, To: SquareH6},
+				{From: SquareB2, To: SquareB3},
+			},
+			wantStatus: StatusActive,
+		},
+		{
+			name:       "solved ending",
+			rules:      AdjudicationRules{Tablebase: true},
+			placement:  "8/7p/4k3/8/3P4/8/8/K7",
+			moves:      []MoveRequest{{From: SquareD4, To: SquareD5}},
+			wantStatus: StatusWhiteWins,
+			wantKind:   AdjudicatedTablebase,
+			wantReason: "adjudicated by tablebase: white wins with best play",
+		},
+		{
+			name:       "too many pawns to solve",
+			rules:      AdjudicationRules{Tablebase: true},
+			placement:  "8/5ppp/4k3/8/3P4/8/P7/K7",
+			moves:      []MoveRequest{{From: SquareD4, To: SquareD5}},
+			wantStatus: StatusActive,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			rules := DefaultRules()
+			rules.Adjudication = tc.rules
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("set rules: %v", err)
+			}
+			if err := eng.LoadPlacement(tc.placement, White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			for _, mv := range tc.moves {
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %s-%s: %v", SquareToCoord(mv.From), SquareToCoord(mv.To), err)
+				}
+			}
+			if got := eng.State().Status; got != tc.wantStatus {
+				t.Fatalf("status = %s want %s", got, tc.wantStatus)
+			}
+			adj, ok := eng.Adjudicated()
+			if ok != (tc.wantKind != 0) {
+				t.Fatalf("Adjudicated() ok = %v, record %+v", ok, adj)
+			}
+			if !ok {
+				return
+			}
+			if adj.Kind != tc.wantKind || adj.Result != tc.wantStatus || !strings.Contains(adj.Reason, tc.wantReason) {
+				t.Fatalf("unexpected adjudication %+v", adj)
+			}
+			restored := NewEngine()
+			if err := restored.Restore(eng.Snapshot()); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			if got, _ := restored.Adjudicated(); got != adj {
+				t.Fatalf("restored adjudication %+v want %+v", got, adj)
+			}
+		})
+	}
+}
+
+func TestAdjudicationRulesValidated(t *testing.T) {
+	rules := DefaultRules()
+	rules.Adjudication = AdjudicationRules{Threshold: -1, Plies: 3}
+	if err := NewEngine().SetRules(rules); err != ErrInvalidRules {
+		t.Fatalf("expected ErrInvalidRules, got %v", err)
+	}
+	rules.Adjudication.Threshold = 300
+	if DefaultRules().Fingerprint() == rules.Fingerprint() {
+		t.Fatalf("adjudication does not change the rules fingerprint")
+	}
+}
@@ -0,0 +1,122 @@
+// path: chessTest/internal/game/attacks.go
+package game
+
+import "math/bits"
+
+var (
+	knightAttacks [64]uint64
+	kingAttacks   [64]uint64
+	pawnAttacks   [2][64]uint64
+)
+
+var (
+	rookRays   = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	bishopRays = [4][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+)
+
+// rookLines and bishopLines hold, per square, the squares along each of
+// rookRays and bishopRays on an empty board.
+var rookLines, bishopLines [64][4]uint64
+
+func init() {
+	knightSteps := [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps := [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	for sq := Square(0); sq <= SquareH8; sq++ {
+		for _, d := range knightSteps {
+			if to := offsetSquare(sq, d[0], d[1]); to != SquareInvalid {
+				knightAttacks[sq] |= uint64(1) << uint(to)
+			}
+		}
+		for _, d := range kingSteps {
+			if to := offsetSquare(sq, d[0], d[1]); to != SquareInvalid {
+				kingAttacks[sq] |= uint64(1) << uint(to)
+			}
+		}
+		for i := range rookRays {
+			rookLines[sq][i] = rayMask(sq, rookRays[i])
+			bishopLines[sq][i] = rayMask(sq, bishopRays[i])
+		}
+		for _, df := range [2]int{-1, 1} {
+			if to := offsetSquare(sq, 1, df); to != SquareInvalid {
+				pawnAttacks[White][sq] |= uint64(1) << uint(to)
+			}
+			if to := offsetSquare(sq, -1, df); to != SquareInvalid {
+				pawnAttacks[Black][sq] |= uint64(1) << uint(to)
+			}
+		}
+	}
+}
+
+// attacked reports whether any piece of color attacks sq under orthodox
+// chess movement, regardless of which moves this engine currently resolves.
+func (b *boardSoA) attacked(sq Square, by Color) bool {
+	if sq == SquareInvalid {
+		return false
+	}
+	c := by.Index()
+	mask := b.pieceMask[c]
+	if knightAttacks[sq]&mask[Knight] != 0 || kingAttacks[sq]&mask[King] != 0 {
+		return true
+	}
+	// A pawn of color by attacks sq if sq is in the opposite colour's pawn
+	// attack set from sq.
+	if pawnAttacks[by.Opposite()][sq]&mask[Pawn] != 0 {
+		return true
+	}
+	occ := b.occupancy[0] | b.occupancy[1]
+	if b.rayHits(sq, rookRays, &rookLines[sq], occ, mask[Rook]|mask[Queen]) {
+		return true
+	}
+	return b.rayHits(sq, bishopRays, &bishopLines[sq], occ, mask[Bishop]|mask[Queen])
+}
+
+func rayMask(sq Square, d [2]int) uint64 {
+	var mask uint64
+	for cur := offsetSquare(sq, d[0], d[1]); cur != SquareInvalid; cur = offsetSquare(cur, d[0], d[1]) {
+		mask |= uint64(1) << uint(cur)
+	}
+	return mask
+}
+
+func (b *boardSoA) rayHits(sq Square, rays [4][2]int, lines *[4]uint64, occ, sliders uint64) bool {
+	if sliders == 0 {
+		return false
+	}
+	for i, d := range rays {
+		if lines[i]&sliders == 0 {
+			continue
+		}
+		cur := sq
+		for {
+			cur = offsetSquare(cur, d[0], d[1])
+			if cur == SquareInvalid {
+				break
+			}
+			bit := uint64(1) << uint(cur)
+			if occ&bit == 0 {
+				continue
+			}
+			if sliders&bit != 0 {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// inCheck reports whether color's king is attacked.
+func (b *boardSoA) inCheck(color Color) bool {
+	kings := b.pieceMask[color.Index()][King]
+	if kings == 0 {
+		return false
+	}
+	return b.attacked(lowestSquare(kings), color.Opposite())
+}
+
+func lowestSquare(mask uint64) Square {
+	if mask == 0 {
+		return SquareInvalid
+	}
+	return Square(bits.TrailingZeros64(mask))
+}
@@ -0,0 +1,131 @@
+// path: chessTest/internal/game/attacks_test.go
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCheckEvents(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		moves     []MoveRequest
+		wantCheck []bool
+	}{
+		{
+			name:      "discovered by a capture",
+			placement: "4k3/8/8/8/8/3p4/4P3/4R1K1",
+			moves:     []MoveRequest{{From: SquareE2, To: SquareD3}},
+			wantCheck: []bool{true},
+		},
+		{
+			name:      "pawn steps into contact",
+			placement: "8/8/8/3k4/8/4P3/7p/4K3",
+			moves:     []MoveRequest{{From: SquareE3, To: SquareE4}, {From: SquareH2, To: SquareH1}},
+			wantCheck: []bool{true, false},
+		},
+		{
+			name:      "far from the king",
+			placement: "k7/8/8/8/8/8/6PP/6RK",
+			moves:     []MoveRequest{{From: SquareH2, To: SquareH3}},
+			wantCheck: []bool{false},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement(tc.placement, White); err != nil {
+				t.Fatalf("placement: %v", err)
+			}
+			for i, mv := range tc.moves {
+				before := len(eng.Events())
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %d: %v", i+1, err)
+				}
+				var checked bool
+				for _, ev := range eng.Events()[before:] {
+					checked = checked || ev.Kind == EventCheck
+				}
+				if checked != tc.wantCheck[i] {
+					t.Fatalf("move %d: check = %v want %v", i+1, checked, tc.wantCheck[i])
+				}
+			}
+		})
+	}
+}
+
+// attackedByWalk is attacked without the line tables: every ray is walked.
+func attackedByWalk(b *boardSoA, sq Square, by Color) bool {
+	mask := b.pieceMask[by.Index()]
+	if knightAttacks[sq]&mask[Knight] != 0 || kingAttacks[sq]&mask[King] != 0 || pawnAttacks[by.Opposite()][sq]&mask[Pawn] != 0 {
+		return true
+	}
+	occ := b.occupancy[0] | b.occupancy[1]
+	for i, rays := range [2][4][2]int{rookRays, bishopRays} {
+		sliders := mask[Queen] | mask[Rook]
+		if i == 1 {
+			sliders = mask[Queen] | mask[Bishop]
+		}
+		for _, d := range rays {
+			for cur := offsetSquare(sq, d[0], d[1]); cur != SquareInvalid; cur = offsetSquare(cur, d[0], d[1]) {
+				bit := uint64(1) << uint(cur)
+				if occ&bit != 0 {
+					if sliders&bit != 0 {
+						return true
+					}
+					break
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestAttackedMatchesRayWalk(t *testing.T) {
+	for _, b := range randomGameBoards(t) {
+		for sq := Square(0); sq <= SquareH8; sq++ {
+			for _, by := range [...]Color{White, Black} {
+				if got, want := b.attacked(sq, by), attackedByWalk(&b, sq, by); got != want {
+					t.Fatalf("%s attacked by %s = %v want %v at %s", SquareToCoord(sq), by, got, want, b.placement())
+				}
+			}
+		}
+	}
+}
+
+// randomGameBoards collects every position of twenty random games from a
+// position with open lines to both kings, as one long game would give.
+func randomGameBoards(tb testing.TB) []boardSoA {
+	tb.Helper()
+	var out []boardSoA
+	for seed := int64(1); seed <= 20; seed++ {
+		eng := NewEngine()
+		if err := eng.LoadPlacement("r2qk2r/pp1p1ppp/2n2n2/2b1p3/2B1P3/2N2N2/PP1P1PPP/R2QK2R", White); err != nil {
+			tb.Fatalf("placement: %v", err)
+		}
+		rng := rand.New(rand.NewSource(seed))
+		for ply := 0; ply < 200; ply++ {
+			moves := eng.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			if err := eng.Move(moves[rng.Intn(len(moves))]); err != nil {
+				tb.Fatalf("seed %d ply %d: %v", seed, ply, err)
+			}
+		}
+		out = append(append(out, eng.history...), eng.board)
+	}
+	return out
+}
+
+func BenchmarkInCheck(b *testing.B) {
+	boards := randomGameBoards(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range boards {
+			boards[j].inCheck(White)
+			boards[j].inCheck(Black)
+		}
+	}
+}
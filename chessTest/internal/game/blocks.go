@@ -0,0 +1,153 @@
+// path: chessTest/internal/game/blocks.go
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Capture-block rules reported by ExplainCaptureBlock.
+const (
+	BlockRuleFacing = "blockpath_facing"
+)
+
+// CaptureBlock names the rule that stops a capture and the piece it protects.
+type CaptureBlock struct {
+	Rule    string
+	Ability Ability
+	PieceID int
+	Facing  Direction
+	Reason  string
+}
+
+// ExplainCaptureBlock reports which rule, if any, prevents the piece on from
+// capturing the piece on to. It only inspects the position; whether the move
+// is otherwise legal is up to Move.
+//
+// BlockPath facing is the only capture block this arena enforces: a piece that
+// turned to face a direction shields itself from attackers arriving from that
+// direction or either neighbouring one.
+func (e *Engine) ExplainCaptureBlock(from, to Square) (CaptureBlock, bool) {
+	idx := e.board.pieceIndexBySquare(from)
+	target := e.board.pieceIndexBySquare(to)
+	if idx < 0 || target < 0 || e.board.colors[idx] == e.board.colors[target] {
+		return CaptureBlock{}, false
+	}
+	return e.captureBlock(idx, target)
+}
+
+func (e *Engine) captureBlock(idx, target int) (CaptureBlock, bool) {
+	id := e.board.ids[target]
+	facing, ok := e.blockFacing[id]
+	if !ok || facing == DirNone {
+		return CaptureBlock{}, false
+	}
+	approach := directionTo(e.board.squares[target], e.board.squares[idx])
+	if approach == DirNone || !withinArc(facing, approach) {
+		return CaptureBlock{}, false
+	}
+	return CaptureBlock{
+		Rule:    BlockRuleFacing,
+		Ability: AbilityBlockPath,
+		PieceID: id,
+		Facing:  facing,
+		Reason: fmt.Sprintf("The %s on %s is holding BlockPath facing %s (its %s) and turns away attacks from the %s.",
+			e.board.types[target], SquareToCoord(e.board.squares[target]), facing,
+			relativeLabel(facing.Relative(e.board.colors[target])), approach),
+	}, true
+}
+
+// directionTo is the compass direction from one square towards another,
+// rounded to the nearest of the eight, or DirNone for the same square.
+func directionTo(from, to Square) Direction {
+	dr := int(to)/8 - int(from)/8
+	df := int(to)%8 - int(from)%8
+	if dr == 0 && df == 0 {
+		return DirNone
+	}
+	// Treat a step as diagonal when neither axis dominates by more than 2:1,
+	// so knight jumps land on the diagonal they lean towards.
+	vert, horiz := sign(dr), sign(df)
+	switch {
+	case abs(dr) > 2*abs(df):
+		horiz = 0
+	case abs(df) > 2*abs(dr):
+		vert = 0
+	}
+	return compass[vert+1][horiz+1]
+}
+
+// compass is indexed by [rank sign + 1][file sign + 1].
+var compass = [3][3]Direction{
+	{DirSW, DirS, DirSE},
+	{DirW, DirNone, DirE},
+	{DirNW, DirN, DirNE},
+}
+
+// withinArc reports whether d is facing or one of its neighbouring directions.
+func withinArc(facing, d Direction) bool {
+	diff := (int(d) - int(facing) + 8) % 8
+	return diff == 0 || diff == 1 || diff == 7
+}
+
+// relativeLabel renders r for notes, e.g. "forward left".
+func relativeLabel(r RelativeDirection) string {
+	return strings.ToLower(strings.ReplaceAll(r.String(), "_", " "))
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// ValidateMove reports whether req would be accepted as the next move without
+// applying it. Ability resolution is not simulated, so a legal move may still
+// be rewound by a DoOver or fail in a handler.
+func (e *Engine) ValidateMove(req MoveRequest) error {
+	if e.locked {
+		return ErrEngineLocked
+	}
+	if e.status != StatusActive {
+		return ErrGameOver
+	}
+	if err := e.checkDrift(req.Drift); err != nil {
+		return err
+	}
+	if req.Shove {
+		_, err := e.planShove(req)
+		return err
+	}
+	_, _, err := e.checkMove(req)
+	return err
+}
+
+// checkMove runs the position checks shared by Move and ValidateMove and
+// returns the mover's slot and the captured slot, or -1 when nothing is
+// captured.
+func (e *Engine) checkMove(req MoveRequest) (int, int, error) {
+	idx := e.board.pieceIndexBySquare(req.From)
+	if idx < 0 || e.board.colors[idx] != e.board.turn {
+		return -1, -1, ErrInvalidMove
+	}
+	if req.To == SquareInvalid || e.board.squareOccupiedBy(e.board.colors[idx], req.To) {
+		return -1, -1, ErrInvalidMove
+	}
+	captureIdx := e.board.pieceIndexBySquare(req.To)
+	if e.rules.Standard() {
+		captureIdx = e.board.standardCapture(idx, req.To)
+	}
+	if err := e.validateMove(idx, req.To, captureIdx >= 0); err != nil {
+		return -1, -1, err
+	}
+	if captureIdx >= 0 {
+		if _, blocked := e.captureBlock(idx, captureIdx); blocked {
+			return -1, -1, ErrCaptureBlocked
+		}
+	}
+	return idx, captureIdx, nil
+}
@@ -0,0 +1,123 @@
+// path: chessTest/internal/game/charges.go
+package game
+
+// ChargeRules configures the optional per-side charge resource. When enabled,
+// abilities with a cost only fire if their owner can pay for them.
+type ChargeRules struct {
+	Enabled bool
+	// Start is each side's balance after Reset.
+	Start int
+	// PerTurn is credited to the mover when its turn ends.
+	PerTurn int
+	// Max caps a side's balance.
+	Max int
+	// Costs overrides DefaultChargeCosts when non-nil.
+	Costs map[Ability]int
+}
+
+// DefaultChargeCosts prices the abilities that remove or rewind pieces.
+func DefaultChargeCosts() map[Ability]int {
+	return map[Ability]int{
+		AbilityDoOver:      3,
+		AbilityScatterShot: 2,
+	}
+}
+
+// DefaultChargeRules is the disabled charge configuration used by DefaultRules.
+func DefaultChargeRules() ChargeRules {
+	return ChargeRules{Start: 0, PerTurn: 1, Max: 5}
+}
+
+// EffectiveCosts returns the price of every ability that costs charges under
+// c, falling back to DefaultChargeCosts.
+func (c ChargeRules) EffectiveCosts() map[Ability]int {
+	if c.Costs == nil {
+		return DefaultChargeCosts()
+	}
+	return c.clone().Costs
+}
+
+func (c ChargeRules) validate() error {
+	if c.Start < 0 || c.PerTurn < 0 || c.Max < 0 || c.Start > c.Max || c.Max > 0xFFFF {
+		return ErrInvalidRules
+	}
+	for id, cost := range c.Costs {
+		if abilityBit(id) == 0 || cost < 0 || cost > 0xFF {
+			return ErrInvalidRules
+		}
+	}
+	return nil
+}
+
+func (c ChargeRules) clone() ChargeRules {
+	if c.Costs == nil {
+		return c
+	}
+	costs := make(map[Ability]int, len(c.Costs))
+	for id, cost := range c.Costs {
+		costs[id] = cost
+	}
+	c.Costs = costs
+	return c
+}
+
+// costTable flattens the cost map for the resolver hot path.
+func (c ChargeRules) costTable() [abilityCountInt]uint8 {
+	var out [abilityCountInt]uint8
+	costs := c.Costs
+	if costs == nil {
+		costs = DefaultChargeCosts()
+	}
+	for id, cost := range costs {
+		out[id] = uint8(cost)
+	}
+	return out
+}
+
+// Charges reports a side's current balance; zero when charges are disabled.
+func (e *Engine) Charges(color Color) int {
+	if !e.rules.Charges.Enabled || int(color) > 1 {
+		return 0
+	}
+	return int(e.charges[color.Index()])
+}
+
+func (e *Engine) resetCharges() {
+	start := uint16(e.rules.Charges.Start)
+	e.charges = [2]uint16{start, start}
+	e.doOverDebt = [2]uint16{}
+}
+
+// accrueCharges credits the side whose turn just ended, less any DoOver turn
+// cost it still owes.
+func (e *Engine) accrueCharges(color Color) {
+	rules := e.rules.Charges
+	if !rules.Enabled {
+		return
+	}
+	idx := color.Index()
+	next := int(e.charges[idx]) + rules.PerTurn - int(e.doOverDebt[idx])
+	e.doOverDebt[idx] = 0
+	if next < 0 {
+		next = 0
+	}
+	if next > rules.Max {
+		next = rules.Max
+	}
+	e.charges[idx] = uint16(next)
+}
+
+// spendCharge debits the ability's cost from color, reporting false when the
+// side cannot afford it. With charges disabled every ability is free.
+func (ctx *resolveContext) spendCharge(color Color, id Ability) bool {
+	if ctx.charges == nil {
+		return true
+	}
+	cost := uint16(ctx.chargeCosts[id])
+	idx := color.Index()
+	if ctx.charges[idx] < cost {
+		return false
+	}
+	ctx.charges[idx] -= cost
+	return true
+}
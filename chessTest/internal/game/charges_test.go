@@ -0,0 +1,134 @@
+// path: chessTest/internal/game/charges_test.go
+package game
+
+import "testing"
+
+func chargeRules(start, perTurn, max int) RulesConfig {
+	rules := DefaultRules()
+	rules.Charges = ChargeRules{Enabled: true, Start: start, PerTurn: perTurn, Max: max}
+	return rules
+}
+
+func TestChargesAccrueAtTurnEnd(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetRules(chargeRules(0, 2, 3)); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	moves := []MoveRequest{
+		{From: SquareA2, To: SquareA3},
+		{From: SquareA7, To: SquareA6},
+		{From: SquareB2, To: SquareB3},
+	}
+	for _, mv := range moves {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %v: %v", mv, err)
+		}
+	}
+	if got := eng.Charges(White); got != 3 {
+		t.Fatalf("white charges = %d want capped 3", got)
+	}
+	if got := eng.Charges(Black); got != 2 {
+		t.Fatalf("black charges = %d want 2", got)
+	}
+	state := eng.State()
+	if state.Charges[White.String()] != 3 || state.Charges[Black.String()] != 2 {
+		t.Fatalf("state charges = %v", state.Charges)
+	}
+	if err := eng.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if eng.Charges(White) != 0 || eng.Charges(Black) != 0 {
+		t.Fatalf("reset did not restore start balance")
+	}
+}
+
+func TestChargesGateExpensiveAbilities(t *testing.T) {
+	cases := []struct {
+		name       string
+		start      int
+		wantDoOver bool
+	}{
+		{name: "unaffordable do-over lets capture stand", start: 2, wantDoOver: false},
+		{name: "affordable do-over rewinds", start: 3, wantDoOver: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.SetRules(chargeRules(tc.start, 0, 5)); err != nil {
+				t.Fatalf("set rules: %v", err)
+			}
+			if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+				t.Fatalf("configure black: %v", err)
+			}
+			for _, mv := range []MoveRequest{{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5}} {
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %v: %v", mv, err)
+				}
+			}
+			err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5})
+			if tc.wantDoOver {
+				if err != ErrDoOverActivated {
+					t.Fatalf("expected do-over, got %v", err)
+				}
+				if got := eng.Charges(Black); got != tc.start-3 {
+					t.Fatalf("black charges = %d want %d", got, tc.start-3)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected capture to stand, got %v", err)
+			}
+			if got := eng.Charges(Black); got != tc.start {
+				t.Fatalf("black charges = %d want unchanged %d", got, tc.start)
+			}
+		})
+	}
+}
+
+func TestChargesGateScatterShotInResolver(t *testing.T) {
+	for _, balance := range []uint16{1, 2} {
+		board := newEmptyBoard()
+		addPiece(&board, 0, 1, White, Knight, SquareD4)
+		addPiece(&board, 1, 2, Black, Pawn, SquareF4)
+		board.ability[0] = NewAbilitySet(AbilityScatterShot)
+		doOver := [2]bool{}
+		charges := [2]uint16{balance, 0}
+		ctx := resolveContext{
+			board:         &board,
+			mover:         0,
+			target:        SquareE4,
+			captureIdx:    -1,
+			sideMask:      NewAbilitySet(AbilityScatterShot),
+			doOverUsed:    &doOver,
+			seed:          1,
+			charges:       &charges,
+			chargeCosts:   DefaultChargeRules().costTable(),
+			removalBudget: DefaultExtraRemovals,
+		}
+		res, err := newAbilityResolver().resolve(ctx)
+		if err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+		fired := res.telemetry.scatterHits > 0
+		if want := balance >= 2; fired != want {
+			t.Fatalf("balance %d: scatter fired = %v want %v", balance, fired, want)
+		}
+	}
+}
+
+func TestChargeRulesValidation(t *testing.T) {
+	eng := NewEngine()
+	bad := []ChargeRules{
+		{Enabled: true, Start: -1},
+		{Enabled: true, Start: 4, Max: 2},
+		{Enabled: true, Max: 2, Costs: map[Ability]int{AbilityNone: 1}},
+		{Enabled: true, Max: 2, Costs: map[Ability]int{AbilityDoOver: -1}},
+	}
+	for _, c := range bad {
+		rules := DefaultRules()
+		rules.Charges = c
+		if err := eng.SetRules(rules); err != ErrInvalidRules {
+			t.Fatalf("SetRules(%+v) = %v want ErrInvalidRules", c, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// path: chessTest/internal/game/clock.go
+package game
+
+import "time"
+
+// TimeControl is a Fischer clock. A zero Initial leaves the game untimed.
+type TimeControl struct {
+	Initial   time.Duration
+	Increment time.Duration
+
+	// Odds gives Black a clock of its own, a handicap between players of
+	// unequal strength: Initial and Increment are then White's, and Black
+	// starts with BlackInitial and gains BlackIncrement per move.
+	Odds           bool
+	BlackInitial   time.Duration
+	BlackIncrement time.Duration
+}
+
+// Timed reports whether the control runs a clock.
+func (tc TimeControl) Timed() bool { return tc.Initial > 0 }
+
+// For reports the starting time and increment of color's clock.
+func (tc TimeControl) For(color Color) (initial, increment time.Duration) {
+	if tc.Odds && color == Black {
+		return tc.BlackInitial, tc.BlackIncrement
+	}
+	return tc.Initial, tc.Increment
+}
+
+func (tc TimeControl) validate() error {
+	if tc.Initial < 0 || tc.Increment < 0 || (tc.Initial == 0 && tc.Increment != 0) {
+		return ErrInvalidRules
+	}
+	if !tc.Odds {
+		if tc.BlackInitial != 0 || tc.BlackIncrement != 0 {
+			return ErrInvalidRules
+		}
+		return nil
+	}
+	// Odds only make sense between two running clocks.
+	if tc.Initial == 0 || tc.BlackInitial <= 0 || tc.BlackIncrement < 0 {
+		return ErrInvalidRules
+	}
+	return nil
+}
+
+// Clock reports a side's remaining time, or zero in untimed games. The side to
+// move is charged for the time spent on the current turn so far.
+func (e *Engine) Clock(color Color) time.Duration {
+	if !e.rules.TimeControl.Timed() || int(color) > 1 {
+		return 0
+	}
+	return e.clockAt(color, e.now())
+}
+
+func (e *Engine) clockAt(color Color, now time.Time) time.Duration {
+	left := e.clocks[color.Index()]
+	if color == e.board.turn && e.status == StatusActive {
+		left -= now.Sub(e.turnStart)
+	}
+	if left < 0 {
+		return 0
+	}
+	return left
+}
+
+// ClockReading is both clocks read at one instant.
+type ClockReading struct {
+	At time.Time
+	// Remaining is each side's time left at At, indexed by Color.
+	Remaining [2]time.Duration
+	// Running reports whether Turn's clock is counting down: the game is
+	// timed and still active.
+	Running bool
+	Turn    Color
+}
+
+// ReadClocks reads both clocks at the same instant, so a client can run its
+// own display from At without the two sides drifting apart. Untimed games
+// read zero and never run.
+func (e *Engine) ReadClocks() ClockReading {
+	now := e.now()
+	out := ClockReading{At: now, Turn: e.board.turn}
+	if !e.rules.TimeControl.Timed() {
+		return out
+	}
+	for _, c := range [...]Color{White, Black} {
+		out.Remaining[c.Index()] = e.clockAt(c, now)
+	}
+	out.Running = e.status == StatusActive
+	return out
+}
+
+// Now reads the engine's wall clock; see SetClock.
+func (e *Engine) Now() time.Time {
+	return e.now()
+}
+
+// MoveAt plays req as Move does with the wall clock reading at throughout,
+// then puts the engine's clock back. Recovery uses it to replay a logged
+// move at the time it was first played, so clock charges, flag falls and
+// move timestamps come out as they did.
+func (e *Engine) MoveAt(at time.Time, req MoveRequest) error {
+	now := e.now
+	e.now = func() time.Time { return at }
+	defer func() { e.now = now }()
+	return e.Move(req)
+}
+
+func (e *Engine) resetClocks() {
+	for _, c := range [...]Color{White, Black} {
+		e.clocks[c.Index()], _ = e.rules.TimeControl.For(c)
+	}
+}
+
+// checkFlag ends the game when the side to move has run out of time.
+func (e *Engine) checkFlag(now time.Time) error {
+	if !e.rules.TimeControl.Timed() {
+		return nil
+	}
+	mover := e.board.turn
+	if now.Sub(e.turnStart) <= e.clocks[mover.Index()] {
+		return nil
+	}
+	e.clocks[mover.Index()] = 0
+	winner := mover.Opposite()
+	status := StatusWhiteWins
+	if winner == Black {
+		status = StatusBlackWins
+	}
+	e.finish(status, mover.String()+" ran out of time", winner, e.board.ply)
+	e.lastNote = "Flag fell"
+	return ErrGameOver
+}
+
+// chargeClock debits the mover's think time and credits the increment.
+func (e *Engine) chargeClock(color Color, at time.Time) {
+	if !e.rules.TimeControl.Timed() {
+		return
+	}
+	idx := color.Index()
+	_, increment := e.rules.TimeControl.For(color)
+	e.clocks[idx] -= at.Sub(e.turnStart)
+	e.clocks[idx] += increment
+}
@@ -0,0 +1,161 @@
+// path: chessTest/internal/game/clock_test.go
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeControlChargesAndFlags(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := base
+	eng := NewEngine()
+	eng.SetClock(func() time.Time { return now })
+	rules := DefaultRules()
+	rules.TimeControl = TimeControl{Initial: time.Minute, Increment: 2 * time.Second}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+
+	now = base.Add(10 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != nil {
+		t.Fatalf("white move: %v", err)
+	}
+	if got, want := eng.Clock(White), 52*time.Second; got != want {
+		t.Fatalf("white clock = %v want %v", got, want)
+	}
+	if got := eng.State().Clocks[White.String()]; got != 52000 {
+		t.Fatalf("state clock = %d want 52000", got)
+	}
+	now = now.Add(4 * time.Second)
+	if got, want := eng.ReadClocks(), (ClockReading{At: now, Remaining: [2]time.Duration{52 * time.Second, 56 * time.Second}, Running: true, Turn: Black}); got != want {
+		t.Fatalf("ReadClocks = %+v want %+v", got, want)
+	}
+
+	now = now.Add(61 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareD7, To: SquareD5}); err != ErrGameOver {
+		t.Fatalf("expected ErrGameOver on flag, got %v", err)
+	}
+	state := eng.State()
+	if state.Status != StatusWhiteWins || state.StatusReason != "black ran out of time" {
+		t.Fatalf("unexpected status %s (%q)", state.Status, state.StatusReason)
+	}
+}
+
+func TestBannedAbilitiesRejected(t *testing.T) {
+	eng := NewEngine()
+	rules := DefaultRules()
+	rules.BannedAbilities = AbilityList{AbilityDoOver}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath, AbilityDoOver}, ElementLight); err != ErrAbilityBanned {
+		t.Fatalf("expected ErrAbilityBanned, got %v", err)
+	}
+	if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("allowed loadout: %v", err)
+	}
+	rules.DisabledAbilities = AbilityList{AbilityScatterShot}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityScatterShot}, ElementShadow); err != ErrAbilityDisabled {
+		t.Fatalf("expected ErrAbilityDisabled, got %v", err)
+	}
+	rules.Variant = "atomic"
+	if err := eng.SetRules(rules); err != ErrInvalidRules {
+		t.Fatalf("expected ErrInvalidRules for unknown variant, got %v", err)
+	}
+}
+
+func TestTimeOdds(t *testing.T) {
+	odds := TimeControl{Initial: time.Minute, Increment: 5 * time.Second, Odds: true, BlackInitial: 20 * time.Second, BlackIncrement: time.Second}
+	cases := []struct {
+		name string
+		// think is how long each ply takes, White first.
+		think      []time.Duration
+		wantClocks [2]time.Duration
+		wantStatus GameStatus
+		wantReason string
+	}{
+		{
+			name:       "unequal increments",
+			think:      []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second},
+			wantClocks: [2]time.Duration{50 * time.Second, 11 * time.Second},
+			wantStatus: StatusActive,
+		},
+		{
+			name:       "black flags on the smaller clock",
+			think:      []time.Duration{time.Second, 15 * time.Second, time.Second, 7 * time.Second},
+			wantClocks: [2]time.Duration{68 * time.Second, 0},
+			wantStatus: StatusWhiteWins,
+			wantReason: "black ran out of time",
+		},
+		{
+			name:       "white flags despite the larger increment",
+			think:      []time.Duration{40 * time.Second, time.Second, 30 * time.Second},
+			wantClocks: [2]time.Duration{0, 20 * time.Second},
+			wantStatus: StatusBlackWins,
+			wantReason: "white ran out of time",
+		},
+	}
+	moves := []MoveRequest{
+		{From: SquareA2, To: SquareA3},
+		{From: SquareH7, To: SquareH6},
+		{From: SquareB2, To: SquareB3},
+		{From: SquareG7, To: SquareG6},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+			eng := NewEngine(WithClock(func() time.Time { return now }))
+			rules := DefaultRules()
+			rules.TimeControl = odds
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("set rules: %v", err)
+			}
+			if got := eng.Clock(Black); got != 20*time.Second {
+				t.Fatalf("black starts with %v want 20s", got)
+			}
+			for i, think := range tc.think {
+				now = now.Add(think)
+				err := eng.Move(moves[i])
+				if err == ErrGameOver {
+					break
+				}
+				if err != nil {
+					t.Fatalf("ply %d: %v", i, err)
+				}
+			}
+			state := eng.State()
+			if state.Status != tc.wantStatus || state.StatusReason != tc.wantReason {
+				t.Fatalf("status %s (%q) want %s (%q)", state.Status, state.StatusReason, tc.wantStatus, tc.wantReason)
+			}
+			if got := [2]time.Duration{eng.Clock(White), eng.Clock(Black)}; got != tc.wantClocks {
+				t.Fatalf("clocks = %v want %v", got, tc.wantClocks)
+			}
+		})
+	}
+}
+
+func TestTimeOddsValidated(t *testing.T) {
+	cases := []struct {
+		name string
+		tc   TimeControl
+		ok   bool
+	}{
+		{name: "odds", tc: TimeControl{Initial: time.Minute, Odds: true, BlackInitial: time.Second}, ok: true},
+		{name: "odds without a white clock", tc: TimeControl{Odds: true, BlackInitial: time.Minute}},
+		{name: "odds without a black clock", tc: TimeControl{Initial: time.Minute, Odds: true, BlackIncrement: time.Second}},
+		{name: "negative black increment", tc: TimeControl{Initial: time.Minute, Odds: true, BlackInitial: time.Minute, BlackIncrement: -time.Second}},
+		{name: "black clock without odds", tc: TimeControl{Initial: time.Minute, BlackInitial: time.Second}},
+	}
+	for _, tc := range cases {
+		rules := DefaultRules()
+		rules.TimeControl = tc.tc
+		err := NewEngine().SetRules(rules)
+		if (err == nil) != tc.ok {
+			t.Fatalf("%s: SetRules = %v", tc.name, err)
+		}
+	}
+}
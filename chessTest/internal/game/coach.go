@@ -0,0 +1,108 @@
+// path: chessTest/internal/game/coach.go
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+var pieceTypeNames = [...]string{Pawn: "pawn", Knight: "knight", Bishop: "bishop", Rook: "rook", Queen: "queen", King: "king"}
+
+func (t PieceType) String() string {
+	if int(t) < len(pieceTypeNames) {
+		return pieceTypeNames[t]
+	}
+	return "piece"
+}
+
+// ExplainMove turns the error Move returned for req into a player-facing
+// explanation by inspecting the unchanged position. It returns "" when err is
+// nil or there is nothing more specific to say than the error itself.
+func (e *Engine) ExplainMove(req MoveRequest, err error) string {
+	if err == nil {
+		return ""
+	}
+	var herr *HandlerError
+	switch {
+	case errors.Is(err, ErrEngineLocked):
+		return "The board is locked; no moves are accepted right now."
+	case errors.Is(err, ErrGameOver):
+		return fmt.Sprintf("The game is over (%s); reset to play again.", e.status)
+	case errors.Is(err, ErrDoOverActivated):
+		return "The defender's DoOver rewound your capture. It is still your move, and DoOver only fires once per side."
+	case errors.Is(err, ErrConflictingAugmentors):
+		return "Mist Shroud and Radiant Vision cancel each other out; remove one of them from the loadout."
+	case errors.Is(err, ErrInvalidOverload):
+		return "Overload needs per-piece ability assignments, but this piece has none."
+	case errors.Is(err, ErrCaptureBlocked):
+		if block, ok := e.ExplainCaptureBlock(req.From, req.To); ok {
+			return block.Reason
+		}
+		return ""
+	case errors.As(err, &herr):
+		return fmt.Sprintf("The %s ability failed to resolve, so the move was undone.", herr.Ability)
+	case !errors.Is(err, ErrInvalidMove):
+		return ""
+	}
+	return e.explainInvalidMove(req)
+}
+
+func (e *Engine) explainInvalidMove(req MoveRequest) string {
+	from, to := SquareToCoord(req.From), SquareToCoord(req.To)
+	idx := e.board.pieceIndexBySquare(req.From)
+	if idx < 0 {
+		return fmt.Sprintf("There is no piece on %s.", from)
+	}
+	color := e.board.colors[idx]
+	typ := e.board.types[idx]
+	if color != e.board.turn {
+		return fmt.Sprintf("That %s belongs to %s, but it is %s's turn.", typ, color, e.board.turn)
+	}
+	if req.To == SquareInvalid {
+		return "Pick a destination square on the board."
+	}
+	if req.To == req.From {
+		return "A piece has to leave its square to move."
+	}
+	if e.board.squareOccupiedBy(color, req.To) {
+		return fmt.Sprintf("Your own piece already stands on %s.", to)
+	}
+	if typ != Pawn {
+		return fmt.Sprintf("This arena only resolves pawn moves so far; the %s on %s cannot move yet.", typ, from)
+	}
+	return e.explainPawn(color, req.From, req.To)
+}
+
+func (e *Engine) explainPawn(color Color, from, to Square) string {
+	dir := 1
+	startRank := 1
+	if color == Black {
+		dir = -1
+		startRank = 6
+	}
+	fromRank, fromFile := int(from)/8, int(from)%8
+	toRank, toFile := int(to)/8, int(to)%8
+	forward := (toRank - fromRank) * dir
+	fileDelta := abs(toFile - fromFile)
+	target := SquareToCoord(to)
+	switch {
+	case forward <= 0:
+		return "Pawns only move forward."
+	case fileDelta == 1 && forward == 1:
+		return fmt.Sprintf("Pawns capture diagonally, but there is no enemy piece on %s.", target)
+	case fileDelta != 0:
+		return "Pawns move straight ahead and capture one square diagonally."
+	case forward == 1:
+		return fmt.Sprintf("Pawns cannot capture straight ahead; %s is occupied.", target)
+	case forward == 2 && fromRank != startRank:
+		return "A pawn may only advance two squares from its starting rank."
+	case forward == 2:
+		middle := Square(int(from) + dir*8)
+		if !e.board.empty(middle) {
+			return fmt.Sprintf("The pawn cannot jump over the piece on %s.", SquareToCoord(middle))
+		}
+		return fmt.Sprintf("The pawn cannot advance onto the occupied square %s.", target)
+	default:
+		return "A pawn advances one square, or two from its starting rank."
+	}
+}
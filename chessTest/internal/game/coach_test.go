@@ -0,0 +1,104 @@
+// path: chessTest/internal/game/coach_test.go
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplainMove(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		turn      Color
+		req       MoveRequest
+		want      string
+	}{
+		{name: "empty square", req: MoveRequest{From: SquareE4, To: SquareE5}, want: "no piece on e4"},
+		{name: "wrong side", req: MoveRequest{From: SquareE7, To: SquareE5}, want: "it is white's turn"},
+		{name: "own piece", req: MoveRequest{From: SquareA1, To: SquareA2}, want: "own piece already stands on a2"},
+		{name: "non pawn", req: MoveRequest{From: SquareG1, To: SquareF3}, want: "knight on g1 cannot move yet"},
+		{name: "backwards", placement: "4k3/8/8/8/4P3/8/8/4K3", req: MoveRequest{From: SquareE4, To: SquareE3}, want: "only move forward"},
+		{name: "empty diagonal", req: MoveRequest{From: SquareE2, To: SquareD3}, want: "no enemy piece on d3"},
+		{name: "blocked push", placement: "4k3/8/8/8/8/4p3/4P3/4K3", req: MoveRequest{From: SquareE2, To: SquareE3}, want: "cannot capture straight ahead"},
+		{name: "jump blocker", placement: "4k3/8/8/8/8/4n3/4P3/4K3", req: MoveRequest{From: SquareE2, To: SquareE4}, want: "jump over the piece on e3"},
+		{name: "late double step", placement: "4k3/8/8/8/8/4P3/8/4K3", req: MoveRequest{From: SquareE3, To: SquareE5}, want: "from its starting rank"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if tc.placement != "" {
+				if err := eng.LoadPlacement(tc.placement, tc.turn); err != nil {
+					t.Fatalf("load placement: %v", err)
+				}
+			}
+			err := eng.Move(tc.req)
+			if err != ErrInvalidMove {
+				t.Fatalf("expected invalid move, got %v", err)
+			}
+			got := eng.ExplainMove(tc.req, err)
+			if !strings.Contains(got, tc.want) {
+				t.Fatalf("explanation %q does not mention %q", got, tc.want)
+			}
+		})
+	}
+	if got := NewEngine().ExplainMove(MoveRequest{}, nil); got != "" {
+		t.Fatalf("expected no explanation for nil error, got %q", got)
+	}
+}
+
+func TestExplainCaptureBlock(t *testing.T) {
+	cases := []struct {
+		name    string
+		facing  Direction
+		blocked bool
+	}{
+		{name: "facing the attacker", facing: DirN, blocked: true},
+		{name: "facing away", facing: DirE},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement("4k3/8/8/3p4/8/8/4P3/4K3", White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+				t.Fatalf("white config: %v", err)
+			}
+			if err := eng.SetSideConfig(Black, AbilityList{}, ElementShadow); err != nil {
+				t.Fatalf("black config: %v", err)
+			}
+			if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4, Dir: tc.facing}); err != nil {
+				t.Fatalf("e2e4: %v", err)
+			}
+			if note := eng.State().LastNote; !strings.Contains(note, "for white") {
+				t.Fatalf("last note %q does not give the relative facing", note)
+			}
+			capture := MoveRequest{From: SquareD5, To: SquareE4}
+			block, ok := eng.ExplainCaptureBlock(capture.From, capture.To)
+			if ok != tc.blocked {
+				t.Fatalf("blocked = %v want %v", ok, tc.blocked)
+			}
+			validateErr := eng.ValidateMove(capture)
+			err := eng.Move(capture)
+			if validateErr != err {
+				t.Fatalf("ValidateMove = %v but Move = %v", validateErr, err)
+			}
+			if !tc.blocked {
+				if err != nil {
+					t.Fatalf("capture: %v", err)
+				}
+				return
+			}
+			if err != ErrCaptureBlocked {
+				t.Fatalf("expected capture blocked, got %v", err)
+			}
+			if block.Rule != BlockRuleFacing || block.Facing != DirN {
+				t.Fatalf("unexpected block %+v", block)
+			}
+			if got := eng.ExplainMove(capture, err); !strings.Contains(got, "BlockPath facing N") {
+				t.Fatalf("explanation %q does not name the facing", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// path: chessTest/internal/game/corpus.go
+package game
+
+import "fmt"
+
+// CorpusEntry is one regression corpus entry under testdata/regressions: a
+// loadout, an optional starting placement, and moves with the error each
+// returned, followed by the position they left. cmd/capturebug writes
+// entries from battle logs and TestRegressionCorpus replays them.
+type CorpusEntry struct {
+	Name string `json:"name"`
+	// Note says what the entry guards. Entries captured from a real battle
+	// log name the bug they reproduced; synthetic ones, written to cover a
+	// rule rather than a reported failure, start with "synthetic:".
+	Note      string       `json:"note"`
+	Placement string       `json:"placement,omitempty"`
+	Turn      string       `json:"turn,omitempty"`
+	White     CorpusSide   `json:"white"`
+	Black     CorpusSide   `json:"black"`
+	Moves     []CorpusMove `json:"moves"`
+	Expect    CorpusExpect `json:"expect"`
+}
+
+// CorpusSide is one side's loadout, by ability and element name.
+type CorpusSide struct {
+	Abilities []string `json:"abilities"`
+	Element   string   `json:"element"`
+}
+
+// CorpusMove is one move and the error text it returned, "" for none.
+type CorpusMove struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Dir  string `json:"dir,omitempty"`
+	Err  string `json:"err,omitempty"`
+}
+
+// CorpusExpect is the position an entry's moves leave. BlockFacing maps the
+// squares of facing pieces to their direction.
+type CorpusExpect struct {
+	Placement   string            `json:"placement"`
+	Turn        string            `json:"turn"`
+	LastNote    string            `json:"lastNote"`
+	BlockFacing map[string]string `json:"blockFacing,omitempty"`
+}
+
+// Replay plays c on a new engine and returns it with each move's error and
+// the expected position replaced by what the engine did now.
+func (c CorpusEntry) Replay() (CorpusEntry, error) {
+	eng := NewEngine()
+	for _, side := range [...]struct {
+		color Color
+		CorpusSide
+	}{{White, c.White}, {Black, c.Black}} {
+		if err := side.configure(eng, side.color); err != nil {
+			return c, fmt.Errorf("%s: %w", side.color, err)
+		}
+	}
+	if c.Placement != "" {
+		turn := White
+		if c.Turn == Black.String() {
+			turn = Black
+		}
+		if err := eng.LoadPlacement(c.Placement, turn); err != nil {
+			return c, err
+		}
+	}
+	c.Moves = append([]CorpusMove(nil), c.Moves...)
+	for i := range c.Moves {
+		mv := &c.Moves[i]
+		from, ok := CoordToSquare(mv.From)
+		if !ok {
+			return c, fmt.Errorf("move %d: invalid from %q", i+1, mv.From)
+		}
+		to, ok := CoordToSquare(mv.To)
+		if !ok {
+			return c, fmt.Errorf("move %d: invalid to %q", i+1, mv.To)
+		}
+		mv.Err = ""
+		if err := eng.Move(MoveRequest{From: from, To: to, Dir: ParseDirection(mv.Dir)}); err != nil {
+			mv.Err = err.Error()
+		}
+	}
+	state := eng.State()
+	c.Expect = CorpusExpect{
+		Placement: eng.Placement(),
+		Turn:      state.Turn.String(),
+		LastNote:  state.LastNote,
+	}
+	for _, pc := range state.Pieces {
+		dir, ok := state.BlockFacing[pc.ID]
+		if !ok {
+			continue
+		}
+		if c.Expect.BlockFacing == nil {
+			c.Expect.BlockFacing = make(map[string]string, len(state.BlockFacing))
+		}
+		c.Expect.BlockFacing[SquareToCoord(pc.Square)] = dir.String()
+	}
+	return c, nil
+}
+
+func (s CorpusSide) configure(eng *Engine, color Color) error {
+	if len(s.Abilities) == 0 && s.Element == "" {
+		return nil
+	}
+	list := make(AbilityList, 0, len(s.Abilities))
+	for _, name := range s.Abilities {
+		a, ok := ParseAbility(name)
+		if !ok {
+			return fmt.Errorf("invalid ability %q; valid: %v", name, AbilityStrings())
+		}
+		list = append(list, a)
+	}
+	element, ok := ParseElement(s.Element)
+	if !ok {
+		return fmt.Errorf("invalid element %q; valid: %v", s.Element, ElementStrings())
+	}
+	return eng.SetSideConfig(color, list, element)
+}
@@ -0,0 +1,112 @@
+// path: chessTest/internal/game/delta.go
+package game
+
+// DeltaHistory is every position of a game, stored compactly for export: the
+// board the game started from and, for each ply, only the pieces that ply
+// changed. Consecutive boards of a long game are almost all alike, and a ply
+// touches the mover, any captured piece and whatever an ability moved.
+type DeltaHistory struct {
+	Start BoardSnapshot
+	Plies []PlyDelta `json:",omitempty"`
+}
+
+// PlyDelta is the change one ply made: the pieces it changed, as they stand
+// after it, keyed by piece ID, and the board's ply and side to move after it.
+type PlyDelta struct {
+	Ply     uint32
+	Turn    Color
+	Changed []PieceSnapshot `json:",omitempty"`
+}
+
+// DeltaHistory exports the game's positions as deltas. An engine that played
+// the game from its start holds every board; one restored from a snapshot
+// keeps only its rewind points, so its boards are rebuilt by replaying the
+// game's PGN on a scratch engine, and ErrInvalidHistory is returned should
+// that replay not reach the current position.
+func (e *Engine) DeltaHistory() (DeltaHistory, error) {
+	boards := e.history
+	if len(boards) != len(e.moveLog) {
+		rules := e.rules
+		rules.TimeControl = TimeControl{}
+		scratch := NewEngine(WithSeed(e.seed))
+		if err := scratch.SetRules(rules); err != nil {
+			return DeltaHistory{}, err
+		}
+		if err := scratch.LoadPGNAndContinue(e.PGN(nil)); err != nil {
+			return DeltaHistory{}, err
+		}
+		if scratch.board.placement() != e.board.placement() || scratch.board.turn != e.board.turn {
+			return DeltaHistory{}, ErrInvalidHistory
+		}
+		boards = scratch.history
+	}
+	boards = append(boards[:len(boards):len(boards)], e.board)
+	h := DeltaHistory{Start: boards[0].snapshot(), Plies: make([]PlyDelta, 0, len(boards)-1)}
+	for i := 1; i < len(boards); i++ {
+		h.Plies = append(h.Plies, boards[i].deltaFrom(&boards[i-1]))
+	}
+	return h, nil
+}
+
+// deltaFrom lists the pieces whose slot differs from prev, matched by ID.
+func (b *boardSoA) deltaFrom(prev *boardSoA) PlyDelta {
+	d := PlyDelta{Ply: b.ply, Turn: b.turn}
+	before := make(map[int]int, len(prev.ids))
+	for i, id := range prev.ids {
+		if id != 0 {
+			before[id] = i
+		}
+	}
+	for i, id := range b.ids {
+		if id == 0 {
+			continue
+		}
+		j, ok := before[id]
+		if ok && prev.squares[j] == b.squares[i] && prev.alive[j] == b.alive[i] &&
+			prev.types[j] == b.types[i] && prev.colors[j] == b.colors[i] && prev.ability[j] == b.ability[i] {
+			continue
+		}
+		d.Changed = append(d.Changed, PieceSnapshot{
+			ID:        id,
+			Color:     b.colors[i],
+			Type:      b.types[i],
+			Square:    b.squares[i],
+			Alive:     b.alive[i],
+			Abilities: b.ability[i],
+		})
+	}
+	return d
+}
+
+// Len is the number of plies the history holds.
+func (h DeltaHistory) Len() int { return len(h.Plies) }
+
+// At rebuilds the board after ply plies of the history, zero being the start,
+// by applying that many deltas: O(ply) work, however long the game. The
+// result is checked as a snapshot is on Restore.
+func (h DeltaHistory) At(ply int) (BoardSnapshot, error) {
+	if ply < 0 || ply > len(h.Plies) {
+		return BoardSnapshot{}, ErrInvalidHistory
+	}
+	out := BoardSnapshot{Turn: h.Start.Turn, Ply: h.Start.Ply}
+	out.Pieces = append([]PieceSnapshot(nil), h.Start.Pieces...)
+	slot := make(map[int]int, len(out.Pieces))
+	for i, pc := range out.Pieces {
+		slot[pc.ID] = i
+	}
+	for _, d := range h.Plies[:ply] {
+		for _, pc := range d.Changed {
+			if i, ok := slot[pc.ID]; ok {
+				out.Pieces[i] = pc
+				continue
+			}
+			slot[pc.ID] = len(out.Pieces)
+			out.Pieces = append(out.Pieces, pc)
+		}
+		out.Turn, out.Ply = d.Turn, d.Ply
+	}
+	if _, err := out.restore(); err != nil {
+		return BoardSnapshot{}, ErrInvalidHistory
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+// path: chessTest/internal/game/delta_test.go
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDeltaHistory(t *testing.T) {
+	eng := NewEngine()
+	moves := []MoveRequest{
+		{From: SquareE2, To: SquareE4},
+		{From: SquareD7, To: SquareD5},
+		{From: SquareE4, To: SquareD5},
+		{From: SquareH7, To: SquareH6},
+	}
+	boards := []BoardSnapshot{eng.Snapshot().Board}
+	for _, mv := range moves {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %s-%s: %v", SquareToCoord(mv.From), SquareToCoord(mv.To), err)
+		}
+		boards = append(boards, eng.Snapshot().Board)
+	}
+
+	h, err := eng.DeltaHistory()
+	if err != nil {
+		t.Fatalf("delta history: %v", err)
+	}
+	if h.Len() != len(moves) {
+		t.Fatalf("history holds %d plies want %d", h.Len(), len(moves))
+	}
+	for ply, want := range boards {
+		got, err := h.At(ply)
+		if err != nil {
+			t.Fatalf("At(%d): %v", ply, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("At(%d) = %+v want %+v", ply, got, want)
+		}
+	}
+	if n := len(h.Plies[2].Changed); n != 2 {
+		t.Fatalf("capture changed %d pieces want 2", n)
+	}
+	for _, ply := range []int{-1, len(moves) + 1} {
+		if _, err := h.At(ply); err != ErrInvalidHistory {
+			t.Fatalf("At(%d) = %v want ErrInvalidHistory", ply, err)
+		}
+	}
+
+	compact, _ := json.Marshal(h)
+	full, _ := json.Marshal(boards)
+	if len(compact) >= len(full)/2 {
+		t.Fatalf("delta export is %d bytes against %d for full boards", len(compact), len(full))
+	}
+
+	restored := NewEngine()
+	if err := restored.Restore(eng.Snapshot()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	replayed, err := restored.DeltaHistory()
+	if err != nil {
+		t.Fatalf("delta history after restore: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, h) {
+		t.Fatalf("restored engine exports a different history")
+	}
+}
@@ -0,0 +1,99 @@
+// path: chessTest/internal/game/draws.go
+package game
+
+import "fmt"
+
+// deadPositionReason reports why neither side can ever remove another piece,
+// or "" when play can still change the material. The analysis is
+// conservative: only pawns resolve moves in this engine and every removal
+// ability fires on its owner's move, so a side without a pawn that can still
+// advance can never capture, trigger an ability, or reach the enemy king.
+func (b *boardSoA) deadPositionReason() string {
+	if b.hasMobilePawn(White) || b.hasMobilePawn(Black) {
+		return ""
+	}
+	return "dead position: neither side has a pawn that can still move, so no capture or ability removal is possible"
+}
+
+// deadPositionReason is the board's dead-position reason under the game's
+// variant: standard chess is dead only when neither side has mating material.
+func (e *Engine) deadPositionReason() string {
+	if !e.rules.Standard() {
+		return e.board.deadPositionReason()
+	}
+	if e.board.insufficientMaterial() {
+		return "insufficient material: neither side can checkmate"
+	}
+	return ""
+}
+
+// hasMobilePawn reports whether color has a pawn short of its final rank. It
+// ignores blockers, which only makes the draw check more cautious.
+func (b *boardSoA) hasMobilePawn(color Color) bool {
+	lastRank := uint64(0xFF) << 56
+	if color == Black {
+		lastRank = 0xFF
+	}
+	return b.pieceMask[color.Index()][Pawn]&^lastRank != 0
+}
+
+// adjudicateDraw ends the game as a draw when the position is dead or has
+// occurred repetitionDrawCount times.
+func (e *Engine) adjudicateDraw(by Color, ply uint32) {
+	if e.status != StatusActive {
+		return
+	}
+	if reason := e.deadPositionReason(); reason != "" {
+		e.finish(StatusDraw, reason, by, ply)
+		return
+	}
+	if e.Repetitions() >= repetitionDrawCount {
+		e.finish(StatusDraw, "threefold repetition", by, ply)
+	}
+}
+
+// levelMargin is the score, in centipawns, within which a position is
+// assessed as level.
+const levelMargin = 50
+
+// DrawAssessment is a rough verdict on the position, shown next to a draw
+// offer so a casual player can judge it.
+type DrawAssessment struct {
+	// Score is the Evaluate score in centipawns, from White's point of view.
+	Score int
+	// Level is set when neither side is ahead by more than levelMargin;
+	// otherwise Favored is the side ahead.
+	Level   bool
+	Favored Color
+	// Summary reads like "engine thinks White is +2.1 equivalent".
+	Summary string
+}
+
+// EvaluateDrawFairness assesses the position with Evaluate, abilities
+// included, in pawn equivalents. It fails with ErrAssessmentDisabled under
+// Competitive rules.
+func (e *Engine) EvaluateDrawFairness() (DrawAssessment, error) {
+	if e.rules.Competitive {
+		return DrawAssessment{}, ErrAssessmentDisabled
+	}
+	score := e.Evaluate().Score
+	out := DrawAssessment{Score: score}
+	if reason := e.deadPositionReason(); reason != "" {
+		out.Level, out.Summary = true, "engine thinks the game is drawn: "+reason
+		return out, nil
+	}
+	if abs(score) <= levelMargin {
+		out.Level, out.Summary = true, "engine thinks the position is level"
+		return out, nil
+	}
+	out.Favored = White
+	if score < 0 {
+		out.Favored = Black
+	}
+	name := "White"
+	if out.Favored == Black {
+		name = "Black"
+	}
+	out.Summary = fmt.Sprintf("engine thinks %s is +%.1f equivalent", name, float64(abs(score))/100)
+	return out, nil
+}
@@ -0,0 +1,133 @@
+// path: chessTest/internal/game/eligibility.go
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+const pieceTypeCount = int(King) + 1
+
+// PieceTypeSet is a bitmask of piece types.
+type PieceTypeSet uint8
+
+// AllPieceTypes holds every piece type.
+const AllPieceTypes PieceTypeSet = 1<<pieceTypeCount - 1
+
+func NewPieceTypeSet(types ...PieceType) PieceTypeSet {
+	var s PieceTypeSet
+	for _, t := range types {
+		if int(t) < pieceTypeCount {
+			s |= 1 << t
+		}
+	}
+	return s
+}
+
+func (s PieceTypeSet) Has(t PieceType) bool { return int(t) < pieceTypeCount && s&(1<<t) != 0 }
+
+// Types lists the members of s from pawn to king.
+func (s PieceTypeSet) Types() []PieceType {
+	var out []PieceType
+	for t := Pawn; int(t) < pieceTypeCount; t++ {
+		if s.Has(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (s PieceTypeSet) String() string {
+	names := make([]string, 0, pieceTypeCount)
+	for _, t := range s.Types() {
+		names = append(names, t.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParsePieceType reads a piece type name such as "knight", or its SAN letter.
+func ParsePieceType(s string) (PieceType, bool) {
+	trimmed := strings.ToLower(strings.TrimSpace(s))
+	for t, name := range pieceTypeNames {
+		if trimmed == name {
+			return PieceType(t), true
+		}
+	}
+	if len(trimmed) == 1 {
+		if t, _, ok := pieceFromLetter(trimmed[0]); ok {
+			return t, true
+		}
+	}
+	return Pawn, false
+}
+
+// ineligibleTable inverts AbilityEligibility into the abilities each piece
+// type may not use. The zero table restricts nothing.
+type ineligibleTable [pieceTypeCount]AbilitySet
+
+func (r RulesConfig) ineligible() ineligibleTable {
+	var out ineligibleTable
+	for id, types := range r.AbilityEligibility {
+		for t := Pawn; int(t) < pieceTypeCount; t++ {
+			if !types.Has(t) {
+				out[t] = out[t].With(id)
+			}
+		}
+	}
+	return out
+}
+
+func validateEligibility(m map[Ability]PieceTypeSet) error {
+	for id, types := range m {
+		if abilityBit(id) == 0 || types == 0 || types&^AllPieceTypes != 0 {
+			return ErrInvalidRules
+		}
+	}
+	return nil
+}
+
+func cloneEligibility(m map[Ability]PieceTypeSet) map[Ability]PieceTypeSet {
+	if m == nil {
+		return nil
+	}
+	out := make(map[Ability]PieceTypeSet, len(m))
+	for id, types := range m {
+		out[id] = types
+	}
+	return out
+}
+
+// checkEligibility rejects a loadout ability that no live piece of color may
+// use, naming the piece types that hold it back.
+func (e *Engine) checkEligibility(color Color, mask AbilitySet) error {
+	present := e.board.pieceTypes(color)
+	for id := Ability(1); id < abilityCount; id++ {
+		if !mask.Has(id) {
+			continue
+		}
+		types, restricted := e.rules.AbilityEligibility[id]
+		if !restricted || present&types != 0 {
+			continue
+		}
+		return fmt.Errorf("%w: %s cannot be used by %s (usable by %s)", ErrAbilityIneligible, id, present, types)
+	}
+	return nil
+}
+
+// restrictAbilities strips abilities from pieces whose type may not use them.
+func (b *boardSoA) restrictAbilities(ineligible ineligibleTable) {
+	for i := range b.ids {
+		b.ability[i] &^= ineligible[b.types[i]]
+	}
+}
+
+// pieceTypes reports which piece types color has on the board.
+func (b *boardSoA) pieceTypes(color Color) PieceTypeSet {
+	var s PieceTypeSet
+	for i := range b.ids {
+		if b.alive[i] && b.colors[i] == color {
+			s |= NewPieceTypeSet(b.types[i])
+		}
+	}
+	return s
+}
@@ -0,0 +1,147 @@
+// path: chessTest/internal/game/eligibility_test.go
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAbilityEligibilityAtConfigTime(t *testing.T) {
+	cases := []struct {
+		name        string
+		eligibility map[Ability]PieceTypeSet
+		loadout     AbilityList
+		wantErr     error
+		wantMsg     string
+		pawnHas     bool
+	}{
+		{name: "unrestricted", loadout: AbilityList{AbilityBlockPath}, pawnHas: true},
+		{name: "pawns excluded", eligibility: map[Ability]PieceTypeSet{AbilityBlockPath: NewPieceTypeSet(King, Queen)}, loadout: AbilityList{AbilityBlockPath}},
+		{name: "nothing eligible on board", eligibility: map[Ability]PieceTypeSet{AbilityBlockPath: NewPieceTypeSet(Knight)}, loadout: AbilityList{AbilityBlockPath}, wantErr: ErrAbilityIneligible, wantMsg: "cannot be used by pawn, king"},
+		{name: "empty set invalid", eligibility: map[Ability]PieceTypeSet{AbilityBlockPath: 0}, wantErr: ErrInvalidRules},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement("4k3/3p4/8/8/8/8/3P4/3QK3", White); err != nil {
+				t.Fatalf("placement: %v", err)
+			}
+			rules := DefaultRules()
+			rules.AbilityEligibility = tc.eligibility
+			err := eng.SetRules(rules)
+			if err == nil {
+				err = eng.SetSideConfig(Black, tc.loadout, ElementNone)
+			}
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.wantMsg) {
+					t.Fatalf("error %q does not name %q", err, tc.wantMsg)
+				}
+				return
+			}
+			pawn := eng.board.pieceIndexBySquare(SquareD7)
+			if got := eng.board.ability[pawn].Has(AbilityBlockPath); got != tc.pawnHas {
+				t.Fatalf("pawn has BlockPath = %v want %v", got, tc.pawnHas)
+			}
+		})
+	}
+}
+
+func TestIneligibleHandlersNotInstantiated(t *testing.T) {
+	for _, eligible := range []PieceTypeSet{AllPieceTypes, NewPieceTypeSet(Queen)} {
+		board := newEmptyBoard()
+		addPiece(&board, 0, 1, White, Knight, SquareD4)
+		addPiece(&board, 1, 2, Black, Pawn, SquareF4)
+		board.ability[0] = NewAbilitySet(AbilityScatterShot)
+		doOver := [2]bool{}
+		rules := RulesConfig{AbilityEligibility: map[Ability]PieceTypeSet{AbilityScatterShot: eligible}}
+		ctx := resolveContext{
+			board:         &board,
+			mover:         0,
+			target:        SquareE4,
+			captureIdx:    -1,
+			sideMask:      NewAbilitySet(AbilityScatterShot),
+			doOverUsed:    &doOver,
+			seed:          1,
+			removalBudget: DefaultExtraRemovals,
+			ineligible:    rules.ineligible(),
+		}
+		res, err := newAbilityResolver().resolve(ctx)
+		if err != nil {
+			t.Fatalf("resolve: %v", err)
+		}
+		fired := res.telemetry.scatterHits > 0
+		if want := eligible.Has(Knight); fired != want {
+			t.Fatalf("eligible %s: scatter fired = %v want %v", eligible, fired, want)
+		}
+	}
+}
+
+func TestTypeAbilities(t *testing.T) {
+	cases := []struct {
+		name        string
+		eligibility map[Ability]PieceTypeSet
+		byType      map[PieceType]AbilityList
+		wantErr     error
+		wantMsg     string
+	}{
+		{name: "queen only", byType: map[PieceType]AbilityList{Queen: {AbilityBlazeRush, AbilityBlazeRush}}},
+		{name: "eligible type", eligibility: map[Ability]PieceTypeSet{AbilityBlazeRush: NewPieceTypeSet(Queen, Rook)}, byType: map[PieceType]AbilityList{Queen: {AbilityBlazeRush}}},
+		{name: "ineligible type", eligibility: map[Ability]PieceTypeSet{AbilityBlazeRush: NewPieceTypeSet(Rook)}, byType: map[PieceType]AbilityList{Queen: {AbilityBlazeRush}}, wantErr: ErrAbilityIneligible, wantMsg: "BlazeRush cannot be used by queen"},
+		{name: "unknown type", byType: map[PieceType]AbilityList{PieceType(9): {AbilityBlazeRush}}, wantErr: ErrInvalidConfig},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement("4k3/3p4/8/8/8/8/3P4/3QK3", White); err != nil {
+				t.Fatalf("placement: %v", err)
+			}
+			rules := DefaultRules()
+			rules.AbilityEligibility = tc.eligibility
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("rules: %v", err)
+			}
+			err := eng.SetSideSetup(White, SideSetup{Abilities: AbilityList{AbilityScorch}, ByType: tc.byType})
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), tc.wantMsg) {
+					t.Fatalf("error %q does not name %q", err, tc.wantMsg)
+				}
+				return
+			}
+			queen := eng.board.ability[eng.board.pieceIndexBySquare(SquareD1)]
+			pawn := eng.board.ability[eng.board.pieceIndexBySquare(SquareD2)]
+			if queen != NewAbilitySet(AbilityScorch, AbilityBlazeRush) || pawn != NewAbilitySet(AbilityScorch) {
+				t.Fatalf("queen %v pawn %v", abilitySetToNames(queen), abilitySetToNames(pawn))
+			}
+			got := eng.State(StateOptions{}).TypeAbilities
+			if len(got) != 1 || strings.Join(got["white"]["queen"], ",") != "BlazeRush" {
+				t.Fatalf("state type abilities = %v", got)
+			}
+
+			restored := NewEngine()
+			if err := restored.Restore(eng.Snapshot()); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			if err := restored.Reset(); err != nil {
+				t.Fatalf("reset: %v", err)
+			}
+			if q := restored.board.ability[restored.board.pieceIndexBySquare(SquareD1)]; !q.Has(AbilityBlazeRush) {
+				t.Fatalf("restored queen lost BlazeRush: %v", abilitySetToNames(q))
+			}
+
+			replayed := NewEngine()
+			if err := replayed.LoadPGNAndContinue(eng.PGN(nil)); err != nil {
+				t.Fatalf("pgn: %v", err)
+			}
+			if q := replayed.board.ability[replayed.board.pieceIndexBySquare(SquareD1)]; !q.Has(AbilityBlazeRush) {
+				t.Fatalf("replayed queen lost BlazeRush: %v", abilitySetToNames(q))
+			}
+		})
+	}
+}
@@ -1,7 +1,13 @@
 // path: chessTest/internal/game/engine.go
 package game
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
 
 type MoveRequest struct {
 	From         Square
@@ -9,6 +15,21 @@ type MoveRequest struct {
 	Dir          Direction
 	Promotion    PieceType
 	HasPromotion bool
+	// Seq, when CheckSeq is set, must equal the engine's current sequence
+	// number; stale or duplicate submissions fail with ErrStaleSequence.
+	Seq      uint64
+	CheckSeq bool
+	// Drift, E or W, asks a Water side's mover to drift one square sideways
+	// at the end of the turn if that square is empty. DirNone skips it.
+	Drift Direction
+	// Shove asks an Earth side's piece on From to push the adjacent enemy on
+	// To one square further along the same line instead of moving.
+	Shove bool
+	// Draws, when set, replays a turn's recorded random draws, taken from
+	// its move event. The resolver consumes them in order before using its
+	// own generator, so a replay matches the original even if seed
+	// derivation has changed since.
+	Draws []uint32
 }
 
 type PieceState struct {
@@ -17,133 +38,616 @@ type PieceState struct {
 	Type      PieceType
 	Square    Square
 	Abilities []string
+	// Capabilities summarises what the piece's abilities let it do; see
+	// CapabilitiesOf.
+	Capabilities PieceCapabilities
+}
+
+type GameStatus uint8
+
+const (
+	StatusActive GameStatus = iota
+	StatusWhiteWins
+	StatusBlackWins
+	StatusDraw
+)
+
+var statusNames = [...]string{
+	StatusActive:    "active",
+	StatusWhiteWins: "white_wins",
+	StatusBlackWins: "black_wins",
+	StatusDraw:      "draw",
+}
+
+func (s GameStatus) String() string {
+	if int(s) < len(statusNames) {
+		return statusNames[s]
+	}
+	return "unknown"
+}
+
+// StateDetail selects how much of the board State reports.
+type StateDetail uint8
+
+const (
+	// StateFull includes every piece, ability list, and block facing.
+	StateFull StateDetail = iota
+	// StateSummary reports only turn, status, hash, lock, and last note; it is
+	// meant for cheap polling.
+	StateSummary
+)
+
+type StateOptions struct {
+	Detail StateDetail
 }
 
 type BoardState struct {
-	Pieces      []PieceState
-	Turn        Color
-	LastNote    string
-	Abilities   map[string][]string
-	BlockFacing map[int]Direction
-	Locked      bool
+	Pieces []PieceState
+	Turn   Color
+	Status GameStatus
+	// Phase is what the game is waiting for; see Engine.Phase.
+	Phase TurnPhase
+	// StatusReason explains how a finished game was decided.
+	StatusReason string `json:",omitempty"`
+	Hash         uint64
+	LastNote     string
+	Abilities    map[string][]string
+	// TypeAbilities maps color to piece type to the abilities that type holds
+	// on top of Abilities. Sides without per-type abilities are left out.
+	TypeAbilities map[string]map[string][]string
+	BlockFacing   map[int]Direction
+	// Charges holds each side's charge balance when charges are enabled.
+	Charges map[string]int
+	// Clocks holds each side's remaining time in milliseconds in timed games.
+	Clocks map[string]int64
+	// Seq counts accepted moves; clients echo it back to order submissions.
+	Seq    uint64
+	Locked bool
+	// EngineVersion and RulesFingerprint record which engine build and rule
+	// set produced the state; see EngineVersion and RulesConfig.Fingerprint.
+	EngineVersion    string `json:",omitempty"`
+	RulesFingerprint string `json:",omitempty"`
+}
+
+// BoardSummary is the part of a BoardState a StateSummary fills in, for
+// callers that send a summary on without the full state's fields.
+type BoardSummary struct {
+	Turn         Color
+	Status       GameStatus
+	Phase        TurnPhase
+	StatusReason string `json:",omitempty"`
+	Hash         uint64
+	LastNote     string
+	Seq          uint64
+	Locked       bool
+}
+
+// Summary returns the BoardSummary fields of st.
+func (st BoardState) Summary() BoardSummary {
+	return BoardSummary{
+		Turn:         st.Turn,
+		Status:       st.Status,
+		Phase:        st.Phase,
+		StatusReason: st.StatusReason,
+		Hash:         st.Hash,
+		LastNote:     st.LastNote,
+		Seq:          st.Seq,
+		Locked:       st.Locked,
+	}
 }
 
 type Engine struct {
-	board        boardSoA
-	history      []boardSoA
+	board   boardSoA
+	history []boardSoA
+	// rewinds runs alongside history; see rewindPoint.
+	rewinds      []rewindPoint
 	abilityLists [2]AbilityList
 	abilityMask  [2]AbilitySet
-	elements     [2]Element
-	doOverUsed   [2]bool
-	resolver     abilityResolver
-	blockFacing  map[int]Direction
-	locked       bool
-	lastNote     string
+	// typeAbilities holds each side's per-type abilities; see SideSetup.ByType.
+	typeAbilities [2]map[PieceType]AbilityList
+	elements      [2]Element
+	doOverUsed    [2]bool
+	resolver      abilityResolver
+	rules         RulesConfig
+	charges       [2]uint16
+	doOverDebt    [2]uint16
+	chargeCosts   [abilityCountInt]uint8
+	banned        AbilitySet
+	disabled      AbilitySet
+	ineligible    ineligibleTable
+	clocks        [2]time.Duration
+	seq           uint64
+	blockFacing   map[int]Direction
+	locked        bool
+	status        GameStatus
+	statusReason  string
+	lastNote      string
+	now           func() time.Time
+	turnStart     time.Time
+	moveLog       []MoveRecord
+	events        []Event
+	eventSeq      uint64
+	sink          func(Event)
+	// positions counts occurrences of each PositionKey for repetition.
+	positions map[uint64]uint8
+	// startFEN is the placement and side to move the game began from, with
+	// castling and en passant under VariantStandard, or "" for the initial
+	// position.
+	startFEN string
+	// seed is mixed into every turn's resolver seed; see WithSeed.
+	seed    uint64
+	logger  *log.Logger
+	metrics func(MoveMetrics)
+	// timings collects the handler runs of the move in progress for metrics.
+	timings []HandlerTiming
+	storage StorageHooks
+	// unconfigured marks the sides that have not set a loadout since the
+	// engine was created.
+	unconfigured [2]bool
+	// adjStreak counts the plies one side has led by the adjudication
+	// threshold: positive for White, negative for Black.
+	adjStreak int
+	// adjudication is set when the engine ended the game early.
+	adjudication *Adjudication
 }
 
-func NewEngine() *Engine {
+// NewEngine returns an engine at the initial position with the default
+// rules, then applies opts in order. It panics if an option fails, which only
+// WithRules and WithSides can do, given an invalid config; call Apply on the
+// new engine to handle that error instead.
+func NewEngine(opts ...Option) *Engine {
 	eng := &Engine{
 		board:       newBoard(),
 		history:     make([]boardSoA, 0, 16),
+		rewinds:     make([]rewindPoint, 0, 16),
 		resolver:    newAbilityResolver(),
+		rules:       DefaultRules(),
+		chargeCosts: DefaultChargeRules().costTable(),
 		blockFacing: make(map[int]Direction),
+		now:         time.Now,
+		moveLog:     make([]MoveRecord, 0, 64),
+		events:      make([]Event, 0, 128),
+	}
+	eng.unconfigured = [2]bool{true, true}
+	eng.turnStart = eng.now()
+	eng.resetPositions()
+	if err := eng.Apply(opts...); err != nil {
+		panic("game: NewEngine: " + err.Error())
 	}
 	return eng
 }
 
 func (e *Engine) Reset() error {
 	e.board = newBoard()
-	e.history = e.history[:0]
+	e.startFEN = ""
+	e.truncateHistory(0)
 	e.doOverUsed = [2]bool{}
 	e.lastNote = ""
 	e.locked = false
+	e.status = StatusActive
+	e.statusReason = ""
+	e.adjudication = nil
+	e.resetLog()
+	e.setVariantBoard()
+	e.resetCharges()
+	e.resetClocks()
 	for k := range e.blockFacing {
 		delete(e.blockFacing, k)
 	}
 	for i := range e.abilityMask {
-		e.board.addAbility(e.abilityMask[i], Color(i))
+		e.board.addAbility(e.pieceMasks(Color(i)), Color(i), e.ineligible)
 	}
+	e.resetPositions()
 	return nil
 }
 
+// SideSetup is one side's loadout.
+type SideSetup struct {
+	Abilities AbilityList
+	Element   Element
+	// ByType gives every piece of a type abilities on top of Abilities, such
+	// as BlazeRush for rooks only. Each must be eligible for its type.
+	ByType map[PieceType]AbilityList
+}
+
 func (e *Engine) SetSideConfig(color Color, abilities AbilityList, element Element) error {
+	return e.SetSideSetup(color, SideSetup{Abilities: abilities, Element: element})
+}
+
+// SetSideSetup applies one side's loadout, per-type abilities included.
+func (e *Engine) SetSideSetup(color Color, setup SideSetup) error {
 	if int(color) > 1 {
 		return ErrInvalidConfig
 	}
-	normalized := normalizeAbilities(abilities)
-	mask := NewAbilitySet(normalized...)
-	e.abilityLists[color.Index()] = normalized
-	e.abilityMask[color.Index()] = mask
-	e.elements[color.Index()] = element
-	e.board.addAbility(mask, color)
-	e.doOverUsed[color.Index()] = false
+	cfg, err := e.prepareSide(color, setup)
+	if err != nil {
+		return err
+	}
+	e.applySideConfig(color, cfg)
 	return nil
 }
 
+// SetSidesConfig applies both loadouts, or neither when either is rejected.
+// The error names the side at fault.
+func (e *Engine) SetSidesConfig(white, black SideSetup) error {
+	setups := [2]SideSetup{White: white, Black: black}
+	var cfgs [2]sideConfig
+	for i, setup := range setups {
+		cfg, err := e.prepareSide(Color(i), setup)
+		if err != nil {
+			return fmt.Errorf("%s: %w", Color(i), err)
+		}
+		cfgs[i] = cfg
+	}
+	for i, cfg := range cfgs {
+		e.applySideConfig(Color(i), cfg)
+	}
+	return nil
+}
+
+// sideConfig is a validated SideSetup.
+type sideConfig struct {
+	list    AbilityList
+	mask    AbilitySet
+	byType  map[PieceType]AbilityList
+	element Element
+}
+
+func (e *Engine) prepareSide(color Color, setup SideSetup) (sideConfig, error) {
+	cfg := sideConfig{list: normalizeAbilities(setup.Abilities), element: setup.Element}
+	cfg.mask = NewAbilitySet(cfg.list...)
+	if err := e.checkLoadout(color, cfg.mask); err != nil {
+		return sideConfig{}, err
+	}
+	byType, err := e.checkTypeLoadout(setup.ByType)
+	if err != nil {
+		return sideConfig{}, err
+	}
+	cfg.byType = byType
+	return cfg, nil
+}
+
+// checkLoadout rejects loadouts the game's rules do not allow.
+func (e *Engine) checkLoadout(color Color, mask AbilitySet) error {
+	if mask != 0 && e.rules.Standard() {
+		return ErrAbilityDisabled
+	}
+	if mask&e.banned != 0 {
+		return ErrAbilityBanned
+	}
+	if mask&e.disabled != 0 {
+		return ErrAbilityDisabled
+	}
+	return e.checkEligibility(color, mask)
+}
+
+// checkTypeLoadout normalizes per-type abilities and rejects any the rules
+// do not allow for that type. Types left with no abilities are dropped.
+func (e *Engine) checkTypeLoadout(byType map[PieceType]AbilityList) (map[PieceType]AbilityList, error) {
+	var out map[PieceType]AbilityList
+	for t, list := range byType {
+		if int(t) >= pieceTypeCount {
+			return nil, ErrInvalidConfig
+		}
+		normalized := normalizeAbilities(list)
+		if len(normalized) == 0 {
+			continue
+		}
+		mask := NewAbilitySet(normalized...)
+		if e.rules.Standard() {
+			return nil, ErrAbilityDisabled
+		}
+		if mask&e.banned != 0 {
+			return nil, ErrAbilityBanned
+		}
+		if mask&e.disabled != 0 {
+			return nil, ErrAbilityDisabled
+		}
+		for _, id := range normalized {
+			if e.ineligible[t].Has(id) {
+				return nil, fmt.Errorf("%w: %s cannot be used by %s (usable by %s)", ErrAbilityIneligible, id, t, e.rules.AbilityEligibility[id])
+			}
+		}
+		if out == nil {
+			out = make(map[PieceType]AbilityList, len(byType))
+		}
+		out[t] = normalized
+	}
+	return out, nil
+}
+
+func (e *Engine) applySideConfig(color Color, cfg sideConfig) {
+	e.abilityLists[color.Index()] = cfg.list
+	e.abilityMask[color.Index()] = cfg.mask
+	e.typeAbilities[color.Index()] = cfg.byType
+	e.elements[color.Index()] = cfg.element
+	e.board.addAbility(e.pieceMasks(color), color, e.ineligible)
+	e.doOverUsed[color.Index()] = false
+	e.unconfigured[color.Index()] = false
+	e.resetPositions()
+}
+
+// pieceMasks is the ability mask each piece type of color starts with: the
+// side loadout plus that type's own abilities.
+func (e *Engine) pieceMasks(color Color) [pieceTypeCount]AbilitySet {
+	var out [pieceTypeCount]AbilitySet
+	for t := range out {
+		out[t] = e.abilityMask[color.Index()] | NewAbilitySet(e.typeAbilities[color.Index()][PieceType(t)]...)
+	}
+	return out
+}
+
+// MoveResult describes what a single Move call did.
+type MoveResult struct {
+	// Captures lists pieces taken by the moving piece itself.
+	Captures []Event
+	// AbilityEvents lists ability removals and DoOver rewinds.
+	AbilityEvents []Event
+	// Events lists everything the move logged, in Seq order. Captures and
+	// AbilityEvents are views of it.
+	Events []Event
+	// Removals is the turn's removal cascade; see RemovalGraph.
+	Removals []RemovalStep
+	// Check reports that the move left the enemy king attacked.
+	Check bool
+	// StepsRemaining is the mover's remaining segment budget. Turns resolve
+	// atomically, so it is always zero once Move returns.
+	StepsRemaining int
+	// TurnEnded reports that the turn passed to the opponent.
+	TurnEnded bool
+	Status    GameStatus
+	Hash      uint64
+	Seq       uint64
+}
+
+// Move plays req. The whole turn is applied and committed within the call;
+// the engine never holds a partly played turn, so a client that disconnects
+// mid-turn leaves nothing behind to expire, commit or cancel.
 func (e *Engine) Move(req MoveRequest) error {
+	_, err := e.MoveEx(req)
+	return err
+}
+
+// MoveEx plays req like Move and reports the events it produced, so callers
+// need not diff State.
+func (e *Engine) MoveEx(req MoveRequest) (MoveResult, error) {
+	if e.storage.Before != nil {
+		if err := e.storage.Before(req); err != nil {
+			return MoveResult{Status: e.status, Hash: e.board.hash(), Seq: e.seq}, err
+		}
+	}
+	started := time.Now()
+	e.timings = e.timings[:0]
+	mark := len(e.events)
+	turn := e.board.turn
+	err := e.move(req)
+	res := MoveResult{
+		TurnEnded: e.board.turn != turn,
+		Status:    e.status,
+		Hash:      e.board.hash(),
+		Seq:       e.seq,
+	}
+	if mark < len(e.events) {
+		res.Events = cloneEvents(e.events[mark:])
+		res.Removals = RemovalGraph(res.Events)
+	}
+	for _, ev := range res.Events {
+		switch ev.Kind {
+		case EventCapture:
+			res.Captures = append(res.Captures, ev)
+		case EventAbilityRemoval, EventDoOver:
+			res.AbilityEvents = append(res.AbilityEvents, ev)
+		case EventCheck:
+			res.Check = true
+		}
+	}
+	if e.storage.After != nil && (err == nil || errors.Is(err, ErrDoOverActivated)) {
+		e.storage.After(req, res)
+	}
+	if e.metrics != nil {
+		m := MoveMetrics{Elapsed: time.Since(started), Err: err, Events: len(res.Events), TurnEnded: res.TurnEnded}
+		if len(e.timings) > 0 {
+			m.Handlers = append([]HandlerTiming(nil), e.timings...)
+		}
+		e.metrics(m)
+	}
+	return res, err
+}
+
+func (e *Engine) move(req MoveRequest) error {
 	if e.locked {
 		return ErrEngineLocked
 	}
-	idx := e.board.pieceIndexBySquare(req.From)
-	if idx < 0 {
-		return ErrInvalidMove
+	if req.CheckSeq && req.Seq != e.seq {
+		return ErrStaleSequence
 	}
-	if e.board.colors[idx] != e.board.turn {
-		return ErrInvalidMove
+	if e.status != StatusActive {
+		return ErrGameOver
 	}
-	if req.To == SquareInvalid {
-		return ErrInvalidMove
+	if err := e.checkFlag(e.now()); err != nil {
+		return err
 	}
-	if e.board.squareOccupiedBy(e.board.colors[idx], req.To) {
-		return ErrInvalidMove
+	if err := e.checkDrift(req.Drift); err != nil {
+		return err
 	}
-	color := e.board.colors[idx]
-	enemyColor := color.Opposite()
-	captureIdx := e.board.pieceIndexBySquare(req.To)
-	if err := e.validateMove(idx, req.To, captureIdx >= 0); err != nil {
+	if err := e.checkStrict(req); err != nil {
 		return err
 	}
+	if req.Shove {
+		return e.shove(req)
+	}
+	idx, captureIdx, err := e.checkMove(req)
+	if err != nil {
+		if err == ErrCaptureBlocked {
+			e.lastNote = "Capture blocked"
+		}
+		return err
+	}
+	color := e.board.colors[idx]
+	enemyColor := color.Opposite()
 	prev := e.board.clone()
-	e.history = append(e.history, prev)
+	prevDoOver := e.doOverUsed
+	prevCharges := e.charges
+	e.pushHistory(prev)
 	if captureIdx >= 0 {
 		e.board.removePiece(captureIdx)
 	}
 	e.board.movePiece(idx, req.To)
-	seed := uint64(e.board.ply)<<32 | uint64(e.board.ids[idx])<<16 | uint64(req.To)
+	if e.rules.Standard() {
+		e.board.completeStandard(idx, req.From, req.To, promotionChoice(req))
+	}
+	seed := e.seed ^ (uint64(e.board.ply)<<32 | uint64(e.board.ids[idx])<<16 | uint64(req.To))
 	ctx := resolveContext{
-		board:        &e.board,
-		mover:        idx,
-		target:       req.To,
-		captureIdx:   captureIdx,
-		sideMask:     e.abilityMask[color.Index()],
-		enemyMask:    e.abilityMask[enemyColor.Index()],
-		doOverUsed:   &e.doOverUsed,
-		requestedDir: req.Dir,
-		sideElement:  e.elements[color.Index()],
-		enemyElement: e.elements[enemyColor.Index()],
-		seed:         seed,
-	}
-	res, err := e.resolver.resolve(ctx)
+		board:         &e.board,
+		mover:         idx,
+		target:        req.To,
+		captureIdx:    captureIdx,
+		sideMask:      e.abilityMask[color.Index()],
+		enemyMask:     e.abilityMask[enemyColor.Index()],
+		doOverUsed:    &e.doOverUsed,
+		requestedDir:  req.Dir,
+		sideElement:   e.elements[color.Index()],
+		enemyElement:  e.elements[enemyColor.Index()],
+		seed:          seed,
+		removalBudget: e.rules.removalBudget(),
+		ineligible:    e.ineligible,
+		replayDraws:   req.Draws,
+	}
+	if e.rules.Charges.Enabled {
+		ctx.charges = &e.charges
+		ctx.chargeCosts = e.chargeCosts
+	}
+	if e.metrics != nil {
+		ctx.timings = &e.timings
+	}
+	var res resolveResult
+	if !e.rules.Standard() {
+		res, err = e.resolver.resolve(ctx)
+	}
 	if err != nil {
+		var handlerErr *HandlerError
+		if errors.As(err, &handlerErr) {
+			e.logf("game: move %s-%s rejected: %v", SquareToCoord(req.From), SquareToCoord(req.To), err)
+		}
+		e.board = prev
+		e.doOverUsed = prevDoOver
+		e.charges = prevCharges
+		e.truncateHistory(len(e.history) - 1)
 		return err
 	}
 	if res.doOver {
-		last := e.history[len(e.history)-1]
-		e.board = last
-		e.history = e.history[:len(e.history)-1]
+		e.rewind(e.rules.rewindPlies())
+		// The mover's effects were rewound; the defender's DoOver stays paid.
+		e.charges[color.Index()] = prevCharges[color.Index()]
+		if e.rules.Charges.Enabled {
+			e.doOverDebt[enemyColor.Index()] += uint16(e.rules.DoOverTurnCost)
+		}
 		e.lastNote = "DoOver rewind"
+		e.seq++
+		e.emit(Event{Ply: e.board.ply, Kind: EventDoOver, Color: enemyColor, Ability: AbilityDoOver, Square: req.To})
+		e.recordPosition()
+		e.adjudicateDraw(enemyColor, e.board.ply)
 		return ErrDoOverActivated
 	}
 	if res.setBlock {
 		e.blockFacing[e.board.ids[idx]] = res.blockDir
 	}
+	driftFrom, driftNote := e.drift(idx, req.Drift, &res)
+	segmentAt := e.now()
+	e.moveLog = append(e.moveLog, MoveRecord{
+		Ply:       e.board.ply,
+		Color:     color,
+		PieceID:   e.board.ids[idx],
+		From:      req.From,
+		To:        req.To,
+		Capture:   captureIdx >= 0,
+		Drift:     driftDir(driftFrom, req.Drift),
+		TurnStart: e.turnStart,
+		SegmentAt: segmentAt,
+		TurnEnd:   segmentAt,
+	})
+	if prev.types[idx] != e.board.types[idx] {
+		e.moveLog[len(e.moveLog)-1].Promotion = e.board.types[idx]
+	}
+	e.chargeClock(color, segmentAt)
+	e.turnStart = segmentAt
+	e.logTurn(&prev, idx, captureIdx, driftFrom, &res)
+	e.accrueCharges(color)
 	e.board.turn = e.board.turn.Opposite()
 	e.board.ply++
-	e.lastNote = ""
+	e.seq++
+	e.lastNote = driftNote
+	if res.setBlock {
+		e.lastNote = fmt.Sprintf("BlockPath facing %s (%s for %s)", res.blockDir, relativeLabel(res.blockDir.Relative(color)), color)
+	}
+	e.recordPly()
+	e.updateStatus(&prev, color)
 	return nil
 }
 
+// updateStatus ends the game when the mover's turn removed the enemy king, or
+// left a position where no further removal is possible.
+func (e *Engine) updateStatus(prev *boardSoA, mover Color) {
+	if e.rules.Standard() {
+		e.updateStandardStatus(mover, prev.ply)
+		return
+	}
+	enemy := mover.Opposite().Index()
+	if prev.pieceMask[enemy][King] == 0 || e.board.pieceMask[enemy][King] != 0 {
+		e.adjudicateDraw(mover, prev.ply)
+		e.adjudicate(mover, prev.ply)
+		return
+	}
+	status := StatusWhiteWins
+	if mover == Black {
+		status = StatusBlackWins
+	}
+	e.finishCaused(status, mover.String()+" captured the king", mover, prev.ply, e.kingRemoval(mover.Opposite()))
+	e.lastNote = "King captured"
+}
+
+// Forfeit ends an active game as a loss for loser, for reasons outside the
+// board such as a player who stopped answering. reason becomes the status
+// reason.
+func (e *Engine) Forfeit(loser Color, reason string) error {
+	if e.locked {
+		return ErrEngineLocked
+	}
+	if e.status != StatusActive {
+		return ErrGameOver
+	}
+	winner := loser.Opposite()
+	status := StatusWhiteWins
+	if winner == Black {
+		status = StatusBlackWins
+	}
+	e.finish(status, reason, winner, e.board.ply)
+	e.lastNote = "Forfeit"
+	return nil
+}
+
+func (e *Engine) finish(status GameStatus, reason string, by Color, ply uint32) {
+	e.finishCaused(status, reason, by, ply, 0)
+}
+
+// finishCaused is finish for an ending the event numbered cause decided.
+func (e *Engine) finishCaused(status GameStatus, reason string, by Color, ply uint32, cause uint64) {
+	e.status = status
+	e.statusReason = reason
+	e.logf("game: over at ply %d: %s", ply, reason)
+	e.emit(Event{Ply: ply, Kind: EventGameOver, Color: by, Note: reason, Cause: cause})
+}
+
+// validateMove accepts pawn moves only; other pieces, knights included, have
+// no movement rules in battle chess. VariantStandard moves every piece by the
+// orthodox rules instead.
 func (e *Engine) validateMove(idx int, to Square, isCapture bool) error {
+	if e.rules.Standard() {
+		if !e.board.standardLegal(idx, to) {
+			return ErrInvalidMove
+		}
+		return nil
+	}
 	from := e.board.squares[idx]
 	typ := e.board.types[idx]
 	color := e.board.colors[idx]
@@ -187,6 +691,8 @@ func (e *Engine) validPawnMove(color Color, from, to Square, isCapture bool) boo
 	if (toRank - fromRank) == dir {
 		return e.board.empty(to)
 	}
+	// Battle chess has no en passant, so a double step leaves no target on
+	// the square it skipped; VariantStandard's is set in completeStandard.
 	if (toRank-fromRank) == 2*dir && fromRank == startRank {
 		middle := Square(int(from) + dir*8)
 		return e.board.empty(middle) && e.board.empty(to)
@@ -194,7 +700,26 @@ func (e *Engine) validPawnMove(color Color, from, to Square, isCapture bool) boo
 	return false
 }
 
-func (e *Engine) State() BoardState {
+// State snapshots the board. With no options it reports full detail. Every
+// slice and map in the result is freshly built, so callers may keep or modify
+// it without touching the engine.
+func (e *Engine) State(opts ...StateOptions) BoardState {
+	var opt StateOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Detail == StateSummary {
+		return BoardState{
+			Turn:         e.board.turn,
+			Status:       e.status,
+			Phase:        e.Phase(),
+			StatusReason: e.statusReason,
+			Hash:         e.board.hash(),
+			LastNote:     e.lastNote,
+			Seq:          e.seq,
+			Locked:       e.locked,
+		}
+	}
 	pieces := make([]PieceState, 0, len(e.board.ids))
 	for i := range e.board.ids {
 		if !e.board.alive[i] {
@@ -202,29 +727,134 @@ func (e *Engine) State() BoardState {
 		}
 		abilities := abilitySetToNames(e.board.ability[i])
 		pieces = append(pieces, PieceState{
-			ID:        e.board.ids[i],
-			Color:     e.board.colors[i],
-			Type:      e.board.types[i],
-			Square:    e.board.squares[i],
-			Abilities: abilities,
+			ID:           e.board.ids[i],
+			Color:        e.board.colors[i],
+			Type:         e.board.types[i],
+			Square:       e.board.squares[i],
+			Abilities:    abilities,
+			Capabilities: CapabilitiesOf(e.board.ability[i]),
 		})
 	}
 	abilityMap := map[string][]string{
 		White.String(): abilityListToStrings(e.abilityLists[White.Index()]),
 		Black.String(): abilityListToStrings(e.abilityLists[Black.Index()]),
 	}
-	blockCopy := make(map[int]Direction, len(e.blockFacing))
-	for id, dir := range e.blockFacing {
-		blockCopy[id] = dir
+	typeMap := typeAbilityView(e.typeAbilities)
+	blockCopy := cloneFacing(e.blockFacing)
+	var charges map[string]int
+	if e.rules.Charges.Enabled {
+		charges = map[string]int{
+			White.String(): int(e.charges[White.Index()]),
+			Black.String(): int(e.charges[Black.Index()]),
+		}
+	}
+	var clocks map[string]int64
+	if e.rules.TimeControl.Timed() {
+		clocks = map[string]int64{
+			White.String(): e.Clock(White).Milliseconds(),
+			Black.String(): e.Clock(Black).Milliseconds(),
+		}
 	}
 	return BoardState{
-		Pieces:      pieces,
-		Turn:        e.board.turn,
-		LastNote:    e.lastNote,
-		Abilities:   abilityMap,
-		BlockFacing: blockCopy,
-		Locked:      e.locked,
+		Pieces:        pieces,
+		Turn:          e.board.turn,
+		Status:        e.status,
+		Phase:         e.Phase(),
+		StatusReason:  e.statusReason,
+		Hash:          e.board.hash(),
+		LastNote:      e.lastNote,
+		Abilities:     abilityMap,
+		TypeAbilities: typeMap,
+		BlockFacing:   blockCopy,
+		Charges:       charges,
+		Clocks:        clocks,
+		Seq:           e.seq,
+		Locked:        e.locked,
+
+		EngineVersion:    EngineVersion,
+		RulesFingerprint: e.RulesFingerprint(),
+	}
+}
+
+// Clone returns a deep copy of st, for callers that share one State between
+// readers that may modify it.
+func (st BoardState) Clone() BoardState {
+	out := st
+	if st.Pieces != nil {
+		out.Pieces = make([]PieceState, len(st.Pieces))
+		for i, pc := range st.Pieces {
+			pc.Abilities = cloneStrings(pc.Abilities)
+			pc.Capabilities = pc.Capabilities.clone()
+			out.Pieces[i] = pc
+		}
+	}
+	if st.Abilities != nil {
+		out.Abilities = make(map[string][]string, len(st.Abilities))
+		for side, list := range st.Abilities {
+			out.Abilities[side] = cloneStrings(list)
+		}
+	}
+	if st.TypeAbilities != nil {
+		out.TypeAbilities = make(map[string]map[string][]string, len(st.TypeAbilities))
+		for side, byType := range st.TypeAbilities {
+			inner := make(map[string][]string, len(byType))
+			for t, list := range byType {
+				inner[t] = cloneStrings(list)
+			}
+			out.TypeAbilities[side] = inner
+		}
+	}
+	if st.BlockFacing != nil {
+		out.BlockFacing = cloneFacing(st.BlockFacing)
+	}
+	if st.Charges != nil {
+		out.Charges = make(map[string]int, len(st.Charges))
+		for side, n := range st.Charges {
+			out.Charges[side] = n
+		}
+	}
+	if st.Clocks != nil {
+		out.Clocks = make(map[string]int64, len(st.Clocks))
+		for side, ms := range st.Clocks {
+			out.Clocks[side] = ms
+		}
+	}
+	return out
+}
+
+// typeAbilityView names each side's per-type abilities for BoardState, or
+// returns nil when no side has any.
+func typeAbilityView(sides [2]map[PieceType]AbilityList) map[string]map[string][]string {
+	var out map[string]map[string][]string
+	for i, byType := range sides {
+		if len(byType) == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]map[string][]string, 2)
+		}
+		side := make(map[string][]string, len(byType))
+		for t, list := range byType {
+			side[t.String()] = abilityListToStrings(list)
+		}
+		out[Color(i).String()] = side
+	}
+	return out
+}
+
+func cloneFacing(in map[int]Direction) map[int]Direction {
+	out := make(map[int]Direction, len(in))
+	for id, dir := range in {
+		out[id] = dir
+	}
+	return out
+}
+
+func cloneStrings(in []string) []string {
+	if in == nil {
+		return nil
 	}
+	return append([]string(nil), in...)
 }
 
 func abilitySetToNames(set AbilitySet) []string {
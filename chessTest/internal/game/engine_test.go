@@ -0,0 +1,374 @@
+// path: chessTest/internal/game/engine_test.go
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateSummaryDetail(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	full := eng.State()
+	summary := eng.State(StateOptions{Detail: StateSummary})
+	if len(full.Pieces) == 0 || len(full.BlockFacing) == 0 || len(full.Abilities) == 0 {
+		t.Fatalf("full state missing detail: %+v", full)
+	}
+	if summary.Pieces != nil || summary.Abilities != nil || summary.BlockFacing != nil {
+		t.Fatalf("summary state leaked detail: %+v", summary)
+	}
+	if summary.Turn != full.Turn || summary.Hash != full.Hash || summary.Status != full.Status || summary.LastNote != full.LastNote {
+		t.Fatalf("summary disagrees with full state: %+v vs %+v", summary, full)
+	}
+}
+
+func TestStateHashTracksPosition(t *testing.T) {
+	eng := NewEngine()
+	start := eng.State(StateOptions{Detail: StateSummary}).Hash
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	moves := []MoveRequest{
+		{From: SquareE2, To: SquareE4},
+		{From: SquareD7, To: SquareD5},
+	}
+	for _, mv := range moves {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %v: %v", mv, err)
+		}
+	}
+	before := eng.State(StateOptions{Detail: StateSummary}).Hash
+	if before == start {
+		t.Fatalf("hash did not change after moves")
+	}
+	if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != ErrDoOverActivated {
+		t.Fatalf("expected do-over, got %v", err)
+	}
+	if got := eng.State(StateOptions{Detail: StateSummary}).Hash; got != before {
+		t.Fatalf("hash after rewind = %x want %x", got, before)
+	}
+}
+
+func TestKingCaptureEndsGame(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.LoadPlacement("8/8/8/8/8/3k4/4P3/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareD3}); err != nil {
+		t.Fatalf("capture king: %v", err)
+	}
+	if got := eng.State().Status; got != StatusWhiteWins {
+		t.Fatalf("status = %s want %s", got, StatusWhiteWins)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE1, To: SquareE2}); err != ErrGameOver {
+		t.Fatalf("expected ErrGameOver, got %v", err)
+	}
+}
+
+func TestForfeitEndsGame(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.Forfeit(White, "white left"); err != nil {
+		t.Fatalf("forfeit: %v", err)
+	}
+	state := eng.State()
+	if state.Status != StatusBlackWins || state.StatusReason != "white left" {
+		t.Fatalf("status = %s %q", state.Status, state.StatusReason)
+	}
+	if events := eng.Events(); len(events) != 1 || events[0].Kind != EventGameOver || events[0].Color != Black {
+		t.Fatalf("events = %+v", events)
+	}
+	if err := eng.Forfeit(Black, "too late"); err != ErrGameOver {
+		t.Fatalf("second forfeit = %v want ErrGameOver", err)
+	}
+}
+
+func TestMoveLogTimestamps(t *testing.T) {
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := base
+	eng := NewEngine()
+	eng.SetClock(func() time.Time { return now })
+
+	now = base.Add(3 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != nil {
+		t.Fatalf("white move: %v", err)
+	}
+	now = base.Add(10 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareD7, To: SquareD5}); err != nil {
+		t.Fatalf("black move: %v", err)
+	}
+	now = base.Add(11 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE3}); err != ErrInvalidMove {
+		t.Fatalf("expected invalid move, got %v", err)
+	}
+
+	log := eng.MoveLog()
+	if len(log) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(log))
+	}
+	if log[0].Color != White || log[0].From != SquareE2 || log[0].To != SquareE4 {
+		t.Fatalf("unexpected first record %+v", log[0])
+	}
+	if got := log[0].ThinkTime(); got != 3*time.Second {
+		t.Fatalf("white think time = %v want 3s", got)
+	}
+	if got := log[1].ThinkTime(); got != 7*time.Second {
+		t.Fatalf("black think time = %v want 7s", got)
+	}
+	if !log[1].TurnStart.Equal(log[0].TurnEnd) {
+		t.Fatalf("black turn should start when white's ends")
+	}
+	if !eng.TurnStarted().Equal(base.Add(10 * time.Second)) {
+		t.Fatalf("turn started = %v", eng.TurnStarted())
+	}
+
+	now = base.Add(time.Minute)
+	if err := eng.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if len(eng.MoveLog()) != 0 || !eng.TurnStarted().Equal(now) {
+		t.Fatalf("reset did not clear move log")
+	}
+}
+
+func TestDeadPositionDraw(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		move      *MoveRequest
+		want      GameStatus
+	}{
+		{name: "pawnless load", placement: "4k3/8/8/8/8/8/8/R3K2R", want: StatusDraw},
+		{name: "last pawn stranded", placement: "4k2n/6P1/8/8/8/8/8/4K3", move: &MoveRequest{From: SquareG7, To: SquareH8}, want: StatusDraw},
+		{name: "pawn still mobile", placement: "4k3/8/8/3p4/4P3/8/8/4K3", move: &MoveRequest{From: SquareE4, To: SquareD5}, want: StatusActive},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement(tc.placement, White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			if tc.move != nil {
+				if err := eng.Move(*tc.move); err != nil {
+					t.Fatalf("move: %v", err)
+				}
+			}
+			state := eng.State()
+			if state.Status != tc.want {
+				t.Fatalf("status = %s want %s", state.Status, tc.want)
+			}
+			if (tc.want == StatusDraw) != (state.StatusReason != "") {
+				t.Fatalf("unexpected reason %q for %s", state.StatusReason, state.Status)
+			}
+		})
+	}
+}
+
+func TestMoveExReportsOutcome(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityScatterShot}, ElementFire); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := eng.LoadPlacement("4k3/8/8/2npn3/4P3/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5})
+	if err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if len(res.Captures) != 1 || res.Captures[0].Type != Pawn || res.Captures[0].Square != SquareD5 {
+		t.Fatalf("unexpected captures %+v", res.Captures)
+	}
+	if len(res.AbilityEvents) != 2 || res.AbilityEvents[0].Ability != AbilityScatterShot {
+		t.Fatalf("unexpected ability events %+v", res.AbilityEvents)
+	}
+	if !res.TurnEnded || res.StepsRemaining != 0 || res.Seq != 1 {
+		t.Fatalf("unexpected turn bookkeeping %+v", res)
+	}
+	if res.Hash != eng.State().Hash {
+		t.Fatalf("result hash %d does not match state", res.Hash)
+	}
+
+	res, err = eng.MoveEx(MoveRequest{From: SquareE8, To: SquareE7})
+	if err != ErrInvalidMove || res.TurnEnded || len(res.Captures) != 0 {
+		t.Fatalf("rejected move reported %+v, %v", res, err)
+	}
+}
+
+func TestExtraRemovalsRule(t *testing.T) {
+	cases := []struct {
+		name     string
+		extra    int
+		removals int
+	}{
+		{name: "default budget", extra: 0, removals: 2},
+		{name: "single removal", extra: 1, removals: 1},
+		{name: "disabled", extra: NoExtraRemovals, removals: 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			rules := DefaultRules()
+			rules.ExtraRemovals = tc.extra
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("rules: %v", err)
+			}
+			if err := eng.SetSideConfig(White, AbilityList{AbilityScatterShot}, ElementFire); err != nil {
+				t.Fatalf("config: %v", err)
+			}
+			if err := eng.LoadPlacement("4k3/8/8/2npn3/4P3/8/8/4K3", White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5})
+			if err != nil {
+				t.Fatalf("move: %v", err)
+			}
+			if got := len(res.AbilityEvents); got != tc.removals {
+				t.Fatalf("removals = %d want %d", got, tc.removals)
+			}
+		})
+	}
+	rules := DefaultRules()
+	rules.ExtraRemovals = maxRemovalBudget + 1
+	if err := NewEngine().SetRules(rules); err != ErrInvalidRules {
+		t.Fatalf("expected ErrInvalidRules, got %v", err)
+	}
+}
+
+func TestDoOverRewindDepthAndCost(t *testing.T) {
+	for _, restored := range []bool{false, true} {
+		eng := NewEngine()
+		rules := chargeRules(3, 1, 5)
+		rules.DoOverRewindPlies = 4
+		rules.DoOverTurnCost = 2
+		if err := eng.SetRules(rules); err != nil {
+			t.Fatalf("set rules: %v", err)
+		}
+		if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+			t.Fatalf("configure black: %v", err)
+		}
+		// Black's d5xe4 is the capture the rewind has to reach back across.
+		for _, mv := range []MoveRequest{
+			{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5},
+			{From: SquareA2, To: SquareA3}, {From: SquareD5, To: SquareE4},
+			{From: SquareD2, To: SquareD3}, {From: SquareH7, To: SquareH6},
+		} {
+			if err := eng.Move(mv); err != nil {
+				t.Fatalf("move %v: %v", mv, err)
+			}
+		}
+		if restored {
+			resumed := NewEngine()
+			if err := resumed.Restore(eng.Snapshot()); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			eng = resumed
+		}
+		if err := eng.Move(MoveRequest{From: SquareD3, To: SquareE4}); err != ErrDoOverActivated {
+			t.Fatalf("restored=%v: expected do-over, got %v", restored, err)
+		}
+		state := eng.State()
+		if state.Turn != Black || len(eng.MoveLog()) != 3 {
+			t.Fatalf("restored=%v: rewound to turn %s with %d logged moves, want black with 3", restored, state.Turn, len(eng.MoveLog()))
+		}
+		if idx := eng.board.pieceIndexBySquare(SquareE4); idx < 0 || eng.board.colors[idx] != White {
+			t.Fatalf("restored=%v: white pawn should be back on e4", restored)
+		}
+		if got := eng.Charges(Black); got != 2 {
+			t.Fatalf("restored=%v: black charges after do-over = %d want 2", restored, got)
+		}
+		if err := eng.Move(MoveRequest{From: SquareD5, To: SquareE4}); err != nil {
+			t.Fatalf("restored=%v: replay capture: %v", restored, err)
+		}
+		if got := eng.Charges(Black); got != 1 {
+			t.Fatalf("restored=%v: black charges after paying the turn cost = %d want 1", restored, got)
+		}
+	}
+}
+
+func TestDoOverRewindRestoresFacingAndRepetition(t *testing.T) {
+	cases := []struct {
+		name       string
+		plies      int
+		restored   bool
+		wantFacing bool
+	}{
+		{name: "reply unwound", plies: 2, wantFacing: true},
+		{name: "BlockPath turn unwound", plies: 3},
+		{name: "BlockPath turn unwound after restore", plies: 3, restored: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			rules := DefaultRules()
+			rules.DoOverRewindPlies = tc.plies
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("set rules: %v", err)
+			}
+			if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+				t.Fatalf("configure white: %v", err)
+			}
+			if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+				t.Fatalf("configure black: %v", err)
+			}
+			var keys []uint64
+			for _, mv := range []MoveRequest{{From: SquareE2, To: SquareE4, Dir: DirN}, {From: SquareD7, To: SquareD5}} {
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %v: %v", mv, err)
+				}
+				keys = append(keys, eng.PositionKey())
+			}
+			if tc.restored {
+				resumed := NewEngine()
+				if err := resumed.Restore(eng.Snapshot()); err != nil {
+					t.Fatalf("restore: %v", err)
+				}
+				eng = resumed
+			}
+			if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != ErrDoOverActivated {
+				t.Fatalf("expected do-over, got %v", err)
+			}
+			if got := len(eng.State().BlockFacing) > 0; got != tc.wantFacing {
+				t.Fatalf("facing present = %v want %v", got, tc.wantFacing)
+			}
+			if tc.restored {
+				return
+			}
+			// The rewind unwinds plies-1 played plies besides the capture.
+			for i, key := range keys {
+				want := uint8(1)
+				if i >= len(keys)-(tc.plies-1) {
+					want = 0
+				}
+				if got := eng.positions[key]; got != want {
+					t.Fatalf("position after ply %d counted %d times, want %d", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDoubleStepLeavesNoEnPassant(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.LoadPlacement("4k3/8/8/8/3p4/8/4P3/4K3", White); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != nil {
+		t.Fatalf("double step: %v", err)
+	}
+	for _, mv := range eng.LegalMoves() {
+		if mv.From == SquareD4 && mv.To == SquareE3 {
+			t.Fatalf("d4xe3 offered after e2-e4")
+		}
+	}
+	if err := eng.Move(MoveRequest{From: SquareD4, To: SquareE3}); err != ErrInvalidMove {
+		t.Fatalf("d4xe3 = %v, want ErrInvalidMove", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareD4, To: SquareD3}); err != nil {
+		t.Fatalf("d4-d3: %v", err)
+	}
+}
@@ -1,18 +1,64 @@
 // path: chessTest/internal/game/errors.go
 package game
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 type AbilityConfigError string
 
 func (e AbilityConfigError) Error() string { return string(e) }
 
+// HandlerError reports an ability handler that was aborted by the dispatch
+// guard. Err is ErrHandlerTimeout, ErrHandlerPanic or ErrHandlerBusy.
+type HandlerError struct {
+	Ability Ability
+	Err     error
+}
+
+func (e *HandlerError) Error() string { return fmt.Sprintf("%s handler: %v", e.Ability, e.Err) }
+
+func (e *HandlerError) Unwrap() error { return e.Err }
+
+// StrictError reports a request that RulesConfig.Strict refused to fill in
+// with a default. It unwraps to ErrStrictDefault.
+type StrictError struct {
+	// Field names the request field at fault, such as "dir".
+	Field  string
+	Reason string
+}
+
+func (e *StrictError) Error() string { return fmt.Sprintf("strict: %s: %s", e.Field, e.Reason) }
+
+func (e *StrictError) Unwrap() error { return ErrStrictDefault }
+
 var (
 	ErrEngineLocked                             = errors.New("engine locked")
 	ErrInvalidConfig                            = errors.New("invalid configuration")
 	ErrInvalidMove                              = errors.New("invalid move")
 	ErrDoOverActivated                          = errors.New("do-over activated")
 	ErrCaptureBlocked                           = errors.New("capture blocked")
+	ErrInvalidPosition                          = errors.New("invalid position")
+	ErrGameOver                                 = errors.New("game over")
+	ErrGameInProgress                           = errors.New("game in progress")
+	ErrInvalidRules                             = errors.New("invalid rules")
+	ErrStaleSequence                            = errors.New("stale move sequence")
+	ErrInvalidPGN                               = errors.New("invalid pgn")
+	ErrIllegalShove                             = errors.New("illegal shove")
+	ErrIllegalDrift                             = errors.New("illegal drift")
+	ErrAbilityBanned                            = errors.New("ability banned by the game rules")
+	ErrAbilityDisabled                          = errors.New("ability not enabled for this game")
+	ErrAbilityIneligible                        = errors.New("ability not usable by these pieces")
+	ErrTutorialMove                             = errors.New("move not part of this tutorial step")
+	ErrTutorialFinished                         = errors.New("tutorial finished")
+	ErrInvalidPuzzle                            = errors.New("invalid puzzle")
+	ErrAssessmentDisabled                       = errors.New("engine assessment disabled for competitive play")
+	ErrHandlerTimeout                           = errors.New("handler timed out")
+	ErrHandlerPanic                             = errors.New("handler panicked")
+	ErrHandlerBusy                              = errors.New("too many handlers still running past their deadline")
+	ErrStrictDefault                            = errors.New("request relies on a default strict rules forbid")
+	ErrInvalidHistory                           = errors.New("invalid history")
 	ErrConflictingAugmentors AbilityConfigError = "conflicting augmentors"
 	ErrInvalidOverload       AbilityConfigError = "invalid overload loadout"
 )
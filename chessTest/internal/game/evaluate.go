@@ -0,0 +1,232 @@
+// path: chessTest/internal/game/evaluate.go
+package game
+
+import "math/bits"
+
+// Evaluation weights, in centipawns per unit of each term.
+const (
+	evalMaterial  = 100
+	evalMobility  = 10
+	evalPassed    = 20
+	evalDoubled   = -15
+	evalIsolated  = -10
+	evalKingZone  = 10
+	evalInCheck   = 50
+	evalPotential = 15
+)
+
+// PawnStructure counts structural pawn features for one side.
+type PawnStructure struct {
+	Doubled  int
+	Isolated int
+	Passed   int
+}
+
+// SideEvaluation is one side's share of an Evaluation.
+type SideEvaluation struct {
+	// Material in pawn units (P=1, N=B=3, R=5, Q=9).
+	Material int
+	// Mobility counts the legal moves available if it were this side's turn.
+	Mobility int
+	// StepBudget is how many segments a turn may spend. Turns resolve
+	// atomically, so it is one while the side has a legal move.
+	StepBudget int
+	Pawns      PawnStructure
+	// KingZoneAttacked counts squares next to the king the enemy attacks.
+	KingZoneAttacked int
+	InCheck          bool
+	// AbilityPotential weighs the side's loadout: offensive and temporal
+	// abilities count double, a spent DoOver or an unaffordable charge cost
+	// counts nothing.
+	AbilityPotential int
+	// Score is the side's total in centipawns.
+	Score int
+}
+
+// Evaluation is a static breakdown of a position. Score is from White's point
+// of view.
+type Evaluation struct {
+	White SideEvaluation
+	Black SideEvaluation
+	Score int
+}
+
+// Evaluate scores the position held by snap without touching any live engine.
+func Evaluate(snap Snapshot) (Evaluation, error) {
+	eng := NewEngine()
+	if err := eng.Restore(snap); err != nil {
+		return Evaluation{}, err
+	}
+	return eng.Evaluate(), nil
+}
+
+// Evaluate scores the current position.
+func (e *Engine) Evaluate() Evaluation {
+	out := Evaluation{White: e.evaluateSide(White), Black: e.evaluateSide(Black)}
+	out.Score = out.White.Score - out.Black.Score
+	return out
+}
+
+func (e *Engine) evaluateSide(color Color) SideEvaluation {
+	var side SideEvaluation
+	for i := range e.board.ids {
+		if !e.board.alive[i] || e.board.colors[i] != color {
+			continue
+		}
+		side.Material += pieceValues[e.board.types[i]]
+		side.Mobility += e.legalMoveCount(i)
+	}
+	if side.Mobility > 0 {
+		side.StepBudget = 1
+	}
+	side.Pawns = e.board.pawnStructure(color)
+	if kings := e.board.pieceMask[color.Index()][King]; kings != 0 {
+		king := lowestSquare(kings)
+		zone := kingAttacks[king]
+		for zone != 0 {
+			sq := lowestSquare(zone)
+			zone &= zone - 1
+			if e.board.attacked(sq, color.Opposite()) {
+				side.KingZoneAttacked++
+			}
+		}
+		side.InCheck = e.board.attacked(king, color.Opposite())
+	}
+	side.AbilityPotential = e.abilityPotential(color)
+
+	side.Score = side.Material*evalMaterial +
+		side.Mobility*evalMobility +
+		side.Pawns.Passed*evalPassed +
+		side.Pawns.Doubled*evalDoubled +
+		side.Pawns.Isolated*evalIsolated -
+		side.KingZoneAttacked*evalKingZone +
+		side.AbilityPotential*evalPotential
+	if side.InCheck {
+		side.Score -= evalInCheck
+	}
+	return side
+}
+
+// legalMoveCount counts the destinations legalTarget accepts for the piece in
+// slot idx, regardless of whose turn it is.
+func (e *Engine) legalMoveCount(idx int) int {
+	n := 0
+	for to := Square(0); to <= SquareH8; to++ {
+		if e.legalTarget(idx, to) {
+			n++
+		}
+	}
+	return n
+}
+
+// LegalMoves lists every move the side to move may play, in board order. The
+// requests carry no facing or promotion choice.
+func (e *Engine) LegalMoves() []MoveRequest {
+	var out []MoveRequest
+	if e.status != StatusActive || e.locked {
+		return out
+	}
+	for from := Square(0); from <= SquareH8; from++ {
+		idx := e.board.pieceIndexBySquare(from)
+		if idx < 0 || e.board.colors[idx] != e.board.turn {
+			continue
+		}
+		for to := Square(0); to <= SquareH8; to++ {
+			if e.legalTarget(idx, to) {
+				out = append(out, MoveRequest{From: from, To: to})
+			}
+		}
+	}
+	return out
+}
+
+// legalTarget reports whether validateMove accepts to, and no capture block
+// forbids it, for the piece in slot idx.
+func (e *Engine) legalTarget(idx int, to Square) bool {
+	color := e.board.colors[idx]
+	if to == e.board.squares[idx] || e.board.squareOccupiedBy(color, to) {
+		return false
+	}
+	target := e.board.pieceIndexBySquare(to)
+	if e.validateMove(idx, to, target >= 0) != nil {
+		return false
+	}
+	if target >= 0 {
+		if _, blocked := e.captureBlock(idx, target); blocked {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Engine) abilityPotential(color Color) int {
+	idx := color.Index()
+	total := 0
+	for _, id := range e.abilityLists[idx] {
+		if id == AbilityDoOver && e.doOverUsed[idx] {
+			continue
+		}
+		if e.rules.Charges.Enabled && uint16(e.chargeCosts[id]) > e.charges[idx] {
+			continue
+		}
+		switch abilityMetaTable[id].phase {
+		case phaseOffense, phaseTemporal:
+			total += 2
+		default:
+			total++
+		}
+	}
+	return total
+}
+
+func (b *boardSoA) pawnStructure(color Color) PawnStructure {
+	const fileA = uint64(0x0101010101010101)
+	var ps PawnStructure
+	own := b.pieceMask[color.Index()][Pawn]
+	enemy := b.pieceMask[color.Opposite().Index()][Pawn]
+	for file := 0; file < 8; file++ {
+		mask := fileA << uint(file)
+		count := bits.OnesCount64(own & mask)
+		if count > 1 {
+			ps.Doubled += count - 1
+		}
+		if count == 0 {
+			continue
+		}
+		var neighbours uint64
+		if file > 0 {
+			neighbours |= fileA << uint(file-1)
+		}
+		if file < 7 {
+			neighbours |= fileA << uint(file+1)
+		}
+		if own&neighbours == 0 {
+			ps.Isolated += count
+		}
+	}
+	for pawns := own; pawns != 0; pawns &= pawns - 1 {
+		sq := lowestSquare(pawns)
+		if enemy&passedSpan(color, sq) == 0 {
+			ps.Passed++
+		}
+	}
+	return ps
+}
+
+// passedSpan is the set of squares ahead of a pawn on sq, on its own and the
+// adjacent files, that an enemy pawn would have to occupy to stop it.
+func passedSpan(color Color, sq Square) uint64 {
+	rank, file := int(sq)/8, int(sq)%8
+	var span uint64
+	for f := file - 1; f <= file+1; f++ {
+		if f < 0 || f > 7 {
+			continue
+		}
+		for r := 0; r < 8; r++ {
+			if (color == White && r > rank) || (color == Black && r < rank) {
+				span |= uint64(1) << uint(r*8+f)
+			}
+		}
+	}
+	return span
+}
@@ -0,0 +1,136 @@
+// path: chessTest/internal/game/evaluate_test.go
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvaluateBreakdown(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		white     SideEvaluation
+		black     SideEvaluation
+	}{
+		{
+			name:      "start position",
+			placement: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR",
+			white:     SideEvaluation{Material: 39, Mobility: 16, StepBudget: 1, Score: 39*evalMaterial + 16*evalMobility},
+			black:     SideEvaluation{Material: 39, Mobility: 16, StepBudget: 1, Score: 39*evalMaterial + 16*evalMobility},
+		},
+		{
+			name:      "isolated and passed pawns",
+			placement: "4k3/8/8/8/8/8/PP1P4/4K3",
+			white: SideEvaluation{
+				Material: 3, Mobility: 6, StepBudget: 1,
+				Pawns: PawnStructure{Isolated: 1, Passed: 3},
+				Score: 3*evalMaterial + 6*evalMobility + 3*evalPassed + evalIsolated,
+			},
+		},
+		{
+			name:      "doubled pawns",
+			placement: "4k3/8/8/8/8/P7/P7/4K3",
+			white: SideEvaluation{
+				Material: 2, Mobility: 1, StepBudget: 1,
+				Pawns: PawnStructure{Doubled: 1, Isolated: 2, Passed: 2},
+				Score: 2*evalMaterial + evalMobility + 2*evalPassed + evalDoubled + 2*evalIsolated,
+			},
+		},
+		{
+			name:      "king under pressure",
+			placement: "4k3/8/8/8/8/8/3p4/4K2P",
+			white: SideEvaluation{
+				Material: 1, Mobility: 1, StepBudget: 1, InCheck: true,
+				Pawns: PawnStructure{Isolated: 1, Passed: 1},
+				Score: evalMaterial + evalMobility + evalPassed + evalIsolated - evalInCheck,
+			},
+			// d2 can advance to d1 or take the king on e1.
+			black: SideEvaluation{
+				Material: 1, Mobility: 2, StepBudget: 1,
+				Pawns: PawnStructure{Isolated: 1, Passed: 1},
+				Score: evalMaterial + 2*evalMobility + evalPassed + evalIsolated,
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement(tc.placement, White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			got := eng.Evaluate()
+			if got.White != tc.white {
+				t.Fatalf("white = %+v\nwant %+v", got.White, tc.white)
+			}
+			if got.Black != tc.black {
+				t.Fatalf("black = %+v\nwant %+v", got.Black, tc.black)
+			}
+			if got.Score != tc.white.Score-tc.black.Score {
+				t.Fatalf("score = %d want %d", got.Score, tc.white.Score-tc.black.Score)
+			}
+		})
+	}
+}
+
+func TestEvaluateAbilityPotentialAndSnapshot(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityDoOver, AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if got := eng.Evaluate().White.AbilityPotential; got != 3 {
+		t.Fatalf("potential = %d want 3", got)
+	}
+	fromSnap, err := Evaluate(eng.Snapshot())
+	if err != nil {
+		t.Fatalf("evaluate snapshot: %v", err)
+	}
+	if fromSnap != eng.Evaluate() {
+		t.Fatalf("snapshot evaluation differs: %+v vs %+v", fromSnap, eng.Evaluate())
+	}
+	rules := DefaultRules()
+	rules.Charges.Enabled = true
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	if got := eng.Evaluate().White.AbilityPotential; got != 1 {
+		t.Fatalf("potential without charges = %d want 1", got)
+	}
+}
+
+func TestEvaluateDrawFairness(t *testing.T) {
+	cases := []struct {
+		name        string
+		placement   string
+		competitive bool
+		level       bool
+		favored     Color
+		summary     string
+		wantErr     error
+	}{
+		{name: "start position", placement: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", level: true, summary: "engine thinks the position is level"},
+		{name: "white up a rook", placement: "4k3/pppp4/8/8/8/8/PPPP4/R3K3", favored: White, summary: "engine thinks White is +"},
+		{name: "black up a queen", placement: "q3k3/pppp4/8/8/8/8/PPPP4/4K3", favored: Black, summary: "engine thinks Black is +"},
+		{name: "dead position", placement: "4k3/8/8/8/8/8/8/R3K3", level: true, summary: "engine thinks the game is drawn"},
+		{name: "competitive", placement: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", competitive: true, wantErr: ErrAssessmentDisabled},
+	}
+	for _, tc := range cases {
+		eng := NewEngine()
+		if err := eng.SetRules(RulesConfig{Competitive: tc.competitive}); err != nil {
+			t.Fatalf("%s: rules: %v", tc.name, err)
+		}
+		if err := eng.LoadPlacement(tc.placement, White); err != nil {
+			t.Fatalf("%s: load: %v", tc.name, err)
+		}
+		got, err := eng.EvaluateDrawFairness()
+		if err != tc.wantErr {
+			t.Fatalf("%s: err = %v, want %v", tc.name, err, tc.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		if got.Level != tc.level || (!tc.level && got.Favored != tc.favored) || !strings.HasPrefix(got.Summary, tc.summary) {
+			t.Fatalf("%s: got %+v", tc.name, got)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// path: chessTest/internal/game/events.go
+package game
+
+type EventKind uint8
+
+const (
+	EventMove EventKind = iota
+	EventCapture
+	EventAbilityRemoval
+	EventDoOver
+	EventCheck
+	EventGameOver
+	EventShove
+	EventDrift
+)
+
+var eventKindNames = [...]string{
+	EventMove:           "move",
+	EventCapture:        "capture",
+	EventAbilityRemoval: "ability_removal",
+	EventDoOver:         "do_over",
+	EventCheck:          "check",
+	EventGameOver:       "game_over",
+	EventShove:          "shove",
+	EventDrift:          "drift",
+}
+
+func (k EventKind) String() string {
+	if int(k) < len(eventKindNames) {
+		return eventKindNames[k]
+	}
+	return "unknown"
+}
+
+// Event is one entry of the structured game log. Color is the acting side;
+// PieceID, Type, and Square describe the piece the event is about.
+//
+// Seq numbers events from 1 in the order they happened and never repeats for
+// an engine, across resets and restores. Within a turn the order is the move,
+// the mover's capture, ability removals in resolution order (phase, then
+// handler priority), a tide drift, check, and game over; see
+// docs/event_ordering.md.
+type Event struct {
+	Seq     uint64
+	Ply     uint32
+	Kind    EventKind
+	Color   Color
+	Ability Ability
+	PieceID int
+	Type    PieceType
+	Square  Square
+	Note    string
+	// Draws, on move events, records the random values the turn's handlers
+	// drew, such as where ScatterShot started its sweep. Pass them back in
+	// MoveRequest.Draws to replay the turn exactly.
+	Draws []uint32 `json:",omitempty"`
+	// Cause is the Seq of the event that set this one off: the move for a
+	// capture, the capture (or the move, on a quiet turn) for an ability
+	// removal, and the king's removal for the game_over it decides. Zero
+	// when nothing in the log caused it. See RemovalGraph.
+	Cause uint64 `json:",omitempty"`
+}
+
+// Events returns a copy of the structured log, oldest first.
+func (e *Engine) Events() []Event {
+	return cloneEvents(e.events)
+}
+
+// clone returns ev with its own Draws, so nothing handed out shares memory
+// with the log.
+func (ev Event) clone() Event {
+	if ev.Draws != nil {
+		ev.Draws = append([]uint32(nil), ev.Draws...)
+	}
+	return ev
+}
+
+func cloneEvents(list []Event) []Event {
+	out := make([]Event, len(list))
+	for i, ev := range list {
+		out[i] = ev.clone()
+	}
+	return out
+}
+
+// EventSeq is the Seq of the latest event, or the last one issued before the
+// log was cleared.
+func (e *Engine) EventSeq() uint64 {
+	return e.eventSeq
+}
+
+func (e *Engine) emit(ev Event) {
+	e.eventSeq++
+	ev.Seq = e.eventSeq
+	e.events = append(e.events, ev)
+	if e.sink != nil {
+		e.sink(ev.clone())
+	}
+}
+
+// SetEventSink hands every event to fn as it is logged, or stops when fn is
+// nil. fn runs on the caller's goroutine in the middle of a move, so it must
+// not block or call back into the engine. PGN replays are not published.
+func (e *Engine) SetEventSink(fn func(Event)) {
+	e.sink = fn
+}
+
+// logTurn records the events of a resolved turn by diffing the board against
+// its state before the move.
+// driftFrom is where the mover landed before a tide drift, or SquareInvalid
+// when it did not drift.
+func (e *Engine) logTurn(prev *boardSoA, mover, captureIdx int, driftFrom Square, res *resolveResult) {
+	ply := e.board.ply
+	color := e.board.colors[mover]
+	landed := e.board.squares[mover]
+	if driftFrom != SquareInvalid {
+		landed = driftFrom
+	}
+	e.emit(Event{
+		Ply:     ply,
+		Kind:    EventMove,
+		Color:   color,
+		PieceID: e.board.ids[mover],
+		Type:    e.board.types[mover],
+		Square:  landed,
+		Draws:   res.draws,
+	})
+	trigger := e.eventSeq
+	if captureIdx >= 0 {
+		e.emitRemoval(prev, ply, EventCapture, color, AbilityNone, captureIdx, trigger)
+		trigger = e.eventSeq
+	}
+	for _, claim := range res.telemetry.removals[:res.telemetry.removalCount] {
+		e.emitRemoval(prev, ply, EventAbilityRemoval, color, claim.ability, int(claim.piece), trigger)
+	}
+	if driftFrom != SquareInvalid {
+		e.emit(Event{
+			Ply:     ply,
+			Kind:    EventDrift,
+			Color:   color,
+			PieceID: e.board.ids[mover],
+			Type:    e.board.types[mover],
+			Square:  e.board.squares[mover],
+			Note:    "drifted from " + SquareToCoord(driftFrom),
+		})
+	}
+	e.emitCheck(ply, color)
+}
+
+// emitCheck logs a check when by's turn left the enemy king attacked.
+func (e *Engine) emitCheck(ply uint32, by Color) {
+	enemy := by.Opposite()
+	if e.board.inCheck(enemy) {
+		kings := e.board.pieceMask[enemy.Index()][King]
+		e.emit(Event{Ply: ply, Kind: EventCheck, Color: by, Type: King, Square: lowestSquare(kings)})
+	}
+}
+
+// emitRemoval logs a piece leaving the board, described by its slot in prev,
+// as a consequence of the event numbered cause.
+func (e *Engine) emitRemoval(prev *boardSoA, ply uint32, kind EventKind, by Color, ability Ability, idx int, cause uint64) {
+	e.emit(Event{
+		Ply:     ply,
+		Kind:    kind,
+		Color:   by,
+		Ability: ability,
+		PieceID: prev.ids[idx],
+		Type:    prev.types[idx],
+		Square:  prev.squares[idx],
+		Cause:   cause,
+	})
+}
+
+// kingRemoval is the Seq of the latest logged removal of color's king, or
+// zero.
+func (e *Engine) kingRemoval(color Color) uint64 {
+	for i := len(e.events) - 1; i >= 0; i-- {
+		ev := e.events[i]
+		if ev.Type == King && ev.Color != color && (ev.Kind == EventCapture || ev.Kind == EventAbilityRemoval) {
+			return ev.Seq
+		}
+	}
+	return 0
+}
+
+// RemovalStep is one piece leaving the board in a turn's removal cascade.
+type RemovalStep struct {
+	// Seq and Cause are the removal's event Seq and the Seq of the event
+	// that triggered it.
+	Seq     uint64
+	Cause   uint64
+	Kind    EventKind
+	Ability Ability
+	PieceID int
+	Type    PieceType
+	Square  Square
+	// Depth counts the links back to the move: 1 for the mover's capture,
+	// 2 for a removal that capture set off, and so on.
+	Depth int
+}
+
+// RemovalGraph follows the Cause links of events and returns their captures
+// and ability removals in Seq order, each with its depth in the cascade. Feed
+// it MoveResult.Events to animate a turn, or the whole log to audit a game.
+func RemovalGraph(events []Event) []RemovalStep {
+	depth := make(map[uint64]int, len(events))
+	var out []RemovalStep
+	for _, ev := range events {
+		if ev.Kind != EventCapture && ev.Kind != EventAbilityRemoval {
+			continue
+		}
+		d := depth[ev.Cause] + 1
+		depth[ev.Seq] = d
+		out = append(out, RemovalStep{
+			Seq:     ev.Seq,
+			Cause:   ev.Cause,
+			Kind:    ev.Kind,
+			Ability: ev.Ability,
+			PieceID: ev.PieceID,
+			Type:    ev.Type,
+			Square:  ev.Square,
+			Depth:   d,
+		})
+	}
+	return out
+}
@@ -0,0 +1,177 @@
+// path: chessTest/internal/game/events_test.go
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+// eventRank is the documented order of event kinds within one turn.
+var eventRank = map[EventKind]int{
+	EventMove:           0,
+	EventCapture:        1,
+	EventAbilityRemoval: 2,
+	EventDoOver:         2,
+	EventCheck:          3,
+	EventGameOver:       4,
+}
+
+func scatterGame(t *testing.T) *Engine {
+	t.Helper()
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityScatterShot}, ElementFire); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := eng.LoadPlacement("8/8/3k4/2npn3/4P3/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	return eng
+}
+
+func TestEventStreamOrder(t *testing.T) {
+	eng := scatterGame(t)
+	res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if !reflect.DeepEqual(res.Events, eng.Events()) {
+		t.Fatalf("move result events differ from the log: %+v", res.Events)
+	}
+	var kinds []EventKind
+	for i, ev := range res.Events {
+		kinds = append(kinds, ev.Kind)
+		if ev.Seq != uint64(i+1) {
+			t.Fatalf("event %d has seq %d", i, ev.Seq)
+		}
+		if i > 0 && eventRank[ev.Kind] < eventRank[res.Events[i-1].Kind] {
+			t.Fatalf("%s logged after %s", ev.Kind, res.Events[i-1].Kind)
+		}
+	}
+	want := []EventKind{EventMove, EventCapture, EventAbilityRemoval, EventAbilityRemoval, EventAbilityRemoval, EventGameOver}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("event kinds = %v want %v", kinds, want)
+	}
+
+	// The same game resolves to the same stream.
+	again := scatterGame(t)
+	if _, err := again.MoveEx(MoveRequest{From: SquareE4, To: SquareD5}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !reflect.DeepEqual(again.Events(), eng.Events()) {
+		t.Fatalf("replayed stream differs:\n%+v\n%+v", again.Events(), eng.Events())
+	}
+}
+
+func TestEventSeqSurvivesResetAndRestore(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	seq := eng.EventSeq()
+	if err := eng.Reset(); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if len(eng.Events()) != 0 || eng.EventSeq() != seq {
+		t.Fatalf("reset should clear the log but keep seq %d, got %d", seq, eng.EventSeq())
+	}
+	snap := eng.Snapshot()
+	restored := NewEngine()
+	if err := restored.Restore(snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if err := restored.Move(MoveRequest{From: SquareD2, To: SquareD4}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if got := restored.Events()[0].Seq; got != seq+1 {
+		t.Fatalf("first event after restore has seq %d want %d", got, seq+1)
+	}
+
+	// Snapshots written before sequencing are numbered on restore.
+	legacy := restored.Snapshot()
+	legacy.EventSeq = 0
+	for i := range legacy.Events {
+		legacy.Events[i].Seq = 0
+	}
+	if err := restored.Restore(legacy); err != nil {
+		t.Fatalf("restore legacy: %v", err)
+	}
+	if events := restored.Events(); events[len(events)-1].Seq != uint64(len(events)) || restored.EventSeq() != uint64(len(events)) {
+		t.Fatalf("legacy events not numbered: %+v", events)
+	}
+}
+
+func TestRecordedDrawsReplayScatterShot(t *testing.T) {
+	eng := scatterGame(t)
+	res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	draws := res.Events[0].Draws
+	if len(draws) != 1 {
+		t.Fatalf("move event draws = %v, want ScatterShot's one draw", draws)
+	}
+	replay := scatterGame(t)
+	if _, err := replay.MoveEx(MoveRequest{From: SquareE4, To: SquareD5, Draws: draws}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !reflect.DeepEqual(replay.Events(), eng.Events()) {
+		t.Fatalf("replay with recorded draws differs:\n%+v\n%+v", replay.Events(), eng.Events())
+	}
+
+	// Recorded draws win over the generator: each one fixes where the sweep
+	// starts (east, west, north, then south, which is empty here).
+	cases := []struct {
+		draw  uint32
+		first Square
+	}{
+		{0, SquareE5},
+		{1, SquareC5},
+		{2, SquareD6},
+		{3, SquareE5},
+	}
+	for _, tc := range cases {
+		eng := scatterGame(t)
+		res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5, Draws: []uint32{tc.draw}})
+		if err != nil {
+			t.Fatalf("draw %d: %v", tc.draw, err)
+		}
+		if got := res.Events[0].Draws; !reflect.DeepEqual(got, []uint32{tc.draw}) {
+			t.Fatalf("draw %d: recorded %v", tc.draw, got)
+		}
+		if got := res.Events[2]; got.Kind != EventAbilityRemoval || got.Square != tc.first {
+			t.Fatalf("draw %d: first removal %+v want %s", tc.draw, got, SquareToCoord(tc.first))
+		}
+	}
+}
+
+func TestRemovalGraph(t *testing.T) {
+	eng := scatterGame(t)
+	res, err := eng.MoveEx(MoveRequest{From: SquareE4, To: SquareD5})
+	if err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	// Seq 1 is the move, 2 the capture on d5; ScatterShot's three removals
+	// all follow from the capture, and the king's removal decides the game.
+	if len(res.Removals) != 4 {
+		t.Fatalf("removals = %+v", res.Removals)
+	}
+	if st := res.Removals[0]; st.Kind != EventCapture || st.Cause != 1 || st.Depth != 1 {
+		t.Fatalf("capture step = %+v", st)
+	}
+	var kingSeq uint64
+	for _, st := range res.Removals[1:] {
+		if st.Kind != EventAbilityRemoval || st.Ability != AbilityScatterShot || st.Cause != 2 || st.Depth != 2 {
+			t.Fatalf("scatter step = %+v", st)
+		}
+		if st.Type == King {
+			kingSeq = st.Seq
+		}
+	}
+	last := res.Events[len(res.Events)-1]
+	if last.Kind != EventGameOver || kingSeq == 0 || last.Cause != kingSeq {
+		t.Fatalf("game over %+v not caused by the king's removal (seq %d)", last, kingSeq)
+	}
+	if !reflect.DeepEqual(RemovalGraph(eng.Events()), res.Removals) {
+		t.Fatalf("graph of the log differs from the move result")
+	}
+}
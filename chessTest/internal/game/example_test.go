@@ -0,0 +1,72 @@
+// path: chessTest/internal/game/example_test.go
+package game_test
+
+import (
+	"fmt"
+
+	"battle_chess_poc/internal/game"
+)
+
+func ExampleEngine_Move() {
+	eng := game.NewEngine()
+	if err := eng.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareE4}); err != nil {
+		fmt.Println("move:", err)
+		return
+	}
+	fmt.Println("to move:", eng.Turn())
+
+	// Only pawns move, so a knight's move is rejected and the turn stays.
+	err := eng.Move(game.MoveRequest{From: game.SquareG8, To: game.SquareF6})
+	fmt.Println("knight:", err == game.ErrInvalidMove, "to move:", eng.Turn())
+	// Output:
+	// to move: black
+	// knight: true to move: black
+}
+
+func ExampleEngine_SetSideConfig() {
+	eng := game.NewEngine()
+	fmt.Println(eng.Phase())
+
+	if err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityDoOver, game.AbilityBlockPath}, game.ElementLight); err != nil {
+		fmt.Println("white:", err)
+		return
+	}
+	if err := eng.SetSideConfig(game.Black, game.AbilityList{game.AbilityScatterShot}, game.ElementShadow); err != nil {
+		fmt.Println("black:", err)
+		return
+	}
+	fmt.Println(eng.Phase())
+
+	// Banned abilities cannot be loaded.
+	rules := game.DefaultRules()
+	rules.BannedAbilities = game.AbilityList{game.AbilityDoOver}
+	if err := eng.SetRules(rules); err != nil {
+		fmt.Println("rules:", err)
+		return
+	}
+	err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityDoOver}, game.ElementLight)
+	fmt.Println(err == game.ErrAbilityBanned)
+	// Output:
+	// awaiting_config
+	// awaiting_move
+	// true
+}
+
+// A turn is one atomic segment. BlazeRush only counts rushes for telemetry:
+// it grants no extra segments, so the turn passes to the opponent with the
+// move, and MoveEx reports no steps left.
+func Example_blazeRushTurn() {
+	eng := game.NewEngine()
+	if err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityBlazeRush}, game.ElementFire); err != nil {
+		fmt.Println("config:", err)
+		return
+	}
+	res, err := eng.MoveEx(game.MoveRequest{From: game.SquareD2, To: game.SquareD4})
+	if err != nil {
+		fmt.Println("move:", err)
+		return
+	}
+	fmt.Println("turn ended:", res.TurnEnded, "steps left:", res.StepsRemaining, "to move:", eng.Turn())
+	// Output:
+	// turn ended: true steps left: 0 to move: black
+}
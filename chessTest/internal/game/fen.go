@@ -0,0 +1,191 @@
+// path: chessTest/internal/game/fen.go
+package game
+
+import "strings"
+
+var pieceLetters = [...]byte{Pawn: 'p', Knight: 'n', Bishop: 'b', Rook: 'r', Queen: 'q', King: 'k'}
+
+// Placement renders the board as the piece-placement field of a FEN string.
+func (e *Engine) Placement() string {
+	return e.board.placement()
+}
+
+// LoadPlacement replaces the board with the given FEN piece-placement field
+// and side to move. Side configs are re-applied and history is cleared.
+func (e *Engine) LoadPlacement(placement string, turn Color) error {
+	if int(turn) > 1 {
+		return ErrInvalidPosition
+	}
+	board, err := parsePlacement(placement)
+	if err != nil {
+		return err
+	}
+	board.turn = turn
+	e.board = board
+	e.truncateHistory(0)
+	e.doOverUsed = [2]bool{}
+	e.doOverDebt = [2]uint16{}
+	e.lastNote = ""
+	e.status = StatusActive
+	e.statusReason = ""
+	e.adjudication = nil
+	e.resetLog()
+	e.setVariantBoard()
+	e.recordStart()
+	e.resetClocks()
+	for k := range e.blockFacing {
+		delete(e.blockFacing, k)
+	}
+	for i := range e.abilityMask {
+		e.board.addAbility(e.pieceMasks(Color(i)), Color(i), e.ineligible)
+	}
+	e.resetPositions()
+	e.adjudicateDraw(turn, e.board.ply)
+	return nil
+}
+
+// LoadFEN replaces the board with a FEN position: placement, side to move
+// and, when given, castling rights and en passant target. Move counters are
+// accepted and ignored. Castling and en passant only apply under
+// VariantStandard, where rights the placement cannot back are dropped. Like
+// LoadPlacement it re-applies the side configs and clears the history.
+func (e *Engine) LoadFEN(fen string) error {
+	fields := strings.Fields(fen)
+	if len(fields) < 2 || len(fields) > 6 {
+		return ErrInvalidPosition
+	}
+	turn := White
+	switch fields[1] {
+	case "w":
+	case "b":
+		turn = Black
+	default:
+		return ErrInvalidPosition
+	}
+	var castling uint8
+	var ep uint64
+	if len(fields) > 2 {
+		rights, ok := parseCastlingField(fields[2])
+		if !ok {
+			return ErrInvalidPosition
+		}
+		castling = rights
+	}
+	if len(fields) > 3 && fields[3] != "-" {
+		sq, ok := CoordToSquare(fields[3])
+		if !ok || int(sq)/8 != 5-3*int(turn) {
+			return ErrInvalidPosition
+		}
+		ep = uint64(1) << uint(sq)
+	}
+	if err := e.LoadPlacement(fields[0], turn); err != nil {
+		return err
+	}
+	if e.rules.Standard() && len(fields) > 2 {
+		e.board.castling &= castling
+		e.board.ep = ep
+		e.recordStart()
+		e.resetPositions()
+	}
+	return nil
+}
+
+// recordStart notes the current position as the one the game began from, as
+// the PGN FEN tag will give it.
+func (e *Engine) recordStart() {
+	e.startFEN = e.board.placement() + " " + turnLetter(e.board.turn)
+	if e.rules.Standard() {
+		e.startFEN += " " + castlingField(e.board.castling) + " " + e.board.enPassantField()
+	}
+}
+
+func (b *boardSoA) placement() string {
+	var grid [64]byte
+	for i := range b.ids {
+		if !b.alive[i] {
+			continue
+		}
+		letter := pieceLetters[b.types[i]]
+		if b.colors[i] == White {
+			letter -= 'a' - 'A'
+		}
+		grid[b.squares[i]] = letter
+	}
+	var sb strings.Builder
+	sb.Grow(71)
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			letter := grid[rank*8+file]
+			if letter == 0 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			sb.WriteByte(letter)
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if rank > 0 {
+			sb.WriteByte('/')
+		}
+	}
+	return sb.String()
+}
+
+func parsePlacement(placement string) (boardSoA, error) {
+	var b boardSoA
+	ranks := strings.Split(strings.TrimSpace(placement), "/")
+	if len(ranks) != 8 {
+		return b, ErrInvalidPosition
+	}
+	idx := 0
+	for i, row := range ranks {
+		rank := 7 - i
+		file := 0
+		for j := 0; j < len(row); j++ {
+			c := row[j]
+			if c >= '1' && c <= '8' {
+				file += int(c - '0')
+				continue
+			}
+			typ, color, ok := pieceFromLetter(c)
+			if !ok || file > 7 || idx >= len(b.ids) {
+				return b, ErrInvalidPosition
+			}
+			sq := Square(rank*8 + file)
+			bit := uint64(1) << uint(sq)
+			b.ids[idx] = idx + 1
+			b.squares[idx] = sq
+			b.types[idx] = typ
+			b.colors[idx] = color
+			b.alive[idx] = true
+			b.occupancy[color.Index()] |= bit
+			b.pieceMask[color.Index()][typ] |= bit
+			idx++
+			file++
+		}
+		if file != 8 {
+			return b, ErrInvalidPosition
+		}
+	}
+	return b, nil
+}
+
+func pieceFromLetter(c byte) (PieceType, Color, bool) {
+	color := Black
+	if c >= 'A' && c <= 'Z' {
+		color = White
+		c += 'a' - 'A'
+	}
+	for typ, letter := range pieceLetters {
+		if letter == c {
+			return PieceType(typ), color, true
+		}
+	}
+	return Pawn, color, false
+}
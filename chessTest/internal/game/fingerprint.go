@@ -0,0 +1,146 @@
+// path: chessTest/internal/game/fingerprint.go
+package game
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"runtime/debug"
+	"sort"
+)
+
+// EngineVersion identifies the engine build that produced a game. Release
+// builds stamp it with
+//
+//	go build -ldflags "-X battle_chess_poc/internal/game.EngineVersion=v1.4.0"
+//
+// Unstamped builds report "dev", followed by the VCS revision when the
+// binary was built from a checkout.
+var EngineVersion = "dev"
+
+func init() {
+	if EngineVersion != "dev" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	var rev string
+	dirty := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			rev = s.Value
+		case "vcs.modified":
+			dirty = s.Value == "true"
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if rev != "" {
+		EngineVersion = "dev+" + rev
+		if dirty {
+			EngineVersion += "-dirty"
+		}
+	}
+}
+
+// fingerprintVersion is bumped whenever the fingerprint input changes shape,
+// so fingerprints from different layouts never collide.
+const fingerprintVersion = 1
+
+// Fingerprint hashes the rules together with the ability metadata the
+// resolver runs on: each ability's phase, priority and default cost and the
+// interaction table. Two games with the same fingerprint resolve abilities
+// the same way; a change to the rules or to the handler ordering changes it.
+// Settings that only spell a default differently, such as a zero
+// ExtraRemovals, hash the same as the default. The result is 16 hex digits.
+func (r RulesConfig) Fingerprint() string {
+	h := sha256.New()
+	put := func(format string, args ...any) { fmt.Fprintf(h, format+"\n", args...) }
+	put("fingerprint %d", fingerprintVersion)
+
+	variant := r.Variant
+	if variant == "" {
+		variant = VariantBattle
+	}
+	put("variant %s", variant)
+	put("handlerTimeout %d isolatePanics %t", r.HandlerTimeout, r.IsolatePanics || r.HandlerTimeout > 0)
+	put("charges %t %d %d %d", r.Charges.Enabled, r.Charges.Start, r.Charges.PerTurn, r.Charges.Max)
+	putAbilityInts(h, "cost", r.Charges.EffectiveCosts())
+	put("timeControl %d %d", r.TimeControl.Initial, r.TimeControl.Increment)
+	if tc := r.TimeControl; tc.Odds {
+		put("timeOdds %d %d", tc.BlackInitial, tc.BlackIncrement)
+	}
+	put("banned %v", sortedAbilityNames(r.BannedAbilities))
+	put("disabled %v", sortedAbilityNames(r.DisabledAbilities))
+	eligible := make(map[Ability]int, len(r.AbilityEligibility))
+	for a, set := range r.AbilityEligibility {
+		eligible[a] = int(set)
+	}
+	putAbilityInts(h, "eligible", eligible)
+	put("extraRemovals %d", r.removalBudget())
+	put("doOver %d %d", r.rewindPlies(), r.DoOverTurnCost)
+	put("strict %t", r.Strict)
+	if adj := r.Adjudication; adj.Enabled() {
+		put("adjudication %d %d %t", adj.Threshold, adj.Plies, adj.Tablebase)
+	}
+
+	for _, info := range AbilityInfos() {
+		put("ability %s %s %d %d", info.Ability, info.Phase, info.Priority, info.Cost)
+	}
+	for _, in := range abilityInteractions {
+		put("interaction %s %s %s", in.Ability, in.Other, in.Kind)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// RulesFingerprint is the Fingerprint of the live rules.
+func (e *Engine) RulesFingerprint() string {
+	return e.rules.Fingerprint()
+}
+
+// Provenance names the engine build and rule set behind a game.
+type Provenance struct {
+	EngineVersion    string
+	RulesFingerprint string
+}
+
+// Provenance reports the running engine's build and live rules.
+func (e *Engine) Provenance() Provenance {
+	return Provenance{EngineVersion: EngineVersion, RulesFingerprint: e.RulesFingerprint()}
+}
+
+// PGNProvenance reads the EngineVersion and RulesFingerprint tags of pgn.
+// Either is empty when the tag is missing, as in PGN from other tools.
+// LoadPGNAndContinue ignores both; compare them with Engine.Provenance
+// before replaying a disputed game.
+func PGNProvenance(pgn string) (Provenance, error) {
+	g, err := parsePGN(pgn)
+	if err != nil {
+		return Provenance{}, err
+	}
+	return Provenance{EngineVersion: g.tags["EngineVersion"], RulesFingerprint: g.tags["RulesFingerprint"]}, nil
+}
+
+func sortedAbilityNames(list AbilityList) []string {
+	names := normalizeAbilities(list).Strings()
+	sort.Strings(names)
+	return names
+}
+
+func putAbilityInts(h hash.Hash, label string, m map[Ability]int) {
+	names := make([]string, 0, len(m))
+	byName := make(map[string]int, len(m))
+	for a, v := range m {
+		names = append(names, a.String())
+		byName[a.String()] = v
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s %s %d\n", label, name, byName[name])
+	}
+}
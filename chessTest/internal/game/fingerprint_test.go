@@ -0,0 +1,65 @@
+// path: chessTest/internal/game/fingerprint_test.go
+package game
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRulesFingerprint(t *testing.T) {
+	base := DefaultRules()
+	cases := []struct {
+		name string
+		edit func(*RulesConfig)
+		same bool
+	}{
+		{name: "explicit variant", edit: func(r *RulesConfig) { r.Variant = VariantBattle }, same: true},
+		{name: "explicit removal budget", edit: func(r *RulesConfig) { r.ExtraRemovals = DefaultExtraRemovals }, same: true},
+		{name: "explicit default costs", edit: func(r *RulesConfig) { r.Charges.Costs = DefaultChargeCosts() }, same: true},
+		{name: "ban order", edit: func(r *RulesConfig) { r.BannedAbilities = AbilityList{AbilityScorch, AbilityDoOver, AbilityScorch} }, same: true},
+		{name: "strict", edit: func(r *RulesConfig) { r.Strict = true }},
+		{name: "clock", edit: func(r *RulesConfig) { r.TimeControl = TimeControl{Initial: time.Minute} }},
+		{name: "cost", edit: func(r *RulesConfig) { r.Charges.Costs = map[Ability]int{AbilityDoOver: 1} }},
+		{name: "eligibility", edit: func(r *RulesConfig) {
+			r.AbilityEligibility = map[Ability]PieceTypeSet{AbilityBlockPath: NewPieceTypeSet(Pawn)}
+		}},
+	}
+	ref := base
+	ref.BannedAbilities = AbilityList{AbilityDoOver, AbilityScorch}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := base
+			tc.edit(&r)
+			want := base.Fingerprint()
+			if tc.name == "ban order" {
+				want = ref.Fingerprint()
+			}
+			if got := r.Fingerprint(); (got == want) != tc.same {
+				t.Fatalf("fingerprint %s vs %s, want same = %v", got, want, tc.same)
+			}
+		})
+	}
+}
+
+func TestProvenanceInStateAndPGN(t *testing.T) {
+	eng := NewEngine()
+	rules := DefaultRules()
+	rules.Strict = true
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	st := eng.State()
+	if st.EngineVersion != EngineVersion || st.RulesFingerprint != rules.Fingerprint() {
+		t.Fatalf("state provenance = %q %q", st.EngineVersion, st.RulesFingerprint)
+	}
+	got, err := PGNProvenance(eng.PGN(nil))
+	if err != nil {
+		t.Fatalf("pgn: %v", err)
+	}
+	if got != eng.Provenance() {
+		t.Fatalf("pgn provenance = %+v want %+v", got, eng.Provenance())
+	}
+	if NewEngine().RulesFingerprint() == got.RulesFingerprint {
+		t.Fatalf("strict and default rules share a fingerprint")
+	}
+}
@@ -0,0 +1,113 @@
+// path: chessTest/internal/game/highlights.go
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// maxSwingHighlights caps how many material swings Highlights reports.
+const maxSwingHighlights = 3
+
+var pieceValues = [...]int{Pawn: 1, Knight: 3, Bishop: 3, Rook: 5, Queen: 9, King: 0}
+
+// Highlight is one key moment of a finished game.
+type Highlight struct {
+	Ply         uint32
+	Kind        string
+	Description string
+}
+
+// Highlights derives the key moments of a finished game from the event log:
+// the largest material swings, turns where abilities removed several pieces,
+// checks, DoOver rewinds, and the end of the game. Moves played from
+// zugzwang are found by replaying the game's positions. It returns
+// ErrGameInProgress while the game is still active.
+func (e *Engine) Highlights() ([]Highlight, error) {
+	if e.status == StatusActive {
+		return nil, ErrGameInProgress
+	}
+	type plyTally struct {
+		color    Color
+		material int
+		removals int
+		ability  Ability
+	}
+	tallies := make(map[uint32]*plyTally)
+	tally := func(ev Event) *plyTally {
+		t, ok := tallies[ev.Ply]
+		if !ok {
+			t = &plyTally{color: ev.Color}
+			tallies[ev.Ply] = t
+		}
+		return t
+	}
+	var out []Highlight
+	for _, ev := range e.events {
+		switch ev.Kind {
+		case EventCapture:
+			tally(ev).material += pieceValues[ev.Type]
+		case EventAbilityRemoval:
+			t := tally(ev)
+			t.material += pieceValues[ev.Type]
+			t.removals++
+			t.ability = ev.Ability
+		case EventCheck:
+			out = append(out, Highlight{
+				Ply:         ev.Ply,
+				Kind:        ev.Kind.String(),
+				Description: fmt.Sprintf("%s gives check to the king on %s", ev.Color, SquareToCoord(ev.Square)),
+			})
+		case EventDoOver:
+			out = append(out, Highlight{
+				Ply:         ev.Ply,
+				Kind:        ev.Kind.String(),
+				Description: fmt.Sprintf("%s's DoOver rewinds the capture on %s", ev.Color, SquareToCoord(ev.Square)),
+			})
+		case EventGameOver:
+			out = append(out, Highlight{
+				Ply:         ev.Ply,
+				Kind:        ev.Kind.String(),
+				Description: ev.Note,
+			})
+		}
+	}
+	plies := make([]uint32, 0, len(tallies))
+	for ply, t := range tallies {
+		plies = append(plies, ply)
+		if t.removals >= 2 {
+			out = append(out, Highlight{
+				Ply:         ply,
+				Kind:        "multi_removal",
+				Description: fmt.Sprintf("%s's %s removes %d pieces", t.color, t.ability, t.removals),
+			})
+		}
+	}
+	sort.Slice(plies, func(i, j int) bool {
+		a, b := tallies[plies[i]], tallies[plies[j]]
+		if a.material != b.material {
+			return a.material > b.material
+		}
+		return plies[i] < plies[j]
+	})
+	for i, ply := range plies {
+		t := tallies[ply]
+		if i == maxSwingHighlights || t.material == 0 {
+			break
+		}
+		out = append(out, Highlight{
+			Ply:         ply,
+			Kind:        "material_swing",
+			Description: fmt.Sprintf("%s wins %d points of material", t.color, t.material),
+		})
+	}
+	for _, z := range e.zugzwangs() {
+		out = append(out, Highlight{
+			Ply:         z.Ply,
+			Kind:        "zugzwang",
+			Description: fmt.Sprintf("%s is in zugzwang: every move weakens its position", z.Color),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Ply < out[j].Ply })
+	return out, nil
+}
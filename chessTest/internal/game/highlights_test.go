@@ -0,0 +1,59 @@
+// path: chessTest/internal/game/highlights_test.go
+package game
+
+import "testing"
+
+func TestHighlightsAfterGameOver(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityScatterShot}, ElementFire); err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if err := eng.LoadPlacement("8/8/3k4/2npn3/4P3/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if _, err := eng.Highlights(); err != ErrGameInProgress {
+		t.Fatalf("expected ErrGameInProgress, got %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != nil {
+		t.Fatalf("capture: %v", err)
+	}
+	if got := eng.State().Status; got != StatusWhiteWins {
+		t.Fatalf("status = %s want %s", got, StatusWhiteWins)
+	}
+	highlights, err := eng.Highlights()
+	if err != nil {
+		t.Fatalf("highlights: %v", err)
+	}
+	kinds := make(map[string]Highlight, len(highlights))
+	for _, h := range highlights {
+		if h.Ply != 0 {
+			t.Fatalf("unexpected ply in %+v", h)
+		}
+		kinds[h.Kind] = h
+	}
+	for _, kind := range []string{"multi_removal", "material_swing", "game_over"} {
+		if _, ok := kinds[kind]; !ok {
+			t.Fatalf("missing %s highlight in %+v", kind, highlights)
+		}
+	}
+	if got, want := kinds["material_swing"].Description, "white wins 7 points of material"; got != want {
+		t.Fatalf("swing = %q want %q", got, want)
+	}
+}
+
+func TestMoveLogsCheckEvent(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.LoadPlacement("8/8/3k4/8/4P3/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE4, To: SquareE5}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	events := eng.Events()
+	if len(events) != 2 || events[0].Kind != EventMove || events[1].Kind != EventCheck {
+		t.Fatalf("unexpected events %+v", events)
+	}
+	if events[1].Square != SquareD6 {
+		t.Fatalf("check square = %s want d6", SquareToCoord(events[1].Square))
+	}
+}
@@ -0,0 +1,146 @@
+// path: chessTest/internal/game/history.go
+package game
+
+import "time"
+
+// MoveRecord is one applied turn in the move log. The engine resolves every
+// turn as a single segment, so SegmentAt stamps that segment and TurnEnd the
+// hand-over to the opponent.
+type MoveRecord struct {
+	Ply     uint32
+	Color   Color
+	PieceID int
+	From    Square
+	To      Square
+	Capture bool
+	// Shove marks an Earth shove: the piece on From pushed the enemy on To
+	// and stayed put.
+	Shove bool
+	// Drift is the direction the mover drifted at turn end, or DirNone.
+	Drift     Direction
+	TurnStart time.Time
+	SegmentAt time.Time
+	TurnEnd   time.Time
+	// Promotion is the piece a pawn became on this move, or Pawn when it did
+	// not promote.
+	Promotion PieceType
+}
+
+// ThinkTime is how long the mover took between gaining the turn and playing.
+func (r MoveRecord) ThinkTime() time.Duration {
+	return r.SegmentAt.Sub(r.TurnStart)
+}
+
+// SetClock injects the wall clock used for move timestamps; nil restores
+// time.Now.
+func (e *Engine) SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	e.now = now
+	e.turnStart = now()
+}
+
+// MoveLog returns a copy of the applied turns, oldest first.
+func (e *Engine) MoveLog() []MoveRecord {
+	out := make([]MoveRecord, len(e.moveLog))
+	copy(out, e.moveLog)
+	return out
+}
+
+// Ply is the number of half-moves played on the current board.
+func (e *Engine) Ply() uint32 {
+	return e.board.ply
+}
+
+// Seq is the move sequence number clients echo in MoveRequest.Seq.
+func (e *Engine) Seq() uint64 {
+	return e.seq
+}
+
+// Turn is the side to move.
+func (e *Engine) Turn() Color {
+	return e.board.turn
+}
+
+// TurnStarted reports when the side to move gained the turn.
+func (e *Engine) TurnStarted() time.Time {
+	return e.turnStart
+}
+
+func (e *Engine) resetLog() {
+	e.moveLog = e.moveLog[:0]
+	e.events = e.events[:0]
+	e.turnStart = e.now()
+}
+
+// rewindPoint is what a history entry does not hold about its board: the
+// BlockPath facings in force on it, nil for none, and the position key the
+// ply played from it recorded, zero until that ply is recorded.
+type rewindPoint struct {
+	facing map[int]Direction
+	key    uint64
+}
+
+// pushHistory saves prev, the board before the ply being played, with the
+// current facings as its rewind point.
+func (e *Engine) pushHistory(prev boardSoA) {
+	e.history = append(e.history, prev)
+	var facing map[int]Direction
+	if len(e.blockFacing) > 0 {
+		facing = cloneFacing(e.blockFacing)
+	}
+	e.rewinds = append(e.rewinds, rewindPoint{facing: facing})
+}
+
+// truncateHistory keeps the first n history entries.
+func (e *Engine) truncateHistory(n int) {
+	e.history = e.history[:n]
+	e.rewinds = e.rewinds[:n]
+}
+
+// recordPly records the position the ply just played reached, for rewind to
+// uncount.
+func (e *Engine) recordPly() {
+	key := e.recordPosition()
+	if n := len(e.rewinds); n > 0 {
+		e.rewinds[n-1].key = key
+	}
+}
+
+// rewind restores the board from plies entries back in the history, counting
+// the entry pushed for the move being undone, along with the block facings in
+// force on it. It uncounts the positions the unwound plies reached for
+// repetition and drops their move log records. Charges and clocks keep their
+// current values: a DoOver does not refund what was spent or hand back time.
+func (e *Engine) rewind(plies int) {
+	if plies > len(e.history) {
+		plies = len(e.history)
+	}
+	if plies <= 0 {
+		return
+	}
+	at := len(e.history) - plies
+	e.board = e.history[at]
+	for _, p := range e.rewinds[at:] {
+		if n := e.positions[p.key]; p.key != 0 && n > 0 {
+			if n == 1 {
+				delete(e.positions, p.key)
+			} else {
+				e.positions[p.key] = n - 1
+			}
+		}
+	}
+	for id := range e.blockFacing {
+		delete(e.blockFacing, id)
+	}
+	for id, dir := range e.rewinds[at].facing {
+		e.blockFacing[id] = dir
+	}
+	e.truncateHistory(at)
+	keep := len(e.moveLog)
+	for keep > 0 && e.moveLog[keep-1].Ply >= e.board.ply {
+		keep--
+	}
+	e.moveLog = e.moveLog[:keep]
+}
@@ -0,0 +1,128 @@
+// path: chessTest/internal/game/options.go
+package game
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// Option configures an engine at construction; see NewEngine and Apply.
+// Options run in the order given.
+type Option func(*Engine) error
+
+// MoveMetrics describes one Move call, for WithMetrics.
+type MoveMetrics struct {
+	// Elapsed is wall time spent in the engine, whatever clock the engine
+	// uses for move timestamps.
+	Elapsed time.Duration
+	// Err is the error Move returned, if any.
+	Err       error
+	Events    int
+	TurnEnded bool
+	// Handlers lists the ability handlers the move ran, in resolution order.
+	Handlers []HandlerTiming
+}
+
+// HandlerTiming is one ability handler run, for MoveMetrics.
+type HandlerTiming struct {
+	Ability Ability
+	Owner   Color
+	Elapsed time.Duration
+	// Err is the *HandlerError of a handler the dispatch guard aborted.
+	Err error
+}
+
+// StorageHooks let a store follow the game. Before runs ahead of every move
+// the engine is about to attempt, and an error from it rejects the move
+// untouched, so a write-ahead log can refuse to let unrecorded moves through.
+// After runs once a move has been applied, DoOver rewinds included.
+type StorageHooks struct {
+	Before func(MoveRequest) error
+	After  func(MoveRequest, MoveResult)
+}
+
+// WithRules replaces the default rules; see SetRules.
+func WithRules(rules RulesConfig) Option {
+	return func(e *Engine) error { return e.SetRules(rules) }
+}
+
+// WithSides configures both loadouts; see SetSidesConfig.
+func WithSides(white, black SideSetup) Option {
+	return func(e *Engine) error { return e.SetSidesConfig(white, black) }
+}
+
+// WithClock sets the wall clock used for move timestamps and time control.
+func WithClock(now func() time.Time) Option {
+	return func(e *Engine) error {
+		e.SetClock(now)
+		e.turnStart = e.now()
+		return nil
+	}
+}
+
+// WithSeed mixes seed into every turn's random draws, so two engines playing
+// the same moves diverge where handlers roll dice. Zero, the default, keeps
+// the draws a function of the moves alone.
+func WithSeed(seed uint64) Option {
+	return func(e *Engine) error {
+		e.seed = seed
+		return nil
+	}
+}
+
+// WithLogger logs game endings and handler failures to l.
+func WithLogger(l *log.Logger) Option {
+	return func(e *Engine) error {
+		e.logger = l
+		return nil
+	}
+}
+
+// WithMetrics calls fn after every Move. fn runs on the caller's goroutine
+// and must not call back into the engine. Handlers are only timed while fn is
+// installed.
+func WithMetrics(fn func(MoveMetrics)) Option {
+	return func(e *Engine) error {
+		e.metrics = fn
+		return nil
+	}
+}
+
+// WithEventSink publishes every event to fn; see SetEventSink.
+func WithEventSink(fn func(Event)) Option {
+	return func(e *Engine) error {
+		e.SetEventSink(fn)
+		return nil
+	}
+}
+
+// WithStorage installs storage hooks. The hooks run on the caller's goroutine
+// and must not call back into the engine.
+func WithStorage(hooks StorageHooks) Option {
+	return func(e *Engine) error {
+		e.storage = hooks
+		return nil
+	}
+}
+
+// Apply runs opts on a live engine, stopping at the first that fails. Use it
+// instead of passing options to NewEngine when they come from untrusted
+// input, such as rules a client submitted.
+func (e *Engine) Apply(opts ...Option) error {
+	for _, opt := range opts {
+		if opt == nil {
+			return errors.New("nil engine option")
+		}
+		if err := opt(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Engine) logf(format string, args ...any) {
+	if e.logger != nil {
+		e.logger.Printf(format, args...)
+	}
+}
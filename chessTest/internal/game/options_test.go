@@ -0,0 +1,151 @@
+// path: chessTest/internal/game/options_test.go
+package game
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngineOptions(t *testing.T) {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	errFull := errors.New("log full")
+	cases := []struct {
+		name  string
+		opts  func(*[]string) []Option
+		move  MoveRequest
+		check func(t *testing.T, eng *Engine, calls []string, err error)
+	}{
+		{
+			name: "defaults",
+			opts: func(*[]string) []Option { return nil },
+			move: MoveRequest{From: SquareE2, To: SquareE4},
+			check: func(t *testing.T, eng *Engine, _ []string, err error) {
+				if err != nil || eng.Rules().Variant != DefaultRules().Variant || eng.seed != 0 {
+					t.Fatalf("defaults changed: %v %+v", err, eng.Rules())
+				}
+			},
+		},
+		{
+			name: "clock and seed",
+			opts: func(*[]string) []Option {
+				return []Option{WithClock(func() time.Time { return start }), WithSeed(42)}
+			},
+			move: MoveRequest{From: SquareE2, To: SquareE4},
+			check: func(t *testing.T, eng *Engine, _ []string, err error) {
+				if err != nil || !eng.TurnStarted().Equal(start) || eng.Snapshot().Seed != 42 {
+					t.Fatalf("clock %v seed %d: %v", eng.TurnStarted(), eng.Snapshot().Seed, err)
+				}
+			},
+		},
+		{
+			name: "storage rejects",
+			opts: func(calls *[]string) []Option {
+				return []Option{WithStorage(StorageHooks{
+					Before: func(MoveRequest) error { *calls = append(*calls, "before"); return errFull },
+					After:  func(MoveRequest, MoveResult) { *calls = append(*calls, "after") },
+				})}
+			},
+			move: MoveRequest{From: SquareE2, To: SquareE4},
+			check: func(t *testing.T, eng *Engine, calls []string, err error) {
+				if !errors.Is(err, errFull) || eng.Seq() != 0 || strings.Join(calls, ",") != "before" {
+					t.Fatalf("err %v seq %d calls %v", err, eng.Seq(), calls)
+				}
+			},
+		},
+		{
+			name: "storage and metrics follow the move",
+			opts: func(calls *[]string) []Option {
+				return []Option{
+					WithStorage(StorageHooks{
+						Before: func(MoveRequest) error { *calls = append(*calls, "before"); return nil },
+						After:  func(_ MoveRequest, res MoveResult) { *calls = append(*calls, "after") },
+					}),
+					WithMetrics(func(m MoveMetrics) {
+						if m.TurnEnded && m.Events == 1 && m.Err == nil {
+							*calls = append(*calls, "metrics")
+						}
+					}),
+					WithEventSink(func(ev Event) { *calls = append(*calls, ev.Kind.String()) }),
+				}
+			},
+			move: MoveRequest{From: SquareE2, To: SquareE4},
+			check: func(t *testing.T, eng *Engine, calls []string, err error) {
+				if err != nil || strings.Join(calls, ",") != "before,move,after,metrics" {
+					t.Fatalf("err %v calls %v", err, calls)
+				}
+			},
+		},
+		{
+			name: "metrics time each handler",
+			opts: func(calls *[]string) []Option {
+				return []Option{
+					WithSides(SideSetup{Abilities: AbilityList{AbilityBlockPath}, Element: ElementEarth}, SideSetup{Element: ElementFire}),
+					WithMetrics(func(m MoveMetrics) {
+						for _, h := range m.Handlers {
+							*calls = append(*calls, h.Owner.String()+" "+h.Ability.String())
+						}
+					}),
+				}
+			},
+			move: MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN},
+			check: func(t *testing.T, eng *Engine, calls []string, err error) {
+				if err != nil || strings.Join(calls, ",") != "white BlockPath" {
+					t.Fatalf("err %v handlers %v", err, calls)
+				}
+			},
+		},
+		{
+			name: "invalid move skips after",
+			opts: func(calls *[]string) []Option {
+				return []Option{WithStorage(StorageHooks{After: func(MoveRequest, MoveResult) { *calls = append(*calls, "after") }})}
+			},
+			move: MoveRequest{From: SquareE2, To: SquareE5},
+			check: func(t *testing.T, eng *Engine, calls []string, err error) {
+				if err == nil || len(calls) != 0 {
+					t.Fatalf("err %v calls %v", err, calls)
+				}
+			},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls []string
+			eng := NewEngine(tc.opts(&calls)...)
+			err := eng.Move(tc.move)
+			tc.check(t, eng, calls, err)
+		})
+	}
+}
+
+func TestEngineOptionErrors(t *testing.T) {
+	bad := DefaultRules()
+	bad.Variant = "atomic"
+	eng := NewEngine()
+	if err := eng.Apply(WithRules(bad)); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("apply invalid rules: %v", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("NewEngine with invalid rules did not panic")
+		}
+	}()
+	NewEngine(WithRules(bad))
+}
+
+func TestEngineLoggerReportsGameOver(t *testing.T) {
+	var buf bytes.Buffer
+	eng := NewEngine(WithLogger(log.New(&buf, "", 0)))
+	if err := eng.LoadPlacement("8/8/8/3k4/4P3/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != nil {
+		t.Fatalf("capture king: %v", err)
+	}
+	if !strings.Contains(buf.String(), "white captured the king") {
+		t.Fatalf("log = %q", buf.String())
+	}
+}
@@ -0,0 +1,417 @@
+// path: chessTest/internal/game/pgn.go
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PGN import understands the standard tag pairs and SAN movetext, plus a few
+// battle-chess extensions:
+//
+//	[WhiteAbilities "BlockPath,DoOver"]  loadout for each side
+//	[WhiteElement "Light"]
+//	[RulesFingerprint "9f86d081884c7d65"]  rules the game was played under; informational
+//	[FEN "4k3/8/8/3p4/4P3/8/8/4K3 w - - 0 1"]  start position (placement and side to move)
+//	e4 {[%dir NW]}                        facing for the preceding move
+//	e4 {[%drift E]}                       Water tide drift for the preceding move
+//	5. {[%shove e3 d4]}                   an Earth shove, which has no SAN
+//
+// Comments, variations, NAGs, move numbers and the result marker are
+// skipped.
+
+// pgnGame is a parsed PGN: its tags and mainline moves.
+type pgnGame struct {
+	tags  map[string]string
+	moves []pgnMove
+}
+
+type pgnMove struct {
+	san   string
+	dir   Direction
+	drift Direction
+	// shove, when set, replaces san: the piece on from pushes the one on to.
+	shove    bool
+	from, to Square
+}
+
+// LoadPGNAndContinue replays the game in pgn and leaves the engine live at
+// its final position, ready for further moves. Loadout tags replace the side
+// configs; without them the current configs are kept. The game starts from
+// the FEN tag when present and the initial position otherwise. Only moves
+// this engine resolves can be replayed. A capture rewound by DoOver counts as
+// played, as it did in the original game. On error the engine is unchanged.
+func (e *Engine) LoadPGNAndContinue(pgn string) error {
+	g, err := parsePGN(pgn)
+	if err != nil {
+		return err
+	}
+	saved := e.Snapshot()
+	sink := e.sink
+	e.sink = nil
+	defer func() { e.sink = sink }()
+	if err := e.replayPGN(g); err != nil {
+		if restoreErr := e.Restore(saved); restoreErr != nil {
+			return errors.Join(err, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (e *Engine) replayPGN(g pgnGame) error {
+	for _, side := range [...]Color{White, Black} {
+		if err := e.applyLoadoutTags(g.tags, side); err != nil {
+			return err
+		}
+	}
+	if fen, ok := g.tags["FEN"]; ok {
+		fields := strings.Fields(fen)
+		if len(fields) < 2 {
+			return fmt.Errorf("%w: FEN tag needs placement and side to move", ErrInvalidPGN)
+		}
+		if fields[1] != "w" && fields[1] != "b" {
+			return fmt.Errorf("%w: FEN side to move %q", ErrInvalidPGN, fields[1])
+		}
+		if err := e.LoadFEN(fen); err != nil {
+			return fmt.Errorf("%w: FEN tag: %v", ErrInvalidPGN, err)
+		}
+	} else if err := e.Reset(); err != nil {
+		return err
+	}
+	for i, mv := range g.moves {
+		if mv.shove {
+			if err := e.Move(MoveRequest{From: mv.from, To: mv.to, Shove: true}); err != nil {
+				return fmt.Errorf("%w: move %d (shove): %v", ErrInvalidPGN, i+1, err)
+			}
+			continue
+		}
+		req, err := e.resolveSAN(mv.san)
+		if err != nil {
+			return fmt.Errorf("%w: move %d (%s): %v", ErrInvalidPGN, i+1, mv.san, err)
+		}
+		req.Dir = mv.dir
+		req.Drift = mv.drift
+		if err := e.Move(req); err != nil && !errors.Is(err, ErrDoOverActivated) {
+			return fmt.Errorf("%w: move %d (%s): %v", ErrInvalidPGN, i+1, mv.san, err)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) applyLoadoutTags(tags map[string]string, side Color) error {
+	prefix := "White"
+	if side == Black {
+		prefix = "Black"
+	}
+	abilityTag, hasAbilities := tags[prefix+"Abilities"]
+	elementTag, hasElement := tags[prefix+"Element"]
+	typeTag, hasTypes := tags[prefix+"TypeAbilities"]
+	if !hasAbilities && !hasElement && !hasTypes {
+		return nil
+	}
+	abilities := e.abilityLists[side.Index()]
+	if hasAbilities {
+		abilities = nil
+		for _, name := range strings.Split(abilityTag, ",") {
+			if strings.TrimSpace(name) == "" {
+				continue
+			}
+			a, ok := ParseAbility(name)
+			if !ok {
+				return fmt.Errorf("%w: %sAbilities names unknown ability %q", ErrInvalidPGN, prefix, name)
+			}
+			abilities = append(abilities, a)
+		}
+	}
+	element := e.elements[side.Index()]
+	if hasElement {
+		var ok bool
+		if element, ok = ParseElement(elementTag); !ok {
+			return fmt.Errorf("%w: %sElement %q", ErrInvalidPGN, prefix, elementTag)
+		}
+	}
+	byType := e.typeAbilities[side.Index()]
+	if hasTypes {
+		var err error
+		if byType, err = parseTypeAbilities(typeTag); err != nil {
+			return fmt.Errorf("%w: %sTypeAbilities: %v", ErrInvalidPGN, prefix, err)
+		}
+	}
+	return e.SetSideSetup(side, SideSetup{Abilities: abilities, Element: element, ByType: byType})
+}
+
+// parseTypeAbilities reads the TypeAbilities tag format, such as
+// "knight=BlazeRush;rook=BlazeRush,Scorch".
+func parseTypeAbilities(tag string) (map[PieceType]AbilityList, error) {
+	out := make(map[PieceType]AbilityList)
+	for _, group := range strings.Split(tag, ";") {
+		if strings.TrimSpace(group) == "" {
+			continue
+		}
+		name, list, ok := strings.Cut(group, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q needs type=abilities", group)
+		}
+		t, ok := ParsePieceType(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown piece type %q", name)
+		}
+		for _, an := range strings.Split(list, ",") {
+			if strings.TrimSpace(an) == "" {
+				continue
+			}
+			a, ok := ParseAbility(an)
+			if !ok {
+				return nil, fmt.Errorf("unknown ability %q", an)
+			}
+			out[t] = append(out[t], a)
+		}
+	}
+	return out, nil
+}
+
+// resolveSAN finds the legal move that san names in the current position.
+// resolveCastling turns O-O or O-O-O into the king's move, under
+// VariantStandard only.
+func (e *Engine) resolveCastling(s string) (MoveRequest, error) {
+	if !e.rules.Standard() {
+		return MoveRequest{}, errors.New("castling is not supported")
+	}
+	from, step := SquareE1, 2
+	if e.board.turn == Black {
+		from = SquareE8
+	}
+	if s == "O-O-O" || s == "0-0-0" {
+		step = -2
+	}
+	req := MoveRequest{From: from, To: Square(int(from) + step)}
+	if err := e.ValidateMove(req); err != nil {
+		return MoveRequest{}, errors.New("castling is not legal")
+	}
+	return req, nil
+}
+
+func (e *Engine) resolveSAN(san string) (MoveRequest, error) {
+	s := strings.TrimRight(san, "+#!?")
+	if strings.HasPrefix(s, "O-O") || strings.HasPrefix(s, "0-0") {
+		return e.resolveCastling(s)
+	}
+	var req MoveRequest
+	if at := strings.IndexByte(s, '='); at >= 0 {
+		pt, ok := ParsePromotionPiece(s[at+1:])
+		if !ok {
+			return MoveRequest{}, errors.New("bad promotion")
+		}
+		req.Promotion, req.HasPromotion = pt, true
+		s = s[:at]
+	}
+	piece := Pawn
+	if s != "" && strings.IndexByte("NBRQK", s[0]) >= 0 {
+		piece, _, _ = pieceFromLetter(s[0] + ('a' - 'A'))
+		s = s[1:]
+	}
+	s = strings.ReplaceAll(s, "x", "")
+	if len(s) < 2 {
+		return MoveRequest{}, errors.New("missing destination")
+	}
+	to, ok := CoordToSquare(s[len(s)-2:])
+	if !ok {
+		return MoveRequest{}, errors.New("bad destination")
+	}
+	hint := s[:len(s)-2]
+	if len(hint) > 2 {
+		return MoveRequest{}, errors.New("bad disambiguation")
+	}
+	found := false
+	for _, mv := range e.LegalMoves() {
+		if mv.To != to || !matchesHint(mv.From, hint) {
+			continue
+		}
+		idx := e.board.pieceIndexBySquare(mv.From)
+		if idx < 0 || e.board.types[idx] != piece {
+			continue
+		}
+		if found {
+			return MoveRequest{}, errors.New("ambiguous move")
+		}
+		req.From, req.To = mv.From, mv.To
+		found = true
+	}
+	if !found {
+		return MoveRequest{}, errors.New("no legal move matches")
+	}
+	return req, nil
+}
+
+// matchesHint checks a SAN disambiguator: a file, a rank or both.
+func matchesHint(from Square, hint string) bool {
+	coord := SquareToCoord(from)
+	for i := 0; i < len(hint); i++ {
+		c := hint[i]
+		switch {
+		case c >= 'a' && c <= 'h':
+			if coord[0] != c {
+				return false
+			}
+		case c >= '1' && c <= '8':
+			if coord[1] != c {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parsePGN reads the first game in pgn.
+func parsePGN(pgn string) (pgnGame, error) {
+	g := pgnGame{tags: make(map[string]string)}
+	s := pgn
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if !strings.HasPrefix(s, "[") {
+			break
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			return g, fmt.Errorf("%w: unterminated tag", ErrInvalidPGN)
+		}
+		name, value, err := parseTag(s[1:end])
+		if err != nil {
+			return g, err
+		}
+		g.tags[name] = value
+		s = s[end+1:]
+	}
+	depth := 0
+	for len(s) > 0 {
+		c := s[0]
+		switch {
+		case c == '{':
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return g, fmt.Errorf("%w: unterminated comment", ErrInvalidPGN)
+			}
+			if depth == 0 {
+				if shove, ok := commentShove(s[1:end]); ok {
+					g.moves = append(g.moves, shove)
+				} else if len(g.moves) > 0 {
+					last := &g.moves[len(g.moves)-1]
+					if dir, ok := commentDirection(s[1:end], "%dir"); ok {
+						last.dir = dir
+					}
+					if dir, ok := commentDirection(s[1:end], "%drift"); ok {
+						last.drift = dir
+					}
+				}
+			}
+			s = s[end+1:]
+		case c == ';':
+			end := strings.IndexByte(s, '\n')
+			if end < 0 {
+				end = len(s) - 1
+			}
+			s = s[end+1:]
+		case c == '(':
+			depth++
+			s = s[1:]
+		case c == ')':
+			if depth == 0 {
+				return g, fmt.Errorf("%w: unbalanced variation", ErrInvalidPGN)
+			}
+			depth--
+			s = s[1:]
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			s = s[1:]
+		default:
+			end := strings.IndexAny(s, " \t\r\n{;()")
+			if end < 0 {
+				end = len(s)
+			}
+			tok := s[:end]
+			s = s[end:]
+			if depth > 0 {
+				continue
+			}
+			if san := moveToken(tok); san != "" {
+				g.moves = append(g.moves, pgnMove{san: san})
+			}
+		}
+	}
+	if depth != 0 {
+		return g, fmt.Errorf("%w: unbalanced variation", ErrInvalidPGN)
+	}
+	return g, nil
+}
+
+func parseTag(body string) (string, string, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(body), " ")
+	rest = strings.TrimSpace(rest)
+	if !ok || len(rest) < 2 || rest[0] != '"' || rest[len(rest)-1] != '"' {
+		return "", "", fmt.Errorf("%w: malformed tag %q", ErrInvalidPGN, body)
+	}
+	value := strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(rest[1 : len(rest)-1])
+	return name, value, nil
+}
+
+// moveToken strips a move number from tok and returns the SAN it carries, or
+// "" for move numbers, NAGs and result markers.
+func moveToken(tok string) string {
+	switch tok {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return ""
+	}
+	if strings.HasPrefix(tok, "$") {
+		return ""
+	}
+	i := 0
+	for i < len(tok) && tok[i] >= '0' && tok[i] <= '9' {
+		i++
+	}
+	if i < len(tok) && tok[i] == '.' {
+		tok = strings.TrimLeft(tok[i:], ".")
+	}
+	return tok
+}
+
+// commentDirection reads a direction command such as [%dir X] from a
+// comment.
+func commentDirection(comment, command string) (Direction, bool) {
+	at := strings.Index(comment, "["+command+" ")
+	if at < 0 {
+		return DirNone, false
+	}
+	rest := comment[at+len(command)+1:]
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return DirNone, false
+	}
+	dir := ParseDirection(strings.TrimSpace(rest[:end]))
+	return dir, dir != DirNone
+}
+
+// commentShove reads a [%shove FROM TO] command from a comment.
+func commentShove(comment string) (pgnMove, bool) {
+	at := strings.Index(comment, "[%shove")
+	if at < 0 {
+		return pgnMove{}, false
+	}
+	rest := comment[at+len("[%shove"):]
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return pgnMove{}, false
+	}
+	fields := strings.Fields(rest[:end])
+	if len(fields) != 2 {
+		return pgnMove{}, false
+	}
+	from, okFrom := CoordToSquare(fields[0])
+	to, okTo := CoordToSquare(fields[1])
+	if !okFrom || !okTo {
+		return pgnMove{}, false
+	}
+	return pgnMove{shove: true, from: from, to: to}, true
+}
@@ -0,0 +1,164 @@
+// path: chessTest/internal/game/pgn_export.go
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pgnRosterTags are the seven standard tags, written first and in this order.
+var pgnRosterTags = [...]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// PGN renders the game as PGN in the dialect LoadPGNAndContinue reads. tags
+// supplies or overrides tag pairs such as Event and Date; the engine fills in
+// Result, the loadouts, EngineVersion, RulesFingerprint and, when the game
+// did not start from the initial position, FEN. Moves a DoOver rewound are not in the move log and are left
+// out, so a replay keeps the position but not the DoOver's spent state.
+func (e *Engine) PGN(tags map[string]string) string {
+	all := map[string]string{"Event": "?", "Site": "?", "Date": "????.??.??", "Round": "?", "White": "?", "Black": "?"}
+	for name, value := range tags {
+		all[name] = value
+	}
+	all["Result"] = pgnResult(e.status)
+	all["EngineVersion"] = EngineVersion
+	all["RulesFingerprint"] = e.RulesFingerprint()
+	for _, side := range [...]Color{White, Black} {
+		prefix := "White"
+		if side == Black {
+			prefix = "Black"
+		}
+		all[prefix+"Abilities"] = strings.Join(e.abilityLists[side.Index()].Strings(), ",")
+		all[prefix+"Element"] = e.elements[side.Index()].String()
+		if byType := e.typeAbilities[side.Index()]; len(byType) > 0 {
+			groups := make([]string, 0, len(byType))
+			for t := Pawn; int(t) < pieceTypeCount; t++ {
+				if list, ok := byType[t]; ok {
+					groups = append(groups, t.String()+"="+strings.Join(list.Strings(), ","))
+				}
+			}
+			all[prefix+"TypeAbilities"] = strings.Join(groups, ";")
+		}
+	}
+	if e.startFEN != "" {
+		fen := e.startFEN
+		if len(strings.Fields(fen)) == 2 {
+			fen += " - -"
+		}
+		all["FEN"] = fen + " 0 1"
+		all["SetUp"] = "1"
+	}
+	if e.rules.Standard() {
+		all["Variant"] = "Standard"
+	}
+
+	var sb strings.Builder
+	for _, name := range pgnRosterTags {
+		writePGNTag(&sb, name, all[name])
+		delete(all, name)
+	}
+	rest := make([]string, 0, len(all))
+	for name := range all {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+	for _, name := range rest {
+		writePGNTag(&sb, name, all[name])
+	}
+	sb.WriteByte('\n')
+
+	checks := make(map[uint32]bool)
+	for _, ev := range e.events {
+		if ev.Kind == EventCheck {
+			checks[ev.Ply] = true
+		}
+	}
+	blackFirst := e.startFEN != "" && strings.Fields(e.startFEN)[1] == "b"
+	var tokens []string
+	for i, rec := range e.moveLog {
+		n := i
+		if blackFirst {
+			n++
+		}
+		switch {
+		case n%2 == 0:
+			tokens = append(tokens, fmt.Sprintf("%d.", n/2+1))
+		case i == 0:
+			tokens = append(tokens, fmt.Sprintf("%d...", n/2+1))
+		}
+		if rec.Shove {
+			tokens = append(tokens, fmt.Sprintf("{[%%shove %s %s]}", SquareToCoord(rec.From), SquareToCoord(rec.To)))
+			continue
+		}
+		san := e.san(i, rec, checks[rec.Ply])
+		tokens = append(tokens, san)
+		if rec.Drift != DirNone {
+			tokens = append(tokens, fmt.Sprintf("{[%%drift %s]}", rec.Drift))
+		}
+	}
+	tokens = append(tokens, pgnResult(e.status))
+	sb.WriteString(strings.Join(tokens, " "))
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// san writes rec, the i-th move, in SAN, marked with "+" when check is set.
+// Only pawns move in battle chess, so no move needs disambiguating; standard
+// chess moves are written from the position before them, with their own
+// check and mate marks, when the engine still holds it.
+func (e *Engine) san(i int, rec MoveRecord, check bool) string {
+	if e.rules.Standard() && len(e.history) == len(e.moveLog) {
+		return e.history[i].standardSAN(rec)
+	}
+	san := e.plainSAN(rec)
+	if check {
+		san += "+"
+	}
+	return san
+}
+
+func (e *Engine) plainSAN(rec MoveRecord) string {
+	typ := Pawn
+	for i := range e.board.ids {
+		if e.board.ids[i] == rec.PieceID {
+			typ = e.board.types[i]
+			break
+		}
+	}
+	to := SquareToCoord(rec.To)
+	if typ == Pawn {
+		if rec.Capture {
+			return SquareToCoord(rec.From)[:1] + "x" + to
+		}
+		return to
+	}
+	letter := string(pieceLetters[typ] - ('a' - 'A'))
+	if rec.Capture {
+		return letter + "x" + to
+	}
+	return letter + to
+}
+
+func pgnResult(status GameStatus) string {
+	switch status {
+	case StatusWhiteWins:
+		return "1-0"
+	case StatusBlackWins:
+		return "0-1"
+	case StatusDraw:
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+func writePGNTag(sb *strings.Builder, name, value string) {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	fmt.Fprintf(sb, "[%s \"%s\"]\n", name, value)
+}
+
+func turnLetter(turn Color) string {
+	if turn == Black {
+		return "b"
+	}
+	return "w"
+}
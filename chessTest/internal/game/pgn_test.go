@@ -0,0 +1,141 @@
+// path: chessTest/internal/game/pgn_test.go
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLoadPGNAndContinue(t *testing.T) {
+	pgn := `[Event "Casual"]
+[WhiteAbilities "BlockPath"]
+[WhiteElement "Light"]
+[BlackAbilities "ScatterShot"]
+[BlackElement "Fire"]
+
+1. e4 {a classic} d5 (1... e5 2. Nf3) 2. exd5 $1 c6 ; the Scandinavian gambit
+3. dxc6 *`
+	eng := NewEngine()
+	if err := eng.LoadPGNAndContinue(pgn); err != nil {
+		t.Fatalf("load pgn: %v", err)
+	}
+	want := NewEngine()
+	if err := want.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("config white: %v", err)
+	}
+	if err := want.SetSideConfig(Black, AbilityList{AbilityScatterShot}, ElementFire); err != nil {
+		t.Fatalf("config black: %v", err)
+	}
+	for _, mv := range [][2]Square{{SquareE2, SquareE4}, {SquareD7, SquareD5}, {SquareE4, SquareD5}, {SquareC7, SquareC6}, {SquareD5, SquareC6}} {
+		if err := want.Move(MoveRequest{From: mv[0], To: mv[1]}); err != nil {
+			t.Fatalf("reference move: %v", err)
+		}
+	}
+	if eng.State().Hash != want.State().Hash || eng.Seq() != 5 || eng.Turn() != Black {
+		t.Fatalf("replayed game differs: seq %d turn %s", eng.Seq(), eng.Turn())
+	}
+	// The engine stays live at the final position.
+	if err := eng.Move(MoveRequest{From: SquareB7, To: SquareC6}); err != nil {
+		t.Fatalf("continue: %v", err)
+	}
+}
+
+func TestLoadPGNFromFENWithFacing(t *testing.T) {
+	eng := NewEngine()
+	pgn := `[FEN "4k3/8/8/3p4/8/8/4P3/4K3 w - - 0 1"]
+[WhiteAbilities "BlockPath"]
+[BlackAbilities "ScatterShot"]
+
+1. e4 {[%dir NW]} *`
+	if err := eng.LoadPGNAndContinue(pgn); err != nil {
+		t.Fatalf("load pgn: %v", err)
+	}
+	state := eng.State()
+	var facing Direction
+	for _, dir := range state.BlockFacing {
+		facing = dir
+	}
+	if facing != DirNW || state.Turn != Black {
+		t.Fatalf("expected e4 facing NW with black to move, got %s (%s)", facing, state.Turn)
+	}
+}
+
+func TestLoadPGNRejectsAndKeepsEngine(t *testing.T) {
+	cases := []struct {
+		name string
+		pgn  string
+	}{
+		{name: "piece move", pgn: `1. Nf3 *`},
+		{name: "castling", pgn: `[FEN "4k3/8/8/8/8/8/8/4K2R w K - 0 1"] 1. O-O *`},
+		{name: "illegal pawn move", pgn: `1. e5 *`},
+		{name: "unknown ability", pgn: `[WhiteAbilities "Teleport"] 1. e4 *`},
+		{name: "unbalanced variation", pgn: `1. e4 (1. d4 *`},
+		{name: "bad fen", pgn: `[FEN "8/8 w"] *`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.SetSideConfig(White, AbilityList{AbilityDoOver}, ElementLight); err != nil {
+				t.Fatalf("config: %v", err)
+			}
+			if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+				t.Fatalf("config: %v", err)
+			}
+			if err := eng.Move(MoveRequest{From: SquareD2, To: SquareD4}); err != nil {
+				t.Fatalf("move: %v", err)
+			}
+			before := eng.State()
+			if err := eng.LoadPGNAndContinue(tc.pgn); !errors.Is(err, ErrInvalidPGN) {
+				t.Fatalf("expected ErrInvalidPGN, got %v", err)
+			}
+			if after := eng.State(); after.Hash != before.Hash || after.Seq != before.Seq {
+				t.Fatal("failed import changed the engine")
+			}
+		})
+	}
+}
+
+func TestPGNExportRoundTrips(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		turn      Color
+		moves     []MoveRequest
+	}{
+		{name: "initial position", moves: []MoveRequest{
+			{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5}, {From: SquareE4, To: SquareD5}, {From: SquareC7, To: SquareC6},
+		}},
+		{name: "shove from fen", placement: "4k3/7p/8/8/3p4/4P3/8/4K3", turn: Black, moves: []MoveRequest{
+			{From: SquareH7, To: SquareH6}, {From: SquareE3, To: SquareD4, Shove: true}, {From: SquareC5, To: SquareC4},
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementEarth); err != nil {
+				t.Fatalf("config white: %v", err)
+			}
+			if tc.placement != "" {
+				if err := eng.LoadPlacement(tc.placement, tc.turn); err != nil {
+					t.Fatalf("load placement: %v", err)
+				}
+			}
+			for _, mv := range tc.moves {
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %+v: %v", mv, err)
+				}
+			}
+			pgn := eng.PGN(map[string]string{"Event": "Export"})
+			replay := NewEngine()
+			if err := replay.LoadPGNAndContinue(pgn); err != nil {
+				t.Fatalf("reload:\n%s\n%v", pgn, err)
+			}
+			if replay.PositionKey() != eng.PositionKey() || replay.Seq() != eng.Seq() {
+				t.Fatalf("replay differs from the original:\n%s", pgn)
+			}
+			if again := replay.PGN(map[string]string{"Event": "Export"}); again != pgn {
+				t.Fatalf("export is not stable:\n%s\nvs\n%s", pgn, again)
+			}
+		})
+	}
+}
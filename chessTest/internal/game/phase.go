@@ -0,0 +1,50 @@
+// path: chessTest/internal/game/phase.go
+package game
+
+// TurnPhase says what the game is waiting for, so clients and the server
+// need not piece it together from Status, Locked and the loadouts.
+type TurnPhase uint8
+
+const (
+	// PhaseAwaitingConfig: a side has not set its loadout and no move has
+	// been played yet. Moves are still accepted; the first one ends the phase.
+	PhaseAwaitingConfig TurnPhase = iota
+	// PhaseAwaitingMove: the side to move may submit its turn.
+	PhaseAwaitingMove
+	// PhaseAwaitingContinuation is reserved for multi-segment turns. Turns
+	// here resolve atomically inside Move, so the engine never reports it.
+	PhaseAwaitingContinuation
+	// PhaseAwaitingDecision: the board is locked and accepts no moves until
+	// it is released.
+	PhaseAwaitingDecision
+	// PhaseGameOver: the game is decided; see Status and StatusReason.
+	PhaseGameOver
+)
+
+var turnPhaseNames = [...]string{
+	PhaseAwaitingConfig:       "awaiting_config",
+	PhaseAwaitingMove:         "awaiting_move",
+	PhaseAwaitingContinuation: "awaiting_continuation",
+	PhaseAwaitingDecision:     "awaiting_decision",
+	PhaseGameOver:             "game_over",
+}
+
+func (p TurnPhase) String() string {
+	if int(p) < len(turnPhaseNames) {
+		return turnPhaseNames[p]
+	}
+	return "unknown"
+}
+
+// Phase reports the engine's current phase.
+func (e *Engine) Phase() TurnPhase {
+	switch {
+	case e.status != StatusActive:
+		return PhaseGameOver
+	case e.locked:
+		return PhaseAwaitingDecision
+	case (e.unconfigured[White.Index()] || e.unconfigured[Black.Index()]) && len(e.history) == 0:
+		return PhaseAwaitingConfig
+	}
+	return PhaseAwaitingMove
+}
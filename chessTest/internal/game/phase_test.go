@@ -0,0 +1,48 @@
+// path: chessTest/internal/game/phase_test.go
+package game
+
+import "testing"
+
+func TestPhase(t *testing.T) {
+	eng := NewEngine()
+	steps := []struct {
+		name string
+		do   func() error
+		want TurnPhase
+	}{
+		{name: "new engine", do: func() error { return nil }, want: PhaseAwaitingConfig},
+		{name: "white configured", do: func() error {
+			return eng.SetSideSetup(White, SideSetup{Abilities: AbilityList{AbilityDoOver}, Element: ElementFire})
+		}, want: PhaseAwaitingConfig},
+		{name: "both configured", do: func() error {
+			return eng.SetSideSetup(Black, SideSetup{Element: ElementWater})
+		}, want: PhaseAwaitingMove},
+		{name: "after a move", do: func() error { return eng.Move(MoveRequest{From: SquareE2, To: SquareE4}) }, want: PhaseAwaitingMove},
+		{name: "locked", do: func() error {
+			snap := eng.Snapshot()
+			snap.Locked = true
+			return eng.Restore(snap)
+		}, want: PhaseAwaitingDecision},
+		{name: "unlocked", do: func() error {
+			snap := eng.Snapshot()
+			snap.Locked = false
+			return eng.Restore(snap)
+		}, want: PhaseAwaitingMove},
+		{name: "king in reach", do: func() error { return eng.LoadPlacement("4k3/3P4/8/8/8/8/8/4K3", White) }, want: PhaseAwaitingMove},
+		{name: "game over", do: func() error { return eng.Move(MoveRequest{From: SquareD7, To: SquareE8}) }, want: PhaseGameOver},
+	}
+	for _, st := range steps {
+		if err := st.do(); err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		if got := eng.Phase(); got != st.want {
+			t.Fatalf("%s: phase %s, want %s", st.name, got, st.want)
+		}
+		if got := eng.State(StateOptions{Detail: StateSummary}).Phase; got != st.want {
+			t.Fatalf("%s: state phase %s, want %s", st.name, got, st.want)
+		}
+	}
+	if restored := NewEngine(); restored.Restore(NewEngine().Snapshot()) != nil || restored.Phase() != PhaseAwaitingConfig {
+		t.Fatalf("restored fresh engine should still await config")
+	}
+}
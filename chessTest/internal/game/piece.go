@@ -0,0 +1,141 @@
+// path: chessTest/internal/game/piece.go
+package game
+
+import "slices"
+
+// AbilityReadiness says whether one of a piece's abilities can fire now.
+type AbilityReadiness struct {
+	Ability Ability
+	// Cost is the charge price, zero when charges are disabled.
+	Cost  int
+	Ready bool
+	// Reason explains why the ability is not ready.
+	Reason string `json:",omitempty"`
+}
+
+// Special moves reported in PieceCapabilities.
+const (
+	// SpecialMoveFacing is a BlockPath turn: the move's Dir sets the
+	// mover's facing.
+	SpecialMoveFacing = "facing"
+)
+
+// PieceCapabilities is what a piece's abilities let it do, taken from the
+// handlers they run, so clients can mark pieces without re-deriving handler
+// logic. Element moves, the Earth shove and the Water drift, belong to the
+// side rather than a handler and are not listed.
+type PieceCapabilities struct {
+	// CanPhase and HasFreeContinuations are always false here: no handler
+	// moves a piece through others, and every turn is a single segment.
+	CanPhase             bool
+	HasFreeContinuations bool
+	// SpecialMoves lists the move forms the piece's handlers add.
+	SpecialMoves []string `json:",omitempty"`
+	// CaptureVetoes lists the capture-block rules, as reported by
+	// ExplainCaptureBlock, that the piece can impose on attackers.
+	CaptureVetoes []string `json:",omitempty"`
+}
+
+// CapabilitiesOf summarises the capabilities the abilities in set declare,
+// in ability order without duplicates.
+func CapabilitiesOf(set AbilitySet) PieceCapabilities {
+	var out PieceCapabilities
+	for _, ability := range AllAbilities {
+		if !set.Has(ability) {
+			continue
+		}
+		caps := abilityCapsTable[ability]
+		out.SpecialMoves = appendUnique(out.SpecialMoves, caps.specialMoves...)
+		out.CaptureVetoes = appendUnique(out.CaptureVetoes, caps.captureVetoes...)
+	}
+	return out
+}
+
+func (c PieceCapabilities) clone() PieceCapabilities {
+	c.SpecialMoves = cloneStrings(c.SpecialMoves)
+	c.CaptureVetoes = cloneStrings(c.CaptureVetoes)
+	return c
+}
+
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(list, item) {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// PieceDetail is one piece plus what the engine can work out about it, for
+// inspection panels.
+type PieceDetail struct {
+	PieceState
+	Element Element
+	// Targets lists the squares the piece could move to if it were its
+	// side's turn, in board order.
+	Targets []Square
+	// BlockFacing is the piece's BlockPath facing, DirNone when it has none.
+	BlockFacing Direction
+	// Attacked reports that an enemy piece attacks the piece's square.
+	Attacked  bool
+	Readiness []AbilityReadiness
+}
+
+// Piece reports the live piece with id, or false when there is none.
+func (e *Engine) Piece(id int) (PieceDetail, bool) {
+	idx := -1
+	for i := range e.board.ids {
+		if e.board.alive[i] && e.board.ids[i] == id {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return PieceDetail{}, false
+	}
+	color := e.board.colors[idx]
+	out := PieceDetail{
+		PieceState: PieceState{
+			ID:           id,
+			Color:        color,
+			Type:         e.board.types[idx],
+			Square:       e.board.squares[idx],
+			Abilities:    abilitySetToNames(e.board.ability[idx]),
+			Capabilities: CapabilitiesOf(e.board.ability[idx]),
+		},
+		Element:     e.elements[color.Index()],
+		Targets:     []Square{},
+		BlockFacing: DirNone,
+		Attacked:    e.board.attacked(e.board.squares[idx], color.Opposite()),
+		Readiness:   []AbilityReadiness{},
+	}
+	if facing, ok := e.blockFacing[id]; ok {
+		out.BlockFacing = facing
+	}
+	for to := Square(0); to <= SquareH8; to++ {
+		if e.legalTarget(idx, to) {
+			out.Targets = append(out.Targets, to)
+		}
+	}
+	for _, ability := range AllAbilities {
+		if e.board.ability[idx].Has(ability) {
+			out.Readiness = append(out.Readiness, e.readiness(color, ability))
+		}
+	}
+	return out, true
+}
+
+func (e *Engine) readiness(color Color, ability Ability) AbilityReadiness {
+	idx := color.Index()
+	r := AbilityReadiness{Ability: ability, Ready: true}
+	if e.rules.Charges.Enabled {
+		r.Cost = int(e.chargeCosts[ability])
+		if uint16(r.Cost) > e.charges[idx] {
+			r.Ready, r.Reason = false, "not enough charges"
+		}
+	}
+	if ability == AbilityDoOver && e.doOverUsed[idx] {
+		r.Ready, r.Reason = false, "already used this game"
+	}
+	return r
+}
@@ -0,0 +1,108 @@
+// path: chessTest/internal/game/piece_test.go
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPieceDetail(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetRules(chargeRules(2, 1, 5)); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	if err := eng.SetSideConfig(White, AbilityList{AbilityDoOver, AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityScatterShot}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN}); err != nil {
+		t.Fatalf("e4: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareD7, To: SquareD5}); err != nil {
+		t.Fatalf("d5: %v", err)
+	}
+	pieceAt := func(sq Square) int {
+		return eng.board.ids[eng.board.pieceIndexBySquare(sq)]
+	}
+
+	cases := []struct {
+		name      string
+		square    Square
+		targets   []Square
+		facing    Direction
+		attacked  bool
+		readiness []AbilityReadiness
+	}{
+		{
+			name: "white e4", square: SquareE4,
+			targets: []Square{SquareD5, SquareE5}, facing: DirN, attacked: true,
+			readiness: []AbilityReadiness{
+				{Ability: AbilityDoOver, Cost: 3, Ready: true},
+				{Ability: AbilityBlockPath, Ready: true},
+			},
+		},
+		{
+			// e4 faces north, so the capture from d5 is blocked.
+			name: "black d5", square: SquareD5,
+			targets: []Square{SquareD4}, facing: DirNone, attacked: true,
+			readiness: []AbilityReadiness{
+				{Ability: AbilityScatterShot, Cost: 2, Ready: false, Reason: "not enough charges"},
+			},
+		},
+	}
+	for _, tc := range cases {
+		got, ok := eng.Piece(pieceAt(tc.square))
+		if !ok {
+			t.Fatalf("%s: piece not found", tc.name)
+		}
+		if got.Square != tc.square || !reflect.DeepEqual(got.Targets, tc.targets) {
+			t.Fatalf("%s: square %s targets %v want %v", tc.name, SquareToCoord(got.Square), got.Targets, tc.targets)
+		}
+		if got.BlockFacing != tc.facing || got.Attacked != tc.attacked {
+			t.Fatalf("%s: facing %s attacked %v", tc.name, got.BlockFacing, got.Attacked)
+		}
+		if !reflect.DeepEqual(got.Readiness, tc.readiness) {
+			t.Fatalf("%s: readiness %+v want %+v", tc.name, got.Readiness, tc.readiness)
+		}
+	}
+	if _, ok := eng.Piece(-1); ok {
+		t.Fatalf("found a piece with id -1")
+	}
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	cases := []struct {
+		name string
+		set  AbilitySet
+		want PieceCapabilities
+	}{
+		{name: "none", set: 0},
+		{name: "telemetry only", set: NewAbilitySet(AbilityScorch, AbilityTailwind)},
+		{
+			name: "blockpath",
+			set:  NewAbilitySet(AbilityDoOver, AbilityBlockPath),
+			want: PieceCapabilities{SpecialMoves: []string{SpecialMoveFacing}, CaptureVetoes: []string{BlockRuleFacing}},
+		},
+	}
+	for _, tc := range cases {
+		if got := CapabilitiesOf(tc.set); !reflect.DeepEqual(got, tc.want) {
+			t.Fatalf("%s: got %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityScatterShot}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	for _, pc := range eng.State().Pieces {
+		vetoes := len(pc.Capabilities.CaptureVetoes) > 0
+		if vetoes != (pc.Color == White) {
+			t.Fatalf("%s %s on %s: capture vetoes %v", pc.Color, pc.Type, SquareToCoord(pc.Square), pc.Capabilities.CaptureVetoes)
+		}
+	}
+}
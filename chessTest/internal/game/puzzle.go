@@ -0,0 +1,318 @@
+// path: chessTest/internal/game/puzzle.go
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Puzzle is a one-turn exercise: a position and a goal the solving turn must
+// meet, such as winning material with a particular ability. Unlike a
+// TutorialStep it accepts any move that meets the goal.
+type Puzzle struct {
+	ID        string
+	Title     string
+	Prompt    string
+	Placement string
+	// Turn is the side to move, the solver.
+	Turn    Color
+	White   SideSetup
+	Black   SideSetup
+	Goal    PuzzleGoal
+	Success string
+}
+
+// PuzzleGoal is what the solving turn must achieve. Every set field must be
+// met.
+type PuzzleGoal struct {
+	// Ability must be credited by one of the turn's events, as ScatterShot
+	// is by the removals it makes. AbilityNone skips the check.
+	Ability Ability
+	// Material is the least the solver must gain over the turn, in pawn
+	// units net of its own losses.
+	Material int
+	// Win requires the turn to end the game in the solver's favour.
+	Win bool
+}
+
+// PuzzleResult is the verdict on one submitted turn.
+type PuzzleResult struct {
+	Solved bool
+	// Missed says which parts of the goal the turn fell short of.
+	Missed []string
+	// Material is what the solver gained over the turn, in pawn units.
+	Material int
+	Events   []Event
+	State    BoardState
+}
+
+// Engine sets up a fresh engine at the puzzle's position.
+func (p Puzzle) Engine() (*Engine, error) {
+	eng := NewEngine()
+	if err := eng.SetSidesConfig(p.White, p.Black); err != nil {
+		return nil, fmt.Errorf("%w: puzzle %s: %v", ErrInvalidPuzzle, p.ID, err)
+	}
+	if err := eng.LoadPlacement(p.Placement, p.Turn); err != nil {
+		return nil, fmt.Errorf("%w: puzzle %s: %v", ErrInvalidPuzzle, p.ID, err)
+	}
+	return eng, nil
+}
+
+// Verify plays req from the puzzle's position and checks the turn against
+// the goal. A move the engine rejects returns its error; a DoOver rewind
+// counts as a played turn that gained nothing.
+func (p Puzzle) Verify(req MoveRequest) (PuzzleResult, error) {
+	eng, err := p.Engine()
+	if err != nil {
+		return PuzzleResult{}, err
+	}
+	before := eng.Evaluate()
+	res, err := eng.MoveEx(req)
+	out := PuzzleResult{Events: res.Events, State: eng.State()}
+	if err != nil && !errors.Is(err, ErrDoOverActivated) {
+		return out, err
+	}
+	out.Material = materialGain(p.Turn, before, eng.Evaluate())
+
+	g := p.Goal
+	if g.Ability != AbilityNone && !creditsAbility(res.Events, g.Ability) {
+		out.Missed = append(out.Missed, fmt.Sprintf("%s did not fire", g.Ability))
+	}
+	if out.Material < g.Material {
+		out.Missed = append(out.Missed, fmt.Sprintf("gained %d material, needs %d", out.Material, g.Material))
+	}
+	if g.Win && res.Status != winFor(p.Turn) {
+		out.Missed = append(out.Missed, "the game is not won")
+	}
+	out.Solved = len(out.Missed) == 0
+	return out, nil
+}
+
+func (g PuzzleGoal) empty() bool {
+	return g.Ability == AbilityNone && g.Material <= 0 && !g.Win
+}
+
+func materialGain(side Color, before, after Evaluation) int {
+	own := func(ev Evaluation, c Color) int {
+		if c == White {
+			return ev.White.Material
+		}
+		return ev.Black.Material
+	}
+	opp := side.Opposite()
+	return (own(after, side) - own(after, opp)) - (own(before, side) - own(before, opp))
+}
+
+func creditsAbility(events []Event, a Ability) bool {
+	for _, ev := range events {
+		if ev.Ability == a {
+			return true
+		}
+	}
+	return false
+}
+
+func winFor(c Color) GameStatus {
+	if c == White {
+		return StatusWhiteWins
+	}
+	return StatusBlackWins
+}
+
+// ---- puzzle packs ----
+
+// PuzzlePack is a named set of puzzles, in the order they are offered.
+type PuzzlePack struct {
+	Name    string
+	Puzzles []Puzzle
+}
+
+// A puzzle pack file is JSON, with abilities, elements and colors by name
+// and the position as a FEN placement plus side to move:
+//
+//	{"name": "Ability basics", "puzzles": [{
+//	  "id": "scatter-guard", "title": "Scatter the guard",
+//	  "prompt": "Win a pawn and both knights.",
+//	  "position": "4k3/8/8/2npnp2/4P3/8/8/4K3 w",
+//	  "white": {"abilities": ["ScatterShot"], "element": "Fire"},
+//	  "black": {"element": "Shadow"},
+//	  "goal": {"ability": "ScatterShot", "material": 7},
+//	  "success": "Both knights are gone."}]}
+type puzzlePackFile struct {
+	Name    string       `json:"name"`
+	Puzzles []puzzleFile `json:"puzzles"`
+}
+
+type puzzleFile struct {
+	ID       string         `json:"id"`
+	Title    string         `json:"title"`
+	Prompt   string         `json:"prompt"`
+	Position string         `json:"position"`
+	White    puzzleSideFile `json:"white"`
+	Black    puzzleSideFile `json:"black"`
+	Goal     puzzleGoalFile `json:"goal"`
+	Success  string         `json:"success"`
+}
+
+type puzzleSideFile struct {
+	Abilities []string `json:"abilities"`
+	Element   string   `json:"element"`
+}
+
+type puzzleGoalFile struct {
+	Ability  string `json:"ability"`
+	Material int    `json:"material"`
+	Win      bool   `json:"win"`
+}
+
+// ParsePuzzlePack reads a puzzle pack file. Every puzzle needs a unique id, a
+// position its loadouts apply to and a goal; the first problem found is
+// returned wrapped in ErrInvalidPuzzle.
+func ParsePuzzlePack(data []byte) (PuzzlePack, error) {
+	var file puzzlePackFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return PuzzlePack{}, fmt.Errorf("%w: %v", ErrInvalidPuzzle, err)
+	}
+	pack := PuzzlePack{Name: file.Name}
+	for i, pf := range file.Puzzles {
+		p, err := pf.puzzle()
+		if err != nil {
+			return PuzzlePack{}, fmt.Errorf("%w: puzzle %d: %v", ErrInvalidPuzzle, i+1, err)
+		}
+		pack.Puzzles = append(pack.Puzzles, p)
+	}
+	if err := pack.Validate(); err != nil {
+		return PuzzlePack{}, err
+	}
+	return pack, nil
+}
+
+// Puzzle returns the puzzle with id.
+func (pack PuzzlePack) Puzzle(id string) (Puzzle, bool) {
+	for _, p := range pack.Puzzles {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Puzzle{}, false
+}
+
+// Validate checks that every puzzle has a unique id, a goal and a position
+// its loadouts apply to.
+func (pack PuzzlePack) Validate() error {
+	if len(pack.Puzzles) == 0 {
+		return fmt.Errorf("%w: pack has no puzzles", ErrInvalidPuzzle)
+	}
+	seen := make(map[string]bool, len(pack.Puzzles))
+	for _, p := range pack.Puzzles {
+		switch {
+		case p.ID == "":
+			return fmt.Errorf("%w: puzzle without an id", ErrInvalidPuzzle)
+		case seen[p.ID]:
+			return fmt.Errorf("%w: duplicate puzzle id %q", ErrInvalidPuzzle, p.ID)
+		case p.Goal.empty():
+			return fmt.Errorf("%w: puzzle %s has no goal", ErrInvalidPuzzle, p.ID)
+		}
+		seen[p.ID] = true
+		if _, err := p.Engine(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pf puzzleFile) puzzle() (Puzzle, error) {
+	p := Puzzle{ID: strings.TrimSpace(pf.ID), Title: pf.Title, Prompt: pf.Prompt, Success: pf.Success}
+	fields := strings.Fields(pf.Position)
+	if len(fields) != 2 {
+		return Puzzle{}, fmt.Errorf("position %q is not a placement and side to move", pf.Position)
+	}
+	p.Placement = fields[0]
+	switch fields[1] {
+	case "w":
+		p.Turn = White
+	case "b":
+		p.Turn = Black
+	default:
+		return Puzzle{}, fmt.Errorf("position %q: side to move must be w or b", pf.Position)
+	}
+	var err error
+	if p.White, err = pf.White.setup(); err != nil {
+		return Puzzle{}, fmt.Errorf("white: %v", err)
+	}
+	if p.Black, err = pf.Black.setup(); err != nil {
+		return Puzzle{}, fmt.Errorf("black: %v", err)
+	}
+	p.Goal = PuzzleGoal{Material: pf.Goal.Material, Win: pf.Goal.Win}
+	if pf.Goal.Ability != "" {
+		a, ok := ParseAbility(pf.Goal.Ability)
+		if !ok {
+			return Puzzle{}, fmt.Errorf("goal: unknown ability %q", pf.Goal.Ability)
+		}
+		p.Goal.Ability = a
+	}
+	return p, nil
+}
+
+func (sf puzzleSideFile) setup() (SideSetup, error) {
+	var out SideSetup
+	for _, name := range sf.Abilities {
+		a, ok := ParseAbility(name)
+		if !ok {
+			return SideSetup{}, fmt.Errorf("unknown ability %q", name)
+		}
+		out.Abilities = append(out.Abilities, a)
+	}
+	element, ok := ParseElement(sf.Element)
+	if !ok {
+		return SideSetup{}, fmt.Errorf("unknown element %q", sf.Element)
+	}
+	out.Element = element
+	return out, nil
+}
+
+// DefaultPuzzles is the pack served when none is configured.
+func DefaultPuzzles() PuzzlePack {
+	shadow := SideSetup{Element: ElementShadow}
+	scatter := SideSetup{Abilities: AbilityList{AbilityScatterShot}, Element: ElementFire}
+	return PuzzlePack{
+		Name: "Ability basics",
+		Puzzles: []Puzzle{
+			{
+				ID:        "scatter-guard",
+				Title:     "Scatter the guard",
+				Prompt:    "Your e-pawn has ScatterShot. Pick the capture that also clears both knights: win at least 7 points of material.",
+				Placement: "4k3/8/8/2npnp2/4P3/8/8/4K3",
+				Turn:      White,
+				White:     scatter,
+				Black:     shadow,
+				Goal:      PuzzleGoal{Ability: AbilityScatterShot, Material: 7},
+				Success:   "exd5 lands between the knights and ScatterShot takes them both.",
+			},
+			{
+				ID:        "king-hunt",
+				Title:     "King hunt",
+				Prompt:    "Games end when a king is captured. Finish this one in a single move.",
+				Placement: "8/8/8/8/8/3k4/4P3/4K3",
+				Turn:      White,
+				White:     SideSetup{Element: ElementLight},
+				Black:     shadow,
+				Goal:      PuzzleGoal{Win: true},
+				Success:   "The king falls and the game is over.",
+			},
+			{
+				ID:        "scatter-black",
+				Title:     "Black scatters",
+				Prompt:    "Now with Black: use ScatterShot to win at least 4 points of material.",
+				Placement: "4k3/8/8/4p3/2RPN3/8/8/4K3",
+				Turn:      Black,
+				White:     SideSetup{Element: ElementLight},
+				Black:     scatter,
+				Goal:      PuzzleGoal{Ability: AbilityScatterShot, Material: 4},
+				Success:   "exd4 sweeps the rook and the knight from beside the pawn.",
+			},
+		},
+	}
+}
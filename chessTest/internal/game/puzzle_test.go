@@ -0,0 +1,77 @@
+// path: chessTest/internal/game/puzzle_test.go
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPuzzlesVerify(t *testing.T) {
+	pack := DefaultPuzzles()
+	if err := pack.Validate(); err != nil {
+		t.Fatalf("default pack: %v", err)
+	}
+	cases := []struct {
+		puzzle string
+		move   MoveRequest
+		solved bool
+		missed string
+	}{
+		{puzzle: "scatter-guard", move: MoveRequest{From: SquareE4, To: SquareD5}, solved: true},
+		{puzzle: "scatter-guard", move: MoveRequest{From: SquareE4, To: SquareF5}, missed: "gained 4 material, needs 7"},
+		{puzzle: "king-hunt", move: MoveRequest{From: SquareE2, To: SquareD3}, solved: true},
+		{puzzle: "king-hunt", move: MoveRequest{From: SquareE2, To: SquareE4}, missed: "not won"},
+		{puzzle: "scatter-black", move: MoveRequest{From: SquareE5, To: SquareD4}, solved: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.puzzle, func(t *testing.T) {
+			p, ok := pack.Puzzle(tc.puzzle)
+			if !ok {
+				t.Fatalf("no puzzle %s", tc.puzzle)
+			}
+			res, err := p.Verify(tc.move)
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if res.Solved != tc.solved || !strings.Contains(strings.Join(res.Missed, "; "), tc.missed) {
+				t.Fatalf("solved %v missed %v, want %v %q", res.Solved, res.Missed, tc.solved, tc.missed)
+			}
+		})
+	}
+	p, _ := pack.Puzzle("king-hunt")
+	if _, err := p.Verify(MoveRequest{From: SquareE2, To: SquareE5}); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("illegal move: err = %v", err)
+	}
+}
+
+func TestParsePuzzlePack(t *testing.T) {
+	const good = `{"name":"p","puzzles":[{"id":"a","position":"4k3/8/8/3p4/4P3/8/8/4K3 w","white":{"abilities":["ScatterShot"],"element":"Fire"},"black":{"element":"Shadow"},"goal":{"ability":"ScatterShot"}}]}`
+	cases := []struct {
+		name    string
+		data    string
+		wantMsg string
+	}{
+		{name: "valid", data: good},
+		{name: "bad json", data: `{`, wantMsg: "invalid puzzle"},
+		{name: "no puzzles", data: `{"name":"p","puzzles":[]}`, wantMsg: "no puzzles"},
+		{name: "no goal", data: strings.Replace(good, `"ability":"ScatterShot"`, `"material":0`, 1), wantMsg: "has no goal"},
+		{name: "unknown ability", data: strings.Replace(good, `"goal":{"ability":"ScatterShot"}`, `"goal":{"ability":"Teleport"}`, 1), wantMsg: "unknown ability"},
+		{name: "bad position", data: strings.Replace(good, " w", " x", 1), wantMsg: "side to move"},
+		{name: "duplicate id", data: strings.Replace(good, `}]}`, `},{"id":"a","position":"4k3/8/8/8/8/8/4P3/4K3 w","black":{"element":"Shadow"},"white":{"element":"Fire"},"goal":{"win":true}}]}`, 1), wantMsg: "duplicate"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pack, err := ParsePuzzlePack([]byte(tc.data))
+			if tc.wantMsg == "" {
+				if err != nil || len(pack.Puzzles) != 1 || pack.Puzzles[0].Goal.Ability != AbilityScatterShot {
+					t.Fatalf("pack %+v err %v", pack, err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidPuzzle) || !strings.Contains(err.Error(), tc.wantMsg) {
+				t.Fatalf("err = %v want %q", err, tc.wantMsg)
+			}
+		})
+	}
+}
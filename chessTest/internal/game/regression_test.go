@@ -0,0 +1,86 @@
+// path: chessTest/internal/game/regression_test.go
+package game
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func loadRegressionCorpus(t *testing.T) []CorpusEntry {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "regressions", "*.json"))
+	if err != nil {
+		t.Fatalf("glob corpus: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("regression corpus is empty")
+	}
+	out := make([]CorpusEntry, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		var entry CorpusEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+func TestRegressionCorpus(t *testing.T) {
+	for _, entry := range loadRegressionCorpus(t) {
+		t.Run(entry.Name, func(t *testing.T) {
+			got, err := entry.Replay()
+			if err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			for i, mv := range entry.Moves {
+				if got.Moves[i].Err != mv.Err {
+					t.Fatalf("move %d %s-%s: got error %q want %q (%s)", i+1, mv.From, mv.To, got.Moves[i].Err, mv.Err, entry.Note)
+				}
+			}
+			want := entry.Expect
+			if got.Expect.Placement != want.Placement {
+				t.Fatalf("placement = %s want %s", got.Expect.Placement, want.Placement)
+			}
+			if got.Expect.Turn != want.Turn {
+				t.Fatalf("turn = %s want %s", got.Expect.Turn, want.Turn)
+			}
+			if got.Expect.LastNote != want.LastNote {
+				t.Fatalf("last note = %q want %q", got.Expect.LastNote, want.LastNote)
+			}
+			if !reflect.DeepEqual(got.Expect.BlockFacing, want.BlockFacing) {
+				t.Fatalf("block facing = %v want %v", got.Expect.BlockFacing, want.BlockFacing)
+			}
+		})
+	}
+}
+
+func TestPlacementRoundTrip(t *testing.T) {
+	eng := NewEngine()
+	start := eng.Placement()
+	if start != "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR" {
+		t.Fatalf("unexpected start placement %s", start)
+	}
+	const sparse = "4k3/8/8/3p4/4P3/8/8/R3K2R"
+	if err := eng.LoadPlacement(sparse, Black); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if got := eng.Placement(); got != sparse {
+		t.Fatalf("round trip = %s want %s", got, sparse)
+	}
+	if eng.State().Turn != Black {
+		t.Fatalf("expected black to move")
+	}
+	for _, bad := range []string{"", "8/8/8", "9/8/8/8/8/8/8/8", "4x3/8/8/8/8/8/8/8", "ppppppppp/8/8/8/8/8/8/8"} {
+		if err := eng.LoadPlacement(bad, White); err != ErrInvalidPosition {
+			t.Fatalf("LoadPlacement(%q) = %v want ErrInvalidPosition", bad, err)
+		}
+	}
+}
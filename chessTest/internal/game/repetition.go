@@ -0,0 +1,100 @@
+// path: chessTest/internal/game/repetition.go
+package game
+
+// RepetitionState selects the ability state that, together with the piece
+// placement and side to move, identifies a position for repetition. Two
+// positions with the same board but, say, a different side's DoOver spent are
+// not the same position: what each side can still do differs.
+type RepetitionState uint8
+
+const (
+	// RepeatDoOver includes which sides have spent their DoOver.
+	RepeatDoOver RepetitionState = 1 << iota
+	// RepeatCharges includes charge balances and DoOver debts when charges
+	// are enabled.
+	RepeatCharges
+	// RepeatFacing includes BlockPath facings.
+	RepeatFacing
+	// RepeatLoadout includes each piece's ability mask.
+	RepeatLoadout
+)
+
+// repetitionDrawCount is how many times a position must occur for the game
+// to be drawn by repetition.
+const repetitionDrawCount = 3
+
+// variantRepetitionState defines, per variant, the ability state that takes
+// part in repetition. Variants not listed compare boards only.
+var variantRepetitionState = map[string]RepetitionState{
+	VariantBattle: RepeatDoOver | RepeatCharges | RepeatFacing | RepeatLoadout,
+}
+
+// RepetitionState reports the ability state r's variant hashes for
+// repetition.
+func (r RulesConfig) RepetitionState() RepetitionState {
+	variant := r.Variant
+	if variant == "" {
+		variant = VariantBattle
+	}
+	return variantRepetitionState[variant]
+}
+
+// PositionKey is the Zobrist key repetition is judged by: the board hash
+// extended with the ability state the variant selects.
+func (e *Engine) PositionKey() uint64 {
+	return e.positionKey(e.rules.RepetitionState())
+}
+
+func (e *Engine) positionKey(state RepetitionState) uint64 {
+	h := e.board.hash()
+	if state&RepeatDoOver != 0 {
+		for i, used := range e.doOverUsed {
+			if used {
+				h ^= zobristDoOver[i]
+			}
+		}
+	}
+	if state&RepeatCharges != 0 && e.rules.Charges.Enabled {
+		for i := range e.charges {
+			h ^= zobristValue(zobristCharges[i], uint64(e.charges[i]))
+			h ^= zobristValue(zobristDebts[i], uint64(e.doOverDebt[i]))
+		}
+	}
+	if state&RepeatFacing != 0 {
+		for id, dir := range e.blockFacing {
+			h ^= zobristValue(zobristFacing, uint64(id)<<8|uint64(dir))
+		}
+	}
+	if state&RepeatLoadout != 0 {
+		for i := range e.board.ids {
+			if e.board.alive[i] && e.board.ability[i] != 0 {
+				h ^= zobristValue(zobristLoadout^uint64(e.board.ids[i]), uint64(e.board.ability[i]))
+			}
+		}
+	}
+	return h
+}
+
+// Repetitions reports how often the current position has occurred since the
+// last reset, placement, or rules or loadout change.
+func (e *Engine) Repetitions() int {
+	return int(e.positions[e.PositionKey()])
+}
+
+// resetPositions restarts repetition counting, and the adjudication streak,
+// from the current position.
+func (e *Engine) resetPositions() {
+	e.positions = map[uint64]uint8{e.PositionKey(): 1}
+	e.adjStreak = 0
+}
+
+func (e *Engine) recordPosition() uint64 {
+	if e.positions == nil {
+		e.positions = make(map[uint64]uint8)
+	}
+	key := e.PositionKey()
+	if e.positions[key] < 0xFF {
+		e.positions[key]++
+	}
+	return key
+}
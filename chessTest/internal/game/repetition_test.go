@@ -0,0 +1,81 @@
+// path: chessTest/internal/game/repetition_test.go
+package game
+
+import "testing"
+
+// A DoOver puts the board back where it was, but the position is not the same
+// one: the defender's DoOver is spent. Hashing that state keeps rewinds from
+// counting as repetitions, while a board-only hash would count them.
+func TestRepetitionHashesAbilityState(t *testing.T) {
+	saved := variantRepetitionState[VariantBattle]
+	defer func() { variantRepetitionState[VariantBattle] = saved }()
+	cases := []struct {
+		name        string
+		state       RepetitionState
+		repetitions int
+		sameKey     bool
+	}{
+		{name: "battle", state: saved, repetitions: 1},
+		{name: "board only", state: 0, repetitions: 2, sameKey: true},
+		{name: "charges only", state: RepeatCharges, repetitions: 2, sameKey: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			variantRepetitionState[VariantBattle] = tc.state
+			eng := NewEngine()
+			if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+				t.Fatalf("configure black: %v", err)
+			}
+			for _, mv := range []MoveRequest{{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5}} {
+				if err := eng.Move(mv); err != nil {
+					t.Fatalf("move %v: %v", mv, err)
+				}
+			}
+			before, boardBefore := eng.PositionKey(), eng.board.hash()
+			if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != ErrDoOverActivated {
+				t.Fatalf("expected do-over, got %v", err)
+			}
+			if eng.board.hash() != boardBefore {
+				t.Fatalf("rewind did not restore the board")
+			}
+			if got := eng.PositionKey() == before; got != tc.sameKey {
+				t.Fatalf("same key after rewind = %v want %v", got, tc.sameKey)
+			}
+			if got := eng.Repetitions(); got != tc.repetitions {
+				t.Fatalf("repetitions = %d want %d", got, tc.repetitions)
+			}
+			if eng.status != StatusActive {
+				t.Fatalf("two occurrences must not draw, status %s", eng.status)
+			}
+		})
+	}
+}
+
+func TestThreefoldRepetitionDraws(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	for _, mv := range []MoveRequest{{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5}} {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %v: %v", mv, err)
+		}
+	}
+	// Pretend the post-rewind position already occurred twice.
+	eng.doOverUsed[Black.Index()] = true
+	eng.positions[eng.PositionKey()] = 2
+	eng.doOverUsed[Black.Index()] = false
+	if err := eng.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != ErrDoOverActivated {
+		t.Fatalf("expected do-over, got %v", err)
+	}
+	if eng.status != StatusDraw || eng.statusReason != "threefold repetition" {
+		t.Fatalf("status = %s (%s), want threefold draw", eng.status, eng.statusReason)
+	}
+	restored := NewEngine()
+	if err := restored.Restore(eng.Snapshot()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.Repetitions() != repetitionDrawCount {
+		t.Fatalf("restored repetitions = %d", restored.Repetitions())
+	}
+}
@@ -0,0 +1,186 @@
+// path: chessTest/internal/game/rules.go
+package game
+
+import "time"
+
+// VariantBattle is the default battle-chess rule set. VariantStandard is the
+// other variant the engine resolves.
+const VariantBattle = "battle"
+
+// RulesConfig carries engine-wide rule and safety knobs. The zero value keeps
+// the default rules.
+//
+// Every turn resolves as one atomic segment. There is no step budget, so no
+// rule, element or ability discounts, extends or continues a turn, and
+// nothing here caps segments or prices a path step by step.
+type RulesConfig struct {
+	// Variant names the rule set: "" or VariantBattle, or VariantStandard.
+	Variant string
+	// HandlerTimeout bounds each ability handler invocation. Zero disables the
+	// guard; when set, handlers run against a private copy of the board so an
+	// abandoned handler can never touch live engine state. An abandoned
+	// handler keeps running until it returns; while too many are, moves fail
+	// with ErrHandlerBusy.
+	HandlerTimeout time.Duration
+	// IsolatePanics converts a panicking handler into ErrHandlerPanic instead
+	// of unwinding through the engine. Always on when HandlerTimeout is set.
+	IsolatePanics bool
+	// Charges enables the per-side ability charge resource.
+	Charges ChargeRules
+	// TimeControl runs a per-side clock when set.
+	TimeControl TimeControl
+	// BannedAbilities may not appear in either side's loadout.
+	BannedAbilities AbilityList
+	// DisabledAbilities are held back by feature flags for this game; like
+	// banned abilities they may not be loaded, but they fail with
+	// ErrAbilityDisabled so clients can tell the two apart.
+	DisabledAbilities AbilityList
+	// AbilityEligibility restricts abilities to the listed piece types.
+	// Abilities without an entry stay usable by every piece. A loadout is
+	// rejected when no piece of the side may use one of its abilities, and
+	// handlers never run for a piece whose type is not eligible.
+	AbilityEligibility map[Ability]PieceTypeSet
+	// ExtraRemovals caps how many pieces abilities may remove in one turn on
+	// top of the captured piece. Zero selects DefaultExtraRemovals and
+	// NoExtraRemovals disables ability removals.
+	ExtraRemovals int
+	// DoOverRewindPlies is how many plies a DoOver rewinds, counting the
+	// capture it interrupts. Zero selects DefaultDoOverRewindPlies. The rewind
+	// stops early when the history runs out.
+	DoOverRewindPlies int
+	// DoOverTurnCost is debited from the DoOver owner's charges when its next
+	// turn ends, ahead of that turn's PerTurn credit. It only applies with
+	// charges enabled.
+	DoOverTurnCost int
+	// Strict rejects requests that would otherwise fall back to a default:
+	// a BlockPath mover without a facing, a facing nobody uses, and a
+	// promotion choice the engine would ignore. See StrictError. Requests
+	// built by LegalMoves carry no facing, so they fail for BlockPath
+	// movers under Strict.
+	Strict bool
+	// Competitive keeps the engine's opinion of the position from players:
+	// EvaluateDrawFairness fails with ErrAssessmentDisabled.
+	Competitive bool
+	// Adjudication ends decided games early, for tournaments between bots.
+	Adjudication AdjudicationRules
+}
+
+const (
+	DefaultExtraRemovals = 4
+	NoExtraRemovals      = -1
+)
+
+const (
+	DefaultDoOverRewindPlies = 1
+	maxDoOverRewindPlies     = 8
+)
+
+// rewindPlies resolves DoOverRewindPlies to the rewind depth.
+func (r RulesConfig) rewindPlies() int {
+	if r.DoOverRewindPlies == 0 {
+		return DefaultDoOverRewindPlies
+	}
+	return r.DoOverRewindPlies
+}
+
+// DoOverRewindDepth is the number of plies a DoOver rewinds under r.
+func (r RulesConfig) DoOverRewindDepth() int {
+	return r.rewindPlies()
+}
+
+// ExtraRemovalBudget is how many pieces abilities may remove per turn under
+// r, on top of the captured piece.
+func (r RulesConfig) ExtraRemovalBudget() int {
+	return int(r.removalBudget())
+}
+
+// removalBudget resolves ExtraRemovals to the per-turn budget.
+func (r RulesConfig) removalBudget() uint8 {
+	switch {
+	case r.ExtraRemovals == 0:
+		return DefaultExtraRemovals
+	case r.ExtraRemovals < 0:
+		return 0
+	default:
+		return uint8(r.ExtraRemovals)
+	}
+}
+
+// DefaultRules returns the rules used by NewEngine.
+func DefaultRules() RulesConfig {
+	return RulesConfig{Charges: DefaultChargeRules()}
+}
+
+func (r RulesConfig) validate() error {
+	if r.HandlerTimeout < 0 || (r.Variant != "" && r.Variant != VariantBattle && r.Variant != VariantStandard) {
+		return ErrInvalidRules
+	}
+	if r.ExtraRemovals < NoExtraRemovals || r.ExtraRemovals > maxRemovalBudget {
+		return ErrInvalidRules
+	}
+	if r.DoOverRewindPlies < 0 || r.DoOverRewindPlies > maxDoOverRewindPlies {
+		return ErrInvalidRules
+	}
+	if r.DoOverTurnCost < 0 || r.DoOverTurnCost > 0xFF {
+		return ErrInvalidRules
+	}
+	for _, id := range r.BannedAbilities {
+		if abilityBit(id) == 0 {
+			return ErrInvalidRules
+		}
+	}
+	for _, id := range r.DisabledAbilities {
+		if abilityBit(id) == 0 {
+			return ErrInvalidRules
+		}
+	}
+	if err := validateEligibility(r.AbilityEligibility); err != nil {
+		return err
+	}
+	if err := r.TimeControl.validate(); err != nil {
+		return err
+	}
+	if err := r.Adjudication.validate(); err != nil {
+		return err
+	}
+	return r.Charges.validate()
+}
+
+// Rules reports the live rule configuration.
+func (e *Engine) Rules() RulesConfig {
+	out := e.rules
+	out.Charges = out.Charges.clone()
+	out.BannedAbilities = append(AbilityList(nil), out.BannedAbilities...)
+	out.DisabledAbilities = append(AbilityList(nil), out.DisabledAbilities...)
+	out.AbilityEligibility = cloneEligibility(out.AbilityEligibility)
+	return out
+}
+
+// SetRules validates and installs a rule configuration. Charges and clocks
+// restart from the new settings.
+func (e *Engine) SetRules(rules RulesConfig) error {
+	if err := rules.validate(); err != nil {
+		return err
+	}
+	rules.Charges = rules.Charges.clone()
+	rules.BannedAbilities = normalizeAbilities(rules.BannedAbilities)
+	rules.DisabledAbilities = normalizeAbilities(rules.DisabledAbilities)
+	rules.AbilityEligibility = cloneEligibility(rules.AbilityEligibility)
+	e.rules = rules
+	e.banned = NewAbilitySet(rules.BannedAbilities...)
+	e.disabled = NewAbilitySet(rules.DisabledAbilities...)
+	e.ineligible = rules.ineligible()
+	e.board.restrictAbilities(e.ineligible)
+	e.setVariantBoard()
+	defer e.resetPositions()
+	e.chargeCosts = rules.Charges.costTable()
+	e.resetCharges()
+	e.resetClocks()
+	guard := newHandlerGuard(rules.HandlerTimeout, rules.IsolatePanics)
+	if guard.slots != nil && e.resolver.guard.slots != nil {
+		// Handlers abandoned under the old rules still hold their slots.
+		guard.slots = e.resolver.guard.slots
+	}
+	e.resolver.guard = guard
+	return nil
+}
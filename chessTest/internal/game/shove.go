@@ -0,0 +1,103 @@
+// path: chessTest/internal/game/shove.go
+package game
+
+import "fmt"
+
+// shovePlan is a validated Earth shove: the piece at target is pushed to dest
+// by the piece at shover.
+type shovePlan struct {
+	shover int
+	target int
+	dest   Square
+}
+
+// planShove checks req as an Earth shove. The shover must belong to the side
+// to move, whose element is Earth; the target must be an enemy piece on an
+// adjacent square, diagonals included; and the square beyond it on the same
+// line must be on the board and empty. A king may not be pushed onto a square
+// the shover's side attacks.
+func (e *Engine) planShove(req MoveRequest) (shovePlan, error) {
+	if e.rules.Standard() {
+		return shovePlan{}, fmt.Errorf("%w: standard chess has no shoves", ErrIllegalShove)
+	}
+	color := e.board.turn
+	shover := e.board.pieceIndexBySquare(req.From)
+	if shover < 0 || e.board.colors[shover] != color {
+		return shovePlan{}, ErrInvalidMove
+	}
+	if e.elements[color.Index()] != ElementEarth {
+		return shovePlan{}, fmt.Errorf("%w: %s is not aligned to Earth", ErrIllegalShove, color)
+	}
+	target := e.board.pieceIndexBySquare(req.To)
+	if target < 0 || e.board.colors[target] == color {
+		return shovePlan{}, fmt.Errorf("%w: no enemy piece on %s", ErrIllegalShove, SquareToCoord(req.To))
+	}
+	dr := int(req.To)/8 - int(req.From)/8
+	df := int(req.To)%8 - int(req.From)%8
+	if max(abs(dr), abs(df)) != 1 {
+		return shovePlan{}, fmt.Errorf("%w: %s is not adjacent to %s", ErrIllegalShove, SquareToCoord(req.To), SquareToCoord(req.From))
+	}
+	dest := offsetSquare(req.To, dr, df)
+	if dest == SquareInvalid {
+		return shovePlan{}, fmt.Errorf("%w: the piece on %s would leave the board", ErrIllegalShove, SquareToCoord(req.To))
+	}
+	if !e.board.empty(dest) {
+		return shovePlan{}, fmt.Errorf("%w: %s is occupied", ErrIllegalShove, SquareToCoord(dest))
+	}
+	if e.board.types[target] == King {
+		after := e.board.clone()
+		after.movePiece(target, dest)
+		if after.attacked(dest, color) {
+			return shovePlan{}, fmt.Errorf("%w: king would be pushed into check on %s", ErrIllegalShove, SquareToCoord(dest))
+		}
+	}
+	return shovePlan{shover: shover, target: target, dest: dest}, nil
+}
+
+// shove plays req as an Earth shove in place of a move. It spends the whole
+// turn, resolves no abilities, and is undone like any other turn through the
+// history DoOver rewinds.
+func (e *Engine) shove(req MoveRequest) error {
+	plan, err := e.planShove(req)
+	if err != nil {
+		return err
+	}
+	color := e.board.turn
+	prev := e.board.clone()
+	e.pushHistory(prev)
+	e.board.movePiece(plan.target, plan.dest)
+
+	segmentAt := e.now()
+	e.moveLog = append(e.moveLog, MoveRecord{
+		Ply:       e.board.ply,
+		Color:     color,
+		PieceID:   e.board.ids[plan.shover],
+		From:      req.From,
+		To:        req.To,
+		Shove:     true,
+		TurnStart: e.turnStart,
+		SegmentAt: segmentAt,
+		TurnEnd:   segmentAt,
+	})
+	e.chargeClock(color, segmentAt)
+	e.turnStart = segmentAt
+	ply := e.board.ply
+	e.emit(Event{
+		Ply:     ply,
+		Kind:    EventShove,
+		Color:   color,
+		PieceID: e.board.ids[plan.target],
+		Type:    e.board.types[plan.target],
+		Square:  plan.dest,
+		Note:    fmt.Sprintf("pushed from %s by %s", SquareToCoord(req.To), SquareToCoord(req.From)),
+	})
+	e.emitCheck(ply, color)
+	e.accrueCharges(color)
+	e.board.turn = e.board.turn.Opposite()
+	e.board.ply++
+	e.seq++
+	e.lastNote = "Earth shove"
+	e.recordPly()
+	e.updateStatus(&prev, color)
+	return nil
+}
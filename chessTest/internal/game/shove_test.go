@@ -0,0 +1,74 @@
+// path: chessTest/internal/game/shove_test.go
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEarthShove(t *testing.T) {
+	cases := []struct {
+		name      string
+		element   Element
+		placement string
+		from, to  Square
+		wantErr   error
+		wantDest  Square
+	}{
+		{name: "pushes diagonally", element: ElementEarth, placement: "4k3/8/8/8/3p4/4P3/8/4K3", from: SquareE3, to: SquareD4, wantDest: SquareC5},
+		{name: "pushes along a file", element: ElementEarth, placement: "4k3/8/8/8/4p3/4P3/8/4K3", from: SquareE3, to: SquareE4, wantDest: SquareE5},
+		{name: "needs earth", element: ElementFire, placement: "4k3/8/8/8/3p4/4P3/8/4K3", from: SquareE3, to: SquareD4, wantErr: ErrIllegalShove},
+		{name: "destination occupied", element: ElementEarth, placement: "4k3/8/8/2p5/3p4/4P3/8/4K3", from: SquareE3, to: SquareD4, wantErr: ErrIllegalShove},
+		{name: "off the board", element: ElementEarth, placement: "4k3/8/8/8/pP6/8/8/4K3", from: SquareB4, to: SquareA4, wantErr: ErrIllegalShove},
+		{name: "not adjacent", element: ElementEarth, placement: "4k3/8/8/2p5/8/4P3/8/4K3", from: SquareE3, to: SquareC5, wantErr: ErrIllegalShove},
+		{name: "own piece", element: ElementEarth, placement: "4k3/8/8/8/3P4/4P3/8/4K3", from: SquareE3, to: SquareD4, wantErr: ErrIllegalShove},
+		{name: "king into check", element: ElementEarth, placement: "8/R7/4k3/4P3/8/8/8/4K3", from: SquareE5, to: SquareE6, wantErr: ErrIllegalShove},
+		{name: "king to safety", element: ElementEarth, placement: "8/8/4k3/4P3/8/8/8/4K3", from: SquareE5, to: SquareE6, wantDest: SquareE7},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.SetSideConfig(White, nil, tc.element); err != nil {
+				t.Fatalf("configure white: %v", err)
+			}
+			if err := eng.LoadPlacement(tc.placement, White); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			before := eng.board.hash()
+			req := MoveRequest{From: tc.from, To: tc.to, Shove: true}
+			if err := eng.ValidateMove(req); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("validate = %v want %v", err, tc.wantErr)
+			}
+			err := eng.Move(req)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("move = %v want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				if eng.board.hash() != before || eng.board.turn != White {
+					t.Fatalf("rejected shove changed the position")
+				}
+				return
+			}
+			if idx := eng.board.pieceIndexBySquare(tc.wantDest); idx < 0 || eng.board.colors[idx] != Black {
+				t.Fatalf("no black piece pushed to %s", SquareToCoord(tc.wantDest))
+			}
+			if eng.board.pieceIndexBySquare(tc.from) < 0 || !eng.board.empty(tc.to) {
+				t.Fatalf("shover must stay put and the target square empty")
+			}
+			if eng.board.turn != Black {
+				t.Fatalf("shove must end the turn")
+			}
+			events := eng.Events()
+			if len(events) == 0 || events[0].Kind != EventShove || events[0].Square != tc.wantDest {
+				t.Fatalf("events = %+v, want a shove to %s", events, SquareToCoord(tc.wantDest))
+			}
+			if log := eng.MoveLog(); len(log) != 1 || !log[0].Shove {
+				t.Fatalf("move log = %+v, want one shove", log)
+			}
+			eng.rewind(1)
+			if eng.board.hash() != before || eng.board.turn != White {
+				t.Fatalf("rewind did not undo the shove")
+			}
+		})
+	}
+}
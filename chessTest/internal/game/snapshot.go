@@ -0,0 +1,336 @@
+// path: chessTest/internal/game/snapshot.go
+package game
+
+import "time"
+
+// PieceSnapshot is one board slot, dead or alive.
+type PieceSnapshot struct {
+	ID        int
+	Color     Color
+	Type      PieceType
+	Square    Square
+	Alive     bool
+	Abilities AbilitySet
+}
+
+// BoardSnapshot is a serialisable copy of the board arrays.
+type BoardSnapshot struct {
+	Pieces []PieceSnapshot
+	Turn   Color
+	Ply    uint32
+	// Castling and EnPassant are the FEN castling rights and en passant
+	// square, set only when standard chess has any.
+	Castling  string `json:",omitempty"`
+	EnPassant string `json:",omitempty"`
+	// BlockFacing holds the facings in force on a rewind point, in Previous
+	// and Earlier only; the current board's are Snapshot.BlockFacing.
+	BlockFacing map[int]Direction `json:",omitempty"`
+}
+
+// Snapshot is a self-contained copy of an engine session. Turns resolve
+// atomically inside Move, so a snapshot taken between requests never holds a
+// half-applied turn: restoring it resumes exactly the turn in progress,
+// including its start time for clock reconciliation.
+type Snapshot struct {
+	Board    BoardSnapshot
+	Previous *BoardSnapshot
+	// Earlier holds the rewind points before Previous, oldest first, that a
+	// DoOver with DoOverRewindPlies above one can still reach.
+	Earlier   []BoardSnapshot
+	Abilities [2]AbilityList
+	// TypeAbilities holds each side's per-type abilities; see SideSetup.ByType.
+	TypeAbilities [2]map[PieceType]AbilityList `json:",omitempty"`
+	Elements      [2]Element
+	DoOverUsed    [2]bool
+	BlockFacing   map[int]Direction
+	Charges       [2]int
+	DoOverDebt    [2]int
+	Clocks        [2]time.Duration
+	Status        GameStatus
+	StatusReason  string
+	LastNote      string
+	Locked        bool
+	Rules         RulesConfig
+	TurnStart     time.Time
+	MoveLog       []MoveRecord
+	Events        []Event
+	// EventSeq is the last event sequence number issued, which may be past
+	// the last logged event after a reset.
+	EventSeq uint64
+	Seq      uint64
+	// Positions counts occurrences of each PositionKey for repetition.
+	// Snapshots without it restart counting from the restored position.
+	Positions map[uint64]uint8 `json:",omitempty"`
+	// StartFEN is the position the game began from, for PGN export; empty
+	// for the initial position.
+	StartFEN string `json:",omitempty"`
+	// Seed is the WithSeed value, so a restored game draws as it would have.
+	Seed uint64 `json:",omitempty"`
+	// Unconfigured marks the sides still to set a loadout; see
+	// PhaseAwaitingConfig.
+	Unconfigured [2]bool `json:",omitempty"`
+	// AdjudicationStreak is the signed score streak, and Adjudication the
+	// record of an adjudicated game; see AdjudicationRules.
+	AdjudicationStreak int           `json:",omitempty"`
+	Adjudication       *Adjudication `json:",omitempty"`
+}
+
+// Snapshot captures the session for persistence or reconnect.
+func (e *Engine) Snapshot() Snapshot {
+	snap := Snapshot{
+		Board:        e.board.snapshot(),
+		Elements:     e.elements,
+		DoOverUsed:   e.doOverUsed,
+		Unconfigured: e.unconfigured,
+		BlockFacing:  cloneFacing(e.blockFacing),
+		Charges:      [2]int{int(e.charges[0]), int(e.charges[1])},
+		DoOverDebt:   [2]int{int(e.doOverDebt[0]), int(e.doOverDebt[1])},
+		Status:       e.status,
+		StatusReason: e.statusReason,
+		LastNote:     e.lastNote,
+		Locked:       e.locked,
+		Rules:        e.Rules(),
+		Clocks:       e.clocks,
+		TurnStart:    e.turnStart,
+		MoveLog:      e.MoveLog(),
+		Events:       e.Events(),
+		EventSeq:     e.eventSeq,
+		Seq:          e.seq,
+		Positions:    make(map[uint64]uint8, len(e.positions)),
+		StartFEN:     e.startFEN,
+		Seed:         e.seed,
+	}
+	snap.AdjudicationStreak = e.adjStreak
+	if e.adjudication != nil {
+		a := *e.adjudication
+		snap.Adjudication = &a
+	}
+	for key, n := range e.positions {
+		snap.Positions[key] = n
+	}
+	if n := len(e.history); n > 0 {
+		prev := e.rewindSnapshot(n - 1)
+		snap.Previous = &prev
+		for i := max(0, n-e.rules.rewindPlies()); i < n-1; i++ {
+			snap.Earlier = append(snap.Earlier, e.rewindSnapshot(i))
+		}
+	}
+	for i, list := range e.abilityLists {
+		snap.Abilities[i] = append(AbilityList(nil), list...)
+	}
+	for i, byType := range e.typeAbilities {
+		for t, list := range byType {
+			if snap.TypeAbilities[i] == nil {
+				snap.TypeAbilities[i] = make(map[PieceType]AbilityList, len(byType))
+			}
+			snap.TypeAbilities[i][t] = append(AbilityList(nil), list...)
+		}
+	}
+	return snap
+}
+
+// Restore replaces the session with a snapshot. The engine is unchanged when
+// the snapshot is rejected.
+func (e *Engine) Restore(snap Snapshot) error {
+	board, err := snap.Board.restore()
+	if err != nil {
+		return err
+	}
+	var history []boardSoA
+	var rewinds []rewindPoint
+	if snap.Previous != nil {
+		for _, earlier := range append(snap.Earlier[:len(snap.Earlier):len(snap.Earlier)], *snap.Previous) {
+			b, err := earlier.restore()
+			if err != nil {
+				return err
+			}
+			history = append(history, b)
+			var facing map[int]Direction
+			if len(earlier.BlockFacing) > 0 {
+				facing = cloneFacing(earlier.BlockFacing)
+			}
+			rewinds = append(rewinds, rewindPoint{facing: facing})
+		}
+	}
+	if int(snap.Status) >= len(statusNames) {
+		return ErrInvalidPosition
+	}
+	if err := snap.Rules.validate(); err != nil {
+		return err
+	}
+	var lists [2]AbilityList
+	var masks [2]AbilitySet
+	for i, list := range snap.Abilities {
+		for _, id := range list {
+			if abilityBit(id) == 0 {
+				return ErrInvalidConfig
+			}
+		}
+		lists[i] = normalizeAbilities(list)
+		masks[i] = NewAbilitySet(lists[i]...)
+	}
+	var typeLists [2]map[PieceType]AbilityList
+	for i, byType := range snap.TypeAbilities {
+		for t, list := range byType {
+			if int(t) >= pieceTypeCount {
+				return ErrInvalidConfig
+			}
+			for _, id := range list {
+				if abilityBit(id) == 0 {
+					return ErrInvalidConfig
+				}
+			}
+			if normalized := normalizeAbilities(list); len(normalized) > 0 {
+				if typeLists[i] == nil {
+					typeLists[i] = make(map[PieceType]AbilityList, len(byType))
+				}
+				typeLists[i][t] = normalized
+			}
+		}
+	}
+	for _, c := range append(snap.Charges[:], snap.DoOverDebt[:]...) {
+		if c < 0 || c > 0xFFFF {
+			return ErrInvalidRules
+		}
+	}
+	if err := e.SetRules(snap.Rules); err != nil {
+		return err
+	}
+	e.board = board
+	e.history = append(e.history[:0], history...)
+	e.rewinds = append(e.rewinds[:0], rewinds...)
+	e.abilityLists = lists
+	e.abilityMask = masks
+	e.typeAbilities = typeLists
+	e.elements = snap.Elements
+	e.doOverUsed = snap.DoOverUsed
+	e.unconfigured = snap.Unconfigured
+	e.charges = [2]uint16{uint16(snap.Charges[0]), uint16(snap.Charges[1])}
+	e.doOverDebt = [2]uint16{uint16(snap.DoOverDebt[0]), uint16(snap.DoOverDebt[1])}
+	e.clocks = snap.Clocks
+	e.status = snap.Status
+	e.statusReason = snap.StatusReason
+	e.lastNote = snap.LastNote
+	e.locked = snap.Locked
+	e.turnStart = snap.TurnStart
+	e.moveLog = append(e.moveLog[:0], snap.MoveLog...)
+	e.events = append(e.events[:0], snap.Events...)
+	var last uint64
+	for i := range e.events {
+		// Snapshots from before event sequencing carry zero Seqs; number
+		// them so the sequence stays strictly increasing.
+		if e.events[i].Seq <= last {
+			e.events[i].Seq = last + 1
+		}
+		last = e.events[i].Seq
+	}
+	e.eventSeq = max(snap.EventSeq, last)
+	e.seq = snap.Seq
+	e.startFEN = snap.StartFEN
+	e.seed = snap.Seed
+	for k := range e.blockFacing {
+		delete(e.blockFacing, k)
+	}
+	for id, dir := range snap.BlockFacing {
+		e.blockFacing[id] = dir
+	}
+	if len(snap.Positions) == 0 {
+		e.resetPositions()
+	} else {
+		e.positions = make(map[uint64]uint8, len(snap.Positions))
+		for key, n := range snap.Positions {
+			e.positions[key] = n
+		}
+	}
+	e.adjStreak = snap.AdjudicationStreak
+	e.adjudication = nil
+	if snap.Adjudication != nil {
+		a := *snap.Adjudication
+		e.adjudication = &a
+	}
+	return nil
+}
+
+// rewindSnapshot is history entry i with the facings of its rewind point.
+// The positions its ply recorded are not kept: a restored game does not
+// uncount them when it rewinds.
+func (e *Engine) rewindSnapshot(i int) BoardSnapshot {
+	out := e.history[i].snapshot()
+	if len(e.rewinds[i].facing) > 0 {
+		out.BlockFacing = cloneFacing(e.rewinds[i].facing)
+	}
+	return out
+}
+
+func (b *boardSoA) snapshot() BoardSnapshot {
+	out := BoardSnapshot{Turn: b.turn, Ply: b.ply}
+	if b.castling != 0 {
+		out.Castling = castlingField(b.castling)
+	}
+	if b.ep != 0 {
+		out.EnPassant = b.enPassantField()
+	}
+	for i := range b.ids {
+		if b.ids[i] == 0 {
+			continue
+		}
+		out.Pieces = append(out.Pieces, PieceSnapshot{
+			ID:        b.ids[i],
+			Color:     b.colors[i],
+			Type:      b.types[i],
+			Square:    b.squares[i],
+			Alive:     b.alive[i],
+			Abilities: b.ability[i],
+		})
+	}
+	return out
+}
+
+func (s BoardSnapshot) restore() (boardSoA, error) {
+	var b boardSoA
+	if len(s.Pieces) > len(b.ids) || int(s.Turn) > 1 {
+		return b, ErrInvalidPosition
+	}
+	seen := make(map[int]struct{}, len(s.Pieces))
+	for i, pc := range s.Pieces {
+		if pc.ID <= 0 || int(pc.Color) > 1 || pc.Type > King || pc.Square > SquareH8 {
+			return b, ErrInvalidPosition
+		}
+		if _, dup := seen[pc.ID]; dup {
+			return b, ErrInvalidPosition
+		}
+		seen[pc.ID] = struct{}{}
+		b.ids[i] = pc.ID
+		b.colors[i] = pc.Color
+		b.types[i] = pc.Type
+		b.squares[i] = pc.Square
+		b.ability[i] = pc.Abilities
+		if !pc.Alive {
+			continue
+		}
+		bit := uint64(1) << uint(pc.Square)
+		if (b.occupancy[0]|b.occupancy[1])&bit != 0 {
+			return b, ErrInvalidPosition
+		}
+		b.alive[i] = true
+		b.occupancy[pc.Color.Index()] |= bit
+		b.pieceMask[pc.Color.Index()][pc.Type] |= bit
+	}
+	b.turn = s.Turn
+	b.ply = s.Ply
+	if s.Castling != "" {
+		rights, ok := parseCastlingField(s.Castling)
+		if !ok {
+			return b, ErrInvalidPosition
+		}
+		b.castling = rights
+	}
+	if s.EnPassant != "" {
+		sq, ok := CoordToSquare(s.EnPassant)
+		if !ok {
+			return b, ErrInvalidPosition
+		}
+		b.ep = uint64(1) << uint(sq)
+	}
+	return b, nil
+}
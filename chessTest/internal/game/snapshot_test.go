@@ -0,0 +1,179 @@
+// path: chessTest/internal/game/snapshot_test.go
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotResumesSession(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.SetSideConfig(White, AbilityList{AbilityBlockPath}, ElementLight); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	for _, mv := range []MoveRequest{{From: SquareE2, To: SquareE4, Dir: DirNE}, {From: SquareD7, To: SquareD5}} {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %v: %v", mv, err)
+		}
+	}
+	data, err := json.Marshal(eng.Snapshot())
+	if err != nil {
+		t.Fatalf("encode snapshot: %v", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	resumed := NewEngine()
+	if err := resumed.Restore(snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	want, got := eng.State(), resumed.State()
+	if got.Hash != want.Hash || got.Turn != want.Turn || len(got.BlockFacing) != 1 {
+		t.Fatalf("resumed state diverged: %+v vs %+v", got, want)
+	}
+	if len(resumed.MoveLog()) != 2 || !resumed.TurnStarted().Equal(eng.TurnStarted()) {
+		t.Fatalf("resumed clock data missing")
+	}
+	// The rewind point survives, so DoOver still works after resuming.
+	for _, e := range []*Engine{eng, resumed} {
+		if err := e.Move(MoveRequest{From: SquareE4, To: SquareD5}); err != ErrDoOverActivated {
+			t.Fatalf("expected do-over after resume, got %v", err)
+		}
+	}
+	if eng.Placement() != resumed.Placement() {
+		t.Fatalf("rewound boards differ: %s vs %s", eng.Placement(), resumed.Placement())
+	}
+}
+
+func TestSnapshotKeepsClocks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	eng := NewEngine(WithClock(clock))
+	rules := DefaultRules()
+	rules.TimeControl = TimeControl{Initial: 5 * time.Minute, Increment: 2 * time.Second}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	now = now.Add(10 * time.Second)
+	if err := eng.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	data, err := json.Marshal(eng.Snapshot())
+	if err != nil {
+		t.Fatalf("encode snapshot: %v", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	resumed := NewEngine(WithClock(clock))
+	if err := resumed.Restore(snap); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	now = now.Add(3 * time.Second)
+	want := [2]time.Duration{5*time.Minute - 8*time.Second, 5*time.Minute - 3*time.Second}
+	for _, e := range []*Engine{eng, resumed} {
+		if got := e.ReadClocks().Remaining; got != want {
+			t.Fatalf("clocks = %v want %v", got, want)
+		}
+	}
+}
+
+func TestRestoreRejectsCorruptSnapshot(t *testing.T) {
+	eng := NewEngine()
+	before := eng.Placement()
+	cases := []struct {
+		name   string
+		mutate func(*Snapshot)
+	}{
+		{"overlapping pieces", func(s *Snapshot) { s.Board.Pieces[1].Square = s.Board.Pieces[0].Square }},
+		{"duplicate ids", func(s *Snapshot) { s.Board.Pieces[1].ID = s.Board.Pieces[0].ID }},
+		{"off-board square", func(s *Snapshot) { s.Board.Pieces[0].Square = SquareInvalid }},
+		{"unknown ability", func(s *Snapshot) { s.Abilities[0] = AbilityList{abilityCount} }},
+		{"bad status", func(s *Snapshot) { s.Status = 99 }},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			snap := NewEngine().Snapshot()
+			tc.mutate(&snap)
+			if err := eng.Restore(snap); err == nil {
+				t.Fatalf("expected restore error")
+			}
+			if eng.Placement() != before {
+				t.Fatalf("rejected snapshot mutated engine")
+			}
+		})
+	}
+}
+
+func TestReturnedStateIsDetached(t *testing.T) {
+	eng := NewEngine()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	eng.SetClock(func() time.Time { return now })
+	rules := DefaultRules()
+	rules.Charges.Enabled = true
+	rules.TimeControl = TimeControl{Initial: time.Minute}
+	rules.BannedAbilities = AbilityList{AbilityScorch}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	if err := eng.SetSideSetup(White, SideSetup{Abilities: AbilityList{AbilityBlockPath}, Element: ElementLight, ByType: map[PieceType]AbilityList{Queen: {AbilityDoOver}}}); err != nil {
+		t.Fatalf("configure white: %v", err)
+	}
+	if err := eng.SetSideConfig(Black, AbilityList{AbilityDoOver}, ElementShadow); err != nil {
+		t.Fatalf("configure black: %v", err)
+	}
+	var sunk []Event
+	eng.SetEventSink(func(ev Event) { sunk = append(sunk, ev) })
+	res, err := eng.MoveEx(MoveRequest{From: SquareE2, To: SquareE4, Dir: DirNE})
+	if err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	eng.events[0].Draws = []uint32{7, 8}
+	wantState := eng.State().Clone()
+	wantSnap, err := json.Marshal(eng.Snapshot())
+	if err != nil {
+		t.Fatalf("encode snapshot: %v", err)
+	}
+
+	st := eng.State()
+	st.Pieces[0].Abilities[0] = "mutated"
+	st.Pieces[0].Square = SquareA8
+	st.Abilities["white"][0] = "mutated"
+	st.TypeAbilities["white"]["queen"][0] = "mutated"
+	st.BlockFacing[5] = DirS
+	st.Charges["white"] = 99
+	st.Clocks["white"] = 1
+	snap := eng.Snapshot()
+	snap.Abilities[0][0] = AbilityScorch
+	snap.TypeAbilities[0][Queen][0] = AbilityScorch
+	snap.BlockFacing[5] = DirS
+	snap.Board.Pieces[0].Square = SquareA8
+	snap.Rules.BannedAbilities[0] = AbilityBlockPath
+	snap.Events[0].Draws[0] = 0
+	snap.MoveLog[0].To = SquareA8
+	snap.Positions[1] = 9
+	eng.Events()[0].Draws[0] = 0
+	eng.Rules().BannedAbilities[0] = AbilityBlockPath
+	res.Events[0].Draws = append(res.Events[0].Draws[:0], 0)
+	for i := range sunk {
+		sunk[i].Draws = append(sunk[i].Draws[:0], 0)
+	}
+
+	if got := eng.State(); !reflect.DeepEqual(got, wantState) {
+		t.Fatalf("state changed through a returned copy:\n got %+v\nwant %+v", got, wantState)
+	}
+	gotSnap, err := json.Marshal(eng.Snapshot())
+	if err != nil {
+		t.Fatalf("encode snapshot: %v", err)
+	}
+	if string(gotSnap) != string(wantSnap) {
+		t.Fatalf("snapshot changed through a returned copy")
+	}
+}
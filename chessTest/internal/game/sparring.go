@@ -0,0 +1,120 @@
+// path: chessTest/internal/game/sparring.go
+package game
+
+import (
+	"math/rand"
+	"strings"
+)
+
+// OpponentPolicy is how a ScriptedOpponent moves once its script is used up.
+type OpponentPolicy uint8
+
+const (
+	// PolicyStop plays nothing after the script.
+	PolicyStop OpponentPolicy = iota
+	// PolicyCapture takes a piece whenever it can, the king first, and
+	// otherwise plays a random legal move.
+	PolicyCapture
+	// PolicyRandom plays a random legal move.
+	PolicyRandom
+)
+
+var opponentPolicyNames = [...]string{
+	PolicyStop:    "stop",
+	PolicyCapture: "capture",
+	PolicyRandom:  "random",
+}
+
+func (p OpponentPolicy) String() string {
+	if int(p) < len(opponentPolicyNames) {
+		return opponentPolicyNames[p]
+	}
+	return "unknown"
+}
+
+// ParseOpponentPolicy reads a policy name; an empty name is PolicyStop.
+func ParseOpponentPolicy(s string) (OpponentPolicy, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return PolicyStop, true
+	}
+	for i, name := range opponentPolicyNames {
+		if s == name {
+			return OpponentPolicy(i), true
+		}
+	}
+	return PolicyStop, false
+}
+
+// ScriptedOpponent is a sparring partner for one side: it plays a recorded
+// move list and then falls back to a simple policy, so a game can be driven
+// to the end without a second player. When a scripted move is not legal in
+// the position reached, the rest of the script is dropped and the policy
+// takes over. A ScriptedOpponent is not safe for concurrent use; callers
+// hold the engine's lock around Next.
+type ScriptedOpponent struct {
+	script []MoveRequest
+	next   int
+	policy OpponentPolicy
+	rng    *rand.Rand
+}
+
+// NewScriptedOpponent returns an opponent that plays script and then policy,
+// drawing its random choices from seed.
+func NewScriptedOpponent(script []MoveRequest, policy OpponentPolicy, seed int64) *ScriptedOpponent {
+	return &ScriptedOpponent{
+		script: append([]MoveRequest(nil), script...),
+		policy: policy,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Policy reports the opponent's fallback policy.
+func (o *ScriptedOpponent) Policy() OpponentPolicy { return o.policy }
+
+// Remaining reports how many scripted moves are still to be played.
+func (o *ScriptedOpponent) Remaining() int { return len(o.script) - o.next }
+
+// Next picks the move for the side to move in e, or reports false when the
+// opponent has nothing to play: the script and policy are exhausted, or the
+// side has no legal move.
+func (o *ScriptedOpponent) Next(e *Engine) (MoveRequest, bool) {
+	moves := e.LegalMoves()
+	if len(moves) == 0 {
+		return MoveRequest{}, false
+	}
+	if o.next < len(o.script) {
+		req := o.script[o.next]
+		for _, mv := range moves {
+			if mv.From == req.From && mv.To == req.To {
+				o.next++
+				return req, true
+			}
+		}
+		o.next = len(o.script)
+	}
+	switch o.policy {
+	case PolicyCapture:
+		best, bestValue := -1, 0
+		for i, mv := range moves {
+			target := e.board.pieceIndexBySquare(mv.To)
+			if target < 0 {
+				continue
+			}
+			value := 1
+			if e.board.types[target] == King {
+				value = 2
+			}
+			if value > bestValue {
+				best, bestValue = i, value
+			}
+		}
+		if best >= 0 {
+			return moves[best], true
+		}
+		return moves[o.rng.Intn(len(moves))], true
+	case PolicyRandom:
+		return moves[o.rng.Intn(len(moves))], true
+	}
+	return MoveRequest{}, false
+}
@@ -0,0 +1,91 @@
+// path: chessTest/internal/game/sparring_test.go
+package game
+
+import "testing"
+
+func TestScriptedOpponent(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		script    []MoveRequest
+		policy    OpponentPolicy
+		want      MoveRequest
+		remaining int
+	}{
+		{
+			name:      "script first",
+			placement: "4k3/4p3/8/8/8/3p4/4P3/4K3",
+			script:    []MoveRequest{{From: SquareE2, To: SquareE4}, {From: SquareE4, To: SquareE5}},
+			policy:    PolicyCapture,
+			want:      MoveRequest{From: SquareE2, To: SquareE4},
+			remaining: 1,
+		},
+		{
+			name:      "illegal script falls back",
+			placement: "4k3/8/8/8/8/3p4/4P3/4K3",
+			script:    []MoveRequest{{From: SquareD2, To: SquareD4}, {From: SquareE2, To: SquareE3}},
+			policy:    PolicyCapture,
+			want:      MoveRequest{From: SquareE2, To: SquareD3},
+		},
+		{
+			name:      "capture prefers the king",
+			placement: "8/8/8/8/8/3p1k2/4P3/4K3",
+			policy:    PolicyCapture,
+			want:      MoveRequest{From: SquareE2, To: SquareF3},
+		},
+	}
+	for _, tc := range cases {
+		eng := NewEngine()
+		if err := eng.LoadPlacement(tc.placement, White); err != nil {
+			t.Fatalf("%s: load: %v", tc.name, err)
+		}
+		opp := NewScriptedOpponent(tc.script, tc.policy, 1)
+		got, ok := opp.Next(eng)
+		if !ok || got.From != tc.want.From || got.To != tc.want.To {
+			t.Fatalf("%s: Next = %s-%s, %t; want %s-%s", tc.name, SquareToCoord(got.From), SquareToCoord(got.To), ok, SquareToCoord(tc.want.From), SquareToCoord(tc.want.To))
+		}
+		if opp.Remaining() != tc.remaining {
+			t.Fatalf("%s: %d scripted moves left, want %d", tc.name, opp.Remaining(), tc.remaining)
+		}
+	}
+
+	eng := NewEngine()
+	opp := NewScriptedOpponent([]MoveRequest{{From: SquareE2, To: SquareE4}}, PolicyStop, 1)
+	if req, ok := opp.Next(eng); !ok || eng.Move(req) != nil {
+		t.Fatalf("scripted move not played")
+	}
+	if err := eng.Move(MoveRequest{From: SquareE7, To: SquareE5}); err != nil {
+		t.Fatalf("reply: %v", err)
+	}
+	if _, ok := opp.Next(eng); ok {
+		t.Fatalf("PolicyStop kept playing after its script")
+	}
+
+	// Random and capturing play only ever pick moves the engine accepts.
+	eng = NewEngine()
+	white, black := NewScriptedOpponent(nil, PolicyRandom, 7), NewScriptedOpponent(nil, PolicyCapture, 7)
+	for ply := 0; ply < 400 && eng.Phase() != PhaseGameOver; ply++ {
+		opp := white
+		if eng.Turn() == Black {
+			opp = black
+		}
+		req, ok := opp.Next(eng)
+		if !ok {
+			break
+		}
+		if err := eng.Move(req); err != nil && err != ErrDoOverActivated {
+			t.Fatalf("ply %d: %s-%s: %v", ply, SquareToCoord(req.From), SquareToCoord(req.To), err)
+		}
+	}
+}
+
+func TestParseOpponentPolicy(t *testing.T) {
+	for _, p := range []OpponentPolicy{PolicyStop, PolicyCapture, PolicyRandom} {
+		if got, ok := ParseOpponentPolicy(" " + p.String() + " "); !ok || got != p {
+			t.Fatalf("ParseOpponentPolicy(%q) = %s, %t", p.String(), got, ok)
+		}
+	}
+	if _, ok := ParseOpponentPolicy("greedy"); ok {
+		t.Fatalf("unknown policy accepted")
+	}
+}
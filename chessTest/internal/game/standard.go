@@ -0,0 +1,466 @@
+// path: chessTest/internal/game/standard.go
+package game
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// VariantStandard is orthodox chess on the same engine: every piece moves,
+// with castling, en passant and promotion, and the game ends in checkmate,
+// stalemate, insufficient material or threefold repetition. Loadouts may
+// hold no abilities, elements have no effect, and Earth shoves and Water
+// drifts are refused. The fifty-move rule is not applied.
+const VariantStandard = "standard"
+
+// Standard reports whether r plays VariantStandard.
+func (r RulesConfig) Standard() bool { return r.Variant == VariantStandard }
+
+// MovablePieceTypes lists the piece types r's variant moves.
+func (r RulesConfig) MovablePieceTypes() []PieceType {
+	if r.Standard() {
+		return []PieceType{Pawn, Knight, Bishop, Rook, Queen, King}
+	}
+	return []PieceType{Pawn}
+}
+
+// setVariantBoard fits the board and loadouts to the variant. Standard chess
+// takes the castling rights the placement allows when no move has been
+// played, and drops any abilities, leaving nothing to configure; battle chess
+// has neither castling nor en passant.
+func (e *Engine) setVariantBoard() {
+	if !e.rules.Standard() {
+		e.board.castling, e.board.ep = 0, 0
+		return
+	}
+	if len(e.moveLog) == 0 {
+		e.board.castling = e.board.homeCastling()
+	}
+	e.abilityLists = [2]AbilityList{}
+	e.abilityMask = [2]AbilitySet{}
+	e.typeAbilities = [2]map[PieceType]AbilityList{}
+	for i := range e.board.ability {
+		e.board.ability[i] = 0
+	}
+	e.unconfigured = [2]bool{}
+}
+
+// Castling rights, as held in boardSoA.castling.
+const (
+	castleWhiteKing uint8 = 1 << iota
+	castleWhiteQueen
+	castleBlackKing
+	castleBlackQueen
+)
+
+// castlingLost maps a square to the rights lost when a piece leaves or is
+// captured on it: the king's and rooks' home squares.
+var castlingLost = [64]uint8{
+	SquareA1: castleWhiteQueen,
+	SquareE1: castleWhiteKing | castleWhiteQueen,
+	SquareH1: castleWhiteKing,
+	SquareA8: castleBlackQueen,
+	SquareE8: castleBlackKing | castleBlackQueen,
+	SquareH8: castleBlackKing,
+}
+
+// homeCastling is the castling rights the placement allows: each king and
+// rook still on its home square.
+func (b *boardSoA) homeCastling() uint8 {
+	var rights uint8
+	own := func(c Color, t PieceType, sq Square) bool {
+		return b.pieceMask[c.Index()][t]&(uint64(1)<<uint(sq)) != 0
+	}
+	if own(White, King, SquareE1) {
+		if own(White, Rook, SquareH1) {
+			rights |= castleWhiteKing
+		}
+		if own(White, Rook, SquareA1) {
+			rights |= castleWhiteQueen
+		}
+	}
+	if own(Black, King, SquareE8) {
+		if own(Black, Rook, SquareH8) {
+			rights |= castleBlackKing
+		}
+		if own(Black, Rook, SquareA8) {
+			rights |= castleBlackQueen
+		}
+	}
+	return rights
+}
+
+// castlingField writes rights as FEN does, "-" for none.
+func castlingField(rights uint8) string {
+	var sb strings.Builder
+	for i, c := range "KQkq" {
+		if rights&(1<<i) != 0 {
+			sb.WriteRune(c)
+		}
+	}
+	if sb.Len() == 0 {
+		return "-"
+	}
+	return sb.String()
+}
+
+func parseCastlingField(s string) (uint8, bool) {
+	if s == "-" {
+		return 0, true
+	}
+	var rights uint8
+	for _, c := range s {
+		i := strings.IndexRune("KQkq", c)
+		if i < 0 || rights&(1<<i) != 0 {
+			return 0, false
+		}
+		rights |= 1 << i
+	}
+	return rights, s != ""
+}
+
+// enPassantField writes the en passant target square as FEN does.
+func (b *boardSoA) enPassantField() string {
+	if b.ep == 0 {
+		return "-"
+	}
+	return SquareToCoord(lowestSquare(b.ep))
+}
+
+// standardTargets lists the squares the piece in slot idx reaches under
+// orthodox movement, castling and en passant included, before checking that
+// its own king is left safe.
+func (b *boardSoA) standardTargets(idx int) uint64 {
+	from, color := b.squares[idx], b.colors[idx]
+	own := b.occupancy[color.Index()]
+	enemy := b.occupancy[color.Opposite().Index()]
+	occ := own | enemy
+	switch b.types[idx] {
+	case Pawn:
+		var out uint64
+		dir, start := 1, 1
+		if color == Black {
+			dir, start = -1, 6
+		}
+		if one := offsetSquare(from, dir, 0); one != SquareInvalid && occ&(uint64(1)<<uint(one)) == 0 {
+			out |= uint64(1) << uint(one)
+			if two := offsetSquare(one, dir, 0); int(from)/8 == start && occ&(uint64(1)<<uint(two)) == 0 {
+				out |= uint64(1) << uint(two)
+			}
+		}
+		return out | pawnAttacks[color][from]&(enemy|b.ep)
+	case Knight:
+		return knightAttacks[from] &^ own
+	case Bishop:
+		return slideTargets(from, bishopRays, occ) &^ own
+	case Rook:
+		return slideTargets(from, rookRays, occ) &^ own
+	case Queen:
+		return (slideTargets(from, rookRays, occ) | slideTargets(from, bishopRays, occ)) &^ own
+	case King:
+		return kingAttacks[from]&^own | b.castlingTargets(color, occ)
+	}
+	return 0
+}
+
+func slideTargets(from Square, rays [4][2]int, occ uint64) uint64 {
+	var out uint64
+	for _, d := range rays {
+		for cur := offsetSquare(from, d[0], d[1]); cur != SquareInvalid; cur = offsetSquare(cur, d[0], d[1]) {
+			bit := uint64(1) << uint(cur)
+			out |= bit
+			if occ&bit != 0 {
+				break
+			}
+		}
+	}
+	return out
+}
+
+// castlingTargets lists the squares color's king may castle to: the right
+// is held, the squares between king and rook are empty, and the king does
+// not start, pass or land on an attacked square.
+func (b *boardSoA) castlingTargets(color Color, occ uint64) uint64 {
+	king, short, long := SquareE1, castleWhiteKing, castleWhiteQueen
+	if color == Black {
+		king, short, long = SquareE8, castleBlackKing, castleBlackQueen
+	}
+	if b.castling&(short|long) == 0 || b.attacked(king, color.Opposite()) {
+		return 0
+	}
+	var out uint64
+	safe := func(squares ...Square) bool {
+		for _, sq := range squares {
+			if b.attacked(sq, color.Opposite()) {
+				return false
+			}
+		}
+		return true
+	}
+	const shortGap, longGap = uint64(0x60), uint64(0x0E)
+	shift := uint(king) - uint(SquareE1)
+	rooks := b.pieceMask[color.Index()][Rook]
+	if b.castling&short != 0 && rooks&(uint64(1)<<uint(king+3)) != 0 && occ&(shortGap<<shift) == 0 && safe(king+1, king+2) {
+		out |= uint64(1) << uint(king+2)
+	}
+	if b.castling&long != 0 && rooks&(uint64(1)<<uint(king-4)) != 0 && occ&(longGap<<shift) == 0 && safe(king-1, king-2) {
+		out |= uint64(1) << uint(king-2)
+	}
+	return out
+}
+
+// standardCapture is the slot an orthodox move of idx to to captures, the
+// pawn behind an en passant target included, or -1.
+func (b *boardSoA) standardCapture(idx int, to Square) int {
+	if b.types[idx] == Pawn && b.ep&(uint64(1)<<uint(to)) != 0 {
+		behind := offsetSquare(to, -1, 0)
+		if b.colors[idx] == Black {
+			behind = offsetSquare(to, 1, 0)
+		}
+		return b.pieceIndexBySquare(behind)
+	}
+	return b.pieceIndexBySquare(to)
+}
+
+// completeStandard finishes an orthodox move after the mover has reached to
+// from from and any capture is off the board: it brings the rook across for
+// castling, promotes a pawn on its last rank to promo, or to a queen when
+// promo is not a piece a pawn may become, and updates the castling rights
+// and en passant target.
+func (b *boardSoA) completeStandard(idx int, from, to Square, promo PieceType) {
+	b.castling &^= castlingLost[from] | castlingLost[to]
+	b.ep = 0
+	switch b.types[idx] {
+	case King:
+		switch int(to) - int(from) {
+		case 2:
+			b.movePiece(b.pieceIndexBySquare(to+1), to-1)
+		case -2:
+			b.movePiece(b.pieceIndexBySquare(to-2), to+1)
+		}
+	case Pawn:
+		switch rank := int(to) / 8; {
+		case abs(rank-int(from)/8) == 2:
+			b.ep = uint64(1) << uint((int(from)+int(to))/2)
+		case rank == 0 || rank == 7:
+			if promo < Knight || promo > Queen {
+				promo = Queen
+			}
+			b.promote(idx, promo)
+		}
+	}
+}
+
+func (b *boardSoA) promote(idx int, to PieceType) {
+	bit := uint64(1) << uint(b.squares[idx])
+	c := b.colors[idx].Index()
+	b.pieceMask[c][b.types[idx]] &^= bit
+	b.pieceMask[c][to] |= bit
+	b.types[idx] = to
+}
+
+// playStandard makes an orthodox move and passes the turn.
+func (b *boardSoA) playStandard(idx int, to Square, promo PieceType) {
+	from := b.squares[idx]
+	if captured := b.standardCapture(idx, to); captured >= 0 {
+		b.removePiece(captured)
+	}
+	b.movePiece(idx, to)
+	b.completeStandard(idx, from, to, promo)
+	b.turn = b.turn.Opposite()
+	b.ply++
+}
+
+// standardLegal reports whether idx may move to to: an orthodox move that
+// leaves its own king safe.
+func (b *boardSoA) standardLegal(idx int, to Square) bool {
+	if b.standardTargets(idx)&(uint64(1)<<uint(to)) == 0 {
+		return false
+	}
+	after := b.clone()
+	after.playStandard(idx, to, Queen)
+	return !after.inCheck(b.colors[idx])
+}
+
+// hasStandardMove reports whether the side to move has any legal move.
+func (b *boardSoA) hasStandardMove() bool {
+	for i := range b.ids {
+		if !b.alive[i] || b.colors[i] != b.turn {
+			continue
+		}
+		for targets := b.standardTargets(i); targets != 0; targets &= targets - 1 {
+			if b.standardLegal(i, lowestSquare(targets)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// insufficientMaterial reports whether neither side can ever mate: no pawns,
+// rooks or queens, and at most one knight or bishop a side.
+func (b *boardSoA) insufficientMaterial() bool {
+	for c := range b.pieceMask {
+		m := b.pieceMask[c]
+		if m[Pawn]|m[Rook]|m[Queen] != 0 || bits.OnesCount64(m[Knight]|m[Bishop]) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// promotionChoice is the piece req asks a promoting pawn to become, or Queen
+// when it names none.
+func promotionChoice(req MoveRequest) PieceType {
+	if req.HasPromotion {
+		return req.Promotion
+	}
+	return Queen
+}
+
+// promotes reports whether req is a pawn reaching its last rank and promoting
+// to a piece it may become.
+func (e *Engine) promotes(req MoveRequest) bool {
+	idx := e.board.pieceIndexBySquare(req.From)
+	if idx < 0 || e.board.types[idx] != Pawn {
+		return false
+	}
+	rank := int(req.To) / 8
+	return (rank == 0 || rank == 7) && req.Promotion >= Knight && req.Promotion <= Queen
+}
+
+// updateStandardStatus ends a standard game after mover's move when the
+// opponent has no legal reply, by checkmate or stalemate, or when the
+// position is drawn.
+func (e *Engine) updateStandardStatus(mover Color, ply uint32) {
+	if e.board.hasStandardMove() {
+		e.adjudicateDraw(mover, ply)
+		e.adjudicate(mover, ply)
+		return
+	}
+	if !e.board.inCheck(mover.Opposite()) {
+		e.finish(StatusDraw, "stalemate", mover, ply)
+		e.lastNote = "Stalemate"
+		return
+	}
+	status := StatusWhiteWins
+	if mover == Black {
+		status = StatusBlackWins
+	}
+	e.finish(status, mover.String()+" checkmated "+mover.Opposite().String(), mover, ply)
+	e.lastNote = "Checkmate"
+}
+
+// standardSAN writes an orthodox move from the position before it: castling
+// as O-O or O-O-O, a piece's file or rank or both when another of its kind
+// could reach the same square, the promotion piece, and "+" for check or "#"
+// for mate.
+func (b *boardSoA) standardSAN(rec MoveRecord) string {
+	idx := b.pieceIndexBySquare(rec.From)
+	if idx < 0 {
+		return SquareToCoord(rec.To)
+	}
+	promo := rec.Promotion
+	if promo == Pawn {
+		promo = Queen
+	}
+	after := b.clone()
+	after.playStandard(idx, rec.To, promo)
+	switch {
+	case !after.inCheck(after.turn):
+		return b.moveSAN(idx, rec)
+	case after.hasStandardMove():
+		return b.moveSAN(idx, rec) + "+"
+	}
+	return b.moveSAN(idx, rec) + "#"
+}
+
+// moveSAN is standardSAN without the check mark.
+func (b *boardSoA) moveSAN(idx int, rec MoveRecord) string {
+	typ := b.types[idx]
+	to := SquareToCoord(rec.To)
+	switch {
+	case typ == King && int(rec.To)-int(rec.From) == 2:
+		return "O-O"
+	case typ == King && int(rec.From)-int(rec.To) == 2:
+		return "O-O-O"
+	case typ == Pawn:
+		san := to
+		if rec.Capture {
+			san = SquareToCoord(rec.From)[:1] + "x" + to
+		}
+		if rec.Promotion != Pawn {
+			san += "=" + string(pieceLetters[rec.Promotion]-('a'-'A'))
+		}
+		return san
+	}
+	from := SquareToCoord(rec.From)
+	var sameFile, sameRank, rivals bool
+	for i := range b.ids {
+		if i == idx || !b.alive[i] || b.colors[i] != b.colors[idx] || b.types[i] != typ || !b.standardLegal(i, rec.To) {
+			continue
+		}
+		rivals = true
+		sameFile = sameFile || int(b.squares[i])%8 == int(rec.From)%8
+		sameRank = sameRank || int(b.squares[i])/8 == int(rec.From)/8
+	}
+	san := string(pieceLetters[typ] - ('a' - 'A'))
+	switch {
+	case !rivals:
+	case !sameFile:
+		san += from[:1]
+	case !sameRank:
+		san += from[1:]
+	default:
+		san += from
+	}
+	if rec.Capture {
+		san += "x"
+	}
+	return san + to
+}
+
+// Perft counts the positions depth plies below the current one under
+// orthodox rules, each promotion piece counted apart. Matching the published
+// perft numbers is the usual proof that a move generator is correct; it
+// needs VariantStandard rules.
+func (e *Engine) Perft(depth int) (uint64, error) {
+	if !e.rules.Standard() {
+		return 0, ErrInvalidRules
+	}
+	if depth < 0 {
+		return 0, ErrInvalidMove
+	}
+	return e.board.perft(depth), nil
+}
+
+func (b *boardSoA) perft(depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	var n uint64
+	mover := b.turn
+	for i := range b.ids {
+		if !b.alive[i] || b.colors[i] != mover {
+			continue
+		}
+		lastRank := b.types[i] == Pawn && (int(b.squares[i])/8 == 6 && mover == White || int(b.squares[i])/8 == 1 && mover == Black)
+		for targets := b.standardTargets(i); targets != 0; targets &= targets - 1 {
+			to := lowestSquare(targets)
+			promos := [...]PieceType{Queen, Rook, Bishop, Knight}
+			choices := promos[:1]
+			if lastRank {
+				choices = promos[:]
+			}
+			for _, promo := range choices {
+				child := b.clone()
+				child.playStandard(i, to, promo)
+				if child.inCheck(mover) {
+					break
+				}
+				n += child.perft(depth - 1)
+			}
+		}
+	}
+	return n
+}
@@ -0,0 +1,193 @@
+// path: chessTest/internal/game/standard_test.go
+package game
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func standardEngine(t *testing.T, fen string) *Engine {
+	t.Helper()
+	eng := NewEngine()
+	rules := DefaultRules()
+	rules.Variant = VariantStandard
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	if fen != "" {
+		if err := eng.LoadFEN(fen); err != nil {
+			t.Fatalf("load %q: %v", fen, err)
+		}
+	}
+	return eng
+}
+
+func TestPerft(t *testing.T) {
+	const (
+		kiwipete  = "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq -"
+		position3 = "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - -"
+		position4 = "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1"
+	)
+	cases := []struct {
+		name  string
+		fen   string
+		depth int
+		want  uint64
+	}{
+		{name: "initial 1", depth: 1, want: 20},
+		{name: "initial 2", depth: 2, want: 400},
+		{name: "initial 3", depth: 3, want: 8902},
+		{name: "initial 4", depth: 4, want: 197281},
+		{name: "kiwipete 1", fen: kiwipete, depth: 1, want: 48},
+		{name: "kiwipete 2", fen: kiwipete, depth: 2, want: 2039},
+		{name: "kiwipete 3", fen: kiwipete, depth: 3, want: 97862},
+		{name: "position 3", fen: position3, depth: 4, want: 43238},
+		{name: "position 4", fen: position4, depth: 3, want: 9467},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if testing.Short() && tc.want > 50000 {
+				t.Skip("deep perft")
+			}
+			got, err := standardEngine(t, tc.fen).Perft(tc.depth)
+			if err != nil || got != tc.want {
+				t.Fatalf("perft(%d) = %d, %v; want %d", tc.depth, got, err, tc.want)
+			}
+		})
+	}
+	if _, err := NewEngine().Perft(1); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("perft under battle rules: %v", err)
+	}
+}
+
+func TestStandardPlay(t *testing.T) {
+	cases := []struct {
+		name   string
+		fen    string
+		moves  []string
+		status GameStatus
+		// want is the placement field after the moves.
+		want string
+	}{
+		{
+			name:   "fool's mate",
+			moves:  []string{"f3", "e5", "g4", "Qh4"},
+			status: StatusBlackWins,
+		},
+		{
+			name:   "castling both ways",
+			fen:    "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+			moves:  []string{"O-O", "O-O-O"},
+			status: StatusActive,
+			want:   "2kr3r/8/8/8/8/8/8/R4RK1",
+		},
+		{
+			name:   "en passant",
+			fen:    "4k3/3p4/8/4P3/8/8/8/4K3 b - - 0 1",
+			moves:  []string{"d5", "exd6"},
+			status: StatusActive,
+			want:   "4k3/8/3P4/8/8/8/8/4K3",
+		},
+		{
+			name:   "underpromotion",
+			fen:    "8/P6k/7p/8/8/8/8/K7 w - - 0 1",
+			moves:  []string{"a8=N"},
+			status: StatusActive,
+			want:   "N7/7k/7p/8/8/8/8/K7",
+		},
+		{
+			name:   "stalemate",
+			fen:    "7k/8/6Q1/8/8/8/8/K7 w - - 0 1",
+			moves:  []string{"Qf7"},
+			status: StatusDraw,
+		},
+		{
+			name:   "insufficient material",
+			fen:    "7k/8/8/8/8/6r1/8/K6N w - - 0 1",
+			moves:  []string{"Nxg3"},
+			status: StatusDraw,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := standardEngine(t, tc.fen)
+			for _, san := range tc.moves {
+				req, err := eng.resolveSAN(san)
+				if err != nil {
+					t.Fatalf("%s: %v", san, err)
+				}
+				if err := eng.Move(req); err != nil {
+					t.Fatalf("%s: %v", san, err)
+				}
+			}
+			if eng.State().Status != tc.status {
+				t.Fatalf("status %s (%s), want %s", eng.State().Status, eng.State().StatusReason, tc.status)
+			}
+			if tc.want != "" && eng.board.placement() != tc.want {
+				t.Fatalf("placement %s, want %s", eng.board.placement(), tc.want)
+			}
+		})
+	}
+}
+
+func TestStandardRefusesBattleRules(t *testing.T) {
+	eng := standardEngine(t, "")
+	if err := eng.SetSideConfig(White, AbilityList{AbilityDoOver}, ElementLight); !errors.Is(err, ErrAbilityDisabled) {
+		t.Fatalf("ability loadout: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareG1, To: SquareF3}); err != nil {
+		t.Fatalf("knight move: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE7, To: SquareE5, Drift: DirE}); !errors.Is(err, ErrIllegalDrift) {
+		t.Fatalf("drift: %v", err)
+	}
+	if err := eng.Move(MoveRequest{From: SquareE8, To: SquareE7}); !errors.Is(err, ErrInvalidMove) {
+		t.Fatalf("king into own pawn: %v", err)
+	}
+}
+
+func TestStandardPGNRoundTrip(t *testing.T) {
+	cases := []struct {
+		name  string
+		moves string
+		// want is a stretch of the exported movetext.
+		want string
+	}{
+		{
+			name:  "disambiguation and check",
+			moves: "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7 Re1 b5 Bb3 d6 c3 O-O h3 Nb8 d4 Nbd7 Bxf7+ Rxf7",
+			want:  "9. h3 Nb8 10. d4 Nbd7 11. Bxf7+ Rxf7",
+		},
+		{
+			name:  "mate",
+			moves: "f3 e5 g4 Qh4",
+			want:  "1. f3 e5 2. g4 Qh4# 0-1",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := standardEngine(t, "")
+			for _, san := range strings.Fields(tc.moves) {
+				req, err := eng.resolveSAN(san)
+				if err != nil {
+					t.Fatalf("%s: %v", san, err)
+				}
+				if err := eng.Move(req); err != nil {
+					t.Fatalf("%s: %v", san, err)
+				}
+			}
+			pgn := eng.PGN(nil)
+			if !strings.Contains(pgn, tc.want) {
+				t.Fatalf("movetext lacks %q:\n%s", tc.want, pgn)
+			}
+			replayed := standardEngine(t, "")
+			if err := replayed.LoadPGNAndContinue(pgn); err != nil {
+				t.Fatalf("replay: %v", err)
+			}
+			if replayed.State().Hash != eng.State().Hash || replayed.State().Status != eng.State().Status {
+				t.Fatalf("replayed game differs")
+			}
+		})
+	}
+}
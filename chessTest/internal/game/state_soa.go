@@ -12,6 +12,10 @@ type boardSoA struct {
 	pieceMask [2][6]uint64
 	turn      Color
 	ply       uint32
+	// castling and ep are the castling rights and en passant target of
+	// VariantStandard; both stay zero in battle chess.
+	castling uint8
+	ep       uint64
 }
 
 func newBoard() boardSoA {
@@ -105,12 +109,12 @@ func (b *boardSoA) removePiece(idx int) {
 	b.alive[idx] = false
 }
 
-func (b *boardSoA) addAbility(mask AbilitySet, color Color) {
+func (b *boardSoA) addAbility(masks [pieceTypeCount]AbilitySet, color Color, ineligible ineligibleTable) {
 	for i := range b.ids {
 		if !b.alive[i] || b.colors[i] != color {
 			continue
 		}
-		b.ability[i] = mask
+		b.ability[i] = masks[b.types[i]] &^ ineligible[b.types[i]]
 	}
 }
 
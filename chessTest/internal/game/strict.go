@@ -0,0 +1,40 @@
+// path: chessTest/internal/game/strict.go
+package game
+
+// checkStrict refuses, under RulesConfig.Strict, the parts of req that the
+// engine would otherwise ignore or fill in. Requests for a square without a
+// piece of the side to move are left to the usual move checks.
+func (e *Engine) checkStrict(req MoveRequest) error {
+	if !e.rules.Strict {
+		return nil
+	}
+	if int(req.Dir) >= len(directionNames) {
+		return &StrictError{Field: "dir", Reason: "unknown direction"}
+	}
+	if req.HasPromotion && !e.rules.Standard() {
+		return &StrictError{Field: "promotion", Reason: "pawns do not promote in this variant"}
+	}
+	if req.HasPromotion && !e.promotes(req) {
+		return &StrictError{Field: "promotion", Reason: "only a pawn reaching its last rank promotes, to a knight, bishop, rook or queen"}
+	}
+	idx := e.board.pieceIndexBySquare(req.From)
+	if idx < 0 || e.board.colors[idx] != e.board.turn {
+		return nil
+	}
+	blocks := !req.Shove && e.holdsBlockPath(idx)
+	switch {
+	case blocks && req.Dir == DirNone:
+		return &StrictError{Field: "dir", Reason: "BlockPath needs a facing"}
+	case !blocks && req.Dir != DirNone:
+		return &StrictError{Field: "dir", Reason: "facing given but the mover has no BlockPath"}
+	}
+	return nil
+}
+
+// holdsBlockPath reports whether BlockPath would run for the piece at idx,
+// from its own abilities or its side's loadout.
+func (e *Engine) holdsBlockPath(idx int) bool {
+	color, typ := e.board.colors[idx], e.board.types[idx]
+	mask := (e.board.ability[idx] | e.abilityMask[color.Index()]) &^ e.ineligible[typ]
+	return mask.Has(AbilityBlockPath)
+}
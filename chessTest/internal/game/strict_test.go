@@ -0,0 +1,56 @@
+// path: chessTest/internal/game/strict_test.go
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictRejectsDefaults(t *testing.T) {
+	cases := []struct {
+		name      string
+		strict    bool
+		loadout   AbilityList
+		req       MoveRequest
+		wantField string
+	}{
+		{name: "lenient facing omitted", loadout: AbilityList{AbilityBlockPath}, req: MoveRequest{From: SquareE2, To: SquareE4}},
+		{name: "lenient promotion ignored", req: MoveRequest{From: SquareE2, To: SquareE4, Promotion: Queen, HasPromotion: true}},
+		{name: "facing given", strict: true, loadout: AbilityList{AbilityBlockPath}, req: MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN}},
+		{name: "plain move", strict: true, req: MoveRequest{From: SquareE2, To: SquareE4}},
+		{name: "facing omitted", strict: true, loadout: AbilityList{AbilityBlockPath}, req: MoveRequest{From: SquareE2, To: SquareE4}, wantField: "dir"},
+		{name: "unused facing", strict: true, req: MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN}, wantField: "dir"},
+		{name: "unknown direction", strict: true, req: MoveRequest{From: SquareE2, To: SquareE4, Dir: Direction(42)}, wantField: "dir"},
+		{name: "promotion", strict: true, req: MoveRequest{From: SquareE2, To: SquareE4, Promotion: Queen, HasPromotion: true}, wantField: "promotion"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			rules := DefaultRules()
+			rules.Strict = tc.strict
+			if err := eng.SetRules(rules); err != nil {
+				t.Fatalf("rules: %v", err)
+			}
+			if err := eng.SetSideConfig(White, tc.loadout, ElementNone); err != nil {
+				t.Fatalf("config: %v", err)
+			}
+			err := eng.Move(tc.req)
+			if tc.wantField == "" {
+				if err != nil {
+					t.Fatalf("move: %v", err)
+				}
+				return
+			}
+			var strictErr *StrictError
+			if !errors.As(err, &strictErr) || !errors.Is(err, ErrStrictDefault) {
+				t.Fatalf("err = %v want StrictError", err)
+			}
+			if strictErr.Field != tc.wantField {
+				t.Fatalf("field = %q want %q", strictErr.Field, tc.wantField)
+			}
+			if eng.State().Seq != 0 {
+				t.Fatal("rejected move was applied")
+			}
+		})
+	}
+}
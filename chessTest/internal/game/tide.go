@@ -0,0 +1,57 @@
+// path: chessTest/internal/game/tide.go
+package game
+
+import "fmt"
+
+// checkDrift validates a requested tide drift before the move is attempted:
+// only a side aligned to Water may drift, and only sideways.
+func (e *Engine) checkDrift(dir Direction) error {
+	if dir == DirNone {
+		return nil
+	}
+	if e.rules.Standard() {
+		return fmt.Errorf("%w: standard chess has no tides", ErrIllegalDrift)
+	}
+	color := e.board.turn
+	if e.elements[color.Index()] != ElementWater {
+		return fmt.Errorf("%w: %s is not aligned to Water", ErrIllegalDrift, color)
+	}
+	if dir != DirE && dir != DirW {
+		return fmt.Errorf("%w: drift must be E or W, not %s", ErrIllegalDrift, dir)
+	}
+	return nil
+}
+
+// drift carries the mover one square toward dir at the end of its turn, for
+// free, when that square is on the board and empty. An enemy FloodWake in
+// play this turn churns the water and holds the mover in place. It returns
+// the square the mover drifted from, or SquareInvalid when it stayed, and a
+// note for the turn.
+func (e *Engine) drift(mover int, dir Direction, res *resolveResult) (Square, string) {
+	if dir == DirNone {
+		return SquareInvalid, ""
+	}
+	from := e.board.squares[mover]
+	df := 1
+	if dir == DirW {
+		df = -1
+	}
+	to := offsetSquare(from, 0, df)
+	switch {
+	case res.tideHeld:
+		return SquareInvalid, "Tide held by FloodWake"
+	case !e.board.empty(to):
+		return SquareInvalid, "Tide drift blocked"
+	}
+	e.board.movePiece(mover, to)
+	return from, "Tide drift to " + SquareToCoord(to)
+}
+
+// driftDir is the direction recorded for a turn's drift: dir when the mover
+// left from, DirNone when it stayed.
+func driftDir(from Square, dir Direction) Direction {
+	if from == SquareInvalid {
+		return DirNone
+	}
+	return dir
+}
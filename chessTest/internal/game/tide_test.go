@@ -0,0 +1,87 @@
+// path: chessTest/internal/game/tide_test.go
+package game
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWaterTideDrift(t *testing.T) {
+	cases := []struct {
+		name      string
+		white     Element
+		whiteAbil AbilityList
+		blackAbil AbilityList
+		placement string
+		move      MoveRequest
+		wantErr   error
+		wantAt    Square
+		wantNote  string
+	}{
+		{name: "drifts east", white: ElementWater, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirE}, wantAt: SquareF4, wantNote: "Tide drift to f4"},
+		{name: "drifts west", white: ElementWater, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirW}, wantAt: SquareD4, wantNote: "Tide drift to d4"},
+		{name: "skipped", white: ElementWater, move: MoveRequest{From: SquareE2, To: SquareE4}, wantAt: SquareE4},
+		{name: "needs water", white: ElementFire, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirE}, wantErr: ErrIllegalDrift},
+		{name: "sideways only", white: ElementWater, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirN}, wantErr: ErrIllegalDrift},
+		{name: "blocked", white: ElementWater, placement: "4k3/8/8/8/5p2/8/4P3/4K3", move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirE}, wantAt: SquareE4, wantNote: "Tide drift blocked"},
+		{name: "board edge", white: ElementWater, move: MoveRequest{From: SquareH2, To: SquareH4, Drift: DirE}, wantAt: SquareH4, wantNote: "Tide drift blocked"},
+		{name: "enemy floodwake holds", white: ElementWater, blackAbil: AbilityList{AbilityFloodWake}, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirE}, wantAt: SquareE4, wantNote: "Tide held by FloodWake"},
+		{name: "own floodwake drifts", white: ElementWater, whiteAbil: AbilityList{AbilityFloodWake}, move: MoveRequest{From: SquareE2, To: SquareE4, Drift: DirE}, wantAt: SquareF4, wantNote: "Tide drift to f4"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.SetSideConfig(White, tc.whiteAbil, tc.white); err != nil {
+				t.Fatalf("configure white: %v", err)
+			}
+			if err := eng.SetSideConfig(Black, tc.blackAbil, ElementShadow); err != nil {
+				t.Fatalf("configure black: %v", err)
+			}
+			if tc.placement != "" {
+				if err := eng.LoadPlacement(tc.placement, White); err != nil {
+					t.Fatalf("load placement: %v", err)
+				}
+			}
+			before := eng.board.hash()
+			if err := eng.ValidateMove(tc.move); !errors.Is(err, tc.wantErr) {
+				t.Fatalf("validate = %v want %v", err, tc.wantErr)
+			}
+			err := eng.Move(tc.move)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("move = %v want %v", err, tc.wantErr)
+			}
+			if tc.wantErr != nil {
+				if eng.board.hash() != before {
+					t.Fatalf("rejected drift changed the board")
+				}
+				return
+			}
+			if idx := eng.board.pieceIndexBySquare(tc.wantAt); idx < 0 || eng.board.types[idx] != Pawn || eng.board.colors[idx] != White {
+				t.Fatalf("mover not on %s", SquareToCoord(tc.wantAt))
+			}
+			if eng.lastNote != tc.wantNote {
+				t.Fatalf("note = %q want %q", eng.lastNote, tc.wantNote)
+			}
+			events := eng.Events()
+			drifted := tc.wantAt != tc.move.To
+			if events[0].Kind != EventMove || events[0].Square != tc.move.To {
+				t.Fatalf("move event = %+v", events[0])
+			}
+			if got := len(events) > 1 && events[1].Kind == EventDrift && events[1].Square == tc.wantAt; got != drifted {
+				t.Fatalf("events = %+v, drifted %v", events, drifted)
+			}
+
+			replay := NewEngine()
+			if err := replay.LoadPGNAndContinue(eng.PGN(nil)); err != nil {
+				t.Fatalf("replay pgn: %v", err)
+			}
+			if replay.PositionKey() != eng.PositionKey() {
+				t.Fatalf("pgn replay lost the drift")
+			}
+			eng.rewind(1)
+			if eng.board.hash() != before {
+				t.Fatalf("rewind did not undo the drift")
+			}
+		})
+	}
+}
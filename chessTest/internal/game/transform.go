@@ -0,0 +1,112 @@
+// path: chessTest/internal/game/transform.go
+package game
+
+import "time"
+
+// Transform is a board symmetry that also swaps the colors, so pawns keep
+// moving towards the opponent and a transformed game plays out the same as
+// the original. Pawn moves, captures and BlockPath arcs are all preserved.
+type Transform uint8
+
+const (
+	// TransformMirror flips the board top to bottom: e2 becomes e7.
+	TransformMirror Transform = iota + 1
+	// TransformRotate turns the board half way round: e2 becomes d7.
+	TransformRotate
+)
+
+// Square maps sq through t. SquareInvalid stays invalid.
+func (t Transform) Square(sq Square) Square {
+	if sq > SquareH8 {
+		return sq
+	}
+	if t == TransformRotate {
+		return SquareH8 - sq
+	}
+	return sq ^ 56
+}
+
+// Direction maps d through t: a mirror swaps north and south, a rotation
+// turns every direction round. DirNone stays DirNone.
+func (t Transform) Direction(d Direction) Direction {
+	if d == DirNone || int(d) >= len(directionNames) {
+		return d
+	}
+	k := int(d) - 1
+	if t == TransformRotate {
+		return Direction((k+4)%8 + 1)
+	}
+	return Direction((12-k)%8 + 1)
+}
+
+// Move maps the squares and directions of req through t.
+func (t Transform) Move(req MoveRequest) MoveRequest {
+	req.From = t.Square(req.From)
+	req.To = t.Square(req.To)
+	req.Dir = t.Direction(req.Dir)
+	req.Drift = t.Direction(req.Drift)
+	return req
+}
+
+// MirrorPosition is TransformMirror.Position.
+func MirrorPosition(snap Snapshot) Snapshot { return TransformMirror.Position(snap) }
+
+// RotatePosition is TransformRotate.Position.
+func RotatePosition(snap Snapshot) Snapshot { return TransformRotate.Position(snap) }
+
+// Position maps a snapshot through t: pieces, rewind points and BlockPath
+// facings move, and everything held per side changes hands, including the
+// winner of a finished game. The move log, events and repetition counts
+// describe moves that were never played on the new board, so they are
+// dropped and StartFEN becomes the transformed position. The engine has no
+// castling or en passant state to carry over.
+func (t Transform) Position(snap Snapshot) Snapshot {
+	out := snap
+	out.Board = t.board(snap.Board)
+	if snap.Previous != nil {
+		prev := t.board(*snap.Previous)
+		out.Previous = &prev
+	}
+	out.Earlier = nil
+	for _, b := range snap.Earlier {
+		out.Earlier = append(out.Earlier, t.board(b))
+	}
+	if snap.BlockFacing != nil {
+		out.BlockFacing = make(map[int]Direction, len(snap.BlockFacing))
+		for id, dir := range snap.BlockFacing {
+			out.BlockFacing[id] = t.Direction(dir)
+		}
+	}
+	out.Abilities = [2]AbilityList{snap.Abilities[1], snap.Abilities[0]}
+	out.TypeAbilities = [2]map[PieceType]AbilityList{snap.TypeAbilities[1], snap.TypeAbilities[0]}
+	out.Elements = [2]Element{snap.Elements[1], snap.Elements[0]}
+	out.DoOverUsed = [2]bool{snap.DoOverUsed[1], snap.DoOverUsed[0]}
+	out.Charges = [2]int{snap.Charges[1], snap.Charges[0]}
+	out.DoOverDebt = [2]int{snap.DoOverDebt[1], snap.DoOverDebt[0]}
+	out.Clocks = [2]time.Duration{snap.Clocks[1], snap.Clocks[0]}
+	switch snap.Status {
+	case StatusWhiteWins:
+		out.Status = StatusBlackWins
+	case StatusBlackWins:
+		out.Status = StatusWhiteWins
+	}
+	out.MoveLog = nil
+	out.Events = nil
+	out.Positions = nil
+	out.StartFEN = ""
+	if b, err := out.Board.restore(); err == nil {
+		out.StartFEN = b.placement() + " " + turnLetter(b.turn)
+	}
+	return out
+}
+
+func (t Transform) board(b BoardSnapshot) BoardSnapshot {
+	out := BoardSnapshot{Turn: b.Turn.Opposite(), Ply: b.Ply}
+	out.Pieces = make([]PieceSnapshot, len(b.Pieces))
+	for i, pc := range b.Pieces {
+		pc.Color = pc.Color.Opposite()
+		pc.Square = t.Square(pc.Square)
+		out.Pieces[i] = pc
+	}
+	return out
+}
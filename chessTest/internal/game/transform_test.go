@@ -0,0 +1,83 @@
+// path: chessTest/internal/game/transform_test.go
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTransformIsAnInvolution(t *testing.T) {
+	for _, tr := range []Transform{TransformMirror, TransformRotate} {
+		for sq := Square(0); sq <= SquareH8; sq++ {
+			if got := tr.Square(tr.Square(sq)); got != sq {
+				t.Fatalf("transform %d: %s maps back to %s", tr, SquareToCoord(sq), SquareToCoord(got))
+			}
+		}
+		for d := DirN; d <= DirNW; d++ {
+			if got := tr.Direction(tr.Direction(d)); got != d {
+				t.Fatalf("transform %d: %s maps back to %s", tr, d, got)
+			}
+		}
+	}
+	if got := TransformMirror.Direction(DirNE); got != DirSE {
+		t.Fatalf("mirror NE = %s want SE", got)
+	}
+	if got := TransformRotate.Square(SquareE2); got != SquareD7 {
+		t.Fatalf("rotate e2 = %s want d7", SquareToCoord(got))
+	}
+}
+
+// TestTransformedGamesAgree plays each game twice, once as written and once
+// transformed with colors swapped, and expects the same outcome move by move.
+func TestTransformedGamesAgree(t *testing.T) {
+	cases := []struct {
+		name         string
+		white, black SideSetup
+		moves        []MoveRequest
+	}{
+		{
+			name: "plain capture",
+			moves: []MoveRequest{
+				{From: SquareE2, To: SquareE4}, {From: SquareD7, To: SquareD5}, {From: SquareE4, To: SquareD5},
+			},
+		},
+		{
+			name:  "BlockPath refuses a capture",
+			white: SideSetup{Abilities: AbilityList{AbilityBlockPath}},
+			moves: []MoveRequest{
+				{From: SquareE2, To: SquareE4, Dir: DirN}, {From: SquareD7, To: SquareD5}, {From: SquareA2, To: SquareA3, Dir: DirNE}, {From: SquareD5, To: SquareE4},
+			},
+		},
+		{
+			name:  "Water drift",
+			white: SideSetup{Element: ElementWater},
+			moves: []MoveRequest{
+				{From: SquareE2, To: SquareE4, Drift: DirE}, {From: SquareD7, To: SquareD5}, {From: SquareF4, To: SquareF5, Drift: DirW},
+			},
+		},
+	}
+	for _, tr := range []Transform{TransformMirror, TransformRotate} {
+		for _, tc := range cases {
+			orig := NewEngine()
+			if err := orig.SetSidesConfig(tc.white, tc.black); err != nil {
+				t.Fatalf("%s: config: %v", tc.name, err)
+			}
+			flipped := NewEngine()
+			if err := flipped.Restore(tr.Position(orig.Snapshot())); err != nil {
+				t.Fatalf("%s: restore: %v", tc.name, err)
+			}
+			for i, mv := range tc.moves {
+				want := orig.Move(mv)
+				got := flipped.Move(tr.Move(mv))
+				if (want == nil) != (got == nil) || (want != nil && !errors.Is(got, want)) {
+					t.Fatalf("transform %d %s move %d: err = %v want %v", tr, tc.name, i+1, got, want)
+				}
+				wantSnap, gotSnap := tr.Position(orig.Snapshot()), flipped.Snapshot()
+				if !reflect.DeepEqual(gotSnap.Board, wantSnap.Board) || !reflect.DeepEqual(gotSnap.BlockFacing, wantSnap.BlockFacing) {
+					t.Fatalf("transform %d %s move %d: boards differ\n got %+v\nwant %+v", tr, tc.name, i+1, gotSnap.Board, wantSnap.Board)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,204 @@
+// path: chessTest/internal/game/tutorial.go
+package game
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TutorialStep is one scripted lesson: a position, the prompt shown with it
+// and the moves that complete it.
+type TutorialStep struct {
+	Title     string
+	Prompt    string
+	Placement string
+	Turn      Color
+	White     SideSetup
+	Black     SideSetup
+	// Allowed lists the moves that complete the step. A request matches when
+	// its squares agree and, if the allowed move names a facing, its Dir does.
+	Allowed []MoveRequest
+	// Success is shown once the step is completed.
+	Success string
+}
+
+// Tutorial drives an engine through scripted steps, accepting only the moves
+// each step allows.
+type Tutorial struct {
+	engine   *Engine
+	steps    []TutorialStep
+	index    int
+	feedback string
+}
+
+// TutorialStatus is the tutorial's position for display.
+type TutorialStatus struct {
+	Step     int
+	Steps    int
+	Title    string
+	Prompt   string
+	Feedback string
+	Done     bool
+	State    BoardState
+}
+
+// NewTutorial loads the first of steps; DefaultTutorial is used when steps
+// is empty.
+func NewTutorial(steps []TutorialStep) (*Tutorial, error) {
+	if len(steps) == 0 {
+		steps = DefaultTutorial()
+	}
+	t := &Tutorial{engine: NewEngine(), steps: steps}
+	if err := t.load(0); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Restart returns to the first step.
+func (t *Tutorial) Restart() error {
+	t.feedback = ""
+	return t.load(0)
+}
+
+// Status reports the current step, or the finished tutorial.
+func (t *Tutorial) Status() TutorialStatus {
+	out := TutorialStatus{Step: t.index + 1, Steps: len(t.steps), Feedback: t.feedback, State: t.engine.State()}
+	if t.index >= len(t.steps) {
+		out.Step = len(t.steps)
+		out.Done = true
+		out.Title = "Tutorial complete"
+		out.Prompt = "You have seen every lesson. Restart to play them again."
+		return out
+	}
+	step := t.steps[t.index]
+	out.Title, out.Prompt = step.Title, step.Prompt
+	return out
+}
+
+// Move plays req if the current step allows it. A move outside the script
+// fails with ErrTutorialMove and leaves the position unchanged; an allowed
+// move the engine rejects returns the engine's error. A DoOver rewind counts
+// as playing the move, since showing it is the point of that lesson.
+func (t *Tutorial) Move(req MoveRequest) (TutorialStatus, error) {
+	if t.index >= len(t.steps) {
+		return t.Status(), ErrTutorialFinished
+	}
+	step := t.steps[t.index]
+	if !step.allows(req) {
+		t.feedback = "That is not the move this lesson is looking for. " + step.Prompt
+		return t.Status(), ErrTutorialMove
+	}
+	if err := t.engine.Move(req); err != nil && !errors.Is(err, ErrDoOverActivated) {
+		t.feedback = t.engine.ExplainMove(req, err)
+		if t.feedback == "" {
+			t.feedback = err.Error()
+		}
+		return t.Status(), err
+	}
+	t.feedback = step.Success
+	if t.index+1 >= len(t.steps) {
+		// Keep the final position on screen.
+		t.index++
+		return t.Status(), nil
+	}
+	if err := t.load(t.index + 1); err != nil {
+		return t.Status(), err
+	}
+	return t.Status(), nil
+}
+
+// Explain describes why req would not advance the current step, for coach
+// style hints. It returns "" when req is allowed.
+func (t *Tutorial) Explain(req MoveRequest) string {
+	if t.index >= len(t.steps) || t.steps[t.index].allows(req) {
+		return ""
+	}
+	var want []string
+	for _, mv := range t.steps[t.index].Allowed {
+		want = append(want, SquareToCoord(mv.From)+SquareToCoord(mv.To))
+	}
+	return fmt.Sprintf("This lesson accepts %v.", want)
+}
+
+func (t *Tutorial) load(index int) error {
+	step := t.steps[index]
+	eng := NewEngine()
+	if err := eng.SetSidesConfig(step.White, step.Black); err != nil {
+		return err
+	}
+	if step.Placement != "" {
+		if err := eng.LoadPlacement(step.Placement, step.Turn); err != nil {
+			return fmt.Errorf("tutorial step %d: %w", index+1, err)
+		}
+	}
+	t.engine = eng
+	t.index = index
+	return nil
+}
+
+func (s TutorialStep) allows(req MoveRequest) bool {
+	if len(s.Allowed) == 0 {
+		return true
+	}
+	for _, mv := range s.Allowed {
+		if mv.From == req.From && mv.To == req.To && (mv.Dir == DirNone || mv.Dir == req.Dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTutorial walks through pawn movement and the abilities a new player
+// meets first. Turns resolve in a single segment, so there is no step-budget
+// lesson yet.
+func DefaultTutorial() []TutorialStep {
+	plain := SideSetup{Element: ElementLight}
+	shadow := SideSetup{Element: ElementShadow}
+	return []TutorialStep{
+		{
+			Title:   "Pawns advance",
+			Prompt:  "Pawns move straight ahead, two squares on their first move. Push the e-pawn from e2 to e4.",
+			White:   plain,
+			Black:   shadow,
+			Allowed: []MoveRequest{{From: SquareE2, To: SquareE4}},
+			Success: "Well played. Only pawn moves resolve in this arena, so pawns carry the fight.",
+		},
+		{
+			Title:     "Capture diagonally",
+			Prompt:    "Pawns capture one square diagonally forward. Take the black pawn on d5.",
+			Placement: "4k3/8/8/3p4/4P3/8/8/4K3",
+			White:     plain,
+			Black:     shadow,
+			Allowed:   []MoveRequest{{From: SquareE4, To: SquareD5}},
+			Success:   "Captured. A capture also triggers any abilities either side has loaded.",
+		},
+		{
+			Title:     "BlockPath",
+			Prompt:    "BlockPath turns a moved piece to face a direction and shields it from attacks arriving from there. Play e2 to e4 facing FORWARD_LEFT to guard against the pawn on d5.",
+			Placement: "4k3/8/8/3p4/8/8/4P3/4K3",
+			White:     SideSetup{Abilities: AbilityList{AbilityBlockPath}, Element: ElementLight},
+			Black:     shadow,
+			Allowed:   []MoveRequest{{From: SquareE2, To: SquareE4, Dir: DirNW}},
+			Success:   "Your pawn now faces north-west; black cannot take it from d5.",
+		},
+		{
+			Title:     "DoOver",
+			Prompt:    "Black has DoOver loaded: the first time you capture one of its pieces, the capture is rewound. Take d5 and watch it happen.",
+			Placement: "4k3/8/8/3p4/4P3/8/8/4K3",
+			White:     plain,
+			Black:     SideSetup{Abilities: AbilityList{AbilityDoOver}, Element: ElementShadow},
+			Allowed:   []MoveRequest{{From: SquareE4, To: SquareD5}},
+			Success:   "Rewound. DoOver fires once per side, so the next capture would stand.",
+		},
+		{
+			Title:     "ScatterShot",
+			Prompt:    "ScatterShot removes enemy pieces next to the square your capturing piece lands on. Take d5 and clear the knights beside it.",
+			Placement: "4k3/8/8/2npn3/4P3/8/8/4K3",
+			White:     SideSetup{Abilities: AbilityList{AbilityScatterShot}, Element: ElementFire},
+			Black:     shadow,
+			Allowed:   []MoveRequest{{From: SquareE4, To: SquareD5}},
+			Success:   "Both knights are gone. That is the end of the tutorial.",
+		},
+	}
+}
@@ -0,0 +1,53 @@
+// path: chessTest/internal/game/tutorial_test.go
+package game
+
+import "testing"
+
+func TestDefaultTutorialPlaysThrough(t *testing.T) {
+	tut, err := NewTutorial(nil)
+	if err != nil {
+		t.Fatalf("new tutorial: %v", err)
+	}
+	if _, err := tut.Move(MoveRequest{From: SquareD2, To: SquareD4}); err != ErrTutorialMove {
+		t.Fatalf("off-script move: expected ErrTutorialMove, got %v", err)
+	}
+	if st := tut.Status(); st.Step != 1 || st.State.Turn != White || st.Feedback == "" {
+		t.Fatalf("off-script move changed the lesson: %+v", st)
+	}
+	for i, step := range DefaultTutorial() {
+		st, err := tut.Move(step.Allowed[0])
+		if err != nil {
+			t.Fatalf("step %d (%s): %v", i+1, step.Title, err)
+		}
+		if st.Feedback != step.Success {
+			t.Fatalf("step %d feedback = %q want %q", i+1, st.Feedback, step.Success)
+		}
+	}
+	st := tut.Status()
+	if !st.Done || st.Step != len(DefaultTutorial()) {
+		t.Fatalf("expected finished tutorial, got %+v", st)
+	}
+	if _, err := tut.Move(MoveRequest{From: SquareE2, To: SquareE4}); err != ErrTutorialFinished {
+		t.Fatalf("expected ErrTutorialFinished, got %v", err)
+	}
+	if err := tut.Restart(); err != nil || tut.Status().Step != 1 {
+		t.Fatalf("restart: %v", err)
+	}
+}
+
+func TestTutorialRequiresScriptedFacing(t *testing.T) {
+	steps := DefaultTutorial()[2:3]
+	tut, err := NewTutorial(steps)
+	if err != nil {
+		t.Fatalf("new tutorial: %v", err)
+	}
+	if _, err := tut.Move(MoveRequest{From: SquareE2, To: SquareE4, Dir: DirN}); err != ErrTutorialMove {
+		t.Fatalf("wrong facing: expected ErrTutorialMove, got %v", err)
+	}
+	if hint := tut.Explain(MoveRequest{From: SquareE2, To: SquareE4}); hint == "" {
+		t.Fatalf("expected a hint for the unfaced move")
+	}
+	if _, err := tut.Move(MoveRequest{From: SquareE2, To: SquareE4, Dir: DirNW}); err != nil {
+		t.Fatalf("scripted move: %v", err)
+	}
+}
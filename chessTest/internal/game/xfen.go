@@ -0,0 +1,132 @@
+// path: chessTest/internal/game/xfen.go
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// XFEN extends a FEN placement and side to move with the battle state that
+// changes what is legal, in six space-separated fields:
+//
+//	4k3/8/8/2npnp2/4P3/8/8/4K3 w ScatterShot,DoOver/Fire -/Shadow e4:N b
+//
+// After the placement and side come each side's loadout as abilities/element
+// ("-" for no abilities), the BlockPath facings as square:direction pairs and
+// the sides that have spent their DoOver ("w", "b", "wb", or "-" for
+// neither). Charges, clocks and per-type abilities are not recorded; the
+// format is for test fixtures under the default rules.
+
+// XFEN renders the position as an XFEN string.
+func (e *Engine) XFEN() string {
+	fields := []string{e.board.placement(), turnLetter(e.board.turn)}
+	for i := range e.abilityLists {
+		abilities := "-"
+		if list := e.abilityLists[i]; len(list) > 0 {
+			abilities = strings.Join(list.Strings(), ",")
+		}
+		fields = append(fields, abilities+"/"+e.elements[i].String())
+	}
+	var facings []string
+	for i, id := range e.board.ids {
+		if dir, ok := e.blockFacing[id]; ok && e.board.alive[i] && dir != DirNone {
+			facings = append(facings, SquareToCoord(e.board.squares[i])+":"+dir.String())
+		}
+	}
+	fields = append(fields, orDash(strings.Join(facings, ",")))
+	used := ""
+	if e.doOverUsed[White.Index()] {
+		used += "w"
+	}
+	if e.doOverUsed[Black.Index()] {
+		used += "b"
+	}
+	fields = append(fields, orDash(used))
+	return strings.Join(fields, " ")
+}
+
+// LoadXFEN sets up the position an XFEN string describes, as LoadPlacement
+// does with the loadouts, facings and spent DoOvers applied on top. Malformed
+// input fails with ErrInvalidPosition, or with the loadout's own error.
+func (e *Engine) LoadXFEN(s string) error {
+	fields := strings.Fields(s)
+	if len(fields) != 6 {
+		return fmt.Errorf("%w: xfen needs 6 fields, got %d", ErrInvalidPosition, len(fields))
+	}
+	var turn Color
+	switch fields[1] {
+	case "w":
+		turn = White
+	case "b":
+		turn = Black
+	default:
+		return fmt.Errorf("%w: xfen side to move %q", ErrInvalidPosition, fields[1])
+	}
+	var setups [2]SideSetup
+	for i := range setups {
+		setup, err := parseXFENSide(fields[2+i])
+		if err != nil {
+			return err
+		}
+		setups[i] = setup
+	}
+	if err := e.SetSidesConfig(setups[White.Index()], setups[Black.Index()]); err != nil {
+		return err
+	}
+	if err := e.LoadPlacement(fields[0], turn); err != nil {
+		return err
+	}
+	if fields[4] != "-" {
+		for _, pair := range strings.Split(fields[4], ",") {
+			coord, name, _ := strings.Cut(pair, ":")
+			sq, ok := CoordToSquare(coord)
+			dir := ParseDirection(name)
+			idx := e.board.pieceIndexBySquare(sq)
+			if !ok || dir == DirNone || idx < 0 {
+				return fmt.Errorf("%w: xfen facing %q", ErrInvalidPosition, pair)
+			}
+			e.blockFacing[e.board.ids[idx]] = dir
+		}
+	}
+	if used := fields[5]; used != "-" {
+		if strings.Trim(used, "wb") != "" {
+			return fmt.Errorf("%w: xfen spent DoOvers %q", ErrInvalidPosition, used)
+		}
+		e.doOverUsed[White.Index()] = strings.Contains(used, "w")
+		e.doOverUsed[Black.Index()] = strings.Contains(used, "b")
+	}
+	return nil
+}
+
+func parseXFENSide(field string) (SideSetup, error) {
+	abilities, elementName, ok := strings.Cut(field, "/")
+	if !ok {
+		return SideSetup{}, fmt.Errorf("%w: xfen loadout %q", ErrInvalidPosition, field)
+	}
+	var setup SideSetup
+	if abilities != "-" {
+		for _, name := range strings.Split(abilities, ",") {
+			a, ok := ParseAbility(name)
+			if !ok {
+				return SideSetup{}, fmt.Errorf("%w: xfen ability %q", ErrInvalidPosition, name)
+			}
+			setup.Abilities = append(setup.Abilities, a)
+		}
+	}
+	setup.Element = ElementNone
+	if elementName != ElementNone.String() {
+		element, ok := ParseElement(elementName)
+		if !ok {
+			return SideSetup{}, fmt.Errorf("%w: xfen element %q", ErrInvalidPosition, elementName)
+		}
+		setup.Element = element
+	}
+	return setup, nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
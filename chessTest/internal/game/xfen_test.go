@@ -0,0 +1,79 @@
+// path: chessTest/internal/game/xfen_test.go
+package game
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestXFENRoundTrip(t *testing.T) {
+	cases := []struct {
+		name    string
+		xfen    string
+		wantErr bool
+	}{
+		{name: "loadouts", xfen: "4k3/8/8/2npnp2/4P3/8/8/4K3 w ScatterShot,DoOver/Fire -/Shadow - -"},
+		{name: "facing and spent", xfen: "4k3/8/8/3p4/4P3/8/8/4K3 b BlockPath/Earth DoOver/None e4:N b"},
+		{name: "both spent", xfen: "4k3/8/8/8/4P3/8/8/4K3 w DoOver/Air DoOver/Air - wb"},
+		{name: "short", xfen: "4k3/8/8/8/8/8/8/4K3 w", wantErr: true},
+		{name: "bad turn", xfen: "4k3/8/8/8/8/8/8/4K3 x -/Fire -/Fire - -", wantErr: true},
+		{name: "bad ability", xfen: "4k3/8/8/8/8/8/8/4K3 w Telekinesis/Fire -/Fire - -", wantErr: true},
+		{name: "facing on empty square", xfen: "4k3/8/8/8/8/8/8/4K3 w BlockPath/Fire -/Fire e4:N -", wantErr: true},
+		{name: "bad spent", xfen: "4k3/8/8/8/8/8/8/4K3 w -/Fire -/Fire - x", wantErr: true},
+	}
+	for _, tc := range cases {
+		eng := NewEngine()
+		err := eng.LoadXFEN(tc.xfen)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err = %v, want error %t", tc.name, err, tc.wantErr)
+		}
+		if err == nil && eng.XFEN() != tc.xfen {
+			t.Fatalf("%s: XFEN() = %q, want %q", tc.name, eng.XFEN(), tc.xfen)
+		}
+	}
+}
+
+// TestMinedPositions checks the fixtures written by cmd/minepositions: each
+// position must load, round-trip and offer the recorded number of moves.
+func TestMinedPositions(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "positions.txt"))
+	if err != nil {
+		t.Fatalf("open fixtures: %v", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	count := 0
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		xfen, rest, _ := strings.Cut(text, ";")
+		xfen = strings.TrimSpace(xfen)
+		want, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(rest), "moves="))
+		if err != nil {
+			t.Fatalf("line %d: bad move count %q", line, rest)
+		}
+		eng := NewEngine()
+		if err := eng.LoadXFEN(xfen); err != nil {
+			t.Fatalf("line %d: load: %v", line, err)
+		}
+		if got := eng.XFEN(); got != xfen {
+			t.Fatalf("line %d: XFEN() = %q, want %q", line, got, xfen)
+		}
+		if got := len(eng.LegalMoves()); got != want {
+			t.Fatalf("line %d: %d legal moves, want %d", line, got, want)
+		}
+		count++
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatalf("read fixtures: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("no mined positions")
+	}
+}
@@ -0,0 +1,76 @@
+// path: chessTest/internal/game/zobrist.go
+package game
+
+var (
+	zobristPieces [2][6][64]uint64
+	zobristTurn   uint64
+	// Ability state keys; see positionKey.
+	zobristDoOver  [2]uint64
+	zobristCharges [2]uint64
+	zobristDebts   [2]uint64
+	zobristFacing  uint64
+	zobristLoadout uint64
+	// Standard chess keys: castling rights and the en passant square.
+	zobristCastling  uint64
+	zobristEnPassant uint64
+)
+
+func init() {
+	seed := uint64(0x9E3779B97F4A7C15)
+	next := func() uint64 {
+		// splitmix64 keeps the table stable across builds and platforms.
+		seed += 0x9E3779B97F4A7C15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		return z ^ (z >> 31)
+	}
+	for c := range zobristPieces {
+		for t := range zobristPieces[c] {
+			for sq := range zobristPieces[c][t] {
+				zobristPieces[c][t][sq] = next()
+			}
+		}
+	}
+	zobristTurn = next()
+	for i := range zobristDoOver {
+		zobristDoOver[i] = next()
+		zobristCharges[i] = next()
+		zobristDebts[i] = next()
+	}
+	zobristFacing = next()
+	zobristLoadout = next()
+	zobristCastling = next()
+	zobristEnPassant = next()
+}
+
+// zobristValue keys a numeric piece of state, such as a charge balance, that
+// is too wide for a table: it mixes v into key with the splitmix64 finalizer.
+func zobristValue(key, v uint64) uint64 {
+	z := key ^ (v+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+// hash returns the Zobrist key of the piece placement and side to move, and
+// of the castling rights and en passant square when standard chess has any.
+func (b *boardSoA) hash() uint64 {
+	var h uint64
+	for i := range b.ids {
+		if !b.alive[i] {
+			continue
+		}
+		h ^= zobristPieces[b.colors[i].Index()][b.types[i]][b.squares[i]]
+	}
+	if b.turn == Black {
+		h ^= zobristTurn
+	}
+	if b.castling != 0 {
+		h ^= zobristValue(zobristCastling, uint64(b.castling))
+	}
+	if b.ep != 0 {
+		h ^= zobristValue(zobristEnPassant, b.ep)
+	}
+	return h
+}
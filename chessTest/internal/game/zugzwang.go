@@ -0,0 +1,128 @@
+// path: chessTest/internal/game/zugzwang.go
+package game
+
+// Zugzwang is a position the side to move would rather pass in: whatever it
+// plays, the opponent's best reply leaves it worse off than if it could stand
+// still and let the opponent move. Pawns only move forward, so a side whose
+// only pushes walk into capture can be made to lose material just by having
+// the move.
+type Zugzwang struct {
+	Ply   uint32
+	Color Color
+	// Standing is the mover's score after the opponent's best reply to a
+	// pass, and Best the highest score after the best reply to any of its
+	// Moves legal moves, both in centipawns from the mover's point of view.
+	Standing int
+	Best     int
+	Moves    int
+}
+
+// zugzwangKingLost scores a line in which the mover's king is taken.
+const zugzwangKingLost = 100000
+
+// Zugzwang reports whether the side to move is in zugzwang. It looks two
+// plies deep, a move and the best reply, and judges the boards they leave
+// with Evaluate less its mobility term: every pawn push gives up a step of
+// mobility, which would otherwise make every move look worse than a pass.
+// Moves are played on the board alone, with the piece moved and any captured
+// piece removed; ability effects are not resolved, which keeps the check
+// cheap enough for a search to extend on.
+func (e *Engine) Zugzwang() (Zugzwang, bool) {
+	moves := e.LegalMoves()
+	if len(moves) == 0 {
+		return Zugzwang{}, false
+	}
+	mover := e.board.turn
+	saved := e.board
+	defer func() { e.board = saved }()
+	e.board.turn = mover.Opposite()
+	z := Zugzwang{Ply: saved.ply, Color: mover, Standing: e.worstReply(mover), Moves: len(moves)}
+	for i, mv := range moves {
+		e.board = saved.clone()
+		e.board.playQuietly(mv)
+		score := e.worstReply(mover)
+		if i == 0 || score > z.Best {
+			z.Best = score
+		}
+		if z.Best >= z.Standing {
+			return Zugzwang{}, false
+		}
+	}
+	return z, true
+}
+
+// worstReply is mover's score after the reply that hurts it most, with the
+// opponent to move on the engine's board, or the score as it stands when the
+// opponent has no reply.
+func (e *Engine) worstReply(mover Color) int {
+	replies := e.LegalMoves()
+	if len(replies) == 0 {
+		return e.positionalScore(mover)
+	}
+	saved := e.board
+	defer func() { e.board = saved }()
+	worst := 0
+	for i, mv := range replies {
+		e.board = saved.clone()
+		e.board.playQuietly(mv)
+		if score := e.positionalScore(mover); i == 0 || score < worst {
+			worst = score
+		}
+	}
+	return worst
+}
+
+// positionalScore is Evaluate without mobility, from color's point of view.
+func (e *Engine) positionalScore(color Color) int {
+	switch {
+	case e.board.pieceMask[color.Index()][King] == 0:
+		return -zugzwangKingLost
+	case e.board.pieceMask[color.Opposite().Index()][King] == 0:
+		return zugzwangKingLost
+	}
+	ev := e.Evaluate()
+	score := ev.Score - (ev.White.Mobility-ev.Black.Mobility)*evalMobility
+	if color == Black {
+		score = -score
+	}
+	return score
+}
+
+// playQuietly moves a piece as mv asks and passes the turn, without rules,
+// abilities or logs.
+func (b *boardSoA) playQuietly(mv MoveRequest) {
+	idx := b.pieceIndexBySquare(mv.From)
+	if captured := b.pieceIndexBySquare(mv.To); captured >= 0 {
+		b.removePiece(captured)
+	}
+	b.movePiece(idx, mv.To)
+	b.turn = b.turn.Opposite()
+}
+
+// zugzwangs finds the plies of the game played from zugzwang, replaying its
+// positions on a scratch engine with the game's rules and loadouts.
+func (e *Engine) zugzwangs() []Zugzwang {
+	h, err := e.DeltaHistory()
+	if err != nil {
+		return nil
+	}
+	scratch := NewEngine()
+	if err := scratch.Restore(e.Snapshot()); err != nil {
+		return nil
+	}
+	scratch.status, scratch.locked = StatusActive, false
+	var out []Zugzwang
+	for ply := 0; ply < h.Len(); ply++ {
+		snap, err := h.At(ply)
+		if err != nil {
+			return out
+		}
+		if scratch.board, err = snap.restore(); err != nil {
+			return out
+		}
+		if z, ok := scratch.Zugzwang(); ok {
+			out = append(out, z)
+		}
+	}
+	return out
+}
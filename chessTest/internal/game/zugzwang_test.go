@@ -0,0 +1,68 @@
+// path: chessTest/internal/game/zugzwang_test.go
+package game
+
+import "testing"
+
+func TestZugzwang(t *testing.T) {
+	cases := []struct {
+		name      string
+		placement string
+		turn      Color
+		want      bool
+	}{
+		{name: "mutual, white to move", placement: "4k3/8/4p3/8/3P4/8/8/4K3", turn: White, want: true},
+		{name: "mutual, black to move", placement: "4k3/8/4p3/8/3P4/8/8/4K3", turn: Black, want: true},
+		{name: "spare tempo", placement: "4k3/8/4p3/8/3P4/8/P7/4K3", turn: White, want: false},
+		{name: "quiet push", placement: "4k3/8/8/8/8/8/5P2/4K3", turn: White, want: false},
+		{name: "initial position", placement: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR", turn: White, want: false},
+		{name: "no legal move", placement: "4k3/8/8/p7/P7/8/8/4K3", turn: White, want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			eng := NewEngine()
+			if err := eng.LoadPlacement(tc.placement, tc.turn); err != nil {
+				t.Fatalf("load placement: %v", err)
+			}
+			before := eng.PositionKey()
+			z, ok := eng.Zugzwang()
+			if ok != tc.want {
+				t.Fatalf("Zugzwang() = %+v, %v want %v", z, ok, tc.want)
+			}
+			if eng.PositionKey() != before {
+				t.Fatalf("Zugzwang() changed the position")
+			}
+			if ok && (z.Color != tc.turn || z.Best >= z.Standing || z.Moves != 1) {
+				t.Fatalf("unexpected zugzwang %+v", z)
+			}
+		})
+	}
+}
+
+func TestHighlightsZugzwang(t *testing.T) {
+	eng := NewEngine()
+	if err := eng.LoadPlacement("4k3/8/4p3/8/3P4/8/8/4K3", White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	for _, mv := range []MoveRequest{{From: SquareD4, To: SquareD5}, {From: SquareE6, To: SquareD5}} {
+		if err := eng.Move(mv); err != nil {
+			t.Fatalf("move %s-%s: %v", SquareToCoord(mv.From), SquareToCoord(mv.To), err)
+		}
+	}
+	if err := eng.Forfeit(White, "resigned"); err != nil {
+		t.Fatalf("forfeit: %v", err)
+	}
+	highlights, err := eng.Highlights()
+	if err != nil {
+		t.Fatalf("highlights: %v", err)
+	}
+	var found []Highlight
+	for _, h := range highlights {
+		if h.Kind == "zugzwang" {
+			found = append(found, h)
+		}
+	}
+	want := "white is in zugzwang: every move weakens its position"
+	if len(found) != 1 || found[0].Ply != 0 || found[0].Description != want {
+		t.Fatalf("zugzwang highlights %+v", found)
+	}
+}
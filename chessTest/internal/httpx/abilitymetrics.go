@@ -0,0 +1,96 @@
+// path: chessTest/internal/httpx/abilitymetrics.go
+package httpx
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/controller"
+	"battle_chess_poc/internal/game"
+)
+
+// handlerStats aggregates the ability handler timings every engine reports
+// through game.WithMetrics, for /api/metrics. A handler run at or above the
+// slow threshold is counted and logged, naming the game, so operators can
+// tell which ability is dragging move latency.
+type handlerStats struct {
+	mu        sync.Mutex
+	slow      time.Duration
+	byAbility map[game.Ability]*handlerStat
+}
+
+type handlerStat struct {
+	calls, errors, slow uint64
+	total, max          time.Duration
+}
+
+type handlerStatView struct {
+	Calls  uint64  `json:"calls"`
+	Errors uint64  `json:"errors"`
+	Slow   uint64  `json:"slow,omitempty"`
+	MeanMs float64 `json:"meanMs"`
+	MaxMs  float64 `json:"maxMs"`
+}
+
+// SetHandlerMetrics starts timing ability handlers in every game, the default
+// one included, and reporting them under "handlers" in /api/metrics. Handler
+// runs taking slow or longer are logged; zero logs none.
+func (s *Server) SetHandlerMetrics(slow time.Duration) {
+	h := &handlerStats{slow: slow, byAbility: make(map[game.Ability]*handlerStat)}
+	s.engineMu.Lock()
+	_ = s.engine.Apply(game.WithMetrics(h.sink(DefaultGameID)))
+	s.engineMu.Unlock()
+	s.games.ctl.OnAdd(func(g *controller.Game) {
+		_ = g.Engine().Apply(game.WithMetrics(h.sink(g.ID)))
+	})
+	s.handlerStats = h
+}
+
+// sink returns the metrics callback for game id.
+func (h *handlerStats) sink(id string) func(game.MoveMetrics) {
+	return func(m game.MoveMetrics) { h.record(id, m.Handlers) }
+}
+
+func (h *handlerStats) record(id string, runs []game.HandlerTiming) {
+	if len(runs) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, run := range runs {
+		st := h.byAbility[run.Ability]
+		if st == nil {
+			st = &handlerStat{}
+			h.byAbility[run.Ability] = st
+		}
+		st.calls++
+		st.total += run.Elapsed
+		st.max = max(st.max, run.Elapsed)
+		if run.Err != nil {
+			st.errors++
+		}
+		if h.slow > 0 && run.Elapsed >= h.slow {
+			st.slow++
+			log.Printf("slow handler: %s for %s took %v in game %s", run.Ability, run.Owner, run.Elapsed, id)
+		}
+	}
+}
+
+func (h *handlerStats) view() map[string]handlerStatView {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make(map[string]handlerStatView, len(h.byAbility))
+	for ability, st := range h.byAbility {
+		out[ability.String()] = handlerStatView{
+			Calls:  st.calls,
+			Errors: st.errors,
+			Slow:   st.slow,
+			MeanMs: durationMs(st.total / time.Duration(st.calls)),
+			MaxMs:  durationMs(st.max),
+		}
+	}
+	return out
+}
+
+func durationMs(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
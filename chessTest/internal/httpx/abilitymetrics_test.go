@@ -0,0 +1,57 @@
+// path: chessTest/internal/httpx/abilitymetrics_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func TestHandlerMetrics(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	b := bus.New()
+	defer b.Close()
+	srv.SetBus(b)
+	handler := srv.routes()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	// One game exists before metrics start and one is created after.
+	var before, after gameResponse
+	if err := json.Unmarshal(do(http.MethodPost, "/api/games", `{}`).Body.Bytes(), &before); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	srv.SetHandlerMetrics(time.Nanosecond)
+	if err := json.Unmarshal(do(http.MethodPost, "/api/games", `{}`).Body.Bytes(), &after); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	for _, prefix := range []string{"/api", "/api/games/" + before.ID, "/api/games/" + after.ID} {
+		if rr := do(http.MethodPost, prefix+"/config", `{"color":"white","abilities":["BlockPath"],"element":"Earth"}`); rr.Code != http.StatusOK {
+			t.Fatalf("%s config: status %d body %s", prefix, rr.Code, rr.Body.String())
+		}
+		if rr := do(http.MethodPost, prefix+"/move", `{"from":"e2","to":"e4","dir":"N"}`); rr.Code != http.StatusOK {
+			t.Fatalf("%s move: status %d body %s", prefix, rr.Code, rr.Body.String())
+		}
+	}
+
+	var metrics metricsView
+	if err := json.Unmarshal(do(http.MethodGet, "/api/metrics", "").Body.Bytes(), &metrics); err != nil {
+		t.Fatalf("metrics: %v", err)
+	}
+	got, ok := metrics.Handlers["BlockPath"]
+	if !ok || got.Calls != 3 || got.Errors != 0 || got.Slow != 3 || got.MaxMs < got.MeanMs {
+		t.Fatalf("handler metrics = %+v", metrics.Handlers)
+	}
+	if len(metrics.Handlers) != 1 {
+		t.Fatalf("only BlockPath ran, got %+v", metrics.Handlers)
+	}
+}
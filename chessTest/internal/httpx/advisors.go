@@ -0,0 +1,361 @@
+// path: chessTest/internal/httpx/advisors.go
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Advisors are bots attached to a human player's seat. An advisor follows the
+// game through the same open endpoints as everyone else (/api/events,
+// /api/games/{id}) and posts suggested moves that only the player who
+// attached it can read. It never plays: the GameManager refuses moves and
+// configs from a subject advising the game, whatever roles its token holds.
+//
+// Advisors need authentication, since both sides of the channel are told
+// apart by token subject; with no authenticator the endpoints answer 403.
+// The default game is reachable as /api/games/default/... here.
+
+// maxSuggestions is how many suggestions a seat keeps; older ones are
+// dropped first.
+const maxSuggestions = 32
+
+// maxSuggestionNote caps the free-text note of a suggestion, in bytes.
+const maxSuggestionNote = 280
+
+type advisorSeat struct {
+	owner   string
+	advisor string
+	next    uint64
+	queue   []suggestionView
+}
+
+type suggestionView struct {
+	ID      uint64    `json:"id"`
+	Advisor string    `json:"advisor"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Dir     string    `json:"dir,omitempty"`
+	Note    string    `json:"note,omitempty"`
+	Seq     uint64    `json:"seq"` // the game's Seq when the suggestion was made
+	At      time.Time `json:"at"`
+}
+
+type advisorView struct {
+	Color   string `json:"color"`
+	Owner   string `json:"owner"`
+	Advisor string `json:"advisor"`
+}
+
+// attachAdvisor puts advisor on key's seat on behalf of owner. A seat has at
+// most one advisor, and an advisor sits on one seat per game. An owner may
+// replace their own advisor.
+func (m *GameManager) attachAdvisor(key seatKey, owner, advisor string) (int, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.advisors == nil {
+		m.advisors = make(map[seatKey]*advisorSeat)
+	}
+	if cur, ok := m.advisors[key]; ok && cur.owner != owner {
+		return http.StatusConflict, "seat already has an advisor"
+	}
+	other := seatKey{game: key.game, color: key.color.Opposite()}
+	if cur, ok := m.advisors[other]; ok && cur.advisor == advisor {
+		return http.StatusConflict, "advisor already advises the other seat"
+	}
+	m.advisors[key] = &advisorSeat{owner: owner, advisor: advisor}
+	return http.StatusOK, ""
+}
+
+// detachAdvisor removes key's advisor if owner attached it.
+func (m *GameManager) detachAdvisor(key seatKey, owner string) (int, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.advisors[key]
+	if !ok {
+		return http.StatusNotFound, "seat has no advisor"
+	}
+	if cur.owner != owner {
+		return http.StatusForbidden, "advisor attached by another player"
+	}
+	delete(m.advisors, key)
+	return http.StatusOK, ""
+}
+
+// advising reports the seat subject advises in game id.
+func (m *GameManager) advising(id, subject string) (seatKey, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, color := range []game.Color{game.White, game.Black} {
+		key := seatKey{game: id, color: color}
+		if cur, ok := m.advisors[key]; ok && cur.advisor == subject {
+			return key, true
+		}
+	}
+	return seatKey{}, false
+}
+
+func (m *GameManager) advisorsOf(id string) []advisorView {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := []advisorView{}
+	for _, color := range []game.Color{game.White, game.Black} {
+		if cur, ok := m.advisors[seatKey{game: id, color: color}]; ok {
+			out = append(out, advisorView{Color: color.String(), Owner: cur.owner, Advisor: cur.advisor})
+		}
+	}
+	return out
+}
+
+// suggest queues s on key's seat if subject is still its advisor and the
+// seat's hint quota for variant allows it.
+func (m *GameManager) suggest(key seatKey, subject, variant string, s suggestionView) (suggestionView, int, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.advisors[key]
+	if !ok || cur.advisor != subject {
+		return suggestionView{}, http.StatusForbidden, "not an advisor in this game"
+	}
+	if status, msg := m.takeHintLocked(key, variant, s.At); msg != "" {
+		return suggestionView{}, status, msg
+	}
+	cur.next++
+	s.ID = cur.next
+	s.Advisor = subject
+	cur.queue = append(cur.queue, s)
+	if n := len(cur.queue); n > maxSuggestions {
+		cur.queue = append(cur.queue[:0:0], cur.queue[n-maxSuggestions:]...)
+	}
+	return s, http.StatusOK, ""
+}
+
+// suggestions returns the suggestions on key's seat after id after, for its
+// owner only.
+func (m *GameManager) suggestions(key seatKey, owner string, after uint64) ([]suggestionView, int, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cur, ok := m.advisors[key]
+	if !ok {
+		return nil, http.StatusNotFound, "seat has no advisor"
+	}
+	if cur.owner != owner {
+		return nil, http.StatusForbidden, "suggestions are for the advised player only"
+	}
+	out := []suggestionView{}
+	for _, s := range cur.queue {
+		if s.ID > after {
+			out = append(out, s)
+		}
+	}
+	return out, http.StatusOK, ""
+}
+
+// refuseAdvisor answers 403 and reports true when the caller advises game id.
+func (s *Server) refuseAdvisor(w http.ResponseWriter, r *http.Request, id string) bool {
+	ident, ok := IdentityFrom(r.Context())
+	if !ok || s.games == nil {
+		return false
+	}
+	if _, advising := s.games.advising(id, ident.Subject); !advising {
+		return false
+	}
+	writeError(w, http.StatusForbidden, "advisors may not play")
+	return true
+}
+
+// advisorSubject is the caller's token subject, or "" after answering 403
+// when the server runs without authentication.
+func advisorSubject(w http.ResponseWriter, r *http.Request) string {
+	ident, ok := IdentityFrom(r.Context())
+	if !ok || ident.Subject == "" {
+		writeError(w, http.StatusForbidden, "advisors require authentication")
+		return ""
+	}
+	return ident.Subject
+}
+
+// ---- API: advisors ----
+
+type advisorBody struct {
+	Color   string `json:"color"`
+	Advisor string `json:"advisor"` // the bot token's subject
+}
+
+// handleAdvisors lists a game's advisors with GET, attaches one to the
+// caller's seat with POST and detaches it with DELETE ?color=. A seat that
+// has sent heartbeats belongs to the subject that sent them.
+func (s *Server) handleAdvisors(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, _, ok := s.lookupGame(id); !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]any{"advisors": s.games.advisorsOf(id)})
+	case http.MethodPost:
+		defer r.Body.Close()
+		var body advisorBody
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		color, ok := game.ParseColor(body.Color)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid color")
+			return
+		}
+		advisor := strings.TrimSpace(body.Advisor)
+		if advisor == "" {
+			writeError(w, http.StatusBadRequest, "advisor required")
+			return
+		}
+		owner := advisorSubject(w, r)
+		if owner == "" {
+			return
+		}
+		if advisor == owner {
+			writeError(w, http.StatusBadRequest, "a player cannot advise themselves")
+			return
+		}
+		key := seatKey{game: id, color: color}
+		if holder := s.seats.holder(key); holder != "" && holder != owner {
+			writeError(w, http.StatusForbidden, "seat held by another player")
+			return
+		}
+		if status, msg := s.games.attachAdvisor(key, owner, advisor); msg != "" {
+			writeError(w, status, msg)
+			return
+		}
+		writeJSON(w, map[string]any{"advisors": s.games.advisorsOf(id)})
+	case http.MethodDelete:
+		color, ok := game.ParseColor(r.URL.Query().Get("color"))
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid color")
+			return
+		}
+		owner := advisorSubject(w, r)
+		if owner == "" {
+			return
+		}
+		if status, msg := s.games.detachAdvisor(seatKey{game: id, color: color}, owner); msg != "" {
+			writeError(w, status, msg)
+			return
+		}
+		writeJSON(w, map[string]any{"advisors": s.games.advisorsOf(id)})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// ---- API: suggestions ----
+
+type suggestionBody struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Dir  string `json:"dir,omitempty"`
+	Note string `json:"note,omitempty"`
+}
+
+// handleSuggestions takes a suggested move from the seat's advisor with POST
+// and hands the seat's suggestions to its player with GET ?color=, optionally
+// only those after ?after=<id>.
+func (s *Server) handleSuggestions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.authorize(RolePlayer, s.listSuggestions)(w, r)
+	case http.MethodPost:
+		s.authorize(RoleAdvisor, s.postSuggestion)(w, r)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) postSuggestion(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+	id := r.PathValue("id")
+	mu, eng, ok := s.lookupGame(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	var body suggestionBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if _, msg := (moveBody{From: body.From, To: body.To}).request(); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if len(body.Note) > maxSuggestionNote {
+		writeError(w, http.StatusBadRequest, "note too long")
+		return
+	}
+	subject := advisorSubject(w, r)
+	if subject == "" {
+		return
+	}
+	key, ok := s.games.advising(id, subject)
+	if !ok {
+		writeError(w, http.StatusForbidden, "not an advisor in this game")
+		return
+	}
+	mu.Lock()
+	seq, variant := eng.Seq(), eng.Rules().Variant
+	mu.Unlock()
+	sv, status, msg := s.games.suggest(key, subject, variant, suggestionView{
+		From: strings.ToLower(strings.TrimSpace(body.From)),
+		To:   strings.ToLower(strings.TrimSpace(body.To)),
+		Dir:  strings.TrimSpace(body.Dir),
+		Note: body.Note,
+		Seq:  seq,
+		At:   time.Now(),
+	})
+	if msg != "" {
+		writeError(w, status, msg)
+		return
+	}
+	writeJSON(w, sv)
+}
+
+func (s *Server) listSuggestions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, _, ok := s.lookupGame(id); !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	color, ok := game.ParseColor(r.URL.Query().Get("color"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid color")
+		return
+	}
+	var after uint64
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid after")
+			return
+		}
+		after = n
+	}
+	owner := advisorSubject(w, r)
+	if owner == "" {
+		return
+	}
+	key := seatKey{game: id, color: color}
+	list, status, msg := s.games.suggestions(key, owner, after)
+	if msg != "" {
+		writeError(w, status, msg)
+		return
+	}
+	out := map[string]any{"suggestions": list}
+	if hints := s.games.hintStatus(key, s.gameVariant(id), time.Now()); hints != nil {
+		out["hints"] = hints
+	}
+	writeJSON(w, out)
+}
@@ -0,0 +1,80 @@
+// path: chessTest/internal/httpx/advisors_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestAdvisorSuggestions(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{
+		"alice":   {Subject: "alice", Roles: []string{RolePlayer}},
+		"mallory": {Subject: "mallory", Roles: []string{RolePlayer}},
+		"bot":     {Subject: "bot", Roles: []string{RoleAdvisor}},
+		"both":    {Subject: "both", Roles: []string{RolePlayer, RoleAdvisor}},
+	})
+	id, err := srv.games.Create(game.DefaultRules(), nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	handler := srv.routes()
+	base := "/api/games/" + id
+
+	steps := []struct {
+		name     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "advisor cannot attach", token: "bot", method: http.MethodPost, path: "/advisors", body: `{"color":"white","advisor":"bot"}`, wantCode: http.StatusForbidden},
+		{name: "player attaches bot", token: "alice", method: http.MethodPost, path: "/advisors", body: `{"color":"white","advisor":"bot"}`, wantCode: http.StatusOK, wantBody: `"advisor":"bot"`},
+		{name: "seat taken", token: "mallory", method: http.MethodPost, path: "/advisors", body: `{"color":"white","advisor":"both"}`, wantCode: http.StatusConflict},
+		{name: "bot on both seats", token: "mallory", method: http.MethodPost, path: "/advisors", body: `{"color":"black","advisor":"bot"}`, wantCode: http.StatusConflict},
+		{name: "mallory attaches both", token: "mallory", method: http.MethodPost, path: "/advisors", body: `{"color":"black","advisor":"both"}`, wantCode: http.StatusOK},
+		{name: "bot suggests", token: "bot", method: http.MethodPost, path: "/suggestions", body: `{"from":"E2","to":"e4","note":"center"}`, wantCode: http.StatusOK, wantBody: `"id":1`},
+		{name: "bad square", token: "bot", method: http.MethodPost, path: "/suggestions", body: `{"from":"e9","to":"e4"}`, wantCode: http.StatusBadRequest},
+		{name: "player cannot suggest", token: "alice", method: http.MethodPost, path: "/suggestions", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "owner reads", token: "alice", method: http.MethodGet, path: "/suggestions?color=white", wantCode: http.StatusOK, wantBody: `"from":"e2","to":"e4"`},
+		{name: "after filters", token: "alice", method: http.MethodGet, path: "/suggestions?color=white&after=1", wantCode: http.StatusOK, wantBody: `"suggestions":[]`},
+		{name: "other player cannot read", token: "mallory", method: http.MethodGet, path: "/suggestions?color=white", wantCode: http.StatusForbidden},
+		{name: "advisor cannot move", token: "bot", method: http.MethodPost, path: "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "advisor with player role cannot move", token: "both", method: http.MethodPost, path: "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden, wantBody: "advisors may not play"},
+		{name: "advisor with player role cannot configure", token: "both", method: http.MethodPost, path: "/config", body: `{"color":"black","abilities":["DoOver"],"element":"Fire"}`, wantCode: http.StatusForbidden, wantBody: "advisors may not play"},
+		{name: "player moves", token: "alice", method: http.MethodPost, path: "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusOK},
+		{name: "other player cannot detach", token: "mallory", method: http.MethodDelete, path: "/advisors?color=white", wantCode: http.StatusForbidden},
+		{name: "owner detaches", token: "alice", method: http.MethodDelete, path: "/advisors?color=white", wantCode: http.StatusOK},
+		{name: "detached bot is silenced", token: "bot", method: http.MethodPost, path: "/suggestions", body: `{"from":"d2","to":"d4"}`, wantCode: http.StatusForbidden},
+	}
+	for _, st := range steps {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(st.method, base+st.path, strings.NewReader(st.body))
+		req.Header.Set("Authorization", "Bearer "+st.token)
+		handler.ServeHTTP(rr, req)
+		if rr.Code != st.wantCode || !strings.Contains(rr.Body.String(), st.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", st.name, rr.Code, rr.Body.String(), st.wantCode, st.wantBody)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, base+"/advisors", nil)
+	req.Header.Set("Authorization", "Bearer alice")
+	handler.ServeHTTP(rr, req)
+	var out struct {
+		Advisors []advisorView `json:"advisors"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(out.Advisors) != 1 || out.Advisors[0] != (advisorView{Color: "black", Owner: "mallory", Advisor: "both"}) {
+		t.Fatalf("advisors = %+v", out.Advisors)
+	}
+}
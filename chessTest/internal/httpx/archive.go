@@ -0,0 +1,309 @@
+// path: chessTest/internal/httpx/archive.go
+package httpx
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// An export holds, per game, under games/<id>/:
+//
+//	game.pgn       the moves, in the PGN dialect LoadPGNAndContinue reads
+//	events.json    the battle log, as /api/move reports events
+//	meta.json      status, rules, loadouts, timestamps, engine version, rules fingerprint
+//	               and, for an adjudicated game, why it was ended early
+//	snapshot.json  the full engine snapshot, for migrating the game
+//	history.json   every position, as the start board and per-ply deltas
+//	               (game.DeltaHistory), compact JSON
+//
+// and a MANIFEST.json, written last, with the SHA-256 of every other file.
+
+type archiveSide struct {
+	Abilities []string `json:"abilities"`
+	Element   string   `json:"element"`
+}
+
+type archiveMeta struct {
+	ID           string      `json:"id"`
+	CreatedAt    *time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt    time.Time   `json:"updatedAt"`
+	Status       string      `json:"status"`
+	StatusReason string      `json:"statusReason,omitempty"`
+	Seq          uint64      `json:"seq"`
+	Moves        int         `json:"moves"`
+	Rules        rulesBody   `json:"rules"`
+	White        archiveSide `json:"white"`
+	Black        archiveSide `json:"black"`
+	// EngineVersion is the build that wrote the archive; RulesFingerprint
+	// identifies the rules the game was played under.
+	EngineVersion    string `json:"engineVersion"`
+	RulesFingerprint string `json:"rulesFingerprint"`
+
+	// Adjudication says why a tournament game was ended early.
+	Adjudication *adjudicationView `json:"adjudication,omitempty"`
+}
+
+type adjudicationView struct {
+	Kind   string `json:"kind"`
+	Result string `json:"result"`
+	Ply    uint32 `json:"ply"`
+	Score  int    `json:"score"`
+	Streak int    `json:"streak,omitempty"`
+	Reason string `json:"reason"`
+}
+
+type manifestFile struct {
+	Path   string `json:"path"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+type archiveManifest struct {
+	ExportedAt time.Time      `json:"exportedAt"`
+	Since      *time.Time     `json:"since,omitempty"`
+	Games      []string       `json:"games"`
+	Files      []manifestFile `json:"files"`
+}
+
+// archivedGame is one game's export files, read under the game's lock.
+type archivedGame struct {
+	id        string
+	updatedAt time.Time
+	paths     []string
+	data      map[string][]byte
+}
+
+func archiveGame(id string, eng *game.Engine, created time.Time) (archivedGame, error) {
+	snap := eng.Snapshot()
+	updated := snap.TurnStart
+	if n := len(snap.MoveLog); n > 0 {
+		updated = snap.MoveLog[n-1].TurnEnd
+	}
+	meta := archiveMeta{
+		ID:           id,
+		UpdatedAt:    updated,
+		Status:       snap.Status.String(),
+		StatusReason: snap.StatusReason,
+		Seq:          snap.Seq,
+		Moves:        len(snap.MoveLog),
+		Rules:        rulesView(snap.Rules),
+		White:        archiveSide{Abilities: snap.Abilities[game.White.Index()].Strings(), Element: snap.Elements[game.White.Index()].String()},
+		Black:        archiveSide{Abilities: snap.Abilities[game.Black.Index()].Strings(), Element: snap.Elements[game.Black.Index()].String()},
+
+		EngineVersion:    game.EngineVersion,
+		RulesFingerprint: snap.Rules.Fingerprint(),
+	}
+	if !created.IsZero() {
+		meta.CreatedAt = &created
+	}
+	if adj := snap.Adjudication; adj != nil {
+		meta.Adjudication = &adjudicationView{
+			Kind:   adj.Kind.String(),
+			Result: adj.Result.String(),
+			Ply:    adj.Ply,
+			Score:  adj.Score,
+			Streak: adj.Streak,
+			Reason: adj.Reason,
+		}
+	}
+	out := archivedGame{id: id, updatedAt: updated, data: make(map[string][]byte)}
+	add := func(name string, data []byte) {
+		path := "games/" + id + "/" + name
+		out.paths = append(out.paths, path)
+		out.data[path] = data
+	}
+	add("game.pgn", []byte(eng.PGN(map[string]string{"Event": "Battle Chess", "Site": id, "Date": updated.UTC().Format("2006.01.02")})))
+	for _, part := range []struct {
+		name string
+		v    any
+	}{
+		{"events.json", moveEventViews(snap.Events)},
+		{"meta.json", meta},
+		{"snapshot.json", snap},
+	} {
+		data, err := json.MarshalIndent(part.v, "", "  ")
+		if err != nil {
+			return archivedGame{}, fmt.Errorf("game %s %s: %w", id, part.name, err)
+		}
+		add(part.name, data)
+	}
+	history, err := eng.DeltaHistory()
+	if err != nil {
+		return archivedGame{}, fmt.Errorf("game %s history.json: %w", id, err)
+	}
+	data, err := json.Marshal(history)
+	if err != nil {
+		return archivedGame{}, fmt.Errorf("game %s history.json: %w", id, err)
+	}
+	add("history.json", data)
+	return out, nil
+}
+
+// eachArchivedGame hands fn every game changed after since, or all of them
+// when since is zero: the default game first, then the rest by id. Each game
+// is read under its own lock, so the export never stops play server-wide.
+func (s *Server) eachArchivedGame(since time.Time, fn func(archivedGame) error) error {
+	keep := func(g archivedGame) error {
+		if since.IsZero() || g.updatedAt.After(since) {
+			return fn(g)
+		}
+		return nil
+	}
+	s.engineMu.Lock()
+	g, err := archiveGame(DefaultGameID, s.engine, time.Time{})
+	s.engineMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := keep(g); err != nil {
+		return err
+	}
+
+	for _, id := range s.games.ctl.IDs() {
+		mg, ok := s.games.get(id)
+		if !ok {
+			continue
+		}
+		var g archivedGame
+		var err error
+		mg.Do(func(eng *game.Engine) { g, err = archiveGame(id, eng, mg.Created) })
+		if err != nil {
+			return err
+		}
+		if err := keep(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveWriter adds files to a tar.gz or zip stream.
+type archiveWriter interface {
+	add(path string, modTime time.Time, data []byte) error
+	Close() error
+}
+
+type tarArchive struct {
+	gz *gzip.Writer
+	tw *tar.Writer
+}
+
+func newTarArchive(w io.Writer) *tarArchive {
+	gz := gzip.NewWriter(w)
+	return &tarArchive{gz: gz, tw: tar.NewWriter(gz)}
+}
+
+func (a *tarArchive) add(path string, modTime time.Time, data []byte) error {
+	hdr := &tar.Header{Name: path, Mode: 0o644, Size: int64(len(data)), ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := a.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := a.tw.Write(data)
+	return err
+}
+
+func (a *tarArchive) Close() error {
+	if err := a.tw.Close(); err != nil {
+		return err
+	}
+	return a.gz.Close()
+}
+
+type zipArchive struct {
+	zw *zip.Writer
+}
+
+func (a *zipArchive) add(path string, modTime time.Time, data []byte) error {
+	f, err := a.zw.CreateHeader(&zip.FileHeader{Name: path, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func (a *zipArchive) Close() error {
+	return a.zw.Close()
+}
+
+// handleExport streams a read-only archive of every game: tar.gz by default,
+// or zip with ?format=zip. ?since=<RFC 3339 time> limits it to games that
+// changed after that time, for incremental backups.
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	applyAPISecurityHeaders(w.Header())
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since: want an RFC 3339 time")
+			return
+		}
+		since = t
+	}
+	format := q.Get("format")
+	switch format {
+	case "":
+		format = "tar.gz"
+	case "tar.gz", "zip":
+	default:
+		writeError(w, http.StatusBadRequest, "invalid format: want tar.gz or zip")
+		return
+	}
+	// Large exports outlast the server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	now := time.Now().UTC()
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="battle_chess-%s.%s"`, now.Format("20060102T150405Z"), format))
+	var archive archiveWriter
+	if format == "zip" {
+		w.Header().Set("Content-Type", "application/zip")
+		archive = &zipArchive{zw: zip.NewWriter(w)}
+	} else {
+		w.Header().Set("Content-Type", "application/gzip")
+		archive = newTarArchive(w)
+	}
+	manifest := archiveManifest{ExportedAt: now, Games: []string{}, Files: []manifestFile{}}
+	if !since.IsZero() {
+		manifest.Since = &since
+	}
+	// Headers are sent with the first file, so a failure past this point can
+	// only cut the stream short; the missing manifest tells the reader.
+	err := s.eachArchivedGame(since, func(g archivedGame) error {
+		manifest.Games = append(manifest.Games, g.id)
+		for _, path := range g.paths {
+			data := g.data[path]
+			if err := archive.add(path, g.updatedAt, data); err != nil {
+				return err
+			}
+			sum := sha256.Sum256(data)
+			manifest.Files = append(manifest.Files, manifestFile{Path: path, Size: len(data), SHA256: hex.EncodeToString(sum[:])})
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := archive.add("MANIFEST.json", now, data); err != nil {
+		return
+	}
+	_ = archive.Close()
+}
@@ -0,0 +1,167 @@
+// path: chessTest/internal/httpx/archive_test.go
+package httpx
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// readArchive returns the files of a tar.gz or zip export by path.
+func readArchive(t *testing.T, format string, body []byte) map[string][]byte {
+	t.Helper()
+	files := make(map[string][]byte)
+	if format == "zip" {
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatalf("open %s: %v", f.Name, err)
+			}
+			files[f.Name], _ = io.ReadAll(rc)
+			rc.Close()
+		}
+		return files
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		files[hdr.Name], _ = io.ReadAll(tr)
+	}
+}
+
+func TestExportArchivesEveryGame(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	if err := srv.engine.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareE4}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	id, err := srv.games.Create(game.DefaultRules(), nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	handler := srv.routes()
+
+	cases := []struct {
+		name      string
+		query     string
+		wantGames []string
+		wantCode  int
+	}{
+		{name: "tar", query: "", wantGames: []string{DefaultGameID, id}, wantCode: http.StatusOK},
+		{name: "zip", query: "?format=zip", wantGames: []string{DefaultGameID, id}, wantCode: http.StatusOK},
+		{name: "nothing since", query: "?since=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339), wantGames: []string{}, wantCode: http.StatusOK},
+		{name: "bad since", query: "?since=yesterday", wantCode: http.StatusBadRequest},
+		{name: "bad format", query: "?format=rar", wantCode: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/export"+tc.query, nil))
+			if rr.Code != tc.wantCode {
+				t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+			}
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			format := "tar.gz"
+			if strings.Contains(tc.query, "zip") {
+				format = "zip"
+			}
+			files := readArchive(t, format, rr.Body.Bytes())
+			var manifest archiveManifest
+			if err := json.Unmarshal(files["MANIFEST.json"], &manifest); err != nil {
+				t.Fatalf("manifest: %v", err)
+			}
+			if strings.Join(manifest.Games, ",") != strings.Join(tc.wantGames, ",") {
+				t.Fatalf("games = %v want %v", manifest.Games, tc.wantGames)
+			}
+			if len(manifest.Files) != 5*len(tc.wantGames) || len(files) != len(manifest.Files)+1 {
+				t.Fatalf("manifest lists %d files, archive holds %d", len(manifest.Files), len(files))
+			}
+			for _, f := range manifest.Files {
+				sum := sha256.Sum256(files[f.Path])
+				if hex.EncodeToString(sum[:]) != f.SHA256 || len(files[f.Path]) != f.Size {
+					t.Fatalf("%s does not match its manifest entry", f.Path)
+				}
+			}
+			if len(tc.wantGames) == 0 {
+				return
+			}
+			var meta archiveMeta
+			if err := json.Unmarshal(files["games/default/meta.json"], &meta); err != nil {
+				t.Fatalf("meta: %v", err)
+			}
+			if meta.EngineVersion != game.EngineVersion || meta.RulesFingerprint != srv.engine.RulesFingerprint() {
+				t.Fatalf("meta provenance = %q %q", meta.EngineVersion, meta.RulesFingerprint)
+			}
+			replay := game.NewEngine()
+			if err := replay.LoadPGNAndContinue(string(files["games/default/game.pgn"])); err != nil {
+				t.Fatalf("exported pgn does not load: %v", err)
+			}
+			if replay.PositionKey() != srv.engine.PositionKey() {
+				t.Fatalf("exported pgn replays to a different position")
+			}
+			var history game.DeltaHistory
+			if err := json.Unmarshal(files["games/default/history.json"], &history); err != nil {
+				t.Fatalf("history: %v", err)
+			}
+			if last, err := history.At(history.Len()); err != nil || last.Ply != srv.engine.Ply() {
+				t.Fatalf("history ends at %+v (%v), game at ply %d", last, err, srv.engine.Ply())
+			}
+		})
+	}
+}
+
+func TestArchiveRecordsAdjudication(t *testing.T) {
+	rules, err := rulesBody{Adjudication: &adjudicationBody{Tablebase: true}}.config()
+	if err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	eng := game.NewEngine(game.WithRules(rules))
+	if err := eng.LoadPlacement("8/7p/4k3/8/3P4/8/8/K7", game.White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if err := eng.Move(game.MoveRequest{From: game.SquareD4, To: game.SquareD5}); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	g, err := archiveGame("cup", eng, time.Time{})
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	var meta archiveMeta
+	if err := json.Unmarshal(g.data["games/cup/meta.json"], &meta); err != nil {
+		t.Fatalf("meta: %v", err)
+	}
+	adj := meta.Adjudication
+	if meta.Status != game.StatusWhiteWins.String() || adj == nil || adj.Kind != "tablebase" || adj.Reason != meta.StatusReason {
+		t.Fatalf("unexpected meta %+v (adjudication %+v)", meta, adj)
+	}
+	if meta.Rules.Adjudication == nil || !meta.Rules.Adjudication.Tablebase {
+		t.Fatalf("rules do not echo tournament mode: %+v", meta.Rules)
+	}
+}
@@ -0,0 +1,423 @@
+// path: chessTest/internal/httpx/auth.go
+package httpx
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Roles understood by the HTTP layer. Admin implies every other role. A
+// player moves only for a seat it holds by heartbeat, or one nobody holds. An
+// advisor may post suggestions to the seat it is attached to but, lacking
+// RolePlayer, cannot move.
+const (
+	RolePlayer  = "player"
+	RoleAdmin   = "admin"
+	RoleAdvisor = "advisor"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Identity is the authenticated caller behind a request.
+type Identity struct {
+	Subject string
+	Roles   []string
+}
+
+// HasRole reports whether the identity may act as role.
+func (id Identity) HasRole(role string) bool {
+	for _, r := range id.Roles {
+		if r == role || r == RoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator validates the credentials carried by a request.
+type Authenticator interface {
+	ValidateToken(r *http.Request) (Identity, error)
+}
+
+type identityKey struct{}
+
+// IdentityFrom returns the identity authorize attached to ctx, if any.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// SetAuthenticator installs auth for the player and admin endpoints. A nil
+// authenticator leaves every endpoint open, which is the default.
+func (s *Server) SetAuthenticator(auth Authenticator) {
+	s.auth = auth
+}
+
+// authorize guards h behind role. Read-only endpoints are not wrapped.
+func (s *Server) authorize(role string, h func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil {
+			h(w, r)
+			return
+		}
+		id, err := s.auth.ValidateToken(r)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="battle_chess"`)
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		if !id.HasRole(role) {
+			writeError(w, http.StatusForbidden, "forbidden")
+			return
+		}
+		h(w, r.WithContext(context.WithValue(r.Context(), identityKey{}, id)))
+	}
+}
+
+func bearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrMissingToken
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// ---- static tokens ----
+
+// StaticTokens authenticates pre-shared bearer tokens, keyed by token.
+type StaticTokens map[string]Identity
+
+func (st StaticTokens) ValidateToken(r *http.Request) (Identity, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return Identity{}, err
+	}
+	// Compare against every entry so timing does not reveal near misses.
+	var match Identity
+	found := false
+	for candidate, id := range st {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			match, found = id, true
+		}
+	}
+	if !found {
+		return Identity{}, ErrInvalidToken
+	}
+	return match, nil
+}
+
+// ParseStaticTokens reads "token:subject:role[|role...]" entries separated by
+// commas, as used by the server's -auth-tokens flag.
+func ParseStaticTokens(spec string) (StaticTokens, error) {
+	out := make(StaticTokens)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid token entry %q", entry)
+		}
+		roles := strings.Split(parts[2], "|")
+		for _, role := range roles {
+			if role != RolePlayer && role != RoleAdmin && role != RoleAdvisor {
+				return nil, fmt.Errorf("invalid role %q in token entry for %s", role, parts[1])
+			}
+		}
+		out[parts[0]] = Identity{Subject: parts[1], Roles: roles}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no tokens configured")
+	}
+	return out, nil
+}
+
+// ---- OIDC bearer tokens ----
+
+// OIDCAuthenticator validates RS256-signed OIDC ID or access tokens. Signing
+// keys come from Keys when set, otherwise from the issuer's discovery
+// document and JWKS, refreshed when an unknown key id shows up.
+type OIDCAuthenticator struct {
+	Issuer   string
+	Audience string
+	// RolesClaim names the claim holding the caller's roles; "roles" when
+	// empty. Tokens without it get RolePlayer.
+	RolesClaim string
+	Keys       map[string]*rsa.PublicKey
+	Client     *http.Client
+	Now        func() time.Time
+
+	// mu guards the key cache only; fetches run outside it, one at a time,
+	// with every caller that needs a refetch waiting on the one in flight.
+	mu       sync.RWMutex
+	fetched  map[string]*rsa.PublicKey
+	lastAt   time.Time
+	inflight *keyFetch
+}
+
+// keyFetch is a JWKS fetch in flight; done closes once keys and err are set.
+type keyFetch struct {
+	done chan struct{}
+	keys map[string]*rsa.PublicKey
+	err  error
+}
+
+// NewOIDCAuthenticator returns an authenticator for tokens minted by issuer
+// for audience.
+func NewOIDCAuthenticator(issuer, audience string) *OIDCAuthenticator {
+	return &OIDCAuthenticator{
+		Issuer:   strings.TrimSuffix(issuer, "/"),
+		Audience: audience,
+		Client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// jwksRefreshInterval rate-limits key refetches triggered by unknown kids;
+// jwksFetchTimeout bounds one refetch.
+const (
+	jwksRefreshInterval = time.Minute
+	jwksFetchTimeout    = 10 * time.Second
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Issuer    string          `json:"iss"`
+	Subject   string          `json:"sub"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+	NotBefore int64           `json:"nbf"`
+}
+
+func (o *OIDCAuthenticator) ValidateToken(r *http.Request) (Identity, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return Identity{}, err
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, ErrInvalidToken
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "RS256" {
+		return Identity{}, ErrInvalidToken
+	}
+	key, err := o.key(r.Context(), header.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	if err := o.checkClaims(claims); err != nil {
+		return Identity{}, err
+	}
+	var raw map[string]json.RawMessage
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	claim := o.RolesClaim
+	if claim == "" {
+		claim = "roles"
+	}
+	roles := []string{RolePlayer}
+	if v, ok := raw[claim]; ok {
+		if err := json.Unmarshal(v, &roles); err != nil {
+			return Identity{}, ErrInvalidToken
+		}
+	}
+	return Identity{Subject: claims.Subject, Roles: roles}, nil
+}
+
+func (o *OIDCAuthenticator) checkClaims(c jwtClaims) error {
+	now := time.Now
+	if o.Now != nil {
+		now = o.Now
+	}
+	at := now().Unix()
+	if c.Issuer != o.Issuer || c.Subject == "" {
+		return ErrInvalidToken
+	}
+	if c.ExpiresAt == 0 || at >= c.ExpiresAt || (c.NotBefore != 0 && at < c.NotBefore) {
+		return ErrInvalidToken
+	}
+	if o.Audience == "" {
+		return nil
+	}
+	var single string
+	if json.Unmarshal(c.Audience, &single) == nil {
+		if single == o.Audience {
+			return nil
+		}
+		return ErrInvalidToken
+	}
+	var many []string
+	if json.Unmarshal(c.Audience, &many) != nil {
+		return ErrInvalidToken
+	}
+	for _, aud := range many {
+		if aud == o.Audience {
+			return nil
+		}
+	}
+	return ErrInvalidToken
+}
+
+func (o *OIDCAuthenticator) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if o.Keys != nil {
+		if key, ok := o.Keys[kid]; ok {
+			return key, nil
+		}
+		return nil, ErrInvalidToken
+	}
+	o.mu.RLock()
+	key, ok := o.fetched[kid]
+	o.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	o.mu.Lock()
+	if key, ok := o.fetched[kid]; ok {
+		o.mu.Unlock()
+		return key, nil
+	}
+	f := o.inflight
+	if f == nil {
+		if !o.lastAt.IsZero() && time.Since(o.lastAt) < jwksRefreshInterval {
+			o.mu.Unlock()
+			return nil, ErrInvalidToken
+		}
+		o.lastAt = time.Now()
+		f = &keyFetch{done: make(chan struct{})}
+		o.inflight = f
+		o.mu.Unlock()
+		o.runFetch(ctx, f)
+	} else {
+		o.mu.Unlock()
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %v", ErrInvalidToken, ctx.Err())
+		}
+	}
+	if f.err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, f.err)
+	}
+	if key, ok := f.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrInvalidToken
+}
+
+// runFetch fetches the issuer's keys for f and installs them in the cache.
+// Other callers wait on the result, so the fetch outlives a cancelled ctx,
+// bounded by jwksFetchTimeout instead.
+func (o *OIDCAuthenticator) runFetch(ctx context.Context, f *keyFetch) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksFetchTimeout)
+	defer cancel()
+	f.keys, f.err = o.fetchKeys(ctx)
+	o.mu.Lock()
+	if f.err == nil {
+		o.fetched = f.keys
+	}
+	o.inflight = nil
+	o.mu.Unlock()
+	close(f.done)
+}
+
+func (o *OIDCAuthenticator) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := o.getJSON(ctx, o.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.JWKSURI == "" {
+		return nil, errors.New("discovery document has no jwks_uri")
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := o.getJSON(ctx, discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+func (o *OIDCAuthenticator) getJSON(ctx context.Context, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	client := o.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxJSONBodyBytes)).Decode(v)
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
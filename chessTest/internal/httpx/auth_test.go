@@ -0,0 +1,229 @@
+// path: chessTest/internal/httpx/auth_test.go
+package httpx
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestAuthorizeStaticTokens(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	srv.SetAuthenticator(StaticTokens{
+		"p-token": {Subject: "alice", Roles: []string{RolePlayer}},
+		"a-token": {Subject: "root", Roles: []string{RoleAdmin}},
+	})
+	handler := srv.routes()
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+	}{
+		{name: "state is public", method: http.MethodGet, path: "/api/state", wantStatus: http.StatusOK},
+		{name: "move without token", method: http.MethodPost, path: "/api/move", wantStatus: http.StatusUnauthorized},
+		{name: "move with bad token", method: http.MethodPost, path: "/api/move", token: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "move as player", method: http.MethodPost, path: "/api/move", token: "p-token", wantStatus: http.StatusBadRequest},
+		{name: "reset as player", method: http.MethodPost, path: "/api/reset", token: "p-token", wantStatus: http.StatusForbidden},
+		{name: "reset as admin", method: http.MethodPost, path: "/api/reset", token: "a-token", wantStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(`{}`))
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestParseStaticTokens(t *testing.T) {
+	tokens, err := ParseStaticTokens("abc:alice:player, def:root:player|admin")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if id := tokens["def"]; id.Subject != "root" || !id.HasRole(RoleAdmin) {
+		t.Fatalf("unexpected identity %+v", id)
+	}
+	for _, bad := range []string{"", "abc:alice", "abc:alice:owner"} {
+		if _, err := ParseStaticTokens(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestOIDCAuthenticatorValidatesTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	defer issuer.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"jwks_uri": issuer.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "k1",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   "AQAB",
+		}}})
+	})
+	auth := NewOIDCAuthenticator(issuer.URL, "battle-chess")
+	auth.Now = func() time.Time { return now }
+
+	valid := map[string]any{"iss": issuer.URL, "sub": "alice", "aud": "battle-chess", "exp": now.Add(time.Hour).Unix()}
+	cases := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		kid     string
+		claims  map[string]any
+		wantErr bool
+		wantSub string
+	}{
+		{name: "valid", key: key, kid: "k1", claims: valid, wantSub: "alice"},
+		{name: "audience list", key: key, kid: "k1", claims: merge(valid, map[string]any{"aud": []string{"x", "battle-chess"}}), wantSub: "alice"},
+		{name: "expired", key: key, kid: "k1", claims: merge(valid, map[string]any{"exp": now.Add(-time.Minute).Unix()}), wantErr: true},
+		{name: "wrong issuer", key: key, kid: "k1", claims: merge(valid, map[string]any{"iss": "https://evil.example"}), wantErr: true},
+		{name: "wrong audience", key: key, kid: "k1", claims: merge(valid, map[string]any{"aud": "other"}), wantErr: true},
+		{name: "bad signature", key: other, kid: "k1", claims: valid, wantErr: true},
+		{name: "unknown kid", key: key, kid: "k2", claims: valid, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/move", nil)
+			req.Header.Set("Authorization", "Bearer "+signJWT(t, tc.key, tc.kid, tc.claims))
+			id, err := auth.ValidateToken(req)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got identity %+v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if id.Subject != tc.wantSub || !id.HasRole(RolePlayer) {
+				t.Fatalf("unexpected identity %+v", id)
+			}
+		})
+	}
+}
+
+func TestOIDCKeyRefetchDoesNotBlockCachedKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	var mu sync.Mutex
+	fetches := 0
+	fetching := make(chan struct{}, 1)
+	release := make(chan struct{})
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	defer issuer.Close()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{"jwks_uri": issuer.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		kids := []string{"k1"}
+		if n > 1 {
+			fetching <- struct{}{}
+			<-release
+			kids = append(kids, "k2")
+		}
+		var keys []map[string]string
+		for _, kid := range kids {
+			keys = append(keys, map[string]string{"kty": "RSA", "kid": kid, "n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()), "e": "AQAB"})
+		}
+		writeJSON(w, map[string]any{"keys": keys})
+	})
+	auth := NewOIDCAuthenticator(issuer.URL, "battle-chess")
+	auth.Now = func() time.Time { return now }
+	claims := map[string]any{"iss": issuer.URL, "sub": "alice", "aud": "battle-chess", "exp": now.Add(time.Hour).Unix()}
+	validate := func(kid string) error {
+		req := httptest.NewRequest(http.MethodPost, "/api/move", nil)
+		req.Header.Set("Authorization", "Bearer "+signJWT(t, key, kid, claims))
+		_, err := auth.ValidateToken(req)
+		return err
+	}
+	if err := validate("k1"); err != nil {
+		t.Fatalf("prime cache: %v", err)
+	}
+	auth.lastAt = time.Time{}
+
+	results := make(chan error, 2)
+	go func() { results <- validate("k2") }()
+	<-fetching
+	go func() { results <- validate("k2") }()
+	if err := validate("k1"); err != nil {
+		t.Fatalf("cached key during refetch: %v", err)
+	}
+	close(release)
+	for i := 0; i < 2; i++ {
+		if err := <-results; err != nil {
+			t.Fatalf("refetched key: %v", err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fetches != 2 {
+		t.Fatalf("jwks fetched %d times, want 2", fetches)
+	}
+}
+
+func merge(base, extra map[string]any) map[string]any {
+	out := make(map[string]any, len(base)+len(extra))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range extra {
+		out[k] = v
+	}
+	return out
+}
+
+func signJWT(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	body, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatalf("marshal claims: %v", err)
+	}
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signing))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
@@ -0,0 +1,189 @@
+// path: chessTest/internal/httpx/chaos.go
+package httpx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChaosConfig describes the faults the chaos middleware injects. It exists to
+// test clients against a slow, lossy server and must not be enabled in
+// production. Rates are probabilities between 0 and 1, drawn per request or,
+// for Drop, per streamed event.
+type ChaosConfig struct {
+	// Latency delays every API request; Jitter adds up to that much more.
+	Latency time.Duration
+	Jitter  time.Duration
+	// Fail answers a request with 500 before the handler runs.
+	Fail float64
+	// Lose runs the handler and then replaces its response with a 500, the
+	// way a dropped connection hides whether a move was applied.
+	Lose float64
+	// Drop discards individual server-sent event frames.
+	Drop float64
+	// Seed makes the injected faults reproducible; zero seeds from the clock.
+	Seed int64
+}
+
+// Enabled reports whether c injects anything.
+func (c ChaosConfig) Enabled() bool {
+	return c.Latency > 0 || c.Jitter > 0 || c.Fail > 0 || c.Lose > 0 || c.Drop > 0
+}
+
+// ParseChaos reads a chaos spec such as
+// "latency=50ms,jitter=20ms,fail=0.05,lose=0.05,drop=0.1,seed=7". An empty
+// spec disables chaos.
+func ParseChaos(spec string) (ChaosConfig, error) {
+	var c ChaosConfig
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return ChaosConfig{}, fmt.Errorf("chaos setting %q: want key=value", part)
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "latency":
+			c.Latency, err = time.ParseDuration(value)
+		case "jitter":
+			c.Jitter, err = time.ParseDuration(value)
+		case "fail":
+			c.Fail, err = parseRate(value)
+		case "lose":
+			c.Lose, err = parseRate(value)
+		case "drop":
+			c.Drop, err = parseRate(value)
+		case "seed":
+			c.Seed, err = strconv.ParseInt(value, 10, 64)
+		default:
+			return ChaosConfig{}, fmt.Errorf("unknown chaos setting %q", key)
+		}
+		if err != nil {
+			return ChaosConfig{}, fmt.Errorf("chaos setting %q: %v", key, err)
+		}
+	}
+	if c.Latency < 0 || c.Jitter < 0 {
+		return ChaosConfig{}, fmt.Errorf("chaos latency and jitter must not be negative")
+	}
+	return c, nil
+}
+
+func parseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %v outside [0, 1]", rate)
+	}
+	return rate, nil
+}
+
+// SetChaos wraps the API in fault injection from cfg. Call it before serving
+// requests; a config that injects nothing removes the middleware.
+func (s *Server) SetChaos(cfg ChaosConfig) {
+	if !cfg.Enabled() {
+		s.chaos = nil
+		return
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s.chaos = &chaos{cfg: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+type chaos struct {
+	cfg ChaosConfig
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (c *chaos) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+func (c *chaos) delay() time.Duration {
+	d := c.cfg.Latency
+	if c.cfg.Jitter > 0 {
+		c.mu.Lock()
+		d += time.Duration(c.rng.Int63n(int64(c.cfg.Jitter) + 1))
+		c.mu.Unlock()
+	}
+	return d
+}
+
+// wrap injects faults into /api/ requests and leaves the UI, static assets
+// and health checks alone.
+func (c *chaos) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if d := c.delay(); d > 0 {
+			select {
+			case <-time.After(d):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if c.roll(c.cfg.Fail) {
+			writeError(w, http.StatusInternalServerError, "chaos: injected failure")
+			return
+		}
+		if c.roll(c.cfg.Lose) {
+			next.ServeHTTP(&lostWriter{header: make(http.Header)}, r)
+			writeError(w, http.StatusInternalServerError, "chaos: response lost")
+			return
+		}
+		if c.cfg.Drop > 0 {
+			w = &dropWriter{ResponseWriter: w, chaos: c}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// lostWriter swallows a response the client will never see.
+type lostWriter struct {
+	header http.Header
+}
+
+func (w *lostWriter) Header() http.Header         { return w.header }
+func (w *lostWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (w *lostWriter) WriteHeader(int)             {}
+
+// dropWriter discards some writes of an event stream. The SSE handler writes
+// each event as one frame, so a dropped write is a missed event and shows up
+// as a gap in the ids the client sees.
+type dropWriter struct {
+	http.ResponseWriter
+	chaos *chaos
+}
+
+func (w *dropWriter) Write(p []byte) (int, error) {
+	if w.Header().Get("Content-Type") == "text/event-stream" && w.chaos.roll(w.chaos.cfg.Drop) {
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer to flush
+// and extend deadlines.
+func (w *dropWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
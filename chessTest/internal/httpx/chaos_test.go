@@ -0,0 +1,182 @@
+// path: chessTest/internal/httpx/chaos_test.go
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func TestParseChaos(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    ChaosConfig
+		wantErr bool
+	}{
+		{spec: "", want: ChaosConfig{}},
+		{spec: "latency=50ms, jitter=10ms,fail=0.1,lose=0.2,drop=1,seed=7", want: ChaosConfig{Latency: 50 * time.Millisecond, Jitter: 10 * time.Millisecond, Fail: 0.1, Lose: 0.2, Drop: 1, Seed: 7}},
+		{spec: "fail=1.5", wantErr: true},
+		{spec: "latency=-1s", wantErr: true},
+		{spec: "latency", wantErr: true},
+		{spec: "explode=1", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseChaos(tc.spec)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err = %v", tc.spec, err)
+		}
+		if !tc.wantErr && got != tc.want {
+			t.Fatalf("%q: got %+v want %+v", tc.spec, got, tc.want)
+		}
+	}
+}
+
+func newChaosServer(t *testing.T, cfg ChaosConfig) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	b := bus.New()
+	srv.SetBus(b)
+	srv.SetChaos(cfg)
+	ts := httptest.NewServer(srv.routes())
+	t.Cleanup(func() {
+		ts.Close()
+		b.Close()
+	})
+	return srv, ts
+}
+
+// chaosMoves are legal in order from the initial position and include
+// captures, so a duplicated or skipped move changes the final board.
+var chaosMoves = [][2]string{{"e2", "e4"}, {"d7", "d5"}, {"e4", "d5"}, {"e7", "e5"}, {"d5", "d6"}, {"c7", "d6"}, {"a2", "a3"}, {"h7", "h6"}}
+
+// playWithRetries submits each move with the sequence number it expects to
+// extend, retrying until the server answers. A conflict whose seq has moved
+// on by one means an earlier attempt was applied and only its response lost.
+func playWithRetries(t *testing.T, url string) (attempts int) {
+	t.Helper()
+	var seq uint64
+	for _, mv := range chaosMoves {
+		for {
+			attempts++
+			if attempts > 200 {
+				t.Fatalf("gave up after %d attempts", attempts)
+			}
+			body := fmt.Sprintf(`{"from":%q,"to":%q,"seq":%d}`, mv[0], mv[1], seq)
+			resp, err := http.Post(url+"/api/move", "application/json", strings.NewReader(body))
+			if err != nil {
+				t.Fatalf("post: %v", err)
+			}
+			var out struct {
+				Seq   uint64               `json:"seq"`
+				State struct{ Seq uint64 } `json:"state"`
+			}
+			decodeErr := json.NewDecoder(resp.Body).Decode(&out)
+			resp.Body.Close()
+			switch resp.StatusCode {
+			case http.StatusOK:
+				if decodeErr != nil || out.State.Seq != seq+1 {
+					t.Fatalf("move %v: seq %d after %d (%v)", mv, out.State.Seq, seq, decodeErr)
+				}
+			case http.StatusConflict:
+				if out.Seq != seq+1 {
+					t.Fatalf("move %v: conflict at seq %d, expected %d", mv, out.Seq, seq+1)
+				}
+			case http.StatusInternalServerError:
+				continue
+			default:
+				t.Fatalf("move %v: status %d", mv, resp.StatusCode)
+			}
+			seq++
+			break
+		}
+	}
+	return attempts
+}
+
+func TestMovesApplyOnceUnderChaos(t *testing.T) {
+	clean, cleanTS := newChaosServer(t, ChaosConfig{})
+	playWithRetries(t, cleanTS.URL)
+
+	srv, ts := newChaosServer(t, ChaosConfig{Jitter: 2 * time.Millisecond, Fail: 0.3, Lose: 0.3, Seed: 1})
+	if attempts := playWithRetries(t, ts.URL); attempts == len(chaosMoves) {
+		t.Fatalf("no faults injected in %d attempts", attempts)
+	}
+
+	if got, want := srv.engine.State().Seq, clean.engine.State().Seq; got != want {
+		t.Fatalf("seq = %d want %d", got, want)
+	}
+	if got, want := srv.engine.PositionKey(), clean.engine.PositionKey(); got != want {
+		t.Fatalf("chaotic game diverged from the clean one")
+	}
+	if got := len(srv.engine.MoveLog()); got != len(chaosMoves) {
+		t.Fatalf("move log has %d entries, want %d", got, len(chaosMoves))
+	}
+}
+
+func TestEventStreamGapsUnderChaos(t *testing.T) {
+	srv, ts := newChaosServer(t, ChaosConfig{Drop: 0.5, Seed: 3})
+	resp, err := http.Get(ts.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	ids := make(chan uint64)
+	go func() {
+		defer close(ids)
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			if v, ok := strings.CutPrefix(sc.Text(), "id: "); ok {
+				id, _ := strconv.ParseUint(v, 10, 64)
+				ids <- id
+			}
+		}
+	}()
+
+	playWithRetries(t, ts.URL)
+	var seen []uint64
+	for idle := false; !idle; {
+		select {
+		case id, ok := <-ids:
+			if !ok {
+				idle = true
+				break
+			}
+			seen = append(seen, id)
+		case <-time.After(300 * time.Millisecond):
+			idle = true
+		}
+	}
+
+	total := srv.engine.EventSeq()
+	if len(seen) == 0 || uint64(len(seen)) >= total {
+		t.Fatalf("saw %d of %d events, want some but not all", len(seen), total)
+	}
+	// Ids still only increase, so the missing ones show up as gaps.
+	for i := 1; i < len(seen); i++ {
+		if seen[i] <= seen[i-1] {
+			t.Fatalf("ids out of order: %v", seen)
+		}
+	}
+
+	// A client that sees a gap refetches the state, which chaos leaves intact.
+	state, err := http.Get(ts.URL + "/api/state")
+	if err != nil || state.StatusCode != http.StatusOK {
+		t.Fatalf("state: %v %v", err, state)
+	}
+	defer state.Body.Close()
+	var got struct {
+		State struct{ Seq uint64 } `json:"state"`
+	}
+	if err := json.NewDecoder(state.Body).Decode(&got); err != nil || got.State.Seq != uint64(len(chaosMoves)) {
+		t.Fatalf("state seq = %d (%v)", got.State.Seq, err)
+	}
+}
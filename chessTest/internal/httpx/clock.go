@@ -0,0 +1,101 @@
+// path: chessTest/internal/httpx/clock.go
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"battle_chess_poc/internal/game"
+)
+
+// /api/clock/sync gives clients what they need to run a smooth local clock
+// display between moves and across reconnects:
+//
+//	GET /api/clock/sync?game=<id>&t0=<client ms>
+//	{"game": "...", "seq": 42, "moveSeq": 7, "serverTimeMs": ..., "t0": ...,
+//	 "timed": true, "running": "black", "clocks": {"white": 52000, "black": 56000}}
+//
+// clocks are authoritative as of serverTimeMs; running names the side whose
+// clock is counting down, and is absent when the game is untimed or over.
+// seq increases with every sync the server answers, so a client that polls
+// concurrently applies a response only when its seq is above the last one it
+// applied. moveSeq is the game's move sequence.
+//
+// Drift correction, on receiving a response at local time t1:
+//
+//  1. rtt = t1 - t0 and offset = serverTimeMs + rtt/2 - t1, the server clock
+//     minus the local one. Keep the offset of the lowest-rtt sample among
+//     the last few; it is the least skewed by network delay.
+//  2. The running side's clock shows clocks[running] - (now + offset -
+//     serverTimeMs); the other side's shows its clocks value.
+//  3. When moveSeq has changed, or the shown value is more than a second off
+//     the corrected one, snap to the corrected one: a move was played or the
+//     client was away. Otherwise slew toward it over the next second rather
+//     than jumping, and never show a running clock going up.
+//
+// The server never trusts the client's clock: flags fall by the engine's
+// time, whatever the display says.
+
+type clockSyncView struct {
+	Game         string           `json:"game"`
+	Seq          uint64           `json:"seq"`
+	MoveSeq      uint64           `json:"moveSeq"`
+	ServerTimeMs int64            `json:"serverTimeMs"`
+	T0           int64            `json:"t0,omitempty"`
+	Timed        bool             `json:"timed"`
+	Running      string           `json:"running,omitempty"`
+	Clocks       map[string]int64 `json:"clocks,omitempty"`
+}
+
+func (s *Server) handleClockSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	var t0 int64
+	if v := q.Get("t0"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid t0")
+			return
+		}
+		t0 = n
+	}
+	id := q.Get("game")
+	if id == "" {
+		id = DefaultGameID
+	}
+	mu, eng, ok := s.lookupGame(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	mu.Lock()
+	reading := eng.ReadClocks()
+	timed := eng.Rules().TimeControl.Timed()
+	moveSeq := eng.Seq()
+	// Numbered under the game lock, so a later seq never carries an older
+	// reading of the same game.
+	seq := s.clockSeq.Add(1)
+	mu.Unlock()
+
+	out := clockSyncView{
+		Game:         id,
+		Seq:          seq,
+		MoveSeq:      moveSeq,
+		ServerTimeMs: reading.At.UnixMilli(),
+		T0:           t0,
+		Timed:        timed,
+	}
+	if timed {
+		out.Clocks = map[string]int64{
+			game.White.String(): reading.Remaining[game.White.Index()].Milliseconds(),
+			game.Black.String(): reading.Remaining[game.Black.Index()].Milliseconds(),
+		}
+	}
+	if reading.Running {
+		out.Running = reading.Turn.String()
+	}
+	writeJSON(w, out)
+}
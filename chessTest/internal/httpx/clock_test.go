@@ -0,0 +1,72 @@
+// path: chessTest/internal/httpx/clock_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestClockSync(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{"alice": {Subject: "alice", Roles: []string{RolePlayer}}})
+	handler := srv.routes()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer alice")
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	sync := func(query string) clockSyncView {
+		t.Helper()
+		rr := do(http.MethodGet, "/api/clock/sync"+query, "")
+		var out clockSyncView
+		if rr.Code != http.StatusOK || json.Unmarshal(rr.Body.Bytes(), &out) != nil {
+			t.Fatalf("sync%s: status %d body %s", query, rr.Code, rr.Body.String())
+		}
+		return out
+	}
+
+	if got := sync(""); got.Game != DefaultGameID || got.Timed || got.Running != "" || got.Clocks != nil || got.Seq != 1 {
+		t.Fatalf("untimed default game: %+v", got)
+	}
+	rr := do(http.MethodPost, "/api/games", `{"preset":"blitz"}`)
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rr.Code, rr.Body.String())
+	}
+	first := sync("?game=" + created.ID + "&t0=1700000000000")
+	if !first.Timed || first.Running != "white" || first.T0 != 1700000000000 || first.MoveSeq != 0 || first.ServerTimeMs == 0 {
+		t.Fatalf("blitz sync: %+v", first)
+	}
+	if white := first.Clocks["white"]; white <= 0 || white > 180000 || first.Clocks["black"] != 180000 {
+		t.Fatalf("blitz clocks: %v", first.Clocks)
+	}
+	if rr := do(http.MethodPost, "/api/games/"+created.ID+"/move", `{"from":"e2","to":"e4"}`); rr.Code != http.StatusOK {
+		t.Fatalf("move: status %d body %s", rr.Code, rr.Body.String())
+	}
+	second := sync("?game=" + created.ID)
+	if second.Seq <= first.Seq || second.MoveSeq != 1 || second.Running != "black" || second.ServerTimeMs < first.ServerTimeMs {
+		t.Fatalf("after move: %+v (before %+v)", second, first)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{query: "?game=nope", want: http.StatusNotFound},
+		{query: "?t0=soon", want: http.StatusBadRequest},
+	} {
+		if rr := do(http.MethodGet, "/api/clock/sync"+tc.query, ""); rr.Code != tc.want {
+			t.Fatalf("%s: status %d, want %d", tc.query, rr.Code, tc.want)
+		}
+	}
+	if rr := do(http.MethodPost, "/api/clock/sync", ""); rr.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST: status %d", rr.Code)
+	}
+}
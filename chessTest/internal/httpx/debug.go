@@ -0,0 +1,61 @@
+// path: chessTest/internal/httpx/debug.go
+package httpx
+
+import (
+	"net/http"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+)
+
+type resolutionStepView struct {
+	Phase    string `json:"phase"`
+	Priority int    `json:"priority"`
+	Ability  string `json:"ability"`
+	Owner    string `json:"owner"`
+}
+
+// handleResolutionOrder reports the order the resolver runs handlers in for
+// the loadouts in ?white= and ?black= (comma-separated ability names) when
+// ?mover= (default white) moves.
+func (s *Server) handleResolutionOrder(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	q := r.URL.Query()
+	var loadouts [2]game.AbilityList
+	for i, side := range [...]string{"white", "black"} {
+		list, err := parseAbilities(splitList(q.Get(side)))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, side+": "+err.Error())
+			return
+		}
+		loadouts[i] = list
+	}
+	mover := game.White
+	if m := q.Get("mover"); m != "" {
+		var ok bool
+		if mover, ok = game.ParseColor(m); !ok {
+			writeError(w, http.StatusBadRequest, "invalid mover")
+			return
+		}
+	}
+	steps := game.ResolutionOrder(mover, loadouts[mover.Index()], loadouts[mover.Opposite().Index()])
+	out := make([]resolutionStepView, 0, len(steps))
+	for _, st := range steps {
+		out = append(out, resolutionStepView{Phase: st.Phase, Priority: st.Priority, Ability: st.Ability.String(), Owner: st.Owner.String()})
+	}
+	writeJSON(w, map[string]any{"mover": mover.String(), "order": out})
+}
+
+// splitList splits a comma-separated query value, dropping empty items.
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+// path: chessTest/internal/httpx/debug_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestHandleResolutionOrder(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	cases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       []string
+	}{
+		{name: "phases then priority", query: "white=Raijin,DoOver,Scorch&black=BlazeRush", wantStatus: http.StatusOK, want: []string{"elemental/1/Scorch/white", "offense/1/BlazeRush/black", "offense/3/Raijin/white", "temporal/1/DoOver/white"}},
+		{name: "light speed runs its side first", query: "white=Raijin,LightSpeed&black=BlazeRush", wantStatus: http.StatusOK, want: []string{"offense/0/LightSpeed/white", "offense/0/Raijin/white", "offense/1/BlazeRush/black"}},
+		{name: "black moves", query: "white=BlazeRush&black=BlazeRush&mover=black", wantStatus: http.StatusOK, want: []string{"offense/1/BlazeRush/black", "offense/1/BlazeRush/white"}},
+		{name: "empty", wantStatus: http.StatusOK, want: []string{}},
+		{name: "unknown ability", query: "white=Teleport", wantStatus: http.StatusBadRequest},
+		{name: "bad mover", query: "mover=red", wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/debug/resolution-order?"+tc.query, nil))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.want == nil {
+				return
+			}
+			var body struct {
+				Order []resolutionStepView `json:"order"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			got := []string{}
+			for _, st := range body.Order {
+				got = append(got, fmt.Sprintf("%s/%d/%s/%s", st.Phase, st.Priority, st.Ability, st.Owner))
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("order = %v want %v", got, tc.want)
+			}
+		})
+	}
+}
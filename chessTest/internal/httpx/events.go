@@ -0,0 +1,166 @@
+// path: chessTest/internal/httpx/events.go
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+// eventStreamBuffer is how many events an SSE client may fall behind by
+// before it starts missing them.
+const eventStreamBuffer = 64
+
+// SetBus publishes the events of the default game and every managed game to b
+// and starts the metrics subscriber. Call it before serving requests.
+func (s *Server) SetBus(b *bus.Bus) {
+	s.engineMu.Lock()
+	s.engine.SetEventSink(b.Sink(DefaultGameID))
+	s.engineMu.Unlock()
+	s.games.ctl.SetBus(b)
+	s.bus = b
+	s.metrics = newEventMetrics()
+	go s.metrics.run(b.Subscribe("", 256))
+}
+
+// handleEvents streams a game's events as server-sent events: the default
+// game, or the managed game named by ?game=. Each event's id is its Seq, so
+// a gap tells the client it fell behind and should refetch the state.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	applyAPISecurityHeaders(w.Header())
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.bus == nil {
+		writeError(w, http.StatusServiceUnavailable, "event stream not available")
+		return
+	}
+	id := r.URL.Query().Get("game")
+	if id == "" {
+		id = DefaultGameID
+	} else if _, ok := s.games.get(id); !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	sub := s.bus.Subscribe(id, eventStreamBuffer)
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if msg.Seat != nil {
+				if err := writeSeatEvent(w, msg.Seat); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+				continue
+			}
+			views := moveEventViews([]game.Event{msg.Event})
+			data, err := json.Marshal(views[0])
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.Event.Seq, views[0].Kind, data); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeSeatEvent sends a seat status change as a "seat" event. It has no id,
+// so it never looks like a gap in the game's Seq numbering.
+func writeSeatEvent(w io.Writer, st *bus.SeatStatus) error {
+	data, err := json.Marshal(seatView{Color: st.Color.String(), Status: st.Status, LastSeen: st.LastSeen})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: seat\ndata: %s\n\n", data)
+	return err
+}
+
+// eventMetrics counts published events by kind.
+type eventMetrics struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+	sub    *bus.Subscription
+}
+
+func newEventMetrics() *eventMetrics {
+	return &eventMetrics{counts: make(map[string]uint64)}
+}
+
+func (m *eventMetrics) run(sub *bus.Subscription) {
+	m.mu.Lock()
+	m.sub = sub
+	m.mu.Unlock()
+	for msg := range sub.C {
+		if msg.Seat != nil {
+			continue
+		}
+		m.mu.Lock()
+		m.counts[msg.Event.Kind.String()]++
+		m.mu.Unlock()
+	}
+}
+
+type metricsView struct {
+	Events  map[string]uint64 `json:"events"`
+	Dropped uint64            `json:"dropped"`
+	// Handlers maps an ability to its handler timings; see
+	// SetHandlerMetrics.
+	Handlers map[string]handlerStatView `json:"handlers,omitempty"`
+}
+
+func (m *eventMetrics) view() metricsView {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := metricsView{Events: make(map[string]uint64, len(m.counts))}
+	for kind, n := range m.counts {
+		out.Events[kind] = n
+	}
+	if m.sub != nil {
+		out.Dropped = m.sub.Dropped()
+	}
+	return out
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if s.metrics == nil {
+		writeError(w, http.StatusServiceUnavailable, "metrics not available")
+		return
+	}
+	out := s.metrics.view()
+	if s.handlerStats != nil {
+		out.Handlers = s.handlerStats.view()
+	}
+	writeJSON(w, out)
+}
@@ -0,0 +1,77 @@
+// path: chessTest/internal/httpx/events_test.go
+package httpx
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func TestEventStreamAndMetricsSubscribe(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	b := bus.New()
+	srv.SetBus(b)
+	ts := httptest.NewServer(srv.routes())
+	defer ts.Close()
+	defer b.Close()
+
+	resp, err := http.Get(ts.URL + "/api/events")
+	if err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %q", ct)
+	}
+
+	move, err := http.Post(ts.URL+"/api/move", "application/json", strings.NewReader(`{"from":"e2","to":"e4"}`))
+	if err != nil || move.StatusCode != http.StatusOK {
+		t.Fatalf("move: %v %v", err, move)
+	}
+	move.Body.Close()
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+	want := []string{"id: 1", "event: move", `data: {"seq":1,"kind":"move","color":"white"`}
+	for _, prefix := range want {
+		select {
+		case line := <-lines:
+			if !strings.HasPrefix(line, prefix) {
+				t.Fatalf("line %q want prefix %q", line, prefix)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", prefix)
+		}
+	}
+
+	var metrics metricsView
+	for deadline := time.Now().Add(2 * time.Second); metrics.Events["move"] == 0 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		rr := httptest.NewRecorder()
+		srv.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/metrics", nil))
+		if err := json.Unmarshal(rr.Body.Bytes(), &metrics); err != nil {
+			t.Fatalf("metrics: %v", err)
+		}
+	}
+	if metrics.Events["move"] != 1 {
+		t.Fatalf("metrics = %+v", metrics)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/events?game=nope", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown game status = %d", rr.Code)
+	}
+}
@@ -0,0 +1,78 @@
+// path: chessTest/internal/httpx/flags.go
+package httpx
+
+import (
+	"net/http"
+
+	"battle_chess_poc/internal/flags"
+	"battle_chess_poc/internal/game"
+)
+
+// SetFlags replaces the feature flags applied to games created through
+// /api/games. Call it before serving requests.
+func (s *Server) SetFlags(set *flags.Set) {
+	s.games.flags = set
+}
+
+type flagView struct {
+	Ability string   `json:"ability"`
+	Enabled bool     `json:"enabled"`
+	Rollout int      `json:"rollout"`
+	Games   []string `json:"games,omitempty"`
+	OptIn   bool     `json:"optIn"`
+}
+
+func viewFlag(f flags.Flag) flagView {
+	return flagView{Ability: f.Ability.String(), Enabled: f.Enabled, Rollout: f.Rollout, Games: f.Games, OptIn: f.OptIn}
+}
+
+// handleFlags lists the flags on GET and adds or replaces one on POST. Changes
+// apply to games created afterwards.
+func (s *Server) handleFlags(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		list := s.games.flags.List()
+		out := make([]flagView, 0, len(list))
+		for _, f := range list {
+			out = append(out, viewFlag(f))
+		}
+		writeJSON(w, map[string]any{"flags": out})
+	case http.MethodPost:
+		defer r.Body.Close()
+		var body flagView
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		ability, ok := game.ParseAbility(body.Ability)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid ability")
+			return
+		}
+		f := flags.Flag{Ability: ability, Enabled: body.Enabled, Rollout: body.Rollout, Games: body.Games, OptIn: body.OptIn}
+		if err := s.games.flags.Put(f); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, map[string]any{"flag": viewFlag(f)})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (s *Server) handleDeleteFlag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	ability, ok := game.ParseAbility(r.PathValue("ability"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid ability")
+		return
+	}
+	if !s.games.flags.Delete(ability) {
+		writeError(w, http.StatusNotFound, "flag not found")
+		return
+	}
+	writeJSON(w, map[string]any{"deleted": ability.String()})
+}
@@ -0,0 +1,71 @@
+// path: chessTest/internal/httpx/flags_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestFeatureFlagsGateNewGames(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+
+	if rr := do(http.MethodPost, "/api/flags", `{"ability":"ScatterShot","optIn":true}`); rr.Code != http.StatusOK {
+		t.Fatalf("post flag status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodPost, "/api/flags", `{"ability":"ScatterShot","rollout":101}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("bad rollout status = %d", rr.Code)
+	}
+	var listed struct {
+		Flags []flagView `json:"flags"`
+	}
+	if err := json.Unmarshal(do(http.MethodGet, "/api/flags", "").Body.Bytes(), &listed); err != nil || len(listed.Flags) != 1 || !listed.Flags[0].OptIn {
+		t.Fatalf("unexpected flag list %+v (%v)", listed, err)
+	}
+
+	cases := []struct {
+		name       string
+		create     string
+		wantStatus int
+	}{
+		{name: "dark by default", create: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "opted in", create: `{"features":["ScatterShot"]}`, wantStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := do(http.MethodPost, "/api/games", tc.create)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("create status = %d: %s", rr.Code, rr.Body.String())
+			}
+			var created gameResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+				t.Fatalf("decode create: %v", err)
+			}
+			if dark := len(created.DisabledAbilities) == 1; dark != (tc.wantStatus != http.StatusOK) {
+				t.Fatalf("disabled abilities = %v", created.DisabledAbilities)
+			}
+			rr = do(http.MethodPost, "/api/games/"+created.ID+"/config", `{"color":"white","abilities":["ScatterShot"],"element":"fire"}`)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("config status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+		})
+	}
+
+	if rr := do(http.MethodDelete, "/api/flags/ScatterShot", ""); rr.Code != http.StatusOK {
+		t.Fatalf("delete status = %d", rr.Code)
+	}
+	if rr := do(http.MethodDelete, "/api/flags/ScatterShot", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("second delete status = %d", rr.Code)
+	}
+}
@@ -0,0 +1,484 @@
+// path: chessTest/internal/httpx/games.go
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/controller"
+	"battle_chess_poc/internal/flags"
+	"battle_chess_poc/internal/game"
+)
+
+var errRulesOutOfRange = errors.New("rules out of range")
+
+// RulesLimits bounds what a create-game request may ask for.
+type RulesLimits struct {
+	Variants          []string
+	MaxHandlerTimeout time.Duration
+	MinInitial        time.Duration
+	MaxInitial        time.Duration
+	MaxIncrement      time.Duration
+	MaxCharges        int
+	AllowBans         bool
+}
+
+// DefaultRulesLimits are the server-side limits used by NewServer.
+func DefaultRulesLimits() RulesLimits {
+	return RulesLimits{
+		Variants:          []string{game.VariantBattle, game.VariantStandard},
+		MaxHandlerTimeout: time.Second,
+		MinInitial:        10 * time.Second,
+		MaxInitial:        3 * time.Hour,
+		MaxIncrement:      time.Minute,
+		MaxCharges:        20,
+		AllowBans:         true,
+	}
+}
+
+// GameManager owns the games created through /api/games: their engines,
+// journals and events live in a controller.Controller, and the manager adds
+// the server's policy around it. The server's original engine stays the
+// default game behind the unscoped endpoints.
+type GameManager struct {
+	mu     sync.Mutex
+	ctl    *controller.Controller
+	limits RulesLimits
+	flags  *flags.Set
+	// advisors holds the bots attached to seats; see advisors.go.
+	advisors map[seatKey]*advisorSeat
+	// hintQuotas and hints ration advisor suggestions; see hints.go.
+	hintQuotas map[string]HintQuota
+	hints      map[seatKey]*hintUsage
+	// gameHints overrides hintQuotas for games created from a preset, and
+	// gamePresets names that preset.
+	gameHints   map[string]HintQuota
+	gamePresets map[string]string
+	// presets are offered at game creation; nil means DefaultPresets.
+	presets []Preset
+	// sparring holds the scripted opponents seated by tests and UI
+	// developers; see sparring.go.
+	sparring map[seatKey]*sparringSeat
+}
+
+func NewGameManager(limits RulesLimits) *GameManager {
+	set, _ := flags.NewSet()
+	return &GameManager{ctl: controller.New(), limits: limits, flags: set}
+}
+
+// Create starts a game with rules after checking them against the limits.
+// Feature flags are evaluated once, here: abilities they hold back from the
+// new game, allowing for the features it opts into, become its
+// DisabledAbilities.
+func (m *GameManager) Create(rules game.RulesConfig, optIn []game.Ability) (string, error) {
+	if err := m.limits.check(rules); err != nil {
+		return "", err
+	}
+	g, err := m.ctl.CreateFor(func(id string) game.RulesConfig {
+		rules.DisabledAbilities = m.flags.Disabled(id, optIn)
+		return rules
+	})
+	if err != nil {
+		return "", err
+	}
+	return g.ID, nil
+}
+
+func (m *GameManager) get(id string) (*controller.Game, bool) {
+	return m.ctl.Get(id)
+}
+
+func (l RulesLimits) check(r game.RulesConfig) error {
+	variant := r.Variant
+	if variant == "" {
+		variant = game.VariantBattle
+	}
+	allowed := false
+	for _, v := range l.Variants {
+		allowed = allowed || v == variant
+	}
+	switch {
+	case !allowed:
+		return fmt.Errorf("%w: variant %q not offered", errRulesOutOfRange, variant)
+	case r.HandlerTimeout > l.MaxHandlerTimeout:
+		return fmt.Errorf("%w: handler timeout above %v", errRulesOutOfRange, l.MaxHandlerTimeout)
+	}
+	for _, c := range []game.Color{game.White, game.Black} {
+		initial, increment := r.TimeControl.For(c)
+		switch {
+		case r.TimeControl.Timed() && (initial < l.MinInitial || initial > l.MaxInitial):
+			return fmt.Errorf("%w: initial time must be between %v and %v", errRulesOutOfRange, l.MinInitial, l.MaxInitial)
+		case increment > l.MaxIncrement:
+			return fmt.Errorf("%w: increment above %v", errRulesOutOfRange, l.MaxIncrement)
+		}
+	}
+	switch {
+	case r.Charges.Max > l.MaxCharges:
+		return fmt.Errorf("%w: charge cap above %d", errRulesOutOfRange, l.MaxCharges)
+	case len(r.BannedAbilities) > 0 && !l.AllowBans:
+		return fmt.Errorf("%w: ability bans are disabled", errRulesOutOfRange)
+	}
+	return nil
+}
+
+// ---- wire format ----
+
+type timeControlBody struct {
+	InitialMs   int64 `json:"initialMs"`
+	IncrementMs int64 `json:"incrementMs"`
+	// Black gives Black its own clock, for time odds; initialMs and
+	// incrementMs are then White's.
+	Black *sideClockBody `json:"black,omitempty"`
+}
+
+type sideClockBody struct {
+	InitialMs   int64 `json:"initialMs"`
+	IncrementMs int64 `json:"incrementMs"`
+}
+
+func (b timeControlBody) control() game.TimeControl {
+	tc := game.TimeControl{
+		Initial:   time.Duration(b.InitialMs) * time.Millisecond,
+		Increment: time.Duration(b.IncrementMs) * time.Millisecond,
+	}
+	if b.Black != nil {
+		tc.Odds = true
+		tc.BlackInitial = time.Duration(b.Black.InitialMs) * time.Millisecond
+		tc.BlackIncrement = time.Duration(b.Black.IncrementMs) * time.Millisecond
+	}
+	return tc
+}
+
+// timeControlView echoes tc, or nil when the game is untimed.
+func timeControlView(tc game.TimeControl) *timeControlBody {
+	if !tc.Timed() {
+		return nil
+	}
+	out := &timeControlBody{
+		InitialMs:   tc.Initial.Milliseconds(),
+		IncrementMs: tc.Increment.Milliseconds(),
+	}
+	if tc.Odds {
+		out.Black = &sideClockBody{InitialMs: tc.BlackInitial.Milliseconds(), IncrementMs: tc.BlackIncrement.Milliseconds()}
+	}
+	return out
+}
+
+type chargesBody struct {
+	Enabled bool `json:"enabled"`
+	Start   int  `json:"start"`
+	PerTurn int  `json:"perTurn"`
+	Max     int  `json:"max"`
+}
+
+type budgetsBody struct {
+	HandlerTimeoutMs int64        `json:"handlerTimeoutMs"`
+	Charges          *chargesBody `json:"charges,omitempty"`
+}
+
+// adjudicationBody ends decided games early; see game.AdjudicationRules.
+type adjudicationBody struct {
+	ThresholdCp int  `json:"thresholdCp,omitempty"`
+	Plies       int  `json:"plies,omitempty"`
+	Tablebase   bool `json:"tablebase,omitempty"`
+}
+
+// rulesBody is the RulesConfig subset clients may choose, and the echo of the
+// live rules in game responses.
+type rulesBody struct {
+	Variant         string           `json:"variant"`
+	TimeControl     *timeControlBody `json:"timeControl,omitempty"`
+	BannedAbilities []string         `json:"bannedAbilities,omitempty"`
+	// AbilityEligibility maps an ability to the piece types that may use it.
+	AbilityEligibility map[string][]string `json:"abilityEligibility,omitempty"`
+	Budgets            *budgetsBody        `json:"budgets,omitempty"`
+	// Strict turns silent defaults, such as an unknown dir meaning auto,
+	// into errors.
+	Strict bool `json:"strict,omitempty"`
+	// Competitive hides engine assessments, such as the draw assessment,
+	// from players.
+	Competitive bool `json:"competitive,omitempty"`
+	// Adjudication is tournament mode: the engine ends games whose result is
+	// no longer in doubt.
+	Adjudication *adjudicationBody `json:"adjudication,omitempty"`
+}
+
+func (b rulesBody) config() (game.RulesConfig, error) {
+	rules := game.DefaultRules()
+	rules.Variant = b.Variant
+	rules.Strict = b.Strict
+	rules.Competitive = b.Competitive
+	if tc := b.TimeControl; tc != nil {
+		rules.TimeControl = tc.control()
+	}
+	if len(b.BannedAbilities) > 0 {
+		banned, err := parseAbilities(b.BannedAbilities)
+		if err != nil {
+			return rules, err
+		}
+		rules.BannedAbilities = banned
+	}
+	if len(b.AbilityEligibility) > 0 {
+		eligibility, err := parseEligibility(b.AbilityEligibility)
+		if err != nil {
+			return rules, err
+		}
+		rules.AbilityEligibility = eligibility
+	}
+	if bud := b.Budgets; bud != nil {
+		rules.HandlerTimeout = time.Duration(bud.HandlerTimeoutMs) * time.Millisecond
+		if c := bud.Charges; c != nil {
+			rules.Charges.Enabled = c.Enabled
+			rules.Charges.Start = c.Start
+			rules.Charges.PerTurn = c.PerTurn
+			rules.Charges.Max = c.Max
+		}
+	}
+	if adj := b.Adjudication; adj != nil {
+		rules.Adjudication = game.AdjudicationRules{Threshold: adj.ThresholdCp, Plies: adj.Plies, Tablebase: adj.Tablebase}
+	}
+	return rules, nil
+}
+
+func rulesView(r game.RulesConfig) rulesBody {
+	out := rulesBody{
+		Variant:         r.Variant,
+		BannedAbilities: r.BannedAbilities.Strings(),
+		Strict:          r.Strict,
+		Competitive:     r.Competitive,
+		Budgets: &budgetsBody{
+			HandlerTimeoutMs: r.HandlerTimeout.Milliseconds(),
+			Charges: &chargesBody{
+				Enabled: r.Charges.Enabled,
+				Start:   r.Charges.Start,
+				PerTurn: r.Charges.PerTurn,
+				Max:     r.Charges.Max,
+			},
+		},
+	}
+	if out.Variant == "" {
+		out.Variant = game.VariantBattle
+	}
+	out.TimeControl = timeControlView(r.TimeControl)
+	if len(r.AbilityEligibility) > 0 {
+		out.AbilityEligibility = eligibilityView(r.AbilityEligibility)
+	}
+	if adj := r.Adjudication; adj.Enabled() {
+		out.Adjudication = &adjudicationBody{ThresholdCp: adj.Threshold, Plies: adj.Plies, Tablebase: adj.Tablebase}
+	}
+	return out
+}
+
+func parseEligibility(in map[string][]string) (map[game.Ability]game.PieceTypeSet, error) {
+	out := make(map[game.Ability]game.PieceTypeSet, len(in))
+	for name, types := range in {
+		ability, ok := game.ParseAbility(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid ability %q", name)
+		}
+		var set game.PieceTypeSet
+		for _, t := range types {
+			pt, ok := game.ParsePieceType(t)
+			if !ok {
+				return nil, fmt.Errorf("invalid piece type %q", t)
+			}
+			set |= game.NewPieceTypeSet(pt)
+		}
+		out[ability] = set
+	}
+	return out, nil
+}
+
+func eligibilityView(in map[game.Ability]game.PieceTypeSet) map[string][]string {
+	out := make(map[string][]string, len(in))
+	for ability, set := range in {
+		names := []string{}
+		for _, t := range set.Types() {
+			names = append(names, t.String())
+		}
+		out[ability.String()] = names
+	}
+	return out
+}
+
+type gameResponse struct {
+	ID    string    `json:"id"`
+	Rules rulesBody `json:"rules"`
+	// DisabledAbilities are held back from this game by feature flags.
+	DisabledAbilities []string `json:"disabledAbilities,omitempty"`
+	Preset            string   `json:"preset,omitempty"`
+	// State is the game's BoardState, or its BoardSummary; see stateView.
+	State any `json:"state"`
+}
+
+// ---- API: games ----
+
+type createGameBody struct {
+	Rules rulesBody `json:"rules"`
+	// Preset names a preset from /api/presets, whose clock replaces any in
+	// Rules and whose hint quota applies to the game.
+	Preset string `json:"preset,omitempty"`
+	// Features opts the game into flagged abilities that allow opt-in.
+	Features []string `json:"features,omitempty"`
+	// Telemetry set to false keeps the game out of anonymous telemetry on a
+	// server that reports it.
+	Telemetry *bool `json:"telemetry,omitempty"`
+}
+
+func (s *Server) handleCreateGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	var body createGameBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	rules, err := body.Rules.config()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var preset Preset
+	if body.Preset != "" {
+		p, ok := s.games.preset(body.Preset)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown preset")
+			return
+		}
+		if body.Rules.TimeControl != nil {
+			writeError(w, http.StatusBadRequest, "choose a preset or a time control, not both")
+			return
+		}
+		preset, rules.TimeControl = p, p.TimeControl
+	}
+	optIn, err := parseAbilities(body.Features)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	id, err := s.games.Create(rules, optIn)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if preset.Name != "" {
+		s.games.applyPreset(id, preset)
+		if preset.Notify && s.notifier != nil {
+			s.notifier.Track(id)
+		}
+	}
+	if s.telemetry != nil && (body.Telemetry == nil || *body.Telemetry) {
+		s.telemetry.Track(id, rules.Variant)
+	}
+	g, _ := s.games.get(id)
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, s.games.response(g, game.StateOptions{}))
+}
+
+func (s *Server) handleGame(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
+	id := r.PathValue("id")
+	g, ok := s.games.get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	writeJSON(w, s.games.response(g, opts))
+}
+
+func (s *Server) handleGameMove(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.games.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	if s.refuseAdvisor(w, r, r.PathValue("id")) {
+		return
+	}
+	serveMove(w, r, g, s.seatGuard(r, g.ID))
+	s.spar(r.PathValue("id"))
+}
+
+func (s *Server) handleGameConfig(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.games.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	if s.refuseAdvisor(w, r, r.PathValue("id")) {
+		return
+	}
+	serveConfig(w, r, g)
+}
+
+func (s *Server) handleGameConfigAll(w http.ResponseWriter, r *http.Request) {
+	g, ok := s.games.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	if s.refuseAdvisor(w, r, r.PathValue("id")) || refuseShare(w, r) {
+		return
+	}
+	serveConfigAll(w, r, g)
+}
+
+func (m *GameManager) response(g *controller.Game, opts game.StateOptions) gameResponse {
+	m.mu.Lock()
+	preset := m.gamePresets[g.ID]
+	m.mu.Unlock()
+	out := gameResponse{ID: g.ID, Preset: preset}
+	g.Do(func(eng *game.Engine) {
+		rules := eng.Rules()
+		out.Rules, out.DisabledAbilities = rulesView(rules), rules.DisabledAbilities.Strings()
+		out.State = stateView(eng.State(opts), opts)
+	})
+	return out
+}
+
+type drawAssessmentView struct {
+	Score   int    `json:"score"`
+	Level   bool   `json:"level"`
+	Favored string `json:"favored,omitempty"`
+	Summary string `json:"summary"`
+}
+
+// handleDrawAssessment reports the engine's rough view of a game, for clients
+// to show next to a draw offer. Games with competitive rules answer 403.
+func (s *Server) handleDrawAssessment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	mu, eng, ok := s.lookupGame(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	mu.Lock()
+	a, err := eng.EvaluateDrawFairness()
+	mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	view := drawAssessmentView{Score: a.Score, Level: a.Level, Summary: a.Summary}
+	if !a.Level {
+		view.Favored = a.Favored.String()
+	}
+	writeJSON(w, view)
+}
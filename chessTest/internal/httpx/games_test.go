@@ -0,0 +1,366 @@
+// path: chessTest/internal/httpx/games_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/persist"
+	"battle_chess_poc/internal/telemetry"
+)
+
+// fullGameResponse decodes a gameResponse carrying a full BoardState.
+type fullGameResponse struct {
+	gameResponse
+	State game.BoardState `json:"state"`
+}
+
+func TestCreateGameWithRules(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	cases := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "defaults", body: `{}`, wantStatus: http.StatusCreated},
+		{name: "full subset", body: `{"rules":{"variant":"battle","timeControl":{"initialMs":300000,"incrementMs":2000},"bannedAbilities":["DoOver"],"budgets":{"handlerTimeoutMs":50,"charges":{"enabled":true,"start":1,"perTurn":1,"max":5}}}}`, wantStatus: http.StatusCreated},
+		{name: "unknown variant", body: `{"rules":{"variant":"atomic"}}`, wantStatus: http.StatusBadRequest},
+		{name: "clock too short", body: `{"rules":{"timeControl":{"initialMs":1000}}}`, wantStatus: http.StatusBadRequest},
+		{name: "timeout too long", body: `{"rules":{"budgets":{"handlerTimeoutMs":60000}}}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown ban", body: `{"rules":{"bannedAbilities":["Teleport"]}}`, wantStatus: http.StatusBadRequest},
+		{name: "time odds", body: `{"rules":{"timeControl":{"initialMs":300000,"incrementMs":5000,"black":{"initialMs":60000,"incrementMs":0}}}}`, wantStatus: http.StatusCreated},
+		{name: "odds clock too short", body: `{"rules":{"timeControl":{"initialMs":300000,"black":{"initialMs":1000}}}}`, wantStatus: http.StatusBadRequest},
+		{name: "odds increment too long", body: `{"rules":{"timeControl":{"initialMs":300000,"black":{"initialMs":60000,"incrementMs":120000}}}}`, wantStatus: http.StatusBadRequest},
+		{name: "odds without a white clock", body: `{"rules":{"timeControl":{"black":{"initialMs":60000}}}}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(tc.body)))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestGameEchoesRulesAndEnforcesBans(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	rr := httptest.NewRecorder()
+	body := `{"rules":{"timeControl":{"initialMs":60000},"bannedAbilities":["DoOver"]}}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(body)))
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/games/"+created.ID, nil))
+	var got fullGameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode game: %v", err)
+	}
+	if got.Rules.Variant != game.VariantBattle || got.Rules.TimeControl == nil || got.Rules.TimeControl.InitialMs != 60000 {
+		t.Fatalf("rules not echoed: %+v", got.Rules)
+	}
+	if len(got.Rules.BannedAbilities) != 1 || got.Rules.BannedAbilities[0] != "DoOver" {
+		t.Fatalf("bans not echoed: %+v", got.Rules.BannedAbilities)
+	}
+	if len(got.State.Clocks) != 2 {
+		t.Fatalf("expected clocks in state, got %+v", got.State.Clocks)
+	}
+
+	rr = httptest.NewRecorder()
+	config := `{"color":"white","abilities":["DoOver"],"element":"Light"}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games/"+created.ID+"/config", strings.NewReader(config)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("banned config status = %d want %d", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/games/missing", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("missing game status = %d want %d", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestGameExposesTimeOdds(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	rr := httptest.NewRecorder()
+	body := `{"rules":{"timeControl":{"initialMs":60000,"incrementMs":2000,"black":{"initialMs":20000,"incrementMs":500}}}}`
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(body)))
+	var created fullGameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rr.Code, rr.Body.String())
+	}
+	tc := created.Rules.TimeControl
+	if tc == nil || tc.InitialMs != 60000 || tc.Black == nil || *tc.Black != (sideClockBody{InitialMs: 20000, IncrementMs: 500}) {
+		t.Fatalf("time odds not echoed: %+v", tc)
+	}
+	// White's clock is already running.
+	if got := created.State.Clocks; got["white"] < 59000 || got["black"] != 20000 {
+		t.Fatalf("clocks = %v want white about 60000, black 20000", got)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/rules?game="+created.ID, nil))
+	var live liveRulesView
+	if err := json.Unmarshal(rr.Body.Bytes(), &live); err != nil {
+		t.Fatalf("decode rules: %v", err)
+	}
+	if live.TimeControl == nil || live.TimeControl.Black == nil || live.TimeControl.Black.IncrementMs != 500 {
+		t.Fatalf("live rules miss the odds: %+v", live.TimeControl)
+	}
+}
+
+func TestStandardGameMovesEveryPiece(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rr
+	}
+	rr := do(http.MethodPost, "/api/games", `{"rules":{"variant":"standard"}}`)
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodPost, "/api/games/"+created.ID+"/move", `{"from":"g1","to":"f3"}`); rr.Code != http.StatusOK {
+		t.Fatalf("knight move: status %d body %s", rr.Code, rr.Body.String())
+	}
+	var live liveRulesView
+	if err := json.Unmarshal(do(http.MethodGet, "/api/rules?game="+created.ID, "").Body.Bytes(), &live); err != nil {
+		t.Fatalf("decode rules: %v", err)
+	}
+	if live.Variant != game.VariantStandard || len(live.Turn.MovablePieces) != 6 {
+		t.Fatalf("live rules = %s moving %v", live.Variant, live.Turn.MovablePieces)
+	}
+}
+
+func TestGamesSurviveRestartWithStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := persist.Open(dir, persist.Options{})
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	if err := srv.SetStore(store); err != nil {
+		t.Fatalf("set store: %v", err)
+	}
+	handler := srv.routes()
+	do := func(path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)))
+		if rr.Code != http.StatusOK && rr.Code != http.StatusCreated {
+			t.Fatalf("%s status = %d: %s", path, rr.Code, rr.Body.String())
+		}
+		return rr
+	}
+	var created gameResponse
+	if err := json.Unmarshal(do("/api/games", `{}`).Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	do("/api/games/"+created.ID+"/config", `{"color":"white","abilities":["BlockPath"],"element":"light"}`)
+	do("/api/games/"+created.ID+"/config", `{"color":"black","abilities":["BlockPath"],"element":"shadow"}`)
+	do("/api/games/"+created.ID+"/move", `{"from":"e2","to":"e4"}`)
+	do("/api/config", `{"color":"white","abilities":["DoOver"],"element":"fire"}`)
+	do("/api/config", `{"color":"black","abilities":["DoOver"],"element":"water"}`)
+	do("/api/move", `{"from":"d2","to":"d4"}`)
+	store.Close()
+
+	reopened, err := persist.Open(dir, persist.Options{})
+	if err != nil {
+		t.Fatalf("reopen store: %v", err)
+	}
+	defer reopened.Close()
+	eng, err := RecoverEngine(reopened)
+	if err != nil || eng == nil {
+		t.Fatalf("recover default game: %v", err)
+	}
+	if eng.State().Hash != srv.engine.State().Hash {
+		t.Fatal("default game differs after recovery")
+	}
+	restarted := &Server{engine: eng, games: NewGameManager(DefaultRulesLimits())}
+	if err := restarted.SetStore(reopened); err != nil {
+		t.Fatalf("recover games: %v", err)
+	}
+	got, ok := restarted.games.get(created.ID)
+	want, _ := srv.games.get(created.ID)
+	if !ok || got.Engine().State().Hash != want.Engine().State().Hash {
+		t.Fatalf("game %s not recovered", created.ID)
+	}
+}
+
+func TestHandleRulesReportsLiveRules(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(`{"rules":{"timeControl":{"initialMs":60000,"incrementMs":1000},"bannedAbilities":["DoOver"],"budgets":{"charges":{"enabled":true,"start":1,"perTurn":2,"max":4}}}}`)))
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("create: %v (%s)", err, rr.Body.String())
+	}
+	cases := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		check      func(t *testing.T, v liveRulesView)
+	}{
+		{name: "default game", method: http.MethodGet, target: "/api/rules", wantStatus: http.StatusOK, check: func(t *testing.T, v liveRulesView) {
+			if v.Variant != game.VariantBattle || v.Board != (boardView{8, 8}) || v.TimeControl != nil || v.Charges.Enabled {
+				t.Fatalf("unexpected default rules %+v", v)
+			}
+			if v.ExtraRemovals != game.DefaultExtraRemovals || v.DoOver.RewindPlies != game.DefaultDoOverRewindPlies {
+				t.Fatalf("defaults not resolved: %+v", v)
+			}
+			if v.Charges.Costs["DoOver"] != 3 || len(v.Turn.MovablePieces) == 0 || v.Limits.MaxCharges != 20 {
+				t.Fatalf("unexpected details %+v", v)
+			}
+		}},
+		{name: "managed game", method: http.MethodGet, target: "/api/rules?game=" + created.ID, wantStatus: http.StatusOK, check: func(t *testing.T, v liveRulesView) {
+			if v.TimeControl == nil || v.TimeControl.InitialMs != 60000 || v.TimeControl.IncrementMs != 1000 {
+				t.Fatalf("time control = %+v", v.TimeControl)
+			}
+			if len(v.BannedAbilities) != 1 || v.BannedAbilities[0] != "DoOver" {
+				t.Fatalf("banned = %v", v.BannedAbilities)
+			}
+			if !v.Charges.Enabled || v.Charges.Start != 1 || v.Charges.PerTurn != 2 || v.Charges.Max != 4 {
+				t.Fatalf("charges = %+v", v.Charges)
+			}
+		}},
+		{name: "unknown game", method: http.MethodGet, target: "/api/rules?game=nope", wantStatus: http.StatusNotFound},
+		{name: "wrong method", method: http.MethodPost, target: "/api/rules", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(tc.method, tc.target, nil))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if tc.check == nil {
+				return
+			}
+			var v liveRulesView
+			if err := json.Unmarshal(rr.Body.Bytes(), &v); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			tc.check(t, v)
+		})
+	}
+}
+
+func TestStrictGameRejectsUnknownDir(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(`{"rules":{"strict":true}}`)))
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if !created.Rules.Strict {
+		t.Fatalf("strict not echoed: %+v", created.Rules)
+	}
+	cases := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "lenient unknown dir", path: "/api/move", body: `{"from":"e2","to":"e4","dir":"UPWARD"}`, wantStatus: http.StatusOK},
+		{name: "strict unknown dir", path: "/api/games/" + created.ID + "/move", body: `{"from":"e2","to":"e4","dir":"UPWARD"}`, wantStatus: http.StatusBadRequest, wantError: "strict: dir"},
+		{name: "strict unused facing", path: "/api/games/" + created.ID + "/move", body: `{"from":"e2","to":"e4","dir":"N"}`, wantStatus: http.StatusBadRequest, wantError: "no BlockPath"},
+		{name: "strict plain move", path: "/api/games/" + created.ID + "/move", body: `{"from":"e2","to":"e4"}`, wantStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tc.wantError) {
+				t.Fatalf("body %s missing %q", rr.Body.String(), tc.wantError)
+			}
+		})
+	}
+}
+
+func TestCreateGameTelemetryOptOut(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	b := bus.New()
+	srv.SetBus(b)
+	defer b.Close()
+	dir := t.TempDir()
+	if err := srv.SetTelemetry(telemetry.Config{Dir: dir, Interval: time.Hour}); err != nil {
+		t.Fatalf("telemetry: %v", err)
+	}
+	handler := srv.routes()
+	for _, body := range []string{`{}`, `{"telemetry":true}`, `{"telemetry":false}`} {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(body)))
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("create %s: status %d", body, rr.Code)
+		}
+	}
+	if err := srv.telemetry.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "telemetry.jsonl"))
+	if err != nil {
+		t.Fatalf("read batch: %v", err)
+	}
+	var batch telemetry.Batch
+	if err := json.Unmarshal(data, &batch); err != nil {
+		t.Fatalf("decode batch: %v", err)
+	}
+	// The default game and the two games that did not opt out.
+	if got := batch.GamesStarted[game.VariantBattle]; got != 3 {
+		t.Fatalf("games started = %d want 3", got)
+	}
+}
+
+func TestDrawAssessment(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	competitive, err := srv.games.Create(game.RulesConfig{Competitive: true}, nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	cases := []struct {
+		name     string
+		id       string
+		wantCode int
+		wantBody string
+	}{
+		{name: "default game", id: DefaultGameID, wantCode: http.StatusOK, wantBody: `"summary":"engine thinks the position is level"`},
+		{name: "competitive", id: competitive, wantCode: http.StatusForbidden, wantBody: "competitive play"},
+		{name: "unknown", id: "nope", wantCode: http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/games/"+tc.id+"/draw-assessment", nil))
+		if rr.Code != tc.wantCode || !strings.Contains(rr.Body.String(), tc.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", tc.name, rr.Code, rr.Body.String(), tc.wantCode, tc.wantBody)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// path: chessTest/internal/httpx/hints.go
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Hint quotas ration the suggestions an advisor may pass to a seat, so an
+// attached bot cannot play every move for its player. Quotas are set per game
+// type (rules variant), or by the preset a game was created from, and counted
+// per seat for the life of the game; a refused suggestion costs nothing.
+// Without a quota for its variant a game allows unlimited hints.
+
+// HintQuota limits the hints one seat receives in one game.
+type HintQuota struct {
+	// PerGame is the number of hints a seat may receive; zero is unlimited.
+	PerGame int
+	// Cooldown is the least time between two hints to the same seat.
+	Cooldown time.Duration
+}
+
+func (q HintQuota) limited() bool { return q.PerGame > 0 || q.Cooldown > 0 }
+
+type hintUsage struct {
+	used int
+	last time.Time
+}
+
+type hintView struct {
+	Used int `json:"used"`
+	// Limit and Remaining are left out when the count is unlimited.
+	Limit     int        `json:"limit,omitempty"`
+	Remaining *int       `json:"remaining,omitempty"`
+	NextAt    *time.Time `json:"nextAt,omitempty"`
+}
+
+// ParseHintQuotas reads quotas as variant=N[/cooldown],..., for example
+// "battle=5/30s". N is the hints per game, zero for no cap.
+func ParseHintQuotas(spec string) (map[string]HintQuota, error) {
+	out := make(map[string]HintQuota)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		variant, value, ok := strings.Cut(part, "=")
+		variant = strings.ToLower(strings.TrimSpace(variant))
+		if !ok || variant == "" {
+			return nil, fmt.Errorf("hint quota %q: want variant=N[/cooldown]", part)
+		}
+		count, cooldown, hasCooldown := strings.Cut(strings.TrimSpace(value), "/")
+		var q HintQuota
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("hint quota %q: invalid hint count", part)
+		}
+		q.PerGame = n
+		if hasCooldown {
+			d, err := time.ParseDuration(strings.TrimSpace(cooldown))
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("hint quota %q: invalid cooldown", part)
+			}
+			q.Cooldown = d
+		}
+		out[variant] = q
+	}
+	return out, nil
+}
+
+// SetHintQuotas replaces the hint quotas, keyed by rules variant. Hints
+// already given still count against the new quotas.
+func (s *Server) SetHintQuotas(quotas map[string]HintQuota) {
+	s.games.mu.Lock()
+	defer s.games.mu.Unlock()
+	s.games.hintQuotas = make(map[string]HintQuota, len(quotas))
+	for variant, q := range quotas {
+		s.games.hintQuotas[variant] = q
+	}
+}
+
+// hintQuotaLocked is the quota for game id, from its preset or else its
+// variant. The caller holds m.mu.
+func (m *GameManager) hintQuotaLocked(id, variant string) HintQuota {
+	if q, ok := m.gameHints[id]; ok {
+		return q
+	}
+	if variant == "" {
+		variant = game.VariantBattle
+	}
+	return m.hintQuotas[variant]
+}
+
+// takeHintLocked charges one hint to key's seat as of now, or reports the
+// status and message refusing it. The caller holds m.mu.
+func (m *GameManager) takeHintLocked(key seatKey, variant string, now time.Time) (int, string) {
+	q := m.hintQuotaLocked(key.game, variant)
+	if !q.limited() {
+		return http.StatusOK, ""
+	}
+	if m.hints == nil {
+		m.hints = make(map[seatKey]*hintUsage)
+	}
+	u, ok := m.hints[key]
+	if !ok {
+		u = &hintUsage{}
+		m.hints[key] = u
+	}
+	if q.PerGame > 0 && u.used >= q.PerGame {
+		return http.StatusTooManyRequests, "hint quota used up for this game"
+	}
+	if u.used > 0 && now.Sub(u.last) < q.Cooldown {
+		return http.StatusTooManyRequests, "hint cooldown: next hint at " + u.last.Add(q.Cooldown).Format(time.RFC3339)
+	}
+	u.used++
+	u.last = now
+	return http.StatusOK, ""
+}
+
+// hintStatus reports key's hint use under its variant's quota, or nil when
+// the variant has none.
+func (m *GameManager) hintStatus(key seatKey, variant string, now time.Time) *hintView {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	q := m.hintQuotaLocked(key.game, variant)
+	if !q.limited() {
+		return nil
+	}
+	var u hintUsage
+	if cur, ok := m.hints[key]; ok {
+		u = *cur
+	}
+	out := &hintView{Used: u.used}
+	if q.PerGame > 0 {
+		left := max(q.PerGame-u.used, 0)
+		out.Limit, out.Remaining = q.PerGame, &left
+	}
+	if u.used > 0 && q.Cooldown > 0 {
+		if next := u.last.Add(q.Cooldown); next.After(now) {
+			out.NextAt = &next
+		}
+	}
+	return out
+}
+
+// resetHints forgets the hints given in game id, for a game that starts over.
+func (m *GameManager) resetHints(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, color := range []game.Color{game.White, game.Black} {
+		delete(m.hints, seatKey{game: id, color: color})
+	}
+}
+
+// gameVariant is the rules variant of game id, "" when there is no such game.
+func (s *Server) gameVariant(id string) string {
+	mu, eng, ok := s.lookupGame(id)
+	if !ok {
+		return ""
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return eng.Rules().Variant
+}
+
+// withHints adds each seat's hint status to views of game id.
+func (s *Server) withHints(id string, views []seatView) []seatView {
+	if s.games == nil {
+		return views
+	}
+	variant := s.gameVariant(id)
+	now := time.Now()
+	for i := range views {
+		color, _ := game.ParseColor(views[i].Color)
+		views[i].Hints = s.games.hintStatus(seatKey{game: id, color: color}, variant, now)
+	}
+	return views
+}
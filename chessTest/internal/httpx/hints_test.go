@@ -0,0 +1,98 @@
+// path: chessTest/internal/httpx/hints_test.go
+package httpx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestParseHintQuotas(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    map[string]HintQuota
+		wantErr bool
+	}{
+		{spec: "", want: map[string]HintQuota{}},
+		{spec: "battle=5", want: map[string]HintQuota{"battle": {PerGame: 5}}},
+		{spec: " Battle = 3/30s ", want: map[string]HintQuota{"battle": {PerGame: 3, Cooldown: 30 * time.Second}}},
+		{spec: "battle=0/1m", want: map[string]HintQuota{"battle": {Cooldown: time.Minute}}},
+		{spec: "5", wantErr: true},
+		{spec: "battle=-1", wantErr: true},
+		{spec: "battle=2/soon", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := ParseHintQuotas(tc.spec)
+		if (err != nil) != tc.wantErr {
+			t.Fatalf("%q: err = %v, want error %t", tc.spec, err, tc.wantErr)
+		}
+		if tc.wantErr {
+			continue
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: got %v, want %v", tc.spec, got, tc.want)
+		}
+		for k, q := range tc.want {
+			if got[k] != q {
+				t.Fatalf("%q: %s = %+v, want %+v", tc.spec, k, got[k], q)
+			}
+		}
+	}
+}
+
+func TestHintQuota(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{
+		"alice": {Subject: "alice", Roles: []string{RolePlayer}},
+		"bot":   {Subject: "bot", Roles: []string{RoleAdvisor}},
+	})
+	srv.SetHintQuotas(map[string]HintQuota{game.VariantBattle: {PerGame: 2, Cooldown: time.Hour}})
+	id, err := srv.games.Create(game.DefaultRules(), nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	handler := srv.routes()
+	base := "/api/games/" + id
+
+	steps := []struct {
+		name     string
+		token    string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "attach", token: "alice", method: http.MethodPost, path: base + "/advisors", body: `{"color":"white","advisor":"bot"}`, wantCode: http.StatusOK},
+		{name: "fresh allowance", token: "alice", method: http.MethodGet, path: base + "/suggestions?color=white", wantCode: http.StatusOK, wantBody: `"hints":{"used":0,"limit":2,"remaining":2}`},
+		{name: "first hint", token: "bot", method: http.MethodPost, path: base + "/suggestions", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusOK},
+		{name: "cooldown", token: "bot", method: http.MethodPost, path: base + "/suggestions", body: `{"from":"d2","to":"d4"}`, wantCode: http.StatusTooManyRequests, wantBody: "cooldown"},
+		{name: "refused hint is free", token: "alice", method: http.MethodGet, path: base + "/suggestions?color=white", wantCode: http.StatusOK, wantBody: `"remaining":1`},
+		{name: "heartbeat", token: "alice", method: http.MethodPost, path: "/api/heartbeat", body: `{"game":"` + id + `","color":"white"}`, wantCode: http.StatusOK, wantBody: `"hints":{"used":1,"limit":2,"remaining":1,"nextAt"`},
+	}
+	for _, st := range steps {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(st.method, st.path, strings.NewReader(st.body))
+		req.Header.Set("Authorization", "Bearer "+st.token)
+		handler.ServeHTTP(rr, req)
+		if rr.Code != st.wantCode || !strings.Contains(rr.Body.String(), st.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", st.name, rr.Code, rr.Body.String(), st.wantCode, st.wantBody)
+		}
+	}
+
+	key := seatKey{game: id, color: game.White}
+	later := time.Now().Add(2 * time.Hour)
+	if _, status, msg := srv.games.suggest(key, "bot", game.VariantBattle, suggestionView{At: later}); msg != "" {
+		t.Fatalf("second hint after cooldown: %d %s", status, msg)
+	}
+	if _, status, msg := srv.games.suggest(key, "bot", game.VariantBattle, suggestionView{At: later.Add(2 * time.Hour)}); status != http.StatusTooManyRequests || !strings.Contains(msg, "used up") {
+		t.Fatalf("third hint: %d %q, want quota used up", status, msg)
+	}
+	if got := srv.games.hintStatus(seatKey{game: id, color: game.Black}, game.VariantBattle, later); got == nil || *got.Remaining != 2 {
+		t.Fatalf("black allowance = %+v, want untouched", got)
+	}
+}
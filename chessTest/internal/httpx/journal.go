@@ -0,0 +1,37 @@
+// path: chessTest/internal/httpx/journal.go
+package httpx
+
+import (
+	"errors"
+	"log"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/persist"
+)
+
+// DefaultGameID names the server's unscoped engine in the persistence store.
+const DefaultGameID = "default"
+
+// SetStore makes every game crash-recoverable through store, first loading
+// the /api/games games it already holds. Call it before serving requests.
+func (s *Server) SetStore(store *persist.Store) error {
+	if err := s.games.ctl.SetStore(store, DefaultGameID); err != nil {
+		return err
+	}
+	s.store = store
+	return nil
+}
+
+// RecoverEngine rebuilds the unscoped game from store. It returns nil, and no
+// error, when none was persisted.
+func RecoverEngine(store *persist.Store) (*game.Engine, error) {
+	eng, replayed, err := store.Recover(DefaultGameID)
+	if errors.Is(err, persist.ErrNoGame) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("recovered game %s (%d logged moves replayed)", DefaultGameID, replayed)
+	return eng, nil
+}
@@ -0,0 +1,109 @@
+// path: chessTest/internal/httpx/notify.go
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/notify"
+)
+
+// Turn notifications are for correspondence play: games created from a preset
+// with Notify set tell the player to move through the webhook or email in
+// their profile. A seat belongs to the subject whose heartbeats hold it, so
+// notifications need authentication. Preferences live in memory, like puzzle
+// progress.
+
+// SetNotifier starts turn notifications with cfg on the server's event bus.
+// cfg.Recipient is supplied by the server. Call it after SetBus and before
+// serving requests.
+func (s *Server) SetNotifier(cfg notify.Config) error {
+	if s.bus == nil {
+		return errors.New("notifications need the event bus")
+	}
+	cfg.Recipient = s.turnRecipient
+	n, err := notify.Start(s.bus, cfg)
+	if err != nil {
+		return err
+	}
+	s.notifier = n
+	return nil
+}
+
+// turnRecipient reports who is to move in game id, with their preferences.
+// It waits for the game's lock, so a turn still being played is seen
+// finished.
+func (s *Server) turnRecipient(id string) (notify.Recipient, bool) {
+	mu, eng, ok := s.lookupGame(id)
+	if !ok {
+		return notify.Recipient{}, false
+	}
+	mu.Lock()
+	st := eng.State(game.StateOptions{Detail: game.StateSummary})
+	mu.Unlock()
+	if st.Phase != game.PhaseAwaitingMove && st.Phase != game.PhaseAwaitingConfig {
+		return notify.Recipient{}, false
+	}
+	subject := s.seats.holder(seatKey{game: id, color: st.Turn})
+	if subject == "" {
+		return notify.Recipient{}, false
+	}
+	s.prefsMu.Lock()
+	prefs, ok := s.notifyPrefs[subject]
+	s.prefsMu.Unlock()
+	if !ok {
+		return notify.Recipient{}, false
+	}
+	return notify.Recipient{Subject: subject, Color: st.Turn, Seq: st.Seq, Prefs: prefs}, true
+}
+
+// ---- API: notification preferences ----
+
+type notifyPrefsBody struct {
+	Webhook string `json:"webhook,omitempty"`
+	Email   string `json:"email,omitempty"`
+}
+
+// handleNotifyPrefs reads the caller's notification preferences with GET and
+// replaces them with PUT; empty preferences turn notifications off.
+func (s *Server) handleNotifyPrefs(w http.ResponseWriter, r *http.Request) {
+	ident, ok := IdentityFrom(r.Context())
+	if !ok || ident.Subject == "" {
+		writeError(w, http.StatusForbidden, "notifications require authentication")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		s.prefsMu.Lock()
+		prefs := s.notifyPrefs[ident.Subject]
+		s.prefsMu.Unlock()
+		writeJSON(w, notifyPrefsBody{Webhook: prefs.Webhook, Email: prefs.Email})
+	case http.MethodPut:
+		defer r.Body.Close()
+		var body notifyPrefsBody
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		prefs := notify.Preferences{Webhook: strings.TrimSpace(body.Webhook), Email: strings.TrimSpace(body.Email)}
+		if err := prefs.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.prefsMu.Lock()
+		if s.notifyPrefs == nil {
+			s.notifyPrefs = make(map[string]notify.Preferences)
+		}
+		if prefs == (notify.Preferences{}) {
+			delete(s.notifyPrefs, ident.Subject)
+		} else {
+			s.notifyPrefs[ident.Subject] = prefs
+		}
+		s.prefsMu.Unlock()
+		writeJSON(w, notifyPrefsBody{Webhook: prefs.Webhook, Email: prefs.Email})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
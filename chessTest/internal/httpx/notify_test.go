@@ -0,0 +1,69 @@
+// path: chessTest/internal/httpx/notify_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestTurnRecipient(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{
+		"alice": {Subject: "alice", Roles: []string{RolePlayer}},
+		"bob":   {Subject: "bob", Roles: []string{RolePlayer}},
+	})
+	handler := srv.routes()
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	steps := []struct {
+		name     string
+		token    string
+		method   string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "anonymous", method: http.MethodGet, wantCode: http.StatusUnauthorized},
+		{name: "bad webhook", token: "bob", method: http.MethodPut, body: `{"webhook":"ftp://example.test"}`, wantCode: http.StatusBadRequest},
+		{name: "set", token: "bob", method: http.MethodPut, body: `{"webhook":" https://example.test/bob "}`, wantCode: http.StatusOK, wantBody: `"webhook":"https://example.test/bob"`},
+		{name: "read back", token: "bob", method: http.MethodGet, wantCode: http.StatusOK, wantBody: `"webhook":"https://example.test/bob"`},
+		{name: "others unset", token: "alice", method: http.MethodGet, wantCode: http.StatusOK, wantBody: `{}`},
+	}
+	for _, st := range steps {
+		rr := do(st.token, st.method, "/api/profile/notifications", st.body)
+		if rr.Code != st.wantCode || !strings.Contains(rr.Body.String(), st.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", st.name, rr.Code, rr.Body.String(), st.wantCode, st.wantBody)
+		}
+	}
+
+	rr := do("alice", http.MethodPost, "/api/games", `{"preset":"correspondence"}`)
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rr.Code, rr.Body.String())
+	}
+	id := created.ID
+	if _, ok := srv.turnRecipient(id); ok {
+		t.Fatalf("white's seat is unheld, nobody to notify")
+	}
+	do("bob", http.MethodPost, "/api/heartbeat", `{"game":"`+id+`","color":"black"}`)
+	if rr := do("alice", http.MethodPost, "/api/games/"+id+"/move", `{"from":"e2","to":"e4"}`); rr.Code != http.StatusOK {
+		t.Fatalf("move: status %d body %s", rr.Code, rr.Body.String())
+	}
+	rcpt, ok := srv.turnRecipient(id)
+	if !ok || rcpt.Subject != "bob" || rcpt.Color != game.Black || rcpt.Seq != 1 || rcpt.Prefs.Webhook != "https://example.test/bob" {
+		t.Fatalf("recipient = %+v, %t; want bob to move black", rcpt, ok)
+	}
+}
@@ -0,0 +1,87 @@
+// path: chessTest/internal/httpx/piece.go
+package httpx
+
+import (
+	"net/http"
+	"strconv"
+
+	"battle_chess_poc/internal/game"
+)
+
+type readinessView struct {
+	Ability string `json:"ability"`
+	Cost    int    `json:"cost"`
+	Ready   bool   `json:"ready"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type pieceView struct {
+	ID        int             `json:"id"`
+	Color     string          `json:"color"`
+	Type      string          `json:"type"`
+	Square    string          `json:"square"`
+	Element   string          `json:"element"`
+	Abilities []string        `json:"abilities"`
+	Targets   []string        `json:"targets"`
+	Facing    string          `json:"facing,omitempty"`
+	Attacked  bool            `json:"attacked"`
+	Readiness []readinessView `json:"readiness"`
+}
+
+func newPieceView(p game.PieceDetail) pieceView {
+	out := pieceView{
+		ID:        p.ID,
+		Color:     p.Color.String(),
+		Type:      p.Type.String(),
+		Square:    game.SquareToCoord(p.Square),
+		Element:   p.Element.String(),
+		Abilities: p.Abilities,
+		Targets:   make([]string, 0, len(p.Targets)),
+		Attacked:  p.Attacked,
+		Readiness: make([]readinessView, 0, len(p.Readiness)),
+	}
+	if out.Abilities == nil {
+		out.Abilities = []string{}
+	}
+	for _, sq := range p.Targets {
+		out.Targets = append(out.Targets, game.SquareToCoord(sq))
+	}
+	if p.BlockFacing != game.DirNone {
+		out.Facing = p.BlockFacing.String()
+	}
+	for _, r := range p.Readiness {
+		out.Readiness = append(out.Readiness, readinessView{Ability: r.Ability.String(), Cost: r.Cost, Ready: r.Ready, Reason: r.Reason})
+	}
+	return out
+}
+
+// handlePiece reports one piece of the default game, or of the managed game
+// named by ?game=, with its legal targets, facing and ability readiness.
+func (s *Server) handlePiece(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid piece id")
+		return
+	}
+	gameID := r.URL.Query().Get("game")
+	if gameID == "" {
+		gameID = DefaultGameID
+	}
+	mu, eng, ok := s.lookupGame(gameID)
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	mu.Lock()
+	piece, ok := eng.Piece(id)
+	mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "piece not found")
+		return
+	}
+	writeJSON(w, map[string]any{"piece": newPieceView(piece)})
+}
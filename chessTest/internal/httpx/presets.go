@@ -0,0 +1,198 @@
+// path: chessTest/internal/httpx/presets.go
+package httpx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// A preset bundles the pace settings of a game type: its clock, its advisor
+// hint quota and whether players are notified of their turn. Create a game
+// from one with {"preset": "blitz"} on /api/games; /api/presets lists what
+// the server offers.
+
+// Preset is a named game-speed bundle.
+type Preset struct {
+	Name        string
+	Description string
+	TimeControl game.TimeControl
+	// Hints, when limited, replaces the variant's hint quota for games
+	// created from the preset.
+	Hints HintQuota
+	// Notify turns on turn notifications for games created from the preset;
+	// see SetNotifier.
+	Notify bool
+}
+
+// DefaultPresets are the presets served when none are configured.
+func DefaultPresets() []Preset {
+	return []Preset{
+		{
+			Name:        "blitz",
+			Description: "Three minutes each plus two seconds a move; few hints.",
+			TimeControl: game.TimeControl{Initial: 3 * time.Minute, Increment: 2 * time.Second},
+			Hints:       HintQuota{PerGame: 3, Cooldown: 20 * time.Second},
+		},
+		{
+			Name:        "rapid",
+			Description: "Fifteen minutes each plus ten seconds a move.",
+			TimeControl: game.TimeControl{Initial: 15 * time.Minute, Increment: 10 * time.Second},
+			Hints:       HintQuota{PerGame: 5, Cooldown: time.Minute},
+		},
+		{
+			Name:        "correspondence",
+			Description: "No clock; a hint every ten minutes and a notification when it is your move.",
+			Hints:       HintQuota{Cooldown: 10 * time.Minute},
+			Notify:      true,
+		},
+	}
+}
+
+// A presets file is a JSON list:
+//
+//	[{"name": "blitz", "description": "3+2", "initialMs": 180000,
+//	  "incrementMs": 2000, "hints": {"perGame": 3, "cooldownMs": 20000},
+//	  "notify": false}]
+type presetFile struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	InitialMs   int64  `json:"initialMs"`
+	IncrementMs int64  `json:"incrementMs"`
+	Hints       struct {
+		PerGame    int   `json:"perGame"`
+		CooldownMs int64 `json:"cooldownMs"`
+	} `json:"hints"`
+	Notify bool `json:"notify"`
+}
+
+// ParsePresets reads a presets file.
+func ParsePresets(data []byte) ([]Preset, error) {
+	var files []presetFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("presets: %v", err)
+	}
+	out := make([]Preset, 0, len(files))
+	for _, f := range files {
+		out = append(out, Preset{
+			Name:        strings.ToLower(strings.TrimSpace(f.Name)),
+			Description: f.Description,
+			TimeControl: game.TimeControl{
+				Initial:   time.Duration(f.InitialMs) * time.Millisecond,
+				Increment: time.Duration(f.IncrementMs) * time.Millisecond,
+			},
+			Hints:  HintQuota{PerGame: f.Hints.PerGame, Cooldown: time.Duration(f.Hints.CooldownMs) * time.Millisecond},
+			Notify: f.Notify,
+		})
+	}
+	return out, nil
+}
+
+// SetPresets replaces the presets offered by /api/games. Every preset needs a
+// unique name, and its clock must fall within the server's rules limits.
+func (s *Server) SetPresets(presets []Preset) error {
+	m := s.games
+	seen := make(map[string]bool, len(presets))
+	for _, p := range presets {
+		switch {
+		case p.Name == "":
+			return fmt.Errorf("preset without a name")
+		case seen[p.Name]:
+			return fmt.Errorf("duplicate preset %q", p.Name)
+		case p.Hints.PerGame < 0 || p.Hints.Cooldown < 0:
+			return fmt.Errorf("preset %s: negative hint quota", p.Name)
+		}
+		seen[p.Name] = true
+		rules := game.DefaultRules()
+		rules.TimeControl = p.TimeControl
+		if err := m.limits.check(rules); err != nil {
+			return fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.presets = append([]Preset(nil), presets...)
+	return nil
+}
+
+// preset finds the preset called name.
+func (m *GameManager) preset(name string) (Preset, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.presets == nil {
+		m.presets = DefaultPresets()
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, p := range m.presets {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
+
+func (m *GameManager) presetList() []Preset {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.presets == nil {
+		m.presets = DefaultPresets()
+	}
+	return append([]Preset(nil), m.presets...)
+}
+
+// applyPreset records that game id was created from p.
+func (m *GameManager) applyPreset(id string, p Preset) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gamePresets == nil {
+		m.gamePresets = make(map[string]string)
+	}
+	m.gamePresets[id] = p.Name
+	if p.Hints.limited() {
+		if m.gameHints == nil {
+			m.gameHints = make(map[string]HintQuota)
+		}
+		m.gameHints[id] = p.Hints
+	}
+}
+
+// ---- API: presets ----
+
+type presetHintsView struct {
+	PerGame    int   `json:"perGame,omitempty"`
+	CooldownMs int64 `json:"cooldownMs,omitempty"`
+}
+
+type presetView struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	TimeControl *timeControlBody `json:"timeControl,omitempty"`
+	Hints       *presetHintsView `json:"hints,omitempty"`
+	Notify      bool             `json:"notify,omitempty"`
+}
+
+func newPresetView(p Preset) presetView {
+	out := presetView{Name: p.Name, Description: p.Description, Notify: p.Notify}
+	out.TimeControl = timeControlView(p.TimeControl)
+	if p.Hints.limited() {
+		out.Hints = &presetHintsView{PerGame: p.Hints.PerGame, CooldownMs: p.Hints.Cooldown.Milliseconds()}
+	}
+	return out
+}
+
+func (s *Server) handlePresets(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	list := s.games.presetList()
+	out := make([]presetView, 0, len(list))
+	for _, p := range list {
+		out = append(out, newPresetView(p))
+	}
+	writeJSON(w, map[string]any{"presets": out})
+}
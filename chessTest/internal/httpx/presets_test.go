@@ -0,0 +1,82 @@
+// path: chessTest/internal/httpx/presets_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestCreateGameFromPreset(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/presets", nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"name":"blitz"`) {
+		t.Fatalf("presets: status %d body %s", rr.Code, rr.Body.String())
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "blitz", body: `{"preset":"Blitz"}`, wantCode: http.StatusCreated, wantBody: `"timeControl":{"initialMs":180000,"incrementMs":2000}`},
+		{name: "correspondence is untimed", body: `{"preset":"correspondence"}`, wantCode: http.StatusCreated, wantBody: `"preset":"correspondence"`},
+		{name: "unknown", body: `{"preset":"bullet"}`, wantCode: http.StatusBadRequest, wantBody: "unknown preset"},
+		{name: "preset and clock", body: `{"preset":"rapid","rules":{"timeControl":{"initialMs":60000}}}`, wantCode: http.StatusBadRequest, wantBody: "not both"},
+	}
+	for _, tc := range cases {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(tc.body)))
+		if rr.Code != tc.wantCode || !strings.Contains(rr.Body.String(), tc.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", tc.name, rr.Code, rr.Body.String(), tc.wantCode, tc.wantBody)
+		}
+		if tc.name != "blitz" {
+			continue
+		}
+		var created gameResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		hints := srv.games.hintStatus(seatKey{game: created.ID, color: game.White}, game.VariantBattle, time.Now())
+		if hints == nil || hints.Limit != 3 {
+			t.Fatalf("blitz hints = %+v, want the preset's quota of 3", hints)
+		}
+	}
+}
+
+func TestSetPresets(t *testing.T) {
+	srv := &Server{games: NewGameManager(DefaultRulesLimits())}
+	presets, err := ParsePresets([]byte(`[{"name":"Bullet","initialMs":60000,"hints":{"perGame":1}}]`))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if err := srv.SetPresets(presets); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if p, ok := srv.games.preset("bullet"); !ok || p.TimeControl.Initial != time.Minute || p.Hints.PerGame != 1 {
+		t.Fatalf("bullet = %+v, %t", p, ok)
+	}
+	if _, ok := srv.games.preset("blitz"); ok {
+		t.Fatalf("configured presets should replace the defaults")
+	}
+
+	bad := [][]Preset{
+		{{Name: ""}},
+		{{Name: "a"}, {Name: "a"}},
+		{{Name: "slow", TimeControl: game.TimeControl{Initial: 24 * time.Hour}}},
+	}
+	for _, list := range bad {
+		if err := srv.SetPresets(list); err == nil {
+			t.Fatalf("SetPresets(%+v) accepted", list)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// path: chessTest/internal/httpx/puzzles.go
+package httpx
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Puzzle progress is kept per profile: the caller's token subject, or with
+// authentication off the ?profile= query parameter, "guest" when absent.
+// Progress lives in memory and starts over when the server restarts.
+
+const guestProfile = "guest"
+
+type puzzleProgress struct {
+	Attempts int        `json:"attempts"`
+	Solved   bool       `json:"solved"`
+	SolvedAt *time.Time `json:"solvedAt,omitempty"`
+}
+
+type puzzleGoalView struct {
+	Ability  string `json:"ability,omitempty"`
+	Material int    `json:"material,omitempty"`
+	Win      bool   `json:"win,omitempty"`
+}
+
+type puzzleView struct {
+	ID       string         `json:"id"`
+	Title    string         `json:"title"`
+	Prompt   string         `json:"prompt"`
+	Turn     string         `json:"turn"`
+	Goal     puzzleGoalView `json:"goal"`
+	Progress puzzleProgress `json:"progress"`
+}
+
+func newPuzzleView(p game.Puzzle, progress puzzleProgress) puzzleView {
+	goal := puzzleGoalView{Material: p.Goal.Material, Win: p.Goal.Win}
+	if p.Goal.Ability != game.AbilityNone {
+		goal.Ability = p.Goal.Ability.String()
+	}
+	return puzzleView{ID: p.ID, Title: p.Title, Prompt: p.Prompt, Turn: p.Turn.String(), Goal: goal, Progress: progress}
+}
+
+// SetPuzzles replaces the puzzle pack served by /api/puzzles. Progress on
+// puzzles whose ids survive is kept.
+func (s *Server) SetPuzzles(pack game.PuzzlePack) error {
+	if err := pack.Validate(); err != nil {
+		return err
+	}
+	s.puzzleMu.Lock()
+	defer s.puzzleMu.Unlock()
+	s.puzzles = &pack
+	return nil
+}
+
+// puzzlePackLocked returns the configured pack, or the default one. The
+// caller holds puzzleMu.
+func (s *Server) puzzlePackLocked() game.PuzzlePack {
+	if s.puzzles == nil {
+		pack := game.DefaultPuzzles()
+		s.puzzles = &pack
+	}
+	return *s.puzzles
+}
+
+// progressLocked returns profile's progress on puzzle id, creating it. The
+// caller holds puzzleMu.
+func (s *Server) progressLocked(profile, id string) *puzzleProgress {
+	if s.puzzleProgress == nil {
+		s.puzzleProgress = make(map[string]map[string]*puzzleProgress)
+	}
+	byPuzzle, ok := s.puzzleProgress[profile]
+	if !ok {
+		byPuzzle = make(map[string]*puzzleProgress)
+		s.puzzleProgress[profile] = byPuzzle
+	}
+	p, ok := byPuzzle[id]
+	if !ok {
+		p = &puzzleProgress{}
+		byPuzzle[id] = p
+	}
+	return p
+}
+
+func puzzleProfile(r *http.Request) string {
+	if ident, ok := IdentityFrom(r.Context()); ok && ident.Subject != "" {
+		return ident.Subject
+	}
+	if p := strings.TrimSpace(r.URL.Query().Get("profile")); p != "" {
+		return p
+	}
+	return guestProfile
+}
+
+// ---- API: puzzles ----
+
+// handlePuzzles lists the pack with the caller's progress on each puzzle.
+func (s *Server) handlePuzzles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	profile := puzzleProfile(r)
+	s.puzzleMu.Lock()
+	pack := s.puzzlePackLocked()
+	views := make([]puzzleView, 0, len(pack.Puzzles))
+	solved := 0
+	for _, p := range pack.Puzzles {
+		progress := *s.progressLocked(profile, p.ID)
+		if progress.Solved {
+			solved++
+		}
+		views = append(views, newPuzzleView(p, progress))
+	}
+	s.puzzleMu.Unlock()
+	writeJSON(w, map[string]any{"pack": pack.Name, "profile": profile, "solved": solved, "total": len(views), "puzzles": views})
+}
+
+// handlePuzzle shows one puzzle with its starting position.
+func (s *Server) handlePuzzle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	profile := puzzleProfile(r)
+	s.puzzleMu.Lock()
+	p, ok := s.puzzlePackLocked().Puzzle(r.PathValue("id"))
+	var progress puzzleProgress
+	if ok {
+		progress = *s.progressLocked(profile, p.ID)
+	}
+	s.puzzleMu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "puzzle not found")
+		return
+	}
+	eng, err := p.Engine()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"puzzle": newPuzzleView(p, progress), "state": eng.State()})
+}
+
+// handlePuzzleSolve plays the submitted turn from the puzzle's position and
+// reports whether it meets the goal. Every turn the engine accepts counts as
+// an attempt; a rejected move does not.
+func (s *Server) handlePuzzleSolve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	var body moveBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	req, msg := body.request()
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	profile := puzzleProfile(r)
+	s.puzzleMu.Lock()
+	p, ok := s.puzzlePackLocked().Puzzle(r.PathValue("id"))
+	s.puzzleMu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "puzzle not found")
+		return
+	}
+	body.applyRelative(p.Turn, &req)
+	res, err := p.Verify(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.puzzleMu.Lock()
+	progress := s.progressLocked(profile, p.ID)
+	progress.Attempts++
+	if res.Solved && !progress.Solved {
+		now := time.Now()
+		progress.Solved, progress.SolvedAt = true, &now
+	}
+	view := newPuzzleView(p, *progress)
+	s.puzzleMu.Unlock()
+
+	out := map[string]any{
+		"solved":   res.Solved,
+		"material": res.Material,
+		"events":   moveEventViews(res.Events),
+		"state":    res.State,
+		"puzzle":   view,
+	}
+	if res.Solved {
+		out["success"] = p.Success
+	} else {
+		out["missed"] = res.Missed
+	}
+	writeJSON(w, out)
+}
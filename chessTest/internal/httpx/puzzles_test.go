@@ -0,0 +1,52 @@
+// path: chessTest/internal/httpx/puzzles_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestPuzzleEndpoints(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	handler := srv.routes()
+	do := func(method, path, body string) (int, map[string]json.RawMessage) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var out map[string]json.RawMessage
+		if err := json.Unmarshal(rr.Body.Bytes(), &out); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		return rr.Code, out
+	}
+
+	steps := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantKey  string
+		wantVal  string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/puzzles", wantCode: http.StatusOK, wantKey: "solved", wantVal: "0"},
+		{name: "show", method: http.MethodGet, path: "/api/puzzles/scatter-guard", wantCode: http.StatusOK, wantKey: "puzzle", wantVal: `"ability":"ScatterShot"`},
+		{name: "unknown", method: http.MethodGet, path: "/api/puzzles/nope", wantCode: http.StatusNotFound, wantKey: "error", wantVal: "puzzle not found"},
+		{name: "illegal move", method: http.MethodPost, path: "/api/puzzles/scatter-guard/solve", body: `{"from":"e4","to":"e6"}`, wantCode: http.StatusBadRequest, wantKey: "error"},
+		{name: "wrong capture", method: http.MethodPost, path: "/api/puzzles/scatter-guard/solve", body: `{"from":"e4","to":"f5"}`, wantCode: http.StatusOK, wantKey: "missed", wantVal: "needs 7"},
+		{name: "solved", method: http.MethodPost, path: "/api/puzzles/scatter-guard/solve", body: `{"from":"e4","to":"d5"}`, wantCode: http.StatusOK, wantKey: "puzzle", wantVal: `"attempts":2,"solved":true`},
+		{name: "progress counted", method: http.MethodGet, path: "/api/puzzles", wantCode: http.StatusOK, wantKey: "solved", wantVal: "1"},
+		{name: "other profile", method: http.MethodGet, path: "/api/puzzles?profile=ana", wantCode: http.StatusOK, wantKey: "solved", wantVal: "0"},
+	}
+	for _, st := range steps {
+		code, out := do(st.method, st.path, st.body)
+		if code != st.wantCode || !strings.Contains(string(out[st.wantKey]), st.wantVal) {
+			t.Fatalf("%s: %d %s=%s, want %d containing %q", st.name, code, st.wantKey, out[st.wantKey], st.wantCode, st.wantVal)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// path: chessTest/internal/httpx/rules.go
+package httpx
+
+import (
+	"net/http"
+	"sort"
+
+	"battle_chess_poc/internal/game"
+)
+
+// liveRulesView describes the rules a game actually runs under, with every
+// default resolved, so clients and bots never have to hardcode them.
+type liveRulesView struct {
+	Variant           string           `json:"variant"`
+	Board             boardView        `json:"board"`
+	Turn              turnView         `json:"turn"`
+	TimeControl       *timeControlBody `json:"timeControl"`
+	BannedAbilities   []string         `json:"bannedAbilities"`
+	DisabledAbilities []string         `json:"disabledAbilities"`
+	// AbilityEligibility lists the piece types allowed each restricted
+	// ability; unlisted abilities are usable by every piece.
+	AbilityEligibility map[string][]string `json:"abilityEligibility"`
+	HandlerTimeoutMs   int64               `json:"handlerTimeoutMs"`
+	IsolatePanics      bool                `json:"isolatePanics"`
+	ExtraRemovals      int                 `json:"extraRemovals"`
+	DoOver             doOverView          `json:"doOver"`
+	Charges            chargeRulesView     `json:"charges"`
+	Limits             limitsView          `json:"limits"`
+	// Adjudication is null unless the game is in tournament mode.
+	Adjudication *adjudicationBody `json:"adjudication"`
+}
+
+type boardView struct {
+	Files int `json:"files"`
+	Ranks int `json:"ranks"`
+}
+
+type turnView struct {
+	SegmentsPerTurn int      `json:"segmentsPerTurn"`
+	MovablePieces   []string `json:"movablePieces"`
+}
+
+type doOverView struct {
+	RewindPlies int `json:"rewindPlies"`
+	TurnCost    int `json:"turnCost"`
+}
+
+type chargeRulesView struct {
+	Enabled bool           `json:"enabled"`
+	Start   int            `json:"start"`
+	PerTurn int            `json:"perTurn"`
+	Max     int            `json:"max"`
+	Costs   map[string]int `json:"costs"`
+}
+
+// limitsView is what a create-game request may ask for on this server.
+type limitsView struct {
+	Variants            []string `json:"variants"`
+	MaxHandlerTimeoutMs int64    `json:"maxHandlerTimeoutMs"`
+	MinInitialMs        int64    `json:"minInitialMs"`
+	MaxInitialMs        int64    `json:"maxInitialMs"`
+	MaxIncrementMs      int64    `json:"maxIncrementMs"`
+	MaxCharges          int      `json:"maxCharges"`
+	AllowBans           bool     `json:"allowBans"`
+}
+
+func liveRules(r game.RulesConfig, l RulesLimits) liveRulesView {
+	out := liveRulesView{
+		Variant:            r.Variant,
+		Board:              boardView{Files: 8, Ranks: 8},
+		Turn:               turnView{SegmentsPerTurn: 1},
+		BannedAbilities:    r.BannedAbilities.Strings(),
+		DisabledAbilities:  r.DisabledAbilities.Strings(),
+		AbilityEligibility: eligibilityView(r.AbilityEligibility),
+		HandlerTimeoutMs:   r.HandlerTimeout.Milliseconds(),
+		IsolatePanics:      r.IsolatePanics || r.HandlerTimeout > 0,
+		ExtraRemovals:      r.ExtraRemovalBudget(),
+		DoOver:             doOverView{RewindPlies: r.DoOverRewindDepth(), TurnCost: r.DoOverTurnCost},
+		Charges: chargeRulesView{
+			Enabled: r.Charges.Enabled,
+			Start:   r.Charges.Start,
+			PerTurn: r.Charges.PerTurn,
+			Max:     r.Charges.Max,
+			Costs:   make(map[string]int),
+		},
+		Limits: limitsView{
+			Variants:            append([]string(nil), l.Variants...),
+			MaxHandlerTimeoutMs: l.MaxHandlerTimeout.Milliseconds(),
+			MinInitialMs:        l.MinInitial.Milliseconds(),
+			MaxInitialMs:        l.MaxInitial.Milliseconds(),
+			MaxIncrementMs:      l.MaxIncrement.Milliseconds(),
+			MaxCharges:          l.MaxCharges,
+			AllowBans:           l.AllowBans,
+		},
+	}
+	if out.Variant == "" {
+		out.Variant = game.VariantBattle
+	}
+	for _, t := range r.MovablePieceTypes() {
+		out.Turn.MovablePieces = append(out.Turn.MovablePieces, t.String())
+	}
+	if adj := r.Adjudication; adj.Enabled() {
+		out.Adjudication = &adjudicationBody{ThresholdCp: adj.Threshold, Plies: adj.Plies, Tablebase: adj.Tablebase}
+	}
+	out.TimeControl = timeControlView(r.TimeControl)
+	for id, cost := range r.Charges.EffectiveCosts() {
+		out.Charges.Costs[id.String()] = cost
+	}
+	if out.BannedAbilities == nil {
+		out.BannedAbilities = []string{}
+	}
+	if out.DisabledAbilities == nil {
+		out.DisabledAbilities = []string{}
+	}
+	sort.Strings(out.Limits.Variants)
+	return out
+}
+
+// handleRules reports the live rules of the default game, or of the managed
+// game named by ?game=.
+func (s *Server) handleRules(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	var rules game.RulesConfig
+	if id := r.URL.Query().Get("game"); id != "" {
+		g, ok := s.games.get(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		g.Do(func(eng *game.Engine) { rules = eng.Rules() })
+	} else {
+		s.engineMu.Lock()
+		rules = s.engine.Rules()
+		s.engineMu.Unlock()
+	}
+	writeJSON(w, liveRules(rules, s.games.limits))
+}
@@ -0,0 +1,489 @@
+// path: chessTest/internal/httpx/schema.go
+package httpx
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Clients pick a JSON profile with query parameters or an Accept profile:
+//
+//	?casing=snake_case&strict=1
+//	Accept: application/json; profile="snake_case strict"
+//
+// Casing renames object fields in responses, BoardState included, and lets
+// request bodies use the same names. Strict rejects request bodies that carry
+// fields the endpoint does not know, naming every one of them.
+
+type fieldCasing uint8
+
+const (
+	casingDefault fieldCasing = iota // field names as declared
+	casingSnake                      // status_reason, turn_started
+	casingCamel                      // statusReason, turnStarted
+)
+
+type jsonProfile struct {
+	casing fieldCasing
+	strict bool
+}
+
+type profileKey struct{}
+
+// profileWriter carries the request's profile to writeJSON and writeState.
+type profileWriter struct {
+	http.ResponseWriter
+	profile jsonProfile
+}
+
+func (w *profileWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+// requestProfile reads the profile from the query, falling back to the
+// Accept header's profile parameter.
+func requestProfile(r *http.Request) (jsonProfile, error) {
+	var p jsonProfile
+	var tokens []string
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || (mediaType != "application/json" && mediaType != "*/*") {
+			continue
+		}
+		tokens = append(tokens, strings.Fields(params["profile"])...)
+	}
+	q := r.URL.Query()
+	if v := q.Get("casing"); v != "" {
+		tokens = append(tokens, v)
+	}
+	for _, tok := range tokens {
+		switch strings.ToLower(strings.ReplaceAll(tok, "-", "_")) {
+		case "snake_case", "snake":
+			p.casing = casingSnake
+		case "camelcase", "camel":
+			p.casing = casingCamel
+		case "default", "go":
+			p.casing = casingDefault
+		case "strict":
+			p.strict = true
+		default:
+			return p, fmt.Errorf("unknown json profile %q; valid: snake_case, camelCase, default, strict", tok)
+		}
+	}
+	if v := q.Get("strict"); v != "" {
+		strict, err := strconv.ParseBool(v)
+		if err != nil {
+			return p, fmt.Errorf("invalid strict %q", v)
+		}
+		p.strict = strict
+	}
+	return p, nil
+}
+
+func profileOf(r *http.Request) jsonProfile {
+	p, _ := r.Context().Value(profileKey{}).(jsonProfile)
+	return p
+}
+
+func withProfile(r *http.Request, p jsonProfile) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), profileKey{}, p))
+}
+
+// ---- responses ----
+
+// profiled maps v to the casing the client asked for; v is returned unchanged
+// when w carries no casing.
+func profiled(w http.ResponseWriter, v any) any {
+	pw, ok := w.(*profileWriter)
+	if !ok || pw.profile.casing == casingDefault {
+		return v
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return v
+	}
+	return recase(tree, reflect.ValueOf(v), pw.profile.casing)
+}
+
+// recase renames the fields of tree, the decoded JSON of v, walking v so
+// that struct fields are renamed while data keys such as squares and colors
+// are not. Values that marshal themselves are left alone.
+func recase(tree any, v reflect.Value, casing fieldCasing) any {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return tree
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || marshalsItself(v.Type()) {
+		return tree
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		obj, ok := tree.(map[string]any)
+		if !ok {
+			return tree
+		}
+		out := make(orderedObject, 0, len(obj))
+		for _, f := range schemaFields(v.Type()) {
+			val, ok := obj[f.name]
+			if !ok {
+				continue
+			}
+			fv, err := v.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			out = append(out, objectField{applyCasing(f.name, casing), recase(val, fv, casing)})
+		}
+		return out
+	case reflect.Map:
+		obj, ok := tree.(map[string]any)
+		if !ok {
+			return tree
+		}
+		schemaKeys := v.Type().Key().Kind() == reflect.String &&
+			(v.Type().Elem().Kind() == reflect.Interface || v.Type().Elem().Kind() == reflect.String)
+		out := make(map[string]any, len(obj))
+		iter := v.MapRange()
+		for iter.Next() {
+			key, ok := mapKeyString(iter.Key())
+			if !ok {
+				continue
+			}
+			val, ok := obj[key]
+			if !ok {
+				continue
+			}
+			if schemaKeys {
+				key = applyCasing(key, casing)
+			}
+			out[key] = recase(val, iter.Value(), casing)
+		}
+		return out
+	case reflect.Slice, reflect.Array:
+		arr, ok := tree.([]any)
+		if !ok {
+			return tree
+		}
+		for i := range arr {
+			if i < v.Len() {
+				arr[i] = recase(arr[i], v.Index(i), casing)
+			}
+		}
+		return arr
+	default:
+		return tree
+	}
+}
+
+// orderedObject keeps struct fields in declaration order once renamed.
+type orderedObject []objectField
+
+type objectField struct {
+	name  string
+	value any
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func mapKeyString(k reflect.Value) (string, bool) {
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		return string(text), err == nil
+	}
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(k.Uint(), 10), true
+	}
+	return "", false
+}
+
+// ---- requests ----
+
+// unknownFieldsError lists every field of a strict request body that the
+// endpoint does not accept.
+type unknownFieldsError struct {
+	fields []unknownField
+}
+
+type unknownField struct {
+	path    string
+	allowed []string
+}
+
+func (e *unknownFieldsError) Error() string {
+	parts := make([]string, len(e.fields))
+	for i, f := range e.fields {
+		parts[i] = fmt.Sprintf("%s (expected one of: %s)", f.path, strings.Join(f.allowed, ", "))
+	}
+	return "unknown fields: " + strings.Join(parts, "; ")
+}
+
+// decodeBody decodes the request body into v. Under a casing profile, field
+// names in that casing are accepted; under strict, unknown fields are
+// rejected with an *unknownFieldsError.
+func decodeBody(r *http.Request, v any) error {
+	p := profileOf(r)
+	if !p.strict && p.casing == casingDefault {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	var unknown []unknownField
+	tree = uncase(tree, reflect.TypeOf(v), "", p.casing, &unknown)
+	if p.strict && len(unknown) > 0 {
+		return &unknownFieldsError{fields: unknown}
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeDecodeError reports a decodeBody failure.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var unknown *unknownFieldsError
+	switch {
+	case isBodyTooLarge(err):
+		writeError(w, http.StatusRequestEntityTooLarge, "request too large")
+	case errors.As(err, &unknown):
+		writeError(w, http.StatusBadRequest, unknown.Error())
+	default:
+		writeError(w, http.StatusBadRequest, "invalid json")
+	}
+}
+
+// uncase maps the fields of tree back to the names declared on t, recording
+// the fields t has no place for.
+func uncase(tree any, t reflect.Type, path string, casing fieldCasing, unknown *[]unknownField) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if unmarshalsItself(t) {
+		return tree
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := tree.(map[string]any)
+		if !ok {
+			return tree
+		}
+		fields := schemaFields(t)
+		byKey := make(map[string]schemaField, len(fields))
+		for _, f := range fields {
+			byKey[foldName(f.name)] = f
+		}
+		out := make(map[string]any, len(obj))
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			f, ok := byKey[foldName(key)]
+			if !ok {
+				allowed := make([]string, len(fields))
+				for i, f := range fields {
+					allowed[i] = applyCasing(f.name, casing)
+				}
+				*unknown = append(*unknown, unknownField{path: path + key, allowed: allowed})
+				continue
+			}
+			out[f.name] = uncase(obj[key], f.typ, path+key+".", casing, unknown)
+		}
+		return out
+	case reflect.Map:
+		obj, ok := tree.(map[string]any)
+		if !ok {
+			return tree
+		}
+		for key, val := range obj {
+			obj[key] = uncase(val, t.Elem(), path+key+".", casing, unknown)
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		arr, ok := tree.([]any)
+		if !ok {
+			return tree
+		}
+		for i := range arr {
+			arr[i] = uncase(arr[i], t.Elem(), fmt.Sprintf("%s%d.", path, i), casing, unknown)
+		}
+		return arr
+	default:
+		return tree
+	}
+}
+
+// foldName matches field names across casings: "turn_started",
+// "turnStarted" and "TurnStarted" all fold to "turnstarted".
+func foldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// ---- schema ----
+
+type schemaField struct {
+	name  string
+	index []int
+	typ   reflect.Type
+}
+
+var schemaCache sync.Map // reflect.Type -> []schemaField
+
+// schemaFields lists the JSON fields of struct type t in declaration order,
+// following encoding/json's naming and embedding rules.
+func schemaFields(t reflect.Type) []schemaField {
+	if cached, ok := schemaCache.Load(t); ok {
+		return cached.([]schemaField)
+	}
+	var out []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := sf.Type
+		if sf.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, inner := range schemaFields(ft) {
+					inner.index = append([]int{i}, inner.index...)
+					out = append(out, inner)
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		out = append(out, schemaField{name: name, index: []int{i}, typ: sf.Type})
+	}
+	schemaCache.Store(t, out)
+	return out
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func marshalsItself(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+func unmarshalsItself(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+// applyCasing renders a declared field name in casing. Acronyms stay whole:
+// PieceID becomes piece_id or pieceID.
+func applyCasing(name string, casing fieldCasing) string {
+	switch casing {
+	case casingSnake:
+		return strings.Join(splitWords(name), "_")
+	case casingCamel:
+		words := splitWords(name)
+		var b strings.Builder
+		for i, w := range words {
+			if i > 0 && len(w) > 0 {
+				if isAcronym(name, w) {
+					w = strings.ToUpper(w)
+				} else {
+					w = strings.ToUpper(w[:1]) + w[1:]
+				}
+			}
+			b.WriteString(w)
+		}
+		return b.String()
+	default:
+		return name
+	}
+}
+
+// splitWords breaks a Go or camelCase identifier into lower-case words.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+			unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) ||
+			cur == '_'
+		if boundary {
+			if w := strings.Trim(string(runes[start:i]), "_"); w != "" {
+				words = append(words, strings.ToLower(w))
+			}
+			start = i
+		}
+	}
+	if w := strings.Trim(string(runes[start:]), "_"); w != "" {
+		words = append(words, strings.ToLower(w))
+	}
+	return words
+}
+
+// isAcronym reports whether word appeared fully upper-case in name.
+func isAcronym(name, word string) bool {
+	return len(word) > 1 && strings.Contains(name, strings.ToUpper(word))
+}
@@ -0,0 +1,126 @@
+// path: chessTest/internal/httpx/schema_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestApplyCasing(t *testing.T) {
+	cases := []struct {
+		name, snake, camel string
+	}{
+		{"StatusReason", "status_reason", "statusReason"},
+		{"turnStarted", "turn_started", "turnStarted"},
+		{"PieceID", "piece_id", "pieceID"},
+		{"ID", "id", "id"},
+		{"HTTPTimeoutMs", "http_timeout_ms", "httpTimeoutMs"},
+		{"state", "state", "state"},
+	}
+	for _, tc := range cases {
+		if got := applyCasing(tc.name, casingSnake); got != tc.snake {
+			t.Fatalf("snake %s = %q want %q", tc.name, got, tc.snake)
+		}
+		if got := applyCasing(tc.name, casingCamel); got != tc.camel {
+			t.Fatalf("camel %s = %q want %q", tc.name, got, tc.camel)
+		}
+	}
+}
+
+func TestJSONProfileCasing(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	cases := []struct {
+		name       string
+		target     string
+		accept     string
+		wantStatus int
+		wantKeys   []string
+		wantAbsent []string
+	}{
+		{name: "default", target: "/api/state", wantStatus: http.StatusOK, wantKeys: []string{`"LastNote"`, `"Pieces"`}},
+		{name: "snake query", target: "/api/state?casing=snake_case", wantStatus: http.StatusOK, wantKeys: []string{`"last_note"`, `"pieces"`, `"square"`}, wantAbsent: []string{`"LastNote"`}},
+		{name: "camel accept profile", target: "/api/state", accept: `application/json; profile="camelCase"`, wantStatus: http.StatusOK, wantKeys: []string{`"lastNote"`, `"seq"`}},
+		{name: "unknown casing", target: "/api/state?casing=kebab", wantStatus: http.StatusBadRequest, wantKeys: []string{"unknown json profile"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			if tc.accept != "" {
+				req.Header.Set("Accept", tc.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			body := rr.Body.String()
+			for _, key := range tc.wantKeys {
+				if !strings.Contains(body, key) {
+					t.Fatalf("expected %s in %s", key, body)
+				}
+			}
+			for _, key := range tc.wantAbsent {
+				if strings.Contains(body, key) {
+					t.Fatalf("unexpected %s in %s", key, body)
+				}
+			}
+		})
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	cases := []struct {
+		name       string
+		target     string
+		body       string
+		wantStatus int
+		wantError  []string
+	}{
+		{name: "lenient by default", target: "/api/config", body: `{"color":"white","abilities":["DoOver"],"element":"fire","colour":"x"}`, wantStatus: http.StatusOK},
+		{name: "strict lists every unknown field", target: "/api/config?strict=1", body: `{"colour":"white","abilities":["DoOver"],"elem":"fire"}`, wantStatus: http.StatusBadRequest, wantError: []string{"colour", "elem", "expected one of: color, abilities, element"}},
+		{name: "strict nested", target: "/api/games?strict=true", body: `{"rules":{"timeControl":{"initialMs":60000,"bonusMs":1}}}`, wantStatus: http.StatusBadRequest, wantError: []string{"rules.timeControl.bonusMs", "initialMs, incrementMs"}},
+		{name: "strict snake names", target: "/api/games?casing=snake&strict=1", body: `{"rules":{"time_control":{"initial_ms":60000},"banned_abilities":["DoOver"]}}`, wantStatus: http.StatusCreated},
+		{name: "strict snake reports snake names", target: "/api/games?casing=snake&strict=1", body: `{"rules":{"time_limit":1}}`, wantStatus: http.StatusBadRequest, wantError: []string{"rules.time_limit", "time_control", "banned_abilities"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.target, strings.NewReader(tc.body)))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			var payload struct {
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			for _, want := range tc.wantError {
+				if !strings.Contains(payload.Error, want) {
+					t.Fatalf("error %q missing %q", payload.Error, want)
+				}
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games?casing=snake", strings.NewReader(`{"rules":{"time_control":{"initial_ms":60000}}}`)))
+	var created struct {
+		Rules struct {
+			TimeControl *struct {
+				InitialMs int64 `json:"initial_ms"`
+			} `json:"time_control"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.Rules.TimeControl == nil || created.Rules.TimeControl.InitialMs != 60000 {
+		t.Fatalf("snake round trip failed: %s (%v)", rr.Body.String(), err)
+	}
+}
@@ -0,0 +1,366 @@
+// path: chessTest/internal/httpx/seats.go
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/controller"
+	"battle_chess_poc/internal/game"
+)
+
+// Seat statuses reported by /api/heartbeat and the seat event stream.
+const (
+	SeatActive = "active"
+	SeatIdle   = "idle"
+	// SeatForfeited means the seat stayed idle past SeatPolicy.ForfeitAfter
+	// and its side lost the game.
+	SeatForfeited = "forfeited"
+)
+
+// SeatPolicy decides when a seat that stopped sending heartbeats goes idle,
+// and whether it then forfeits.
+type SeatPolicy struct {
+	// IdleAfter is how long a seat may go without a heartbeat before it is
+	// marked idle.
+	IdleAfter time.Duration
+	// ForfeitAfter, when set, ends the game as a loss for a seat that has
+	// gone that long without a heartbeat. Zero never forfeits.
+	ForfeitAfter time.Duration
+}
+
+// DefaultSeatPolicy marks seats idle after 30 seconds and never forfeits.
+func DefaultSeatPolicy() SeatPolicy {
+	return SeatPolicy{IdleAfter: 30 * time.Second}
+}
+
+// errSeatHeld refuses a player acting for a seat another player holds.
+var errSeatHeld = errors.New("seat held by another player")
+
+type seatKey struct {
+	game  string
+	color game.Color
+}
+
+type seat struct {
+	subject  string
+	status   string
+	lastSeen time.Time
+	// expired is set once the seat passes ForfeitAfter, so the forfeit is
+	// tried only once per absence.
+	expired bool
+}
+
+// seatTracker follows heartbeats per game and color. Only seats that have
+// sent a heartbeat are tracked. The zero value tracks seats but never marks
+// them idle.
+type seatTracker struct {
+	mu     sync.Mutex
+	policy SeatPolicy
+	seats  map[seatKey]*seat
+	stop   chan struct{}
+}
+
+// SetSeatPolicy replaces the seat policy and checks seats against it every
+// quarter of IdleAfter, or of ForfeitAfter when seats never idle, until
+// Close.
+func (s *Server) SetSeatPolicy(policy SeatPolicy) {
+	t := &s.seats
+	t.mu.Lock()
+	t.policy = policy
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+	every := policy.IdleAfter
+	if every <= 0 || (policy.ForfeitAfter > 0 && policy.ForfeitAfter < every) {
+		every = policy.ForfeitAfter
+	}
+	if every <= 0 {
+		t.mu.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	t.stop = stop
+	t.mu.Unlock()
+	go func() {
+		ticker := time.NewTicker(max(every/4, 10*time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.sweepSeats(now)
+			}
+		}
+	}()
+}
+
+func (t *seatTracker) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		close(t.stop)
+		t.stop = nil
+	}
+}
+
+// beat records a heartbeat and reports whether the seat's status changed. A
+// seat another subject keeps active is not taken over; ok is false then.
+func (t *seatTracker) beat(key seatKey, subject string, now time.Time) (status bus.SeatStatus, changed, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seats == nil {
+		t.seats = make(map[seatKey]*seat)
+	}
+	st, held := t.seats[key]
+	if !held {
+		st = &seat{}
+		t.seats[key] = st
+	}
+	if st.subject != "" && st.subject != subject && st.status == SeatActive {
+		return bus.SeatStatus{}, false, false
+	}
+	changed = st.status != SeatActive
+	st.subject, st.status, st.lastSeen, st.expired = subject, SeatActive, now, false
+	return bus.SeatStatus{Color: key.color, Status: SeatActive, LastSeen: now}, changed, true
+}
+
+// seatChange is a seat a sweep found gone quiet.
+type seatChange struct {
+	key    seatKey
+	status bus.SeatStatus
+	// idled: the seat just went idle. expired: it just passed ForfeitAfter.
+	idled, expired bool
+}
+
+// due marks seats idle as of now and returns those that went idle or expired.
+func (t *seatTracker) due(now time.Time) []seatChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []seatChange
+	for key, st := range t.seats {
+		if st.expired {
+			continue
+		}
+		quiet := now.Sub(st.lastSeen)
+		ch := seatChange{key: key}
+		if t.policy.ForfeitAfter > 0 && quiet >= t.policy.ForfeitAfter {
+			st.expired, ch.expired = true, true
+		}
+		if st.status == SeatActive && t.policy.IdleAfter > 0 && quiet >= t.policy.IdleAfter {
+			st.status, ch.idled = SeatIdle, true
+		}
+		if ch.idled || ch.expired {
+			ch.status = bus.SeatStatus{Color: key.color, Status: st.status, LastSeen: st.lastSeen}
+			out = append(out, ch)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].key.game != out[j].key.game {
+			return out[i].key.game < out[j].key.game
+		}
+		return out[i].key.color < out[j].key.color
+	})
+	return out
+}
+
+// forfeited records that an expired seat's side lost the game.
+func (t *seatTracker) forfeited(key seatKey) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.seats[key]; ok && st.expired {
+		st.status = SeatForfeited
+	}
+}
+
+// holder is the subject behind key's latest heartbeat, if any.
+func (t *seatTracker) holder(key seatKey) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.seats[key]; ok {
+		return st.subject
+	}
+	return ""
+}
+
+// mayPlay reports whether subject may move for key's side: the seat is
+// subject's, or nobody holds it and subject does not hold the other side.
+func (t *seatTracker) mayPlay(key seatKey, subject string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if st, ok := t.seats[key]; ok && st.subject != "" {
+		return st.subject == subject
+	}
+	st, ok := t.seats[seatKey{game: key.game, color: key.color.Opposite()}]
+	return !ok || st.subject != subject
+}
+
+// seatGuard is the check serveMove runs against the side to move: nil when
+// the request carries no identity, as on a server without authentication and
+// without a share link.
+func (s *Server) seatGuard(r *http.Request, id string) func(game.Color) error {
+	ident, ok := IdentityFrom(r.Context())
+	if !ok {
+		return nil
+	}
+	return func(color game.Color) error {
+		if err := shareScope(r, id, color); err != nil {
+			return err
+		}
+		if !s.seats.mayPlay(seatKey{game: id, color: color}, ident.Subject) {
+			return errSeatHeld
+		}
+		return nil
+	}
+}
+
+func (t *seatTracker) view(id string) []seatView {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := []seatView{}
+	for _, color := range []game.Color{game.White, game.Black} {
+		if st, ok := t.seats[seatKey{game: id, color: color}]; ok {
+			out = append(out, seatView{Color: color.String(), Subject: st.subject, Status: st.status, LastSeen: st.lastSeen})
+		}
+	}
+	return out
+}
+
+// sweepSeats applies the seat policy as of now: it announces seats that went
+// idle and forfeits the active game of seats that stayed away too long.
+func (s *Server) sweepSeats(now time.Time) {
+	for _, ch := range s.seats.due(now) {
+		if ch.expired && s.forfeitSeat(ch.key) {
+			s.seats.forfeited(ch.key)
+			ch.status.Status = SeatForfeited
+		} else if !ch.idled {
+			continue
+		}
+		s.publishSeat(ch.key.game, ch.status)
+	}
+}
+
+// forfeitSeat ends key's game as a loss for the seat's side and reports
+// whether it did; a game that is already over is left alone.
+func (s *Server) forfeitSeat(key seatKey) bool {
+	g, ok := s.gameByID(key.game)
+	if !ok {
+		return false
+	}
+	_, err := g.Update(func(eng *game.Engine) error {
+		return eng.Forfeit(key.color, key.color.String()+" stopped responding")
+	}, game.StateOptions{})
+	return err == nil
+}
+
+// lookupGame finds the engine behind a game id and the lock guarding it.
+func (s *Server) lookupGame(id string) (*sync.Mutex, *game.Engine, bool) {
+	g, ok := s.gameByID(id)
+	if !ok {
+		return nil, nil, false
+	}
+	return g.Lock(), g.Engine(), true
+}
+
+// gameByID finds a game by id: the default game, attached to the server's
+// own engine and store, or a managed one.
+func (s *Server) gameByID(id string) (*controller.Game, bool) {
+	if id == DefaultGameID {
+		return s.defaultGame(), true
+	}
+	if s.games == nil {
+		return nil, false
+	}
+	return s.games.get(id)
+}
+
+// defaultGame is the unscoped engine as a controller.Game.
+func (s *Server) defaultGame() *controller.Game {
+	return controller.Attach(DefaultGameID, &s.engineMu, s.engine, s.store)
+}
+
+func (s *Server) publishSeat(id string, st bus.SeatStatus) {
+	if s.bus != nil {
+		s.bus.Publish(bus.Message{Game: id, Seat: &st})
+	}
+}
+
+// ---- API: heartbeat ----
+
+type heartbeatBody struct {
+	// Game is a managed game id; empty means the default game.
+	Game  string `json:"game,omitempty"`
+	Color string `json:"color"`
+}
+
+type seatView struct {
+	Color    string    `json:"color"`
+	Subject  string    `json:"subject,omitempty"`
+	Status   string    `json:"status"`
+	LastSeen time.Time `json:"lastSeen"`
+	// Hints is the seat's advisor hint allowance when its game has a quota.
+	Hints *hintView `json:"hints,omitempty"`
+}
+
+// handleHeartbeat keeps a seat active with POST and lists a game's seats with
+// GET (?game=<id>, default game when absent). A seat that comes back from
+// idle is announced to the game's event stream.
+func (s *Server) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		id := r.URL.Query().Get("game")
+		if id == "" {
+			id = DefaultGameID
+		}
+		if _, _, ok := s.lookupGame(id); !ok {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		writeJSON(w, map[string]any{"seats": s.withHints(id, s.seats.view(id))})
+	case http.MethodPost:
+		defer r.Body.Close()
+		var body heartbeatBody
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		id := body.Game
+		if id == "" {
+			id = DefaultGameID
+		}
+		color, ok := game.ParseColor(body.Color)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid color")
+			return
+		}
+		if _, _, ok := s.lookupGame(id); !ok {
+			writeError(w, http.StatusNotFound, "game not found")
+			return
+		}
+		if err := shareScope(r, id, color); err != nil {
+			writeError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		var subject string
+		if ident, ok := IdentityFrom(r.Context()); ok {
+			subject = ident.Subject
+		}
+		st, changed, ok := s.seats.beat(seatKey{game: id, color: color}, subject, time.Now())
+		if !ok {
+			writeError(w, http.StatusForbidden, errSeatHeld.Error())
+			return
+		}
+		if changed {
+			s.publishSeat(id, st)
+		}
+		writeJSON(w, map[string]any{"seats": s.withHints(id, s.seats.view(id))})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
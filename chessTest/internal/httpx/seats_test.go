@@ -0,0 +1,161 @@
+// path: chessTest/internal/httpx/seats_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func newSeatServer(t *testing.T, policy SeatPolicy) (*Server, *bus.Subscription) {
+	t.Helper()
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	b := bus.New()
+	srv.SetBus(b)
+	srv.SetSeatPolicy(policy)
+	sub := b.Subscribe(DefaultGameID, 16)
+	t.Cleanup(func() {
+		srv.seats.close()
+		b.Close()
+	})
+	return srv, sub
+}
+
+func heartbeat(t *testing.T, srv *Server, body string) (int, []seatView) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/heartbeat", strings.NewReader(body)))
+	var out struct {
+		Seats []seatView `json:"seats"`
+	}
+	if rec.Code == http.StatusOK {
+		if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	return rec.Code, out.Seats
+}
+
+// nextSeat returns the next seat message on sub, skipping game events.
+func nextSeat(t *testing.T, sub *bus.Subscription) bus.SeatStatus {
+	t.Helper()
+	for {
+		select {
+		case msg := <-sub.C:
+			if msg.Seat != nil {
+				return *msg.Seat
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no seat message")
+		}
+	}
+}
+
+func TestHeartbeatIdleAndReturn(t *testing.T) {
+	srv, sub := newSeatServer(t, SeatPolicy{IdleAfter: time.Hour})
+	code, seats := heartbeat(t, srv, `{"color":"white"}`)
+	if code != http.StatusOK || len(seats) != 1 || seats[0].Status != SeatActive {
+		t.Fatalf("heartbeat = %d %+v", code, seats)
+	}
+	if st := nextSeat(t, sub); st.Color != game.White || st.Status != SeatActive {
+		t.Fatalf("seat message = %+v", st)
+	}
+
+	seen := seats[0].LastSeen
+	srv.sweepSeats(seen.Add(30 * time.Minute))
+	srv.sweepSeats(seen.Add(time.Hour))
+	if st := nextSeat(t, sub); st.Status != SeatIdle {
+		t.Fatalf("seat message = %+v, want idle", st)
+	}
+	srv.sweepSeats(seen.Add(2 * time.Hour))
+
+	if _, seats = heartbeat(t, srv, `{"color":"white"}`); seats[0].Status != SeatActive {
+		t.Fatalf("seat after return = %+v", seats)
+	}
+	if st := nextSeat(t, sub); st.Status != SeatActive {
+		t.Fatalf("seat message = %+v, want active", st)
+	}
+	select {
+	case msg := <-sub.C:
+		t.Fatalf("unexpected message %+v", msg)
+	default:
+	}
+}
+
+func TestHeartbeatForfeit(t *testing.T) {
+	srv, sub := newSeatServer(t, SeatPolicy{IdleAfter: time.Hour, ForfeitAfter: 2 * time.Hour})
+	_, seats := heartbeat(t, srv, `{"color":"b"}`)
+	nextSeat(t, sub)
+	seen := seats[0].LastSeen
+
+	srv.sweepSeats(seen.Add(2 * time.Hour))
+	if st := nextSeat(t, sub); st.Color != game.Black || st.Status != SeatForfeited {
+		t.Fatalf("seat message = %+v, want black forfeited", st)
+	}
+	state := srv.engine.State()
+	if state.Status != game.StatusWhiteWins || state.StatusReason != "black stopped responding" {
+		t.Fatalf("status = %v %q", state.Status, state.StatusReason)
+	}
+
+	// A second absence in a finished game goes idle but forfeits nothing.
+	_, seats = heartbeat(t, srv, `{"color":"black"}`)
+	nextSeat(t, sub)
+	srv.sweepSeats(seats[0].LastSeen.Add(3 * time.Hour))
+	if st := nextSeat(t, sub); st.Status != SeatIdle {
+		t.Fatalf("seat message = %+v, want idle", st)
+	}
+}
+
+func TestHeartbeatRejects(t *testing.T) {
+	srv, _ := newSeatServer(t, SeatPolicy{})
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"color":"green"}`, http.StatusBadRequest},
+		{`{"color":"white","game":"nope"}`, http.StatusNotFound},
+		{`{"colour":"white"}`, http.StatusBadRequest},
+	} {
+		if code, _ := heartbeat(t, srv, tc.body); code != tc.want {
+			t.Fatalf("%s: status %d want %d", tc.body, code, tc.want)
+		}
+	}
+}
+
+func TestMovesNeedTheSeat(t *testing.T) {
+	srv, _ := newSeatServer(t, SeatPolicy{})
+	srv.SetAuthenticator(StaticTokens{
+		"alice": {Subject: "alice", Roles: []string{RolePlayer}},
+		"bob":   {Subject: "bob", Roles: []string{RolePlayer}},
+		"carol": {Subject: "carol", Roles: []string{RolePlayer}},
+	})
+	handler := srv.routes()
+	for _, st := range []struct {
+		name, token, path, body string
+		want                    int
+	}{
+		{"alice takes white", "alice", "/api/heartbeat", `{"color":"white"}`, http.StatusOK},
+		{"alice moves for open black", "alice", "/api/move", `{"from":"e2","to":"e4"}`, http.StatusOK},
+		{"alice cannot move black", "alice", "/api/move", `{"from":"e7","to":"e5"}`, http.StatusForbidden},
+		{"bob cannot take white", "bob", "/api/heartbeat", `{"color":"white"}`, http.StatusForbidden},
+		{"bob takes black", "bob", "/api/heartbeat", `{"color":"black"}`, http.StatusOK},
+		{"carol cannot move black", "carol", "/api/move", `{"from":"e7","to":"e5"}`, http.StatusForbidden},
+		{"bob moves black", "bob", "/api/move", `{"from":"e7","to":"e5"}`, http.StatusOK},
+		{"bob cannot move white", "bob", "/api/move", `{"from":"d2","to":"d4"}`, http.StatusForbidden},
+		{"alice moves white", "alice", "/api/move", `{"from":"d2","to":"d4"}`, http.StatusOK},
+	} {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, st.path, strings.NewReader(st.body))
+		req.Header.Set("Authorization", "Bearer "+st.token)
+		handler.ServeHTTP(rr, req)
+		if rr.Code != st.want {
+			t.Fatalf("%s: status %d want %d: %s", st.name, rr.Code, st.want, rr.Body.String())
+		}
+	}
+}
@@ -2,18 +2,27 @@
 package httpx
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/controller"
 	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/notify"
+	"battle_chess_poc/internal/persist"
+	"battle_chess_poc/internal/protocol"
+	"battle_chess_poc/internal/telemetry"
 )
 
 // Server wires the HTTP layer to the chess engine and templates.
@@ -25,6 +34,31 @@ type Server struct {
 	elements  []string
 	srvMu     sync.Mutex
 	srv       *http.Server
+	auth      Authenticator
+	games     *GameManager
+	store     *persist.Store
+	bus       *bus.Bus
+	metrics   *eventMetrics
+	chaos     *chaos
+	seats     seatTracker
+	telemetry *telemetry.Reporter
+	notifier  *notify.Notifier
+	// clockSeq numbers /api/clock/sync responses.
+	clockSeq atomic.Uint64
+	// handlerStats times ability handlers; see SetHandlerMetrics.
+	handlerStats *handlerStats
+	// shareKey signs share links; see SetShareKey.
+	shareKey []byte
+
+	tutorialMu sync.Mutex
+	tutorial   *game.Tutorial
+
+	puzzleMu       sync.Mutex
+	puzzles        *game.PuzzlePack
+	puzzleProgress map[string]map[string]*puzzleProgress // profile -> puzzle id
+
+	prefsMu     sync.Mutex
+	notifyPrefs map[string]notify.Preferences // token subject
 }
 
 const (
@@ -43,7 +77,11 @@ func NewServer(engine *game.Engine) *Server {
 		tmpl:      t,
 		abilities: abilityNames(),
 		elements:  elementNames(),
+		games:     NewGameManager(DefaultRulesLimits()),
+		shareKey:  newShareKey(),
 	}
+	s.SetBus(bus.New())
+	s.SetSeatPolicy(DefaultSeatPolicy())
 	return s
 }
 
@@ -76,8 +114,19 @@ func (s *Server) Listen(addr string) error {
 	return nil
 }
 
-// Close attempts a graceful shutdown of the HTTP server.
+// Close attempts a graceful shutdown of the HTTP server. Event streams are
+// ended first so they do not hold the shutdown open.
 func (s *Server) Close(ctx context.Context) error {
+	s.seats.close()
+	if s.bus != nil {
+		s.bus.Close()
+	}
+	if s.telemetry != nil {
+		s.telemetry.Close()
+	}
+	if s.notifier != nil {
+		s.notifier.Close()
+	}
 	s.srvMu.Lock()
 	srv := s.srv
 	s.srvMu.Unlock()
@@ -94,9 +143,43 @@ func (s *Server) routes() http.Handler {
 
 	// JSON APIs
 	mux.HandleFunc("/api/state", s.withJSON(s.handleState))
-	mux.HandleFunc("/api/move", s.withJSON(s.handleMove))
-	mux.HandleFunc("/api/config", s.withJSON(s.handleConfig))
-	mux.HandleFunc("/api/reset", s.withJSON(s.handleReset)) // <-- NEW
+	mux.HandleFunc("/api/move", s.withJSON(s.seated(s.handleMove)))
+	mux.HandleFunc("/api/config", s.withJSON(s.seated(s.handleConfig)))
+	mux.HandleFunc("/api/config/all", s.withJSON(s.seated(s.handleConfigAll)))
+	mux.HandleFunc("/api/reset", s.withJSON(s.authorize(RoleAdmin, s.handleReset))) // <-- NEW
+	mux.HandleFunc("/api/history", s.withJSON(s.handleHistory))
+	mux.HandleFunc("/api/rules", s.withJSON(s.handleRules))
+	mux.HandleFunc("/api/clock/sync", s.withJSON(s.handleClockSync))
+	mux.HandleFunc("/api/debug/resolution-order", s.withJSON(s.handleResolutionOrder))
+	mux.HandleFunc("/api/events", s.handleEvents)
+	mux.HandleFunc("/api/metrics", s.withJSON(s.authorize(RoleAdmin, s.handleMetrics)))
+	mux.HandleFunc("/api/export", s.authorize(RoleAdmin, s.handleExport))
+	mux.HandleFunc("/api/heartbeat", s.withJSON(s.seated(s.handleHeartbeat)))
+	mux.HandleFunc("/api/session", s.withJSON(s.authorize(RoleAdmin, s.handleSession)))
+	mux.HandleFunc("/api/piece/{id}", s.withJSON(s.handlePiece))
+	mux.HandleFunc("/api/highlights", s.withJSON(s.handleHighlights))
+	mux.HandleFunc("/api/validate", s.withJSON(s.handleValidate))
+	mux.HandleFunc("/api/tutorial", s.withJSON(s.handleTutorial))
+	mux.HandleFunc("/api/tutorial/move", s.withJSON(s.authorize(RolePlayer, s.handleTutorialMove)))
+	mux.HandleFunc("/api/tutorial/restart", s.withJSON(s.authorize(RolePlayer, s.handleTutorialRestart)))
+	mux.HandleFunc("/api/puzzles", s.withJSON(s.authorize(RolePlayer, s.handlePuzzles)))
+	mux.HandleFunc("/api/puzzles/{id}", s.withJSON(s.authorize(RolePlayer, s.handlePuzzle)))
+	mux.HandleFunc("/api/puzzles/{id}/solve", s.withJSON(s.authorize(RolePlayer, s.handlePuzzleSolve)))
+	mux.HandleFunc("/api/flags", s.withJSON(s.authorize(RoleAdmin, s.handleFlags)))
+	mux.HandleFunc("/api/flags/{ability}", s.withJSON(s.authorize(RoleAdmin, s.handleDeleteFlag)))
+	mux.HandleFunc("/api/presets", s.withJSON(s.handlePresets))
+	mux.HandleFunc("/api/profile/notifications", s.withJSON(s.authorize(RolePlayer, s.handleNotifyPrefs)))
+	mux.HandleFunc("/api/games", s.withJSON(s.authorize(RolePlayer, s.handleCreateGame)))
+	mux.HandleFunc("/api/games/{id}", s.withJSON(s.handleGame))
+	mux.HandleFunc("/api/games/{id}/move", s.withJSON(s.seated(s.handleGameMove)))
+	mux.HandleFunc("/api/games/{id}/config", s.withJSON(s.seated(s.handleGameConfig)))
+	mux.HandleFunc("/api/games/{id}/config/all", s.withJSON(s.seated(s.handleGameConfigAll)))
+	mux.HandleFunc("/api/games/{id}/draw-assessment", s.withJSON(s.handleDrawAssessment))
+	mux.HandleFunc("/api/games/{id}/advisors", s.withJSON(s.authorize(RolePlayer, s.handleAdvisors)))
+	mux.HandleFunc("/api/games/{id}/suggestions", s.withJSON(s.handleSuggestions))
+	mux.HandleFunc("/api/games/{id}/sparring", s.withJSON(s.authorize(RolePlayer, s.handleSparring)))
+	mux.HandleFunc("/api/games/{id}/share", s.withJSON(s.authorize(RolePlayer, s.handleShare)))
+	mux.HandleFunc("/g/{token}", s.handleShareLink)
 
 	// Static assets under /static/
 	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))))
@@ -106,26 +189,40 @@ func (s *Server) routes() http.Handler {
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
+	if s.chaos != nil {
+		return s.chaos.wrap(mux)
+	}
 	return mux
 }
 
 // ---- UI ----
 
 func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
-	applyHTMLSecurityHeaders(w.Header())
 	// Build initial payload embedding current engine state and option lists.
 	s.engineMu.Lock()
 	state := s.engine.State()
 	s.engineMu.Unlock()
-	init := struct {
-		State     game.BoardState `json:"state"`
-		Abilities []string        `json:"abilities"`
-		Elements  []string        `json:"elements"`
-	}{
-		State:     state,
-		Abilities: s.abilities,
-		Elements:  s.elements,
-	}
+	s.renderIndex(w, indexInit{State: state})
+}
+
+// indexInit is the payload app.js reads from the page. Game and Role are set
+// when the page was opened from a share link; the UI then plays that game
+// under /api/games/{game} instead of the default one.
+type indexInit struct {
+	State     game.BoardState `json:"state"`
+	Abilities []string        `json:"abilities"`
+	Elements  []string        `json:"elements"`
+	Game      string          `json:"game,omitempty"`
+	Role      string          `json:"role,omitempty"`
+	// Token is the share token the page was opened with; the page sends it
+	// back as its credential.
+	Token string `json:"token,omitempty"`
+}
+
+func (s *Server) renderIndex(w http.ResponseWriter, init indexInit) {
+	applyHTMLSecurityHeaders(w.Header())
+	init.Abilities = s.abilities
+	init.Elements = s.elements
 	data := map[string]any{
 		"Init": mustJSON(init),
 	}
@@ -146,14 +243,19 @@ func (s *Server) withJSON(h func(http.ResponseWriter, *http.Request)) http.Handl
 		if r.Body != nil && r.Body != http.NoBody {
 			r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
 		}
-		h(w, r)
+		profile, err := requestProfile(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h(&profileWriter{ResponseWriter: w, profile: profile}, withProfile(r, profile))
 	}
 }
 
 func writeJSON(w http.ResponseWriter, v any) {
 	enc := json.NewEncoder(w)
 	enc.SetEscapeHTML(false)
-	_ = enc.Encode(v)
+	_ = enc.Encode(profiled(w, v))
 }
 
 func writeError(w http.ResponseWriter, status int, msg string) {
@@ -161,6 +263,17 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, map[string]string{"error": msg})
 }
 
+// writeExplainedError adds a coach-mode explanation to the error body when
+// one is available.
+func writeExplainedError(w http.ResponseWriter, status int, msg, explanation string) {
+	if explanation == "" {
+		writeError(w, status, msg)
+		return
+	}
+	w.WriteHeader(status)
+	writeJSON(w, map[string]string{"error": msg, "explanation": explanation})
+}
+
 func mustJSON(v any) template.JS {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -193,78 +306,333 @@ func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
 	s.engineMu.Lock()
-	state := s.engine.State()
+	state := s.engine.State(opts)
 	s.engineMu.Unlock()
-	writeJSON(w, map[string]any{"state": state})
+	writeState(w, r, state, opts)
+}
+
+// writeState sends state as JSON, or in the binary encoding when the client's
+// Accept header asks for it, gzip-compressing either when accepted.
+func writeState(w http.ResponseWriter, r *http.Request, state game.BoardState, opts game.StateOptions) {
+	h := w.Header()
+	h.Add("Vary", "Accept")
+	h.Add("Vary", "Accept-Encoding")
+	var out io.Writer = w
+	if acceptsGzip(r.Header.Get("Accept-Encoding")) {
+		h.Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	}
+	if protocol.Accepts(r.Header.Get("Accept")) {
+		h.Set("Content-Type", protocol.ContentType)
+		_, _ = out.Write(protocol.EncodeState(state))
+		return
+	}
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(profiled(w, map[string]any{"state": stateView(state, opts)}))
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// stateOptions reads the optional ?detail=summary|full query parameter.
+func stateOptions(r *http.Request) (game.StateOptions, bool) {
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("detail"))) {
+	case "", "full":
+		return game.StateOptions{Detail: game.StateFull}, true
+	case "summary":
+		return game.StateOptions{Detail: game.StateSummary}, true
+	default:
+		return game.StateOptions{}, false
+	}
+}
+
+// stateView is the state a JSON response carries: all of it, or only its
+// BoardSummary when opts asked for a summary.
+func stateView(state game.BoardState, opts game.StateOptions) any {
+	if opts.Detail == game.StateSummary {
+		return state.Summary()
+	}
+	return state
+}
+
+// coachRequested reports whether the client opted into coach mode with
+// ?coach=1 (or true/on).
+func coachRequested(r *http.Request) bool {
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("coach"))) {
+	case "1", "true", "on", "yes":
+		return true
+	default:
+		return false
+	}
 }
 
 // ---- API: move ----
 
 type moveBody struct {
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Dir       string `json:"dir"` // optional: N,NE,E,SE,S,SW,W,NW or "" (auto)
-	Promotion string `json:"promotion"`
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Dir       string  `json:"dir"` // optional: N,NE,E,SE,S,SW,W,NW, FORWARD,BACK_LEFT,... (mover's view) or "" (auto); unknown names mean auto unless the rules are strict
+	Promotion string  `json:"promotion"`
+	Shove     bool    `json:"shove,omitempty"` // Earth: push the enemy on to instead of moving
+	Drift     string  `json:"drift,omitempty"` // Water: E, W, LEFT or RIGHT to drift at turn end
+	Seq       *uint64 `json:"seq,omitempty"`   // optional: state.Seq the client last saw
 }
 
-func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
-		return
+// applyRelative resolves relative names such as "FORWARD" in the facing and
+// the drift from the point of view of turn, the side to move. Absolute names
+// are handled by request.
+func (body moveBody) applyRelative(turn game.Color, req *game.MoveRequest) {
+	if rel, ok := game.ParseRelativeDirection(body.Dir); ok {
+		req.Dir = rel.Absolute(turn)
 	}
-	defer r.Body.Close()
-	var body moveBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		if isBodyTooLarge(err) {
-			writeError(w, http.StatusRequestEntityTooLarge, "request too large")
-			return
-		}
-		writeError(w, http.StatusBadRequest, "invalid json")
-		return
+	if rel, ok := game.ParseRelativeDirection(body.Drift); ok {
+		req.Drift = rel.Absolute(turn)
+	}
+}
+
+// unknownDir reports a dir that names no direction, which lenient rules
+// treat as auto.
+func (body moveBody) unknownDir() bool {
+	dir := strings.TrimSpace(body.Dir)
+	if dir == "" || game.ParseDirection(dir) != game.DirNone {
+		return false
 	}
+	_, relative := game.ParseRelativeDirection(dir)
+	return !relative
+}
+
+// request converts the body into a MoveRequest, or returns the client-facing
+// reason it cannot.
+func (body moveBody) request() (game.MoveRequest, string) {
 	from, ok := game.CoordToSquare(strings.ToLower(strings.TrimSpace(body.From)))
 	if !ok {
-		writeError(w, http.StatusBadRequest, "invalid from square")
-		return
+		return game.MoveRequest{}, "invalid from square"
 	}
 	to, ok := game.CoordToSquare(strings.ToLower(strings.TrimSpace(body.To)))
 	if !ok {
-		writeError(w, http.StatusBadRequest, "invalid to square")
-		return
+		return game.MoveRequest{}, "invalid to square"
+	}
+	req := game.MoveRequest{From: from, To: to, Dir: game.ParseDirection(body.Dir), Shove: body.Shove}
+	if drift := strings.TrimSpace(body.Drift); drift != "" {
+		req.Drift = game.ParseDirection(drift)
+		if _, relative := game.ParseRelativeDirection(drift); req.Drift == game.DirNone && !relative {
+			return game.MoveRequest{}, "invalid drift"
+		}
 	}
-	dir := parseDirection(body.Dir)
-
-	req := game.MoveRequest{From: from, To: to, Dir: dir}
 	if promotion := strings.TrimSpace(body.Promotion); promotion != "" {
 		pt, ok := game.ParsePromotionPiece(promotion)
 		if !ok {
-			writeError(w, http.StatusBadRequest, "invalid promotion choice")
-			return
+			return game.MoveRequest{}, "invalid promotion choice"
 		}
 		req.Promotion = pt
 		req.HasPromotion = true
 	}
+	if body.Seq != nil {
+		req.Seq = *body.Seq
+		req.CheckSeq = true
+	}
+	return req, ""
+}
 
-	s.engineMu.Lock()
-	err := s.engine.Move(req)
-	state := s.engine.State()
-	s.engineMu.Unlock()
+func (s *Server) handleMove(w http.ResponseWriter, r *http.Request) {
+	if s.refuseAdvisor(w, r, DefaultGameID) {
+		return
+	}
+	serveMove(w, r, s.defaultGame(), s.seatGuard(r, DefaultGameID))
+	s.spar(DefaultGameID)
+}
+
+// serveMove applies a move to eng, holding mu for the engine calls. The move
+// is recorded in j, when set, before the engine sees it. seat, when set,
+// vets the side to move under the same lock and refuses with 403.
+func serveMove(w http.ResponseWriter, r *http.Request, g *controller.Game, seat func(game.Color) error) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
+	var body moveBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	req, msg := body.request()
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
 
+	out, err := g.Move(req, controller.MoveOptions{
+		Prepare: func(eng *game.Engine, req *game.MoveRequest) error {
+			if seat != nil {
+				if err := seat(eng.Turn()); err != nil {
+					return err
+				}
+			}
+			if body.unknownDir() && eng.Rules().Strict {
+				return &game.StrictError{Field: "dir", Reason: fmt.Sprintf("unknown direction %q", body.Dir)}
+			}
+			body.applyRelative(eng.Turn(), req)
+			return nil
+		},
+		Explain: coachRequested(r),
+		State:   opts,
+	})
+	result, state, explanation := out.Result, out.State, out.Explanation
+	if errors.Is(err, controller.ErrNotRecorded) {
+		writeError(w, http.StatusInternalServerError, "could not record move")
+		return
+	}
+	if errors.Is(err, errSeatHeld) || errors.Is(err, errShareScope) {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	if err != nil {
+		if errors.Is(err, game.ErrStaleSequence) {
+			w.WriteHeader(http.StatusConflict)
+			writeJSON(w, map[string]any{"error": err.Error(), "seq": state.Seq, "state": stateView(state, opts)})
+			return
+		}
 		if errors.Is(err, game.ErrDoOverActivated) || errors.Is(err, game.ErrCaptureBlocked) {
 			writeJSON(w, struct {
-				State   game.BoardState `json:"state"`
-				Message string          `json:"message"`
-			}{State: state, Message: err.Error()})
+				State       any            `json:"state"`
+				Result      moveResultView `json:"result"`
+				Message     string         `json:"message"`
+				Explanation string         `json:"explanation,omitempty"`
+			}{State: stateView(state, opts), Result: newMoveResultView(result), Message: err.Error(), Explanation: explanation})
 			return
 		}
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeExplainedError(w, http.StatusBadRequest, err.Error(), explanation)
 		return
 	}
 	writeJSON(w, struct {
-		State game.BoardState `json:"state"`
-	}{State: state})
+		State  any            `json:"state"`
+		Result moveResultView `json:"result"`
+	}{State: stateView(state, opts), Result: newMoveResultView(result)})
+}
+
+type moveEventView struct {
+	Seq     uint64 `json:"seq"`
+	Kind    string `json:"kind"`
+	Color   string `json:"color"`
+	Ability string `json:"ability,omitempty"`
+	PieceID int    `json:"pieceId,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Square  string `json:"square"`
+	// Draws are the turn's recorded random draws, on move events.
+	Draws []uint32 `json:"draws,omitempty"`
+	// Cause is the seq of the event that triggered this one.
+	Cause uint64 `json:"cause,omitempty"`
+}
+
+// removalView is one step of a turn's removal cascade, for animating it.
+type removalView struct {
+	Seq     uint64 `json:"seq"`
+	Cause   uint64 `json:"cause"`
+	Kind    string `json:"kind"`
+	Ability string `json:"ability,omitempty"`
+	PieceID int    `json:"pieceId"`
+	Type    string `json:"type"`
+	Square  string `json:"square"`
+	Depth   int    `json:"depth"`
+}
+
+type moveResultView struct {
+	// Events is the move's full event stream in seq order; clients animate
+	// from it. Captures and AbilityEvents are filtered views.
+	Events        []moveEventView `json:"events"`
+	Captures      []moveEventView `json:"captures"`
+	AbilityEvents []moveEventView `json:"abilityEvents"`
+	// Removals links every capture and ability removal to what caused it.
+	Removals       []removalView `json:"removals,omitempty"`
+	Check          bool          `json:"check"`
+	StepsRemaining int           `json:"stepsRemaining"`
+	TurnEnded      bool          `json:"turnEnded"`
+	Status         string        `json:"status"`
+	Hash           uint64        `json:"hash"`
+	Seq            uint64        `json:"seq"`
+}
+
+func newMoveResultView(res game.MoveResult) moveResultView {
+	return moveResultView{
+		Events:         moveEventViews(res.Events),
+		Captures:       moveEventViews(res.Captures),
+		AbilityEvents:  moveEventViews(res.AbilityEvents),
+		Removals:       removalViews(res.Removals),
+		Check:          res.Check,
+		StepsRemaining: res.StepsRemaining,
+		TurnEnded:      res.TurnEnded,
+		Status:         res.Status.String(),
+		Hash:           res.Hash,
+		Seq:            res.Seq,
+	}
+}
+
+func removalViews(steps []game.RemovalStep) []removalView {
+	if len(steps) == 0 {
+		return nil
+	}
+	out := make([]removalView, 0, len(steps))
+	for _, st := range steps {
+		view := removalView{
+			Seq:     st.Seq,
+			Cause:   st.Cause,
+			Kind:    st.Kind.String(),
+			PieceID: st.PieceID,
+			Type:    st.Type.String(),
+			Square:  game.SquareToCoord(st.Square),
+			Depth:   st.Depth,
+		}
+		if st.Ability != game.AbilityNone {
+			view.Ability = st.Ability.String()
+		}
+		out = append(out, view)
+	}
+	return out
+}
+
+func moveEventViews(events []game.Event) []moveEventView {
+	out := make([]moveEventView, 0, len(events))
+	for _, ev := range events {
+		view := moveEventView{
+			Seq:     ev.Seq,
+			Kind:    ev.Kind.String(),
+			Color:   ev.Color.String(),
+			PieceID: ev.PieceID,
+			Square:  game.SquareToCoord(ev.Square),
+			Draws:   ev.Draws,
+			Cause:   ev.Cause,
+		}
+		if ev.Ability != game.AbilityNone {
+			view.Ability = ev.Ability.String()
+		}
+		if ev.PieceID != 0 {
+			view.Type = ev.Type.String()
+		}
+		out = append(out, view)
+	}
+	return out
 }
 
 // ---- API: config ----
@@ -273,49 +641,138 @@ type configBody struct {
 	Color     string   `json:"color"`
 	Abilities []string `json:"abilities"`
 	Element   string   `json:"element"`
+	// ByType maps a piece type to abilities only that type gets, on top of
+	// Abilities.
+	ByType map[string][]string `json:"byType,omitempty"`
 }
 
 func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if s.refuseAdvisor(w, r, DefaultGameID) {
+		return
+	}
+	serveConfig(w, r, s.defaultGame())
+}
+
+// serveConfig applies a side loadout to eng, holding mu for the engine calls.
+func serveConfig(w http.ResponseWriter, r *http.Request, g *controller.Game) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 	defer r.Body.Close()
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
 	var body configBody
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		if isBodyTooLarge(err) {
-			writeError(w, http.StatusRequestEntityTooLarge, "request too large")
-			return
-		}
-		writeError(w, http.StatusBadRequest, "invalid json")
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
 		return
 	}
 
-	color, ok := parseColor(body.Color)
+	color, ok := game.ParseColor(body.Color)
 	if !ok {
 		writeError(w, http.StatusBadRequest, "invalid color")
 		return
 	}
+	if err := shareScope(r, g.ID, color); err != nil {
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
 	abilityList, err := parseAbilities(body.Abilities)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	element, ok := parseElement(body.Element)
+	element, ok := game.ParseElement(body.Element)
 	if !ok {
 		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid element %q", body.Element))
 		return
 	}
+	byType, err := parseTypeAbilities(body.ByType)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	s.engineMu.Lock()
-	err = s.engine.SetSideConfig(color, abilityList, element)
-	state := s.engine.State()
-	s.engineMu.Unlock()
+	state, err := g.Configure(color, game.SideSetup{Abilities: abilityList, Element: element, ByType: byType}, opts)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeJSON(w, map[string]any{"state": state})
+	writeJSON(w, map[string]any{"state": stateView(state, opts)})
+}
+
+type sideConfigBody struct {
+	Abilities []string            `json:"abilities"`
+	Element   string              `json:"element"`
+	ByType    map[string][]string `json:"byType,omitempty"`
+}
+
+type configAllBody struct {
+	White sideConfigBody `json:"white"`
+	Black sideConfigBody `json:"black"`
+}
+
+func (b sideConfigBody) setup() (game.SideSetup, error) {
+	abilityList, err := parseAbilities(b.Abilities)
+	if err != nil {
+		return game.SideSetup{}, err
+	}
+	element, ok := game.ParseElement(b.Element)
+	if !ok {
+		return game.SideSetup{}, fmt.Errorf("invalid element %q", b.Element)
+	}
+	byType, err := parseTypeAbilities(b.ByType)
+	if err != nil {
+		return game.SideSetup{}, err
+	}
+	return game.SideSetup{Abilities: abilityList, Element: element, ByType: byType}, nil
+}
+
+func (s *Server) handleConfigAll(w http.ResponseWriter, r *http.Request) {
+	if s.refuseAdvisor(w, r, DefaultGameID) || refuseShare(w, r) {
+		return
+	}
+	serveConfigAll(w, r, s.defaultGame())
+}
+
+// serveConfigAll applies both sides' loadouts to eng together: either both
+// take effect or neither does.
+func serveConfigAll(w http.ResponseWriter, r *http.Request, g *controller.Game) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
+	var body configAllBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	white, err := body.White.setup()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "white: "+err.Error())
+		return
+	}
+	black, err := body.Black.setup()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "black: "+err.Error())
+		return
+	}
+
+	state, err := g.ConfigureBoth(white, black, opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, map[string]any{"state": stateView(state, opts)})
 }
 
 // ---- API: reset (NEW) ----
@@ -328,90 +785,189 @@ func (s *Server) handleReset(w http.ResponseWriter, r *http.Request) {
 	if r.Body != nil {
 		r.Body.Close()
 	}
-	s.engineMu.Lock()
-	err := s.engine.Reset()
-	state := s.engine.State()
-	s.engineMu.Unlock()
+	opts, ok := stateOptions(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid detail")
+		return
+	}
+	state, err := s.defaultGame().Update((*game.Engine).Reset, opts)
+	if err == nil && s.games != nil {
+		s.games.resetHints(DefaultGameID)
+	}
 
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	writeJSON(w, map[string]any{"state": state})
+	writeJSON(w, map[string]any{"state": stateView(state, opts)})
 }
 
-// ---- parsing helpers ----
+// ---- API: history ----
+
+type historyEntry struct {
+	Ply       uint32    `json:"ply"`
+	Color     string    `json:"color"`
+	PieceID   int       `json:"pieceId"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Capture   bool      `json:"capture"`
+	Shove     bool      `json:"shove,omitempty"`
+	TurnStart time.Time `json:"turnStart"`
+	SegmentAt time.Time `json:"segmentAt"`
+	TurnEnd   time.Time `json:"turnEnd"`
+	ThinkMs   int64     `json:"thinkMs"`
+}
 
-func parseColor(s string) (game.Color, bool) {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "white", "w":
-		return game.White, true
-	case "black", "b":
-		return game.Black, true
-	default:
-		return 0, false
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
+	s.engineMu.Lock()
+	records := s.engine.MoveLog()
+	turnStarted := s.engine.TurnStarted()
+	s.engineMu.Unlock()
+
+	moves := make([]historyEntry, 0, len(records))
+	for _, rec := range records {
+		moves = append(moves, historyEntry{
+			Ply:       rec.Ply,
+			Color:     rec.Color.String(),
+			PieceID:   rec.PieceID,
+			From:      game.SquareToCoord(rec.From),
+			To:        game.SquareToCoord(rec.To),
+			Capture:   rec.Capture,
+			Shove:     rec.Shove,
+			TurnStart: rec.TurnStart,
+			SegmentAt: rec.SegmentAt,
+			TurnEnd:   rec.TurnEnd,
+			ThinkMs:   rec.ThinkTime().Milliseconds(),
+		})
+	}
+	writeJSON(w, map[string]any{"moves": moves, "turnStarted": turnStarted})
+}
+
+// ---- API: highlights ----
+
+type highlightEntry struct {
+	Ply         uint32 `json:"ply"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
 }
 
-func parseAbility(s string) (game.Ability, bool) {
-	if ability, ok := game.ParseAbility(s); ok {
-		return ability, true
+type captureBlockView struct {
+	Rule    string `json:"rule"`
+	Ability string `json:"ability"`
+	PieceID int    `json:"pieceId"`
+	Facing  string `json:"facing,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+type validateResponse struct {
+	Legal       bool              `json:"legal"`
+	Error       string            `json:"error,omitempty"`
+	Explanation string            `json:"explanation,omitempty"`
+	Block       *captureBlockView `json:"block,omitempty"`
+}
+
+// handleValidate checks a move against the live position without playing it
+// and says which rule rejects it.
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
-	if s == "" {
-		return game.AbilityNone, false
+	defer r.Body.Close()
+	var body moveBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
 	}
-	needle := strings.ToLower(strings.TrimSpace(s))
-	for _, a := range game.AllAbilities {
-		if strings.ToLower(a.String()) == needle {
-			return a, true
+	req, msg := body.request()
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	s.engineMu.Lock()
+	body.applyRelative(s.engine.Turn(), &req)
+	err := s.engine.ValidateMove(req)
+	out := validateResponse{Legal: err == nil}
+	if err != nil {
+		out.Error = err.Error()
+		out.Explanation = s.engine.ExplainMove(req, err)
+		if block, ok := s.engine.ExplainCaptureBlock(req.From, req.To); ok && errors.Is(err, game.ErrCaptureBlocked) {
+			out.Block = &captureBlockView{
+				Rule:    block.Rule,
+				Ability: block.Ability.String(),
+				PieceID: block.PieceID,
+				Facing:  block.Facing.String(),
+				Reason:  block.Reason,
+			}
 		}
 	}
-	return game.AbilityNone, false
+	s.engineMu.Unlock()
+	writeJSON(w, out)
 }
 
-func parseElement(s string) (game.Element, bool) {
-	if s == "" {
-		return game.ElementLight, false
+func (s *Server) handleHighlights(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
 	}
-	needle := strings.ToLower(strings.TrimSpace(s))
-	for _, e := range game.AllElements {
-		if strings.ToLower(e.String()) == needle {
-			return e, true
-		}
+	s.engineMu.Lock()
+	highlights, err := s.engine.Highlights()
+	s.engineMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	out := make([]highlightEntry, 0, len(highlights))
+	for _, h := range highlights {
+		out = append(out, highlightEntry{Ply: h.Ply, Kind: h.Kind, Description: h.Description})
 	}
-	return game.ElementLight, false
-}
-
-func parseDirection(s string) game.Direction {
-	needle := strings.ToUpper(strings.TrimSpace(s))
-	switch needle {
-	case "", "AUTO":
-		return game.DirNone
-	case "N":
-		return game.DirN
-	case "NE":
-		return game.DirNE
-	case "E":
-		return game.DirE
-	case "SE":
-		return game.DirSE
-	case "S":
-		return game.DirS
-	case "SW":
-		return game.DirSW
-	case "W":
-		return game.DirW
-	case "NW":
-		return game.DirNW
+	writeJSON(w, map[string]any{"highlights": out})
+}
+
+// ---- API: session ----
+
+// handleSession lets a reconnecting client (or a restarted server) fetch the
+// full session snapshot with GET and resume it with POST.
+func (s *Server) handleSession(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.engineMu.Lock()
+		snap := s.engine.Snapshot()
+		s.engineMu.Unlock()
+		writeJSON(w, map[string]any{"snapshot": snap})
+	case http.MethodPost:
+		defer r.Body.Close()
+		var body struct {
+			Snapshot game.Snapshot `json:"snapshot"`
+		}
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		state, err := s.defaultGame().Update(func(eng *game.Engine) error {
+			return eng.Restore(body.Snapshot)
+		}, game.StateOptions{})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, map[string]any{"state": state})
 	default:
-		return game.DirNone
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
 	}
 }
 
+// ---- parsing helpers ----
+
 func parseAbilities(list []string) (game.AbilityList, error) {
 	abilities := make(game.AbilityList, 0, len(list))
 	for _, item := range list {
-		ability, ok := parseAbility(item)
+		ability, ok := game.ParseAbility(item)
 		if !ok {
 			return nil, fmt.Errorf("invalid ability %q", item)
 		}
@@ -420,6 +976,27 @@ func parseAbilities(list []string) (game.AbilityList, error) {
 	return abilities, nil
 }
 
+// parseTypeAbilities reads a piece type → abilities map such as
+// {"knight": ["BlazeRush"]}.
+func parseTypeAbilities(m map[string][]string) (map[game.PieceType]game.AbilityList, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	out := make(map[game.PieceType]game.AbilityList, len(m))
+	for name, list := range m {
+		t, ok := game.ParsePieceType(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid piece type %q", name)
+		}
+		abilities, err := parseAbilities(list)
+		if err != nil {
+			return nil, err
+		}
+		out[t] = append(out[t], abilities...)
+	}
+	return out, nil
+}
+
 func abilityNames() []string {
 	out := make([]string, 0, len(game.AllAbilities))
 	for _, a := range game.AllAbilities {
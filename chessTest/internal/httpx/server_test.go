@@ -3,13 +3,17 @@ package httpx
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"battle_chess_poc/internal/game"
+	"battle_chess_poc/internal/protocol"
 )
 
 func TestHandleMoveDoOverReturnsState(t *testing.T) {
@@ -47,6 +51,7 @@ func TestHandleMoveDoOverReturnsState(t *testing.T) {
 
 	var payload struct {
 		State   game.BoardState `json:"state"`
+		Result  moveResultView  `json:"result"`
 		Message string          `json:"message"`
 	}
 	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
@@ -55,6 +60,9 @@ func TestHandleMoveDoOverReturnsState(t *testing.T) {
 	if payload.Message != game.ErrDoOverActivated.Error() {
 		t.Fatalf("expected DoOver message, got %q", payload.Message)
 	}
+	if payload.Result.TurnEnded || len(payload.Result.AbilityEvents) != 1 || payload.Result.AbilityEvents[0].Kind != "do_over" {
+		t.Fatalf("expected a do_over ability event without turn end, got %+v", payload.Result)
+	}
 	if len(payload.State.Pieces) == 0 {
 		t.Fatalf("expected non-empty state payload")
 	}
@@ -196,3 +204,419 @@ func equalStringSlices(a, b []string) bool {
 	}
 	return true
 }
+func TestHandleStateDetailParam(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	cases := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantPieces bool
+	}{
+		{name: "default full", query: "", wantStatus: http.StatusOK, wantPieces: true},
+		{name: "explicit full", query: "?detail=full", wantStatus: http.StatusOK, wantPieces: true},
+		{name: "summary", query: "?detail=summary", wantStatus: http.StatusOK, wantPieces: false},
+		{name: "invalid", query: "?detail=verbose", wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/state"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			srv.handleState(rr, req)
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d", rr.Code, tc.wantStatus)
+			}
+			if tc.wantStatus != http.StatusOK {
+				return
+			}
+			var payload struct {
+				State game.BoardState `json:"state"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if got := len(payload.State.Pieces) > 0; got != tc.wantPieces {
+				t.Fatalf("pieces present = %v want %v", got, tc.wantPieces)
+			}
+			if payload.State.Hash == 0 {
+				t.Fatalf("expected position hash in state")
+			}
+			// A full state keeps its empty maps; a summary has no such keys.
+			var raw struct {
+				State map[string]json.RawMessage `json:"state"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &raw); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			for _, key := range []string{"BlockFacing", "Charges", "Clocks"} {
+				if _, ok := raw.State[key]; ok != tc.wantPieces {
+					t.Fatalf("%s present = %v want %v", key, ok, tc.wantPieces)
+				}
+			}
+		})
+	}
+}
+func TestHandleMoveCoachExplanation(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		wantCoach bool
+	}{
+		{name: "plain", query: "", wantCoach: false},
+		{name: "coach", query: "?coach=1", wantCoach: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := &Server{engine: game.NewEngine()}
+			req := httptest.NewRequest(http.MethodPost, "/api/move"+tc.query, strings.NewReader(`{"from":"e2","to":"d3"}`))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.handleMove(rr, req)
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d want 400", rr.Code)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body: %v", err)
+			}
+			if body["error"] != game.ErrInvalidMove.Error() {
+				t.Fatalf("error = %q", body["error"])
+			}
+			if got := body["explanation"] != ""; got != tc.wantCoach {
+				t.Fatalf("explanation present = %v want %v (%q)", got, tc.wantCoach, body["explanation"])
+			}
+		})
+	}
+}
+func TestHandleHighlights(t *testing.T) {
+	eng := game.NewEngine()
+	if err := eng.LoadPlacement("8/8/8/8/8/3k4/4P3/4K3", game.White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	srv := &Server{engine: eng}
+
+	rr := httptest.NewRecorder()
+	srv.handleHighlights(rr, httptest.NewRequest(http.MethodGet, "/api/highlights", nil))
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("in-progress status = %d want %d", rr.Code, http.StatusConflict)
+	}
+
+	if err := eng.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareD3}); err != nil {
+		t.Fatalf("capture king: %v", err)
+	}
+	rr = httptest.NewRecorder()
+	srv.handleHighlights(rr, httptest.NewRequest(http.MethodGet, "/api/highlights", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d want %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var payload struct {
+		Highlights []highlightEntry `json:"highlights"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if n := len(payload.Highlights); n == 0 || payload.Highlights[n-1].Kind != "game_over" {
+		t.Fatalf("unexpected highlights %+v", payload.Highlights)
+	}
+}
+func TestHandleStateNegotiatesEncoding(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	want := srv.engine.State()
+	cases := []struct {
+		name     string
+		accept   string
+		encoding string
+		wantType string
+		wantGzip bool
+	}{
+		{name: "json", wantType: "application/json; charset=utf-8"},
+		{name: "binary", accept: protocol.ContentType, wantType: protocol.ContentType},
+		{name: "binary gzip", accept: protocol.ContentType, encoding: "gzip", wantType: protocol.ContentType, wantGzip: true},
+		{name: "json gzip", encoding: "br, gzip", wantType: "application/json; charset=utf-8", wantGzip: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/state", nil)
+			req.Header.Set("Accept", tc.accept)
+			req.Header.Set("Accept-Encoding", tc.encoding)
+			rr := httptest.NewRecorder()
+			srv.withJSON(srv.handleState)(rr, req)
+			if got := rr.Header().Get("Content-Type"); got != tc.wantType {
+				t.Fatalf("content type = %q want %q", got, tc.wantType)
+			}
+			var body io.Reader = rr.Body
+			if tc.wantGzip {
+				if rr.Header().Get("Content-Encoding") != "gzip" {
+					t.Fatalf("expected gzip content encoding")
+				}
+				gz, err := gzip.NewReader(rr.Body)
+				if err != nil {
+					t.Fatalf("gzip reader: %v", err)
+				}
+				body = gz
+			}
+			raw, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatalf("read body: %v", err)
+			}
+			var got game.BoardState
+			if tc.wantType == protocol.ContentType {
+				got, err = protocol.DecodeState(raw)
+			} else {
+				var payload struct {
+					State game.BoardState `json:"state"`
+				}
+				err = json.Unmarshal(raw, &payload)
+				got = payload.State
+			}
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Hash != want.Hash || len(got.Pieces) != len(want.Pieces) {
+				t.Fatalf("decoded state differs: hash %d/%d pieces %d/%d", got.Hash, want.Hash, len(got.Pieces), len(want.Pieces))
+			}
+		})
+	}
+}
+func TestHandleMoveSequencing(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	post := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		srv.handleMove(rr, httptest.NewRequest(http.MethodPost, "/api/move", strings.NewReader(body)))
+		return rr
+	}
+
+	// Two racing submissions against the same sequence number: exactly one wins.
+	codes := make(chan int, 2)
+	for _, body := range []string{`{"from":"e2","to":"e4","seq":0}`, `{"from":"d2","to":"d4","seq":0}`} {
+		go func(body string) { codes <- post(body).Code }(body)
+	}
+	got := map[int]int{}
+	for i := 0; i < 2; i++ {
+		got[<-codes]++
+	}
+	if got[http.StatusOK] != 1 || got[http.StatusConflict] != 1 {
+		t.Fatalf("expected one 200 and one 409, got %v", got)
+	}
+
+	rr := post(`{"from":"e7","to":"e5","seq":0}`)
+	if rr.Code != http.StatusConflict {
+		t.Fatalf("duplicate seq status = %d want %d", rr.Code, http.StatusConflict)
+	}
+	var conflict struct {
+		Seq uint64 `json:"seq"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &conflict); err != nil || conflict.Seq != 1 {
+		t.Fatalf("expected current seq 1 in conflict body, got %s (%v)", rr.Body.String(), err)
+	}
+	if rr := post(`{"from":"e7","to":"e5","seq":1}`); rr.Code != http.StatusOK {
+		t.Fatalf("in-order move status = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := post(`{"from":"e4","to":"e3"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("unsequenced moves still validate normally, got %d", rr.Code)
+	}
+}
+func TestHandleValidate(t *testing.T) {
+	eng := game.NewEngine()
+	if err := eng.LoadPlacement("4k3/8/8/3p4/8/8/4P3/4K3", game.White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	if err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityBlockPath}, game.ElementLight); err != nil {
+		t.Fatalf("white config: %v", err)
+	}
+	if err := eng.SetSideConfig(game.Black, game.AbilityList{}, game.ElementShadow); err != nil {
+		t.Fatalf("black config: %v", err)
+	}
+	if err := eng.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareE4, Dir: game.DirN}); err != nil {
+		t.Fatalf("e2e4: %v", err)
+	}
+	srv := &Server{engine: eng}
+	cases := []struct {
+		name      string
+		body      string
+		wantLegal bool
+		wantRule  string
+		wantError string
+	}{
+		{name: "legal push", body: `{"from":"d5","to":"d4"}`, wantLegal: true},
+		{name: "blocked capture", body: `{"from":"d5","to":"e4"}`, wantRule: game.BlockRuleFacing, wantError: game.ErrCaptureBlocked.Error()},
+		{name: "illegal move", body: `{"from":"d5","to":"d3"}`, wantError: game.ErrInvalidMove.Error()},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.handleValidate(rr, httptest.NewRequest(http.MethodPost, "/api/validate", strings.NewReader(tc.body)))
+			if rr.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rr.Code, rr.Body.String())
+			}
+			var got validateResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Legal != tc.wantLegal || got.Error != tc.wantError {
+				t.Fatalf("unexpected response %+v", got)
+			}
+			if tc.wantRule != "" && (got.Block == nil || got.Block.Rule != tc.wantRule || got.Explanation == "") {
+				t.Fatalf("expected %s block with explanation, got %+v", tc.wantRule, got)
+			}
+		})
+	}
+	if eng.State().Turn != game.Black {
+		t.Fatalf("validate must not play the move")
+	}
+}
+func TestHandleMoveRelativeDirection(t *testing.T) {
+	eng := game.NewEngine()
+	if err := eng.SetSideConfig(game.Black, game.AbilityList{game.AbilityBlockPath}, game.ElementShadow); err != nil {
+		t.Fatalf("black config: %v", err)
+	}
+	srv := &Server{engine: eng}
+	for _, body := range []string{`{"from":"e2","to":"e4"}`, `{"from":"d7","to":"d5","dir":"forward-left"}`} {
+		rr := httptest.NewRecorder()
+		srv.handleMove(rr, httptest.NewRequest(http.MethodPost, "/api/move", strings.NewReader(body)))
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: status = %d: %s", body, rr.Code, rr.Body.String())
+		}
+	}
+	state := eng.State()
+	for _, pc := range state.Pieces {
+		if pc.Square != game.SquareD5 {
+			continue
+		}
+		// Black's forward is south, so forward-left is the board's south-east.
+		if dir := state.BlockFacing[pc.ID]; dir != game.DirSE {
+			t.Fatalf("black facing = %s want SE", dir)
+		}
+		return
+	}
+	t.Fatalf("black pawn missing from d5")
+}
+
+func TestHandleConfigAllIsAtomic(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	handler := srv.routes()
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/games", strings.NewReader(`{"rules":{"bannedAbilities":["DoOver"]}}`)))
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	cases := []struct {
+		name       string
+		path       string
+		body       string
+		wantStatus int
+		wantError  string
+	}{
+		{name: "bad element", path: "/api/config/all", body: `{"white":{"abilities":["BlockPath"],"element":"light"},"black":{"abilities":["DoOver"],"element":"plasma"}}`, wantStatus: http.StatusBadRequest, wantError: "black: invalid element"},
+		{name: "banned on one side", path: "/api/games/" + created.ID + "/config/all", body: `{"white":{"abilities":["BlockPath"],"element":"light"},"black":{"abilities":["DoOver"],"element":"shadow"}}`, wantStatus: http.StatusBadRequest, wantError: "black: ability banned"},
+		{name: "bad piece type", path: "/api/config/all", body: `{"white":{"abilities":["BlockPath"],"element":"light","byType":{"dragon":["Scorch"]}},"black":{"abilities":["DoOver"],"element":"shadow"}}`, wantStatus: http.StatusBadRequest, wantError: `white: invalid piece type \"dragon\"`},
+		{name: "both sides", path: "/api/config/all", body: `{"white":{"abilities":["BlockPath"],"element":"light","byType":{"rook":["BlazeRush"]}},"black":{"abilities":["DoOver"],"element":"shadow"}}`, wantStatus: http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body)))
+			if rr.Code != tc.wantStatus {
+				t.Fatalf("status = %d want %d: %s", rr.Code, tc.wantStatus, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tc.wantError) {
+				t.Fatalf("body %s missing %q", rr.Body.String(), tc.wantError)
+			}
+		})
+	}
+	g, _ := srv.games.get(created.ID)
+	if abilities := g.Engine().State().Abilities; len(abilities["white"]) != 0 {
+		t.Fatalf("rejected request configured white: %v", abilities)
+	}
+	state := srv.engine.State()
+	if len(state.Abilities["white"]) != 1 || len(state.Abilities["black"]) != 1 {
+		t.Fatalf("expected both sides configured, got %v", state.Abilities)
+	}
+	if got := state.TypeAbilities["white"]["rook"]; len(got) != 1 || got[0] != "BlazeRush" {
+		t.Fatalf("white rook abilities = %v", state.TypeAbilities)
+	}
+}
+
+// TestWireNamesParseThroughGame pins the names the HTTP layer accepted when
+// it kept its own parsers, now that it relies on the game package's.
+func TestWireNamesParseThroughGame(t *testing.T) {
+	colors := []struct {
+		in   string
+		want game.Color
+		ok   bool
+	}{
+		{"white", game.White, true}, {" W ", game.White, true}, {"Black", game.Black, true}, {"b", game.Black, true}, {"red", game.White, false}, {"", game.White, false},
+	}
+	for _, tc := range colors {
+		if got, ok := game.ParseColor(tc.in); ok != tc.ok || (ok && got != tc.want) {
+			t.Fatalf("ParseColor(%q) = %v, %v want %v, %v", tc.in, got, ok, tc.want, tc.ok)
+		}
+	}
+	dirs := []struct {
+		in   string
+		want game.Direction
+	}{
+		{"", game.DirNone}, {"auto", game.DirNone}, {"n", game.DirN}, {" NE ", game.DirNE}, {"sw", game.DirSW}, {"up", game.DirNone},
+	}
+	for _, tc := range dirs {
+		if got := game.ParseDirection(tc.in); got != tc.want {
+			t.Fatalf("ParseDirection(%q) = %v want %v", tc.in, got, tc.want)
+		}
+	}
+	for _, e := range game.AllElements {
+		for _, name := range []string{e.String(), strings.ToUpper(e.String()), " " + e.String() + " "} {
+			if got, ok := game.ParseElement(name); !ok || got != e {
+				t.Fatalf("ParseElement(%q) = %v, %v", name, got, ok)
+			}
+		}
+	}
+	for _, a := range game.AllAbilities {
+		for _, name := range []string{a.String(), strings.ToLower(a.String()), strings.ToUpper(a.String())} {
+			if got, ok := game.ParseAbility(name); !ok || got != a {
+				t.Fatalf("ParseAbility(%q) = %v, %v", name, got, ok)
+			}
+		}
+	}
+	if _, ok := game.ParseElement(""); ok {
+		t.Fatalf("empty element accepted")
+	}
+}
+
+func TestPieceEndpoint(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	h := srv.routes()
+	var id int
+	for _, p := range srv.engine.State().Pieces {
+		if p.Square == game.SquareE2 {
+			id = p.ID
+		}
+	}
+	cases := []struct {
+		path string
+		want int
+	}{
+		{fmt.Sprintf("/api/piece/%d", id), http.StatusOK},
+		{"/api/piece/999", http.StatusNotFound},
+		{"/api/piece/e2", http.StatusBadRequest},
+		{fmt.Sprintf("/api/piece/%d?game=nope", id), http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Fatalf("%s: status %d want %d", tc.path, rec.Code, tc.want)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/piece/%d", id), nil))
+	var out struct {
+		Piece pieceView `json:"piece"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Piece.Square != "e2" || out.Piece.Type != "pawn" || strings.Join(out.Piece.Targets, ",") != "e3,e4" {
+		t.Fatalf("piece = %+v", out.Piece)
+	}
+}
@@ -0,0 +1,282 @@
+// path: chessTest/internal/httpx/share.go
+package httpx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Share links let a player hand a game to a friend as a single URL:
+//
+//	POST /api/games/{id}/share {"role": "black", "ttl": "24h"}
+//	{"token": "...", "url": "/g/...", "game": "...", "role": "black", "expiresAt": ...}
+//
+// The token names the game, the role it is for (a seat, "white" or "black",
+// or "spectator") and when it expires, signed with the server's share key so
+// it cannot be edited into a link to another game or seat. GET /g/{token}
+// opens the UI on that game; with Accept: application/json it answers the
+// game's state, role and expiry instead.
+//
+// The page sends the token back in the X-Share-Token header, and on the move,
+// config and heartbeat endpoints that header is the caller's credential, in
+// place of a bearer token when authentication is on: a seat link acts for its
+// own game and color only, and a spectator link may not act at all. Without
+// authentication, requests that carry no link stay as open as the rest of
+// the API.
+//
+// The key is random per process unless SetShareKey installs one, so links
+// outlive a restart only when the key is configured.
+
+const (
+	defaultShareTTL = 7 * 24 * time.Hour
+	maxShareTTL     = 30 * 24 * time.Hour
+	// shareMACBytes is how much of the HMAC-SHA256 a token carries.
+	shareMACBytes = 16
+	roleSpectator = "spectator"
+	shareHeader   = "X-Share-Token"
+)
+
+var (
+	errShareInvalid = errors.New("invalid share link")
+	errShareExpired = errors.New("share link expired")
+	errShareScope   = errors.New("share link does not cover this seat")
+)
+
+type shareClaimsKey struct{}
+
+type shareClaims struct {
+	Game    string
+	Role    string
+	Expires time.Time
+}
+
+type shareBody struct {
+	// Role is "white", "black" or "spectator" (the default).
+	Role string `json:"role,omitempty"`
+	// TTL is a Go duration; seven days when empty, at most thirty.
+	TTL string `json:"ttl,omitempty"`
+}
+
+type shareView struct {
+	Token     string `json:"token"`
+	URL       string `json:"url"`
+	Game      string `json:"game"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"expiresAt"` // unix ms
+}
+
+// SetShareKey sets the key share links are signed with, invalidating links
+// signed with the previous one. An empty key turns share links off.
+func (s *Server) SetShareKey(key []byte) {
+	s.shareKey = append([]byte(nil), key...)
+}
+
+func newShareKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil
+	}
+	return key
+}
+
+func parseShareRole(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" || s == roleSpectator {
+		return roleSpectator, true
+	}
+	if color, ok := game.ParseColor(s); ok {
+		return color.String(), true
+	}
+	return "", false
+}
+
+// signShare encodes c as "<payload>.<mac>", both base64url without padding.
+func signShare(key []byte, c shareClaims) string {
+	payload := c.Game + "|" + c.Role + "|" + strconv.FormatInt(c.Expires.Unix(), 10)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(shareMAC(key, payload))
+}
+
+// verifyShare checks token's signature and expiry at now.
+func verifyShare(key []byte, token string, now time.Time) (shareClaims, error) {
+	enc := base64.RawURLEncoding
+	p, m, ok := strings.Cut(token, ".")
+	if !ok || len(key) == 0 {
+		return shareClaims{}, errShareInvalid
+	}
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return shareClaims{}, errShareInvalid
+	}
+	mac, err := enc.DecodeString(m)
+	if err != nil || !hmac.Equal(mac, shareMAC(key, string(payload))) {
+		return shareClaims{}, errShareInvalid
+	}
+	parts := strings.Split(string(payload), "|")
+	if len(parts) != 3 {
+		return shareClaims{}, errShareInvalid
+	}
+	exp, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return shareClaims{}, errShareInvalid
+	}
+	c := shareClaims{Game: parts[0], Role: parts[1], Expires: time.Unix(exp, 0)}
+	if !now.Before(c.Expires) {
+		return c, errShareExpired
+	}
+	return c, nil
+}
+
+func shareMAC(key []byte, payload string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(payload))
+	return h.Sum(nil)[:shareMACBytes]
+}
+
+// seated guards a seat endpoint. A request carrying a share token acts as the
+// token's seat, and the handler checks it against shareScope; any other
+// request needs RolePlayer.
+func (s *Server) seated(h func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+	player := s.authorize(RolePlayer, h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(shareHeader)
+		if token == "" {
+			player(w, r)
+			return
+		}
+		c, err := verifyShare(s.shareKey, token, time.Now())
+		if err != nil {
+			writeError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		ident := Identity{Subject: "link:" + c.Game + "/" + c.Role, Roles: []string{RolePlayer}}
+		ctx := context.WithValue(r.Context(), identityKey{}, ident)
+		h(w, r.WithContext(context.WithValue(ctx, shareClaimsKey{}, c)))
+	}
+}
+
+// shareScope refuses a request made with a share token unless it acts for the
+// token's own game and seat. Requests without one pass.
+func shareScope(r *http.Request, id string, color game.Color) error {
+	c, ok := r.Context().Value(shareClaimsKey{}).(shareClaims)
+	if ok && (c.Game != id || c.Role != color.String()) {
+		return errShareScope
+	}
+	return nil
+}
+
+// refuseShare answers 403 to a request made with a share token, for
+// endpoints that act for both seats at once.
+func refuseShare(w http.ResponseWriter, r *http.Request) bool {
+	if _, ok := r.Context().Value(shareClaimsKey{}).(shareClaims); !ok {
+		return false
+	}
+	writeError(w, http.StatusForbidden, errShareScope.Error())
+	return true
+}
+
+// ---- API: share links ----
+
+// handleShare mints a share link for game id.
+func (s *Server) handleShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	var body shareBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	role, ok := parseShareRole(body.Role)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalid role")
+		return
+	}
+	ttl := defaultShareTTL
+	if body.TTL != "" {
+		d, err := time.ParseDuration(body.TTL)
+		if err != nil || d <= 0 || d > maxShareTTL {
+			writeError(w, http.StatusBadRequest, "invalid ttl")
+			return
+		}
+		ttl = d
+	}
+	id := r.PathValue("id")
+	if _, _, ok := s.lookupGame(id); !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	if len(s.shareKey) == 0 {
+		writeError(w, http.StatusServiceUnavailable, "share links are disabled")
+		return
+	}
+	c := shareClaims{Game: id, Role: role, Expires: time.Now().Add(ttl).Truncate(time.Second)}
+	token := signShare(s.shareKey, c)
+	writeJSON(w, shareView{
+		Token:     token,
+		URL:       "/g/" + token,
+		Game:      id,
+		Role:      role,
+		ExpiresAt: c.Expires.UnixMilli(),
+	})
+}
+
+// handleShareLink opens the game a share token points at.
+func (s *Server) handleShareLink(w http.ResponseWriter, r *http.Request) {
+	asJSON := strings.Contains(r.Header.Get("Accept"), "application/json")
+	fail := func(status int, msg string) {
+		if asJSON {
+			applyAPISecurityHeaders(w.Header())
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			writeError(w, status, msg)
+			return
+		}
+		http.Error(w, msg, status)
+	}
+	if r.Method != http.MethodGet {
+		fail(http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	c, err := verifyShare(s.shareKey, r.PathValue("token"), time.Now())
+	switch {
+	case errors.Is(err, errShareExpired):
+		fail(http.StatusGone, err.Error())
+		return
+	case err != nil:
+		fail(http.StatusNotFound, err.Error())
+		return
+	}
+	mu, eng, ok := s.lookupGame(c.Game)
+	if !ok {
+		fail(http.StatusNotFound, "game not found")
+		return
+	}
+	mu.Lock()
+	state := eng.State()
+	mu.Unlock()
+
+	if asJSON {
+		applyAPISecurityHeaders(w.Header())
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		writeJSON(w, map[string]any{
+			"game":      c.Game,
+			"role":      c.Role,
+			"expiresAt": c.Expires.UnixMilli(),
+			"state":     state,
+		})
+		return
+	}
+	s.renderIndex(w, indexInit{State: state, Game: c.Game, Role: c.Role, Token: r.PathValue("token")})
+}
@@ -0,0 +1,193 @@
+// path: chessTest/internal/httpx/share_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestShareTokens(t *testing.T) {
+	key := []byte("0123456789abcdef")
+	now := time.Unix(1_700_000_000, 0)
+	claims := shareClaims{Game: "abc234", Role: "black", Expires: now.Add(time.Hour)}
+	token := signShare(key, claims)
+	payload, mac, _ := strings.Cut(token, ".")
+	forged := signShare(key, shareClaims{Game: "abc234", Role: "white", Expires: claims.Expires})
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	cases := []struct {
+		name    string
+		key     []byte
+		token   string
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", key: key, token: token, at: now},
+		{name: "expired", key: key, token: token, at: claims.Expires, wantErr: errShareExpired},
+		{name: "other key", key: []byte("fedcba9876543210"), token: token, at: now, wantErr: errShareInvalid},
+		{name: "no key", token: token, at: now, wantErr: errShareInvalid},
+		{name: "edited role", key: key, token: forgedPayload + "." + mac, at: now, wantErr: errShareInvalid},
+		{name: "no mac", key: key, token: payload, at: now, wantErr: errShareInvalid},
+		{name: "garbage", key: key, token: "!!.!!", at: now, wantErr: errShareInvalid},
+	}
+	for _, tc := range cases {
+		got, err := verifyShare(tc.key, tc.token, tc.at)
+		if err != tc.wantErr {
+			t.Fatalf("%s: err = %v want %v", tc.name, err, tc.wantErr)
+		}
+		if err == nil && got != claims {
+			t.Fatalf("%s: claims = %+v want %+v", tc.name, got, claims)
+		}
+	}
+}
+
+func TestGameIDsAreShortAndUnique(t *testing.T) {
+	m := NewGameManager(DefaultRulesLimits())
+	format := regexp.MustCompile(`^[a-z2-7]{13}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 64; i++ {
+		id, err := m.Create(game.DefaultRules(), nil)
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if !format.MatchString(id) || seen[id] {
+			t.Fatalf("game id %q: want 13 fresh base32 characters", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestShareLinks(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{"alice": {Subject: "alice", Roles: []string{RolePlayer}}})
+	handler := srv.routes()
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("Accept", "application/json")
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	id, err := srv.games.Create(game.DefaultRules(), nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	share := "/api/games/" + id + "/share"
+
+	if rr := do("alice", http.MethodPost, share, `{"role":"black"}`); rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("without a key: status %d want 503", rr.Code)
+	}
+	srv.SetShareKey([]byte("0123456789abcdef"))
+
+	steps := []struct {
+		name     string
+		token    string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{name: "anonymous", path: share, body: `{}`, wantCode: http.StatusUnauthorized},
+		{name: "bad role", token: "alice", path: share, body: `{"role":"referee"}`, wantCode: http.StatusBadRequest},
+		{name: "ttl too long", token: "alice", path: share, body: `{"ttl":"2000h"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown game", token: "alice", path: "/api/games/nope/share", body: `{}`, wantCode: http.StatusNotFound},
+	}
+	for _, step := range steps {
+		if rr := do(step.token, http.MethodPost, step.path, step.body); rr.Code != step.wantCode {
+			t.Fatalf("%s: status %d want %d (%s)", step.name, rr.Code, step.wantCode, rr.Body.String())
+		}
+	}
+
+	rr := do("alice", http.MethodPost, share, `{"role":"Black","ttl":"1h"}`)
+	var link shareView
+	if err := json.Unmarshal(rr.Body.Bytes(), &link); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("share: status %d body %s", rr.Code, rr.Body.String())
+	}
+	if link.Role != "black" || link.Game != id || link.URL != "/g/"+link.Token {
+		t.Fatalf("unexpected link %+v", link)
+	}
+
+	rr = do("", http.MethodGet, link.URL, "")
+	var opened struct {
+		Game  string          `json:"game"`
+		Role  string          `json:"role"`
+		State game.BoardState `json:"state"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &opened); err != nil || rr.Code != http.StatusOK {
+		t.Fatalf("open: status %d body %s", rr.Code, rr.Body.String())
+	}
+	if opened.Game != id || opened.Role != "black" || len(opened.State.Pieces) == 0 {
+		t.Fatalf("unexpected link target %+v", opened)
+	}
+	if rr := do("", http.MethodGet, link.URL+"x", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("tampered link: status %d want 404", rr.Code)
+	}
+	expired := signShare(srv.shareKey, shareClaims{Game: id, Role: "white", Expires: time.Now().Add(-time.Minute)})
+	if rr := do("", http.MethodGet, "/g/"+expired, ""); rr.Code != http.StatusGone {
+		t.Fatalf("expired link: status %d want 410", rr.Code)
+	}
+	srv.SetShareKey([]byte("a different key!"))
+	if rr := do("", http.MethodGet, link.URL, ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("rotated key: status %d want 404", rr.Code)
+	}
+}
+
+func TestShareLinksActForTheirSeat(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{"alice": {Subject: "alice", Roles: []string{RolePlayer}}})
+	srv.SetShareKey([]byte("0123456789abcdef"))
+	handler := srv.routes()
+	id, err := srv.games.Create(game.DefaultRules(), nil)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	link := func(game, role string) string {
+		return signShare(srv.shareKey, shareClaims{Game: game, Role: role, Expires: time.Now().Add(time.Hour)})
+	}
+	white, black, spectator := link(id, "white"), link(id, "black"), link(id, roleSpectator)
+	base := "/api/games/" + id
+
+	steps := []struct {
+		name     string
+		link     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{name: "no credential", method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusUnauthorized},
+		{name: "forged link", link: white + "x", method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusUnauthorized},
+		{name: "spectator cannot move", link: spectator, method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "black cannot move white", link: black, method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "link to another game", link: link("elsewhere", "white"), method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "link on the default game", link: white, method: http.MethodPost, path: "/api/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusForbidden},
+		{name: "white cannot configure black", link: white, method: http.MethodPost, path: base + "/config", body: `{"color":"black","abilities":["DoOver"],"element":"Fire"}`, wantCode: http.StatusForbidden},
+		{name: "no link configures both sides", link: white, method: http.MethodPost, path: base + "/config/all", body: `{}`, wantCode: http.StatusForbidden},
+		{name: "white configures white", link: white, method: http.MethodPost, path: base + "/config", body: `{"color":"white","abilities":["DoOver"],"element":"Fire"}`, wantCode: http.StatusOK},
+		{name: "white moves", link: white, method: http.MethodPost, path: base + "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusOK},
+		{name: "spectator cannot take a seat", link: spectator, method: http.MethodPost, path: "/api/heartbeat", body: `{"game":"` + id + `","color":"black"}`, wantCode: http.StatusForbidden},
+		{name: "black takes its seat", link: black, method: http.MethodPost, path: "/api/heartbeat", body: `{"game":"` + id + `","color":"black"}`, wantCode: http.StatusOK},
+		{name: "spectator reads seats", link: spectator, method: http.MethodGet, path: "/api/heartbeat?game=" + id, wantCode: http.StatusOK},
+		{name: "black moves", link: black, method: http.MethodPost, path: base + "/move", body: `{"from":"e7","to":"e5"}`, wantCode: http.StatusOK},
+	}
+	for _, st := range steps {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(st.method, st.path, strings.NewReader(st.body))
+		if st.link != "" {
+			req.Header.Set(shareHeader, st.link)
+		}
+		handler.ServeHTTP(rr, req)
+		if rr.Code != st.wantCode {
+			t.Fatalf("%s: status %d want %d (%s)", st.name, rr.Code, st.wantCode, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// path: chessTest/internal/httpx/sparring.go
+package httpx
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"battle_chess_poc/internal/controller"
+	"battle_chess_poc/internal/game"
+)
+
+// Sparring opponents are game.ScriptedOpponents seated by the GameManager, so
+// a UI developer or tester can play a whole game alone. Whenever a move on
+// the game is accepted, or an opponent is seated, opponents whose side is to
+// move reply at once, each move going through the journal like any other.
+// Seating one on both sides plays the game out, up to maxSparringPlies per
+// trigger. A seat held by a player's heartbeats cannot take an opponent.
+
+// maxSparringPlies bounds how many moves opponents play in a row, so two
+// opponents facing each other cannot hold a request forever.
+const maxSparringPlies = 256
+
+// errTurnPassed and errNoReply stop an opponent's move before it is recorded:
+// someone else moved first, or the opponent's script has nothing to play.
+var (
+	errTurnPassed = errors.New("turn passed")
+	errNoReply    = errors.New("no reply")
+)
+
+type sparringSeat struct {
+	opponent *game.ScriptedOpponent
+	seatedBy string
+}
+
+type sparringView struct {
+	Color     string `json:"color"`
+	Policy    string `json:"policy"`
+	Remaining int    `json:"remaining"` // scripted moves not yet played
+	SeatedBy  string `json:"seatedBy,omitempty"`
+}
+
+// seatOpponent puts opp on key's seat, replacing any opponent already there.
+func (m *GameManager) seatOpponent(key seatKey, opp *game.ScriptedOpponent, by string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sparring == nil {
+		m.sparring = make(map[seatKey]*sparringSeat)
+	}
+	m.sparring[key] = &sparringSeat{opponent: opp, seatedBy: by}
+}
+
+// unseatOpponent removes key's opponent, reporting whether there was one.
+func (m *GameManager) unseatOpponent(key seatKey) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.sparring[key]
+	delete(m.sparring, key)
+	return ok
+}
+
+func (m *GameManager) opponent(key seatKey) *game.ScriptedOpponent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if seat, ok := m.sparring[key]; ok {
+		return seat.opponent
+	}
+	return nil
+}
+
+// opponentsOf lists game id's opponents, reading how much of each script is
+// left under mu, the game's lock.
+func (m *GameManager) opponentsOf(id string, mu *sync.Mutex) []sparringView {
+	m.mu.Lock()
+	seats := make(map[game.Color]*sparringSeat, 2)
+	for _, color := range []game.Color{game.White, game.Black} {
+		if seat, ok := m.sparring[seatKey{game: id, color: color}]; ok {
+			seats[color] = seat
+		}
+	}
+	m.mu.Unlock()
+	out := []sparringView{}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, color := range []game.Color{game.White, game.Black} {
+		if seat, ok := seats[color]; ok {
+			out = append(out, sparringView{
+				Color:     color.String(),
+				Policy:    seat.opponent.Policy().String(),
+				Remaining: seat.opponent.Remaining(),
+				SeatedBy:  seat.seatedBy,
+			})
+		}
+	}
+	return out
+}
+
+// spar lets game id's opponents reply while it is their side to move.
+func (s *Server) spar(id string) {
+	if s.games == nil {
+		return
+	}
+	g, ok := s.gameByID(id)
+	if !ok {
+		return
+	}
+	for ply := 0; ply < maxSparringPlies; ply++ {
+		var turn game.Color
+		g.Do(func(eng *game.Engine) { turn = eng.Turn() })
+		opp := s.games.opponent(seatKey{game: id, color: turn})
+		if opp == nil {
+			return
+		}
+		_, err := g.Move(game.MoveRequest{}, controller.MoveOptions{
+			Prepare: func(eng *game.Engine, req *game.MoveRequest) error {
+				if eng.Turn() != turn {
+					return errTurnPassed
+				}
+				next, ok := opp.Next(eng)
+				if !ok {
+					return errNoReply
+				}
+				*req = next
+				return nil
+			},
+		})
+		if errors.Is(err, errTurnPassed) {
+			continue
+		}
+		if err != nil && !errors.Is(err, game.ErrDoOverActivated) && !errors.Is(err, game.ErrCaptureBlocked) {
+			return
+		}
+	}
+}
+
+// ---- API: sparring ----
+
+type sparringBody struct {
+	Color string `json:"color"`
+	// Moves is the script, played in order while it stays legal.
+	Moves []moveBody `json:"moves,omitempty"`
+	// Policy is what follows the script: "stop" (the default), "capture" or
+	// "random".
+	Policy string `json:"policy,omitempty"`
+	Seed   int64  `json:"seed,omitempty"`
+}
+
+// handleSparring lists a game's sparring opponents with GET, seats one with
+// POST and removes it with DELETE ?color=.
+func (s *Server) handleSparring(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	mu, _, ok := s.lookupGame(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "game not found")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]any{"opponents": s.games.opponentsOf(id, mu)})
+	case http.MethodPost:
+		defer r.Body.Close()
+		var body sparringBody
+		if err := decodeBody(r, &body); err != nil {
+			writeDecodeError(w, err)
+			return
+		}
+		color, ok := game.ParseColor(body.Color)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid color")
+			return
+		}
+		policy, ok := game.ParseOpponentPolicy(body.Policy)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid policy")
+			return
+		}
+		script := make([]game.MoveRequest, 0, len(body.Moves))
+		for _, mv := range body.Moves {
+			req, msg := mv.request()
+			if msg != "" {
+				writeError(w, http.StatusBadRequest, msg)
+				return
+			}
+			mv.applyRelative(color, &req)
+			script = append(script, req)
+		}
+		key := seatKey{game: id, color: color}
+		if holder := s.seats.holder(key); holder != "" {
+			writeError(w, http.StatusConflict, "seat held by a player")
+			return
+		}
+		var by string
+		if ident, ok := IdentityFrom(r.Context()); ok {
+			by = ident.Subject
+		}
+		s.games.seatOpponent(key, game.NewScriptedOpponent(script, policy, body.Seed), by)
+		s.spar(id)
+		writeJSON(w, map[string]any{"opponents": s.games.opponentsOf(id, mu)})
+	case http.MethodDelete:
+		color, ok := game.ParseColor(r.URL.Query().Get("color"))
+		if !ok {
+			writeError(w, http.StatusBadRequest, "invalid color")
+			return
+		}
+		if !s.games.unseatOpponent(seatKey{game: id, color: color}) {
+			writeError(w, http.StatusNotFound, "seat has no opponent")
+			return
+		}
+		writeJSON(w, map[string]any{"opponents": s.games.opponentsOf(id, mu)})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
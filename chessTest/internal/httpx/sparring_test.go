@@ -0,0 +1,84 @@
+// path: chessTest/internal/httpx/sparring_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestSparring(t *testing.T) {
+	srv := &Server{engine: game.NewEngine(), games: NewGameManager(DefaultRulesLimits())}
+	srv.SetAuthenticator(StaticTokens{
+		"alice": {Subject: "alice", Roles: []string{RolePlayer}},
+	})
+	handler := srv.routes()
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+	rr := do("alice", http.MethodPost, "/api/games", `{}`)
+	var created gameResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || rr.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body %s", rr.Code, rr.Body.String())
+	}
+	base := "/api/games/" + created.ID
+	state := func() game.BoardState {
+		t.Helper()
+		var got fullGameResponse
+		rr := do("alice", http.MethodGet, base, "")
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatalf("state: %v", err)
+		}
+		return got.State
+	}
+
+	do("alice", http.MethodPost, "/api/heartbeat", `{"game":"`+created.ID+`","color":"white"}`)
+	steps := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "held seat", method: http.MethodPost, path: "/sparring", body: `{"color":"white","policy":"random"}`, wantCode: http.StatusConflict},
+		{name: "bad policy", method: http.MethodPost, path: "/sparring", body: `{"color":"black","policy":"greedy"}`, wantCode: http.StatusBadRequest},
+		{name: "bad script", method: http.MethodPost, path: "/sparring", body: `{"color":"black","moves":[{"from":"e9","to":"e5"}]}`, wantCode: http.StatusBadRequest},
+		{name: "seat", method: http.MethodPost, path: "/sparring", body: `{"color":"black","moves":[{"from":"e7","to":"e5"},{"from":"e5","to":"e4"}],"policy":"capture"}`, wantCode: http.StatusOK, wantBody: `"remaining":2`},
+		{name: "white moves", method: http.MethodPost, path: "/move", body: `{"from":"d2","to":"d4"}`, wantCode: http.StatusOK},
+		{name: "script played", method: http.MethodGet, path: "/sparring", wantCode: http.StatusOK, wantBody: `"remaining":1`},
+		{name: "white blocks the script", method: http.MethodPost, path: "/move", body: `{"from":"e2","to":"e4"}`, wantCode: http.StatusOK},
+		{name: "script abandoned", method: http.MethodGet, path: "/sparring", wantCode: http.StatusOK, wantBody: `"remaining":0`},
+		{name: "unseat", method: http.MethodDelete, path: "/sparring?color=black", wantCode: http.StatusOK, wantBody: `"opponents":[]`},
+		{name: "unseat again", method: http.MethodDelete, path: "/sparring?color=black", wantCode: http.StatusNotFound},
+	}
+	for _, st := range steps {
+		rr := do("alice", st.method, base+st.path, st.body)
+		if rr.Code != st.wantCode || !strings.Contains(rr.Body.String(), st.wantBody) {
+			t.Fatalf("%s: status %d body %s, want %d containing %q", st.name, rr.Code, rr.Body.String(), st.wantCode, st.wantBody)
+		}
+		if st.name == "script played" && state().Seq != 2 {
+			t.Fatalf("opponent did not reply to d4")
+		}
+	}
+	// e5-e4 was blocked, so the capture policy took over with e5xd4.
+	st := state()
+	if st.Seq != 4 || st.Turn != game.White {
+		t.Fatalf("after sparring: seq %d turn %s", st.Seq, st.Turn)
+	}
+	captured := false
+	for _, pc := range st.Pieces {
+		captured = captured || (pc.Square == game.SquareD4 && pc.Color == game.Black)
+	}
+	if !captured {
+		t.Fatalf("black did not capture on d4")
+	}
+}
@@ -0,0 +1,28 @@
+// path: chessTest/internal/httpx/telemetry.go
+package httpx
+
+import (
+	"errors"
+
+	"battle_chess_poc/internal/telemetry"
+)
+
+// SetTelemetry starts anonymous telemetry with cfg on the server's event bus
+// and tracks the default game. Games created through /api/games are tracked
+// unless they ask not to be. Call it after SetBus and before serving
+// requests.
+func (s *Server) SetTelemetry(cfg telemetry.Config) error {
+	if s.bus == nil {
+		return errors.New("telemetry needs the event bus")
+	}
+	r, err := telemetry.Start(s.bus, cfg)
+	if err != nil {
+		return err
+	}
+	s.engineMu.Lock()
+	variant := s.engine.Rules().Variant
+	s.engineMu.Unlock()
+	r.Track(DefaultGameID, variant)
+	s.telemetry = r
+	return nil
+}
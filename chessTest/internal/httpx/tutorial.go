@@ -0,0 +1,133 @@
+// path: chessTest/internal/httpx/tutorial.go
+package httpx
+
+import (
+	"errors"
+	"net/http"
+
+	"battle_chess_poc/internal/game"
+)
+
+type tutorialView struct {
+	Step     int             `json:"step"`
+	Steps    int             `json:"steps"`
+	Title    string          `json:"title"`
+	Prompt   string          `json:"prompt"`
+	Feedback string          `json:"feedback,omitempty"`
+	Done     bool            `json:"done"`
+	State    game.BoardState `json:"state"`
+}
+
+func newTutorialView(st game.TutorialStatus) tutorialView {
+	return tutorialView{
+		Step:     st.Step,
+		Steps:    st.Steps,
+		Title:    st.Title,
+		Prompt:   st.Prompt,
+		Feedback: st.Feedback,
+		Done:     st.Done,
+		State:    st.State,
+	}
+}
+
+// tutorialLocked returns the server's tutorial, starting it on first use.
+// The caller holds tutorialMu.
+func (s *Server) tutorialLocked() (*game.Tutorial, error) {
+	if s.tutorial == nil {
+		tut, err := game.NewTutorial(nil)
+		if err != nil {
+			return nil, err
+		}
+		s.tutorial = tut
+	}
+	return s.tutorial, nil
+}
+
+// ---- API: tutorial ----
+
+func (s *Server) handleTutorial(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.tutorialMu.Lock()
+	tut, err := s.tutorialLocked()
+	var view tutorialView
+	if err == nil {
+		view = newTutorialView(tut.Status())
+	}
+	s.tutorialMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, view)
+}
+
+func (s *Server) handleTutorialMove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	defer r.Body.Close()
+	var body moveBody
+	if err := decodeBody(r, &body); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	req, msg := body.request()
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	s.tutorialMu.Lock()
+	tut, err := s.tutorialLocked()
+	if err != nil {
+		s.tutorialMu.Unlock()
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	body.applyRelative(tut.Status().State.Turn, &req)
+	hint := tut.Explain(req)
+	st, err := tut.Move(req)
+	s.tutorialMu.Unlock()
+
+	view := newTutorialView(st)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, game.ErrTutorialFinished) {
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		writeJSON(w, struct {
+			Error       string       `json:"error"`
+			Explanation string       `json:"explanation,omitempty"`
+			Tutorial    tutorialView `json:"tutorial"`
+		}{Error: err.Error(), Explanation: hint, Tutorial: view})
+		return
+	}
+	writeJSON(w, view)
+}
+
+func (s *Server) handleTutorialRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	s.tutorialMu.Lock()
+	tut, err := s.tutorialLocked()
+	if err == nil {
+		err = tut.Restart()
+	}
+	var view tutorialView
+	if err == nil {
+		view = newTutorialView(tut.Status())
+	}
+	s.tutorialMu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, view)
+}
@@ -0,0 +1,53 @@
+// path: chessTest/internal/httpx/tutorial_test.go
+package httpx
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestTutorialEndpoints(t *testing.T) {
+	srv := &Server{engine: game.NewEngine()}
+	handler := srv.routes()
+	do := func(method, path, body string) (int, tutorialView) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(method, path, strings.NewReader(body)))
+		var view tutorialView
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &view); err != nil {
+				t.Fatalf("decode %s: %v", path, err)
+			}
+			return rr.Code, view
+		}
+		var failed struct {
+			Explanation string       `json:"explanation"`
+			Tutorial    tutorialView `json:"tutorial"`
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &failed); err != nil {
+			t.Fatalf("decode %s: %v", path, err)
+		}
+		if failed.Explanation == "" {
+			t.Fatalf("%s: rejected move without explanation: %s", path, rr.Body.String())
+		}
+		return rr.Code, failed.Tutorial
+	}
+
+	if code, view := do(http.MethodGet, "/api/tutorial", ""); code != http.StatusOK || view.Step != 1 || view.Prompt == "" {
+		t.Fatalf("unexpected start %d %+v", code, view)
+	}
+	if code, view := do(http.MethodPost, "/api/tutorial/move", `{"from":"d2","to":"d4"}`); code != http.StatusBadRequest || view.Step != 1 {
+		t.Fatalf("off-script move: %d %+v", code, view)
+	}
+	if code, view := do(http.MethodPost, "/api/tutorial/move", `{"from":"e2","to":"e4"}`); code != http.StatusOK || view.Step != 2 || view.Feedback == "" {
+		t.Fatalf("scripted move: %d %+v", code, view)
+	}
+	if code, view := do(http.MethodPost, "/api/tutorial/restart", ""); code != http.StatusOK || view.Step != 1 {
+		t.Fatalf("restart: %d %+v", code, view)
+	}
+}
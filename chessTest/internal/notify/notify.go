@@ -0,0 +1,389 @@
+// path: chessTest/internal/notify/notify.go
+// Package notify tells players of slow games that it is their turn. A
+// Notifier subscribes to the event bus; after each turn of a tracked game it
+// asks its Recipient callback who is to move and how they want to hear about
+// it, then POSTs to their webhook and/or mails them. Deliveries are queued
+// for a fixed pool of workers, which retry failures with exponential
+// backoff, so a dead endpoint never holds up the bus; when the queue is full
+// the notification is dropped and logged. Webhooks may not reach loopback,
+// private or link-local addresses unless the operator allows the network.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+// Defaults used when Config leaves the retry policy or pool size unset.
+const (
+	DefaultAttempts = 5
+	DefaultBackoff  = 2 * time.Second
+	DefaultWorkers  = 4
+	DefaultQueue    = 64
+)
+
+// ErrBlockedDestination reports a webhook aimed at a loopback, private or
+// link-local address.
+var ErrBlockedDestination = errors.New("notify: webhook destination not allowed")
+
+// Preferences is how one player wants to hear that it is their turn. Either
+// channel may be empty; with both empty the player is not notified.
+type Preferences struct {
+	// Webhook is an http or https URL that receives a Notification as a
+	// JSON POST.
+	Webhook string
+	// Email is an address mailed through Config.SMTPAddr.
+	Email string
+}
+
+// Validate checks that the webhook is an http(s) URL whose host is not
+// localhost or a loopback, private or link-local address, and that the email
+// address looks like one. Names are checked again, once resolved, when the
+// webhook is sent.
+func (p Preferences) Validate() error {
+	if p.Webhook != "" {
+		u, err := url.Parse(p.Webhook)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %q is not an http(s) URL", p.Webhook)
+		}
+		host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+		if addr, err := netip.ParseAddr(host); (err == nil && internal(addr)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("webhook %q: %w", p.Webhook, ErrBlockedDestination)
+		}
+	}
+	if p.Email != "" && (strings.ContainsAny(p.Email, " \r\n<>,") || !strings.Contains(strings.TrimPrefix(p.Email, "@"), "@")) {
+		return fmt.Errorf("invalid email address %q", p.Email)
+	}
+	return nil
+}
+
+func (p Preferences) empty() bool { return p.Webhook == "" && p.Email == "" }
+
+// internal reports whether addr is loopback, private, link-local, multicast
+// or unspecified: somewhere a player's webhook has no business reaching.
+func internal(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		addr.IsMulticast() || addr.IsUnspecified()
+}
+
+// ParseNetworks parses a comma-separated list of CIDR prefixes for
+// Config.AllowNetworks.
+func ParseNetworks(s string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(part)
+		if err != nil {
+			return nil, fmt.Errorf("notify: network %q: %w", part, err)
+		}
+		out = append(out, prefix.Masked())
+	}
+	return out, nil
+}
+
+// Notification is the webhook body, and the content of the mail.
+type Notification struct {
+	Game    string    `json:"game"`
+	Color   string    `json:"color"`
+	Subject string    `json:"subject"`
+	Seq     uint64    `json:"seq"`
+	At      time.Time `json:"at"`
+}
+
+// Recipient is who is to move in a game and where to tell them.
+type Recipient struct {
+	Subject string
+	Color   game.Color
+	// Seq is the game's sequence number when it became their turn; a turn is
+	// notified once.
+	Seq   uint64
+	Prefs Preferences
+}
+
+// Config wires a Notifier.
+type Config struct {
+	// Recipient reports who is to move in game id, or false when nobody
+	// should be told: the game is over or the seat has no preferences.
+	Recipient func(id string) (Recipient, bool)
+	// SMTPAddr is the host:port mail is sent through; email preferences are
+	// ignored when it is empty.
+	SMTPAddr string
+	From     string
+	// Attempts is how many times a delivery is tried; Backoff is the wait
+	// before the first retry, doubled for each one after.
+	Attempts int
+	Backoff  time.Duration
+	// Workers is how many deliveries run at once, and Queue how many more
+	// may wait for a worker; zero uses DefaultWorkers and DefaultQueue.
+	Workers int
+	Queue   int
+	// AllowNetworks lists internal networks webhooks may reach anyway, such
+	// as an in-cluster relay. Preferences.Validate still refuses literal
+	// addresses in them; only names resolving there are let through.
+	AllowNetworks []netip.Prefix
+	// Client sends webhooks; nil uses a client with a 10 second timeout
+	// that refuses internal destinations outside AllowNetworks. A client
+	// given here is used as is.
+	Client *http.Client
+	// SendMail sends mail; nil uses smtp.SendMail.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Notifier sends turn notifications for tracked games until Close.
+type Notifier struct {
+	cfg  Config
+	sub  *bus.Subscription
+	jobs chan delivery
+	done chan struct{}
+	stop chan struct{}
+	once sync.Once
+	wg   sync.WaitGroup
+
+	mu      sync.Mutex
+	tracked map[string]uint64 // game id -> last Seq notified
+}
+
+// Start validates cfg and begins watching b.
+func Start(b *bus.Bus, cfg Config) (*Notifier, error) {
+	if cfg.Recipient == nil {
+		return nil, errors.New("notify: no recipient callback")
+	}
+	if cfg.SMTPAddr != "" && cfg.From == "" {
+		return nil, errors.New("notify: mail needs a From address")
+	}
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = DefaultAttempts
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultWorkers
+	}
+	if cfg.Queue <= 0 {
+		cfg.Queue = DefaultQueue
+	}
+	if cfg.Client == nil {
+		cfg.Client = webhookClient(cfg.AllowNetworks)
+	}
+	if cfg.SendMail == nil {
+		cfg.SendMail = smtp.SendMail
+	}
+	n := &Notifier{
+		cfg:     cfg,
+		sub:     b.Subscribe("", 256),
+		jobs:    make(chan delivery, cfg.Queue),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		tracked: make(map[string]uint64),
+	}
+	n.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go n.work()
+	}
+	go n.run()
+	return n, nil
+}
+
+// webhookClient dials only public addresses, or ones inside allow. The check
+// runs on the resolved address of every connection, redirects included.
+func webhookClient(allow []netip.Prefix) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			ap, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrBlockedDestination, address)
+			}
+			addr := ap.Addr().Unmap()
+			if !internal(addr) {
+				return nil
+			}
+			for _, prefix := range allow {
+				if prefix.Contains(addr) {
+					return nil
+				}
+			}
+			return fmt.Errorf("%w: %s", ErrBlockedDestination, addr)
+		},
+	}
+	return &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, address)
+			},
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        16,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// Track turns on notifications for game id.
+func (n *Notifier) Track(id string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if _, ok := n.tracked[id]; !ok {
+		n.tracked[id] = 0
+	}
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+	for {
+		select {
+		case msg, ok := <-n.sub.C:
+			if !ok {
+				return
+			}
+			n.observe(msg)
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+// observe reacts to the events that hand the turn over: a move, or a DoOver
+// rewinding one.
+func (n *Notifier) observe(msg bus.Message) {
+	if msg.Seat != nil || (msg.Event.Kind != game.EventMove && msg.Event.Kind != game.EventDoOver) {
+		return
+	}
+	n.mu.Lock()
+	_, ok := n.tracked[msg.Game]
+	n.mu.Unlock()
+	if !ok {
+		return
+	}
+	rcpt, ok := n.cfg.Recipient(msg.Game)
+	if !ok || rcpt.Prefs.empty() {
+		return
+	}
+	n.mu.Lock()
+	if last := n.tracked[msg.Game]; rcpt.Seq <= last {
+		n.mu.Unlock()
+		return
+	}
+	n.tracked[msg.Game] = rcpt.Seq
+	n.mu.Unlock()
+
+	note := Notification{Game: msg.Game, Color: rcpt.Color.String(), Subject: rcpt.Subject, Seq: rcpt.Seq, At: time.Now().UTC()}
+	if rcpt.Prefs.Webhook != "" {
+		n.deliver(delivery{channel: "webhook", game: msg.Game, send: func() error { return n.postWebhook(rcpt.Prefs.Webhook, note) }})
+	}
+	if rcpt.Prefs.Email != "" && n.cfg.SMTPAddr != "" {
+		n.deliver(delivery{channel: "email", game: msg.Game, send: func() error { return n.sendMail(rcpt.Prefs.Email, note) }})
+	}
+}
+
+// delivery is one notification waiting for a worker.
+type delivery struct {
+	channel string
+	game    string
+	send    func() error
+}
+
+// deliver queues d for the workers, or drops and logs it when the queue is
+// full. It reports whether d was queued.
+func (n *Notifier) deliver(d delivery) bool {
+	select {
+	case n.jobs <- d:
+		return true
+	default:
+		log.Printf("notify: queue full, dropping %s for game %s", d.channel, d.game)
+		return false
+	}
+}
+
+// work runs queued deliveries until the notifier closes.
+func (n *Notifier) work() {
+	defer n.wg.Done()
+	for {
+		select {
+		case d := <-n.jobs:
+			n.attempt(d)
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+// attempt runs d, retrying with backoff until it succeeds, runs out of
+// attempts or the notifier closes.
+func (n *Notifier) attempt(d delivery) {
+	wait := n.cfg.Backoff
+	var err error
+	for attempt := 1; attempt <= n.cfg.Attempts; attempt++ {
+		if err = d.send(); err == nil {
+			return
+		}
+		if attempt == n.cfg.Attempts || errors.Is(err, ErrBlockedDestination) {
+			break
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+			wait *= 2
+		case <-n.stop:
+			timer.Stop()
+			return
+		}
+	}
+	log.Printf("notify: %s for game %s gave up: %v", d.channel, d.game, err)
+}
+
+func (n *Notifier) postWebhook(target string, note Notification) error {
+	data, err := json.Marshal(note)
+	if err != nil {
+		return err
+	}
+	resp, err := n.cfg.Client.Post(target, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+func (n *Notifier) sendMail(to string, note Notification) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: Your move in game %s\r\n\r\n", n.cfg.From, to, note.Game)
+	fmt.Fprintf(&msg, "It is %s's turn (move %d) in game %s.\r\n", note.Color, note.Seq, note.Game)
+	return n.cfg.SendMail(n.cfg.SMTPAddr, nil, n.cfg.From, []string{to}, []byte(msg.String()))
+}
+
+// Close stops watching the bus and abandons queued deliveries and pending
+// retries.
+func (n *Notifier) Close() {
+	n.once.Do(func() {
+		close(n.stop)
+		<-n.done
+		n.sub.Close()
+		n.wg.Wait()
+	})
+}
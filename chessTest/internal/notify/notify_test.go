@@ -0,0 +1,184 @@
+// path: chessTest/internal/notify/notify_test.go
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func TestPreferencesValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		prefs   Preferences
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "webhook", prefs: Preferences{Webhook: "https://example.test/hook"}},
+		{name: "email", prefs: Preferences{Email: "bob@example.test"}},
+		{name: "not http", prefs: Preferences{Webhook: "file:///etc/passwd"}, wantErr: true},
+		{name: "no host", prefs: Preferences{Webhook: "http://"}, wantErr: true},
+		{name: "loopback", prefs: Preferences{Webhook: "http://127.0.0.1:8080/hook"}, wantErr: true},
+		{name: "loopback v6", prefs: Preferences{Webhook: "http://[::1]/hook"}, wantErr: true},
+		{name: "localhost", prefs: Preferences{Webhook: "http://localhost/hook"}, wantErr: true},
+		{name: "private", prefs: Preferences{Webhook: "https://10.1.2.3/hook"}, wantErr: true},
+		{name: "link-local", prefs: Preferences{Webhook: "http://169.254.169.254/latest/meta-data"}, wantErr: true},
+		{name: "public address", prefs: Preferences{Webhook: "https://203.0.113.7/hook"}},
+		{name: "no at", prefs: Preferences{Email: "bob"}, wantErr: true},
+		{name: "header injection", prefs: Preferences{Email: "bob@example.test\r\nBcc: eve@example.test"}, wantErr: true},
+	}
+	for _, tc := range cases {
+		if err := tc.prefs.Validate(); (err != nil) != tc.wantErr {
+			t.Fatalf("%s: err = %v, want error %t", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestNotifierRetriesAndDedups(t *testing.T) {
+	var mu sync.Mutex
+	var got []Notification
+	calls := 0
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var n Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err == nil {
+			got = append(got, n)
+		}
+	}))
+	defer hook.Close()
+
+	var mails []string
+	b := bus.New()
+	defer b.Close()
+	n, err := Start(b, Config{
+		Recipient: func(id string) (Recipient, bool) {
+			return Recipient{Subject: "bob", Color: game.Black, Seq: 1, Prefs: Preferences{Webhook: hook.URL, Email: "bob@example.test"}}, id == "g1"
+		},
+		SMTPAddr:      "mail.example.test:25",
+		From:          "chess@example.test",
+		Backoff:       time.Millisecond,
+		AllowNetworks: []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")},
+		SendMail: func(_ string, _ smtp.Auth, _ string, to []string, msg []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			mails = append(mails, to[0]+": "+string(msg))
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	n.Track("g1")
+	b.Publish(bus.Message{Game: "g1", Event: game.Event{Seq: 1, Kind: game.EventMove}})
+	b.Publish(bus.Message{Game: "g1", Event: game.Event{Seq: 2, Kind: game.EventCapture}})
+	b.Publish(bus.Message{Game: "g1", Event: game.Event{Seq: 3, Kind: game.EventMove}}) // same turn as seen by Recipient
+	b.Publish(bus.Message{Game: "g2", Event: game.Event{Seq: 1, Kind: game.EventMove}}) // untracked
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		done := len(got) > 0 && len(mails) > 0
+		mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	n.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 || len(got) != 1 || got[0] != (Notification{Game: "g1", Color: "black", Subject: "bob", Seq: 1, At: got[0].At}) {
+		t.Fatalf("webhook calls %d, notifications %+v; want one retried delivery for g1", calls, got)
+	}
+	if len(mails) != 1 || !strings.Contains(mails[0], "bob@example.test: From: chess@example.test") {
+		t.Fatalf("mails = %q", mails)
+	}
+}
+
+func TestWebhookClientRefusesInternalDestinations(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer hook.Close()
+	cases := []struct {
+		name    string
+		allow   string
+		blocked bool
+	}{
+		{name: "loopback refused", blocked: true},
+		{name: "other network allowed", allow: "10.0.0.0/8", blocked: true},
+		{name: "loopback allowed", allow: "127.0.0.0/8, ::1/128"},
+	}
+	for _, tc := range cases {
+		allow, err := ParseNetworks(tc.allow)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", tc.name, err)
+		}
+		resp, err := webhookClient(allow).Post(hook.URL, "application/json", strings.NewReader("{}"))
+		if err == nil {
+			resp.Body.Close()
+		}
+		if errors.Is(err, ErrBlockedDestination) != tc.blocked {
+			t.Fatalf("%s: err = %v, want blocked %t", tc.name, err, tc.blocked)
+		}
+	}
+	if _, err := ParseNetworks("10.0.0.0/33"); err == nil {
+		t.Fatalf("bad prefix parsed")
+	}
+}
+
+func TestNotifierDropsWhenQueueFull(t *testing.T) {
+	b := bus.New()
+	defer b.Close()
+	n, err := Start(b, Config{
+		Recipient: func(string) (Recipient, bool) { return Recipient{}, false },
+		Workers:   1,
+		Queue:     1,
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer n.Close()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	block := func() error {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return nil
+	}
+	cases := []struct {
+		name   string
+		queued bool
+	}{
+		{name: "taken by the worker", queued: true},
+		{name: "waits in the queue", queued: true},
+		{name: "dropped", queued: false},
+	}
+	for i, tc := range cases {
+		if got := n.deliver(delivery{channel: "webhook", game: "g1", send: block}); got != tc.queued {
+			t.Fatalf("%s: queued = %t want %t", tc.name, got, tc.queued)
+		}
+		if i == 0 {
+			<-started
+		}
+	}
+}
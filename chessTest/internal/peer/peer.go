@@ -0,0 +1,392 @@
+// path: chessTest/internal/peer/peer.go
+// Package peer lets two battle_chess processes play a match against each other
+// over a stream connection, with no client in between.
+//
+// Messages are newline-delimited JSON objects. A match runs:
+//
+//	challenger -> challenge{version, name, color, hash}
+//	responder  -> accept{version, name} or decline{reason}
+//	challenger -> loadout{abilities, element}
+//	responder  -> loadout{abilities, element}
+//	mover      -> move{from, to, dir, promotion, ply, hash}   (repeated)
+//	challenger -> result{status, reason, ply, hash}
+//	responder  -> result{status, reason, ply, hash}
+//
+// Each side runs its own engine. Every move carries the hash the mover reached
+// after playing it; the receiver replays the move and aborts with an error
+// message when its own hash differs. Either side may send error{reason} at any
+// point to abandon the match.
+package peer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Version is bumped whenever the message flow changes.
+const Version = 1
+
+var (
+	ErrDeclined = errors.New("peer declined the challenge")
+	ErrDesync   = errors.New("peer position hash mismatch")
+	ErrProtocol = errors.New("unexpected peer message")
+	ErrAborted  = errors.New("peer abandoned the match")
+)
+
+// Message is the single wire shape; Type selects which fields are meaningful.
+type Message struct {
+	Type      string   `json:"type"`
+	Version   int      `json:"version,omitempty"`
+	Name      string   `json:"name,omitempty"`
+	Color     string   `json:"color,omitempty"`
+	Abilities []string `json:"abilities,omitempty"`
+	Element   string   `json:"element,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	Dir       string   `json:"dir,omitempty"`
+	Promotion string   `json:"promotion,omitempty"`
+	Ply       uint32   `json:"ply,omitempty"`
+	Hash      uint64   `json:"hash,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+}
+
+const (
+	msgChallenge = "challenge"
+	msgAccept    = "accept"
+	msgDecline   = "decline"
+	msgLoadout   = "loadout"
+	msgMove      = "move"
+	msgResult    = "result"
+	msgError     = "error"
+)
+
+// Strategy picks the local side's moves.
+type Strategy interface {
+	ChooseMove(eng *game.Engine) (game.MoveRequest, error)
+}
+
+// StrategyFunc adapts a function to Strategy.
+type StrategyFunc func(eng *game.Engine) (game.MoveRequest, error)
+
+func (f StrategyFunc) ChooseMove(eng *game.Engine) (game.MoveRequest, error) {
+	return f(eng)
+}
+
+// FirstLegal plays the first legal move in board order.
+var FirstLegal = StrategyFunc(func(eng *game.Engine) (game.MoveRequest, error) {
+	return eng.LegalMoves()[0], nil
+})
+
+// RandomLegal plays a uniformly random legal move drawn from r.
+func RandomLegal(r *rand.Rand) Strategy {
+	return StrategyFunc(func(eng *game.Engine) (game.MoveRequest, error) {
+		moves := eng.LegalMoves()
+		return moves[r.Intn(len(moves))], nil
+	})
+}
+
+// Config is one side's entry into a match.
+type Config struct {
+	Name string
+	// Color is the side the challenger plays; the responder takes the other.
+	Color     game.Color
+	Abilities game.AbilityList
+	Element   game.Element
+	// Strategy chooses moves; FirstLegal when nil. It is only consulted when
+	// the side to move has at least one legal move.
+	Strategy Strategy
+	// MaxPlies ends the match as a draw once the board reaches it; zero means
+	// no limit.
+	MaxPlies uint32
+}
+
+// Result is how a match ended, as both peers agreed.
+type Result struct {
+	Opponent string
+	Color    game.Color
+	Status   game.GameStatus
+	Reason   string
+	Plies    uint32
+	Hash     uint64
+}
+
+type session struct {
+	enc      *json.Encoder
+	dec      *json.Decoder
+	eng      *game.Engine
+	cfg      Config
+	color    game.Color
+	opponent string
+	lead     bool
+}
+
+// Challenge opens a match on conn as the challenger. eng must hold the
+// starting position both peers agreed on; the responder rejects the challenge
+// when its own starting hash differs.
+func Challenge(conn io.ReadWriter, eng *game.Engine, cfg Config) (Result, error) {
+	s := newSession(conn, eng, cfg, cfg.Color)
+	s.lead = true
+	err := s.send(Message{Type: msgChallenge, Version: Version, Name: cfg.Name, Color: cfg.Color.String(), Hash: eng.State().Hash})
+	if err != nil {
+		return Result{}, err
+	}
+	reply, err := s.recv()
+	if err != nil {
+		return Result{}, err
+	}
+	switch reply.Type {
+	case msgAccept:
+	case msgDecline:
+		return Result{}, fmt.Errorf("%w: %s", ErrDeclined, reply.Reason)
+	default:
+		return Result{}, fmt.Errorf("%w: %q during handshake", ErrProtocol, reply.Type)
+	}
+	s.opponent = reply.Name
+	if err := s.sendLoadout(); err != nil {
+		return Result{}, err
+	}
+	if err := s.recvLoadout(); err != nil {
+		return Result{}, err
+	}
+	return s.play()
+}
+
+// Accept answers a challenge read from conn and plays the match. cfg.Color is
+// ignored; the responder takes the color the challenger left.
+func Accept(conn io.ReadWriter, eng *game.Engine, cfg Config) (Result, error) {
+	s := newSession(conn, eng, cfg, game.White)
+	msg, err := s.recv()
+	if err != nil {
+		return Result{}, err
+	}
+	if msg.Type != msgChallenge {
+		return Result{}, s.abort(fmt.Errorf("%w: %q before challenge", ErrProtocol, msg.Type))
+	}
+	var reason string
+	switch {
+	case msg.Version != Version:
+		reason = fmt.Sprintf("protocol version %d, want %d", msg.Version, Version)
+	case msg.Color != game.White.String() && msg.Color != game.Black.String():
+		reason = fmt.Sprintf("unknown color %q", msg.Color)
+	case msg.Hash != eng.State().Hash:
+		reason = "starting positions differ"
+	}
+	if reason != "" {
+		if err := s.send(Message{Type: msgDecline, Reason: reason}); err != nil {
+			return Result{}, err
+		}
+		return Result{}, fmt.Errorf("%w: %s", ErrDeclined, reason)
+	}
+	if msg.Color == game.White.String() {
+		s.color = game.Black
+	}
+	s.opponent = msg.Name
+	if err := s.send(Message{Type: msgAccept, Version: Version, Name: cfg.Name}); err != nil {
+		return Result{}, err
+	}
+	if err := s.recvLoadout(); err != nil {
+		return Result{}, err
+	}
+	if err := s.sendLoadout(); err != nil {
+		return Result{}, err
+	}
+	return s.play()
+}
+
+func newSession(conn io.ReadWriter, eng *game.Engine, cfg Config, color game.Color) *session {
+	if cfg.Strategy == nil {
+		cfg.Strategy = FirstLegal
+	}
+	return &session{enc: json.NewEncoder(conn), dec: json.NewDecoder(conn), eng: eng, cfg: cfg, color: color}
+}
+
+func (s *session) sendLoadout() error {
+	if err := s.eng.SetSideConfig(s.color, s.cfg.Abilities, s.cfg.Element); err != nil {
+		return s.abort(err)
+	}
+	return s.send(Message{Type: msgLoadout, Abilities: s.cfg.Abilities.Strings(), Element: s.cfg.Element.String()})
+}
+
+func (s *session) recvLoadout() error {
+	msg, err := s.recv()
+	if err != nil {
+		return err
+	}
+	if msg.Type != msgLoadout {
+		return s.abort(fmt.Errorf("%w: %q instead of loadout", ErrProtocol, msg.Type))
+	}
+	abilities := make(game.AbilityList, 0, len(msg.Abilities))
+	for _, name := range msg.Abilities {
+		id, ok := game.ParseAbility(name)
+		if !ok {
+			return s.abort(fmt.Errorf("%w: unknown ability %q", ErrProtocol, name))
+		}
+		abilities = append(abilities, id)
+	}
+	element, ok := game.ParseElement(msg.Element)
+	if !ok {
+		return s.abort(fmt.Errorf("%w: unknown element %q", ErrProtocol, msg.Element))
+	}
+	if err := s.eng.SetSideConfig(s.color.Opposite(), abilities, element); err != nil {
+		return s.abort(err)
+	}
+	return nil
+}
+
+// play relays moves until the match ends, then exchanges results. Every end
+// condition is decided from the shared position, so both peers reach it on
+// the same ply without negotiating.
+func (s *session) play() (Result, error) {
+	for {
+		if res, over := s.outcome(); over {
+			return s.exchangeResult(res)
+		}
+		if s.eng.State().Turn == s.color {
+			if err := s.playLocal(); err != nil {
+				return Result{}, err
+			}
+			continue
+		}
+		if err := s.playRemote(); err != nil {
+			return Result{}, err
+		}
+	}
+}
+
+func (s *session) outcome() (Result, bool) {
+	st := s.eng.State()
+	res := Result{Opponent: s.opponent, Color: s.color, Status: st.Status, Reason: st.StatusReason, Plies: s.eng.Ply(), Hash: st.Hash}
+	switch {
+	case st.Status != game.StatusActive:
+		return res, true
+	case s.cfg.MaxPlies > 0 && res.Plies >= s.cfg.MaxPlies:
+		res.Status, res.Reason = game.StatusDraw, "ply limit reached"
+		return res, true
+	case len(s.eng.LegalMoves()) == 0:
+		res.Status, res.Reason = game.StatusDraw, st.Turn.String()+" has no legal move"
+		return res, true
+	}
+	return res, false
+}
+
+func (s *session) playLocal() error {
+	req, err := s.cfg.Strategy.ChooseMove(s.eng)
+	if err != nil {
+		return s.abort(err)
+	}
+	if _, err := s.eng.MoveEx(req); err != nil && !errors.Is(err, game.ErrDoOverActivated) {
+		return s.abort(fmt.Errorf("strategy chose %s%s: %w", game.SquareToCoord(req.From), game.SquareToCoord(req.To), err))
+	}
+	st := s.eng.State()
+	msg := Message{
+		Type: msgMove,
+		From: game.SquareToCoord(req.From),
+		To:   game.SquareToCoord(req.To),
+		Dir:  req.Dir.String(),
+		Ply:  s.eng.Ply(),
+		Hash: st.Hash,
+	}
+	if req.HasPromotion {
+		msg.Promotion = req.Promotion.String()
+	}
+	return s.send(msg)
+}
+
+func (s *session) playRemote() error {
+	msg, err := s.recv()
+	if err != nil {
+		return err
+	}
+	if msg.Type != msgMove {
+		return s.abort(fmt.Errorf("%w: %q instead of move", ErrProtocol, msg.Type))
+	}
+	req, err := moveRequest(msg)
+	if err != nil {
+		return s.abort(err)
+	}
+	if _, err := s.eng.MoveEx(req); err != nil && !errors.Is(err, game.ErrDoOverActivated) {
+		return s.abort(fmt.Errorf("peer move %s%s: %w", msg.From, msg.To, err))
+	}
+	if got := s.eng.State().Hash; got != msg.Hash {
+		return s.abort(fmt.Errorf("%w after %s%s: have %x, peer %x", ErrDesync, msg.From, msg.To, got, msg.Hash))
+	}
+	return nil
+}
+
+// exchangeResult reports res and checks the peer saw the same ending. The
+// challenger speaks first so an unbuffered connection cannot deadlock.
+func (s *session) exchangeResult(res Result) (Result, error) {
+	mine := Message{Type: msgResult, Status: res.Status.String(), Reason: res.Reason, Ply: res.Plies, Hash: res.Hash}
+	if s.lead {
+		if err := s.send(mine); err != nil {
+			return Result{}, err
+		}
+	}
+	theirs, err := s.recv()
+	if err != nil {
+		return Result{}, err
+	}
+	if theirs.Type != msgResult {
+		return Result{}, s.abort(fmt.Errorf("%w: %q instead of result", ErrProtocol, theirs.Type))
+	}
+	if theirs.Hash != mine.Hash || theirs.Status != mine.Status || theirs.Ply != mine.Ply {
+		return Result{}, s.abort(fmt.Errorf("%w: peer reported %s at ply %d (%x)", ErrDesync, theirs.Status, theirs.Ply, theirs.Hash))
+	}
+	if !s.lead {
+		if err := s.send(mine); err != nil {
+			return Result{}, err
+		}
+	}
+	return res, nil
+}
+
+func moveRequest(msg Message) (game.MoveRequest, error) {
+	from, okFrom := game.CoordToSquare(strings.ToLower(msg.From))
+	to, okTo := game.CoordToSquare(strings.ToLower(msg.To))
+	if !okFrom || !okTo {
+		return game.MoveRequest{}, fmt.Errorf("%w: bad squares %q %q", ErrProtocol, msg.From, msg.To)
+	}
+	req := game.MoveRequest{From: from, To: to}
+	if msg.Dir != "" {
+		if req.Dir = game.ParseDirection(msg.Dir); req.Dir == game.DirNone {
+			return game.MoveRequest{}, fmt.Errorf("%w: bad direction %q", ErrProtocol, msg.Dir)
+		}
+	}
+	if msg.Promotion != "" {
+		pt, ok := game.ParsePromotionPiece(msg.Promotion)
+		if !ok {
+			return game.MoveRequest{}, fmt.Errorf("%w: bad promotion %q", ErrProtocol, msg.Promotion)
+		}
+		req.Promotion, req.HasPromotion = pt, true
+	}
+	return req, nil
+}
+
+func (s *session) send(msg Message) error {
+	return s.enc.Encode(msg)
+}
+
+// recv reads the next message, turning a peer error message into ErrAborted.
+func (s *session) recv() (Message, error) {
+	var msg Message
+	if err := s.dec.Decode(&msg); err != nil {
+		return msg, err
+	}
+	if msg.Type == msgError {
+		return msg, fmt.Errorf("%w: %s", ErrAborted, msg.Reason)
+	}
+	return msg, nil
+}
+
+// abort tells the peer why the match is being abandoned and returns err.
+func (s *session) abort(err error) error {
+	s.send(Message{Type: msgError, Reason: err.Error()})
+	return err
+}
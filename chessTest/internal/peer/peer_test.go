@@ -0,0 +1,112 @@
+// path: chessTest/internal/peer/peer_test.go
+package peer
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"net"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+type outcome struct {
+	res Result
+	err error
+}
+
+func TestMatchBetweenPeers(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	done := make(chan outcome, 1)
+	go func() {
+		res, err := Accept(b, game.NewEngine(), Config{
+			Name:      "south",
+			Abilities: game.AbilityList{game.AbilityDoOver},
+			Element:   game.ElementShadow,
+			Strategy:  RandomLegal(rand.New(rand.NewSource(7))),
+			MaxPlies:  40,
+		})
+		done <- outcome{res, err}
+	}()
+	local, err := Challenge(a, game.NewEngine(), Config{
+		Name:      "north",
+		Color:     game.White,
+		Abilities: game.AbilityList{game.AbilityBlockPath},
+		Element:   game.ElementLight,
+		MaxPlies:  40,
+	})
+	if err != nil {
+		t.Fatalf("challenger: %v", err)
+	}
+	remote := <-done
+	if remote.err != nil {
+		t.Fatalf("responder: %v", remote.err)
+	}
+	if local.Opponent != "south" || remote.res.Opponent != "north" || remote.res.Color != game.Black {
+		t.Fatalf("unexpected pairing %+v / %+v", local, remote.res)
+	}
+	if local.Hash != remote.res.Hash || local.Status != remote.res.Status || local.Plies != remote.res.Plies {
+		t.Fatalf("peers disagree: %+v vs %+v", local, remote.res)
+	}
+	if local.Plies == 0 || local.Reason == "" {
+		t.Fatalf("match ended without play or reason: %+v", local)
+	}
+}
+
+func TestAcceptDeclinesMismatchedStart(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	other := game.NewEngine()
+	if err := other.LoadPlacement("4k3/4p3/8/8/8/8/4P3/4K3", game.White); err != nil {
+		t.Fatalf("load placement: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := Accept(b, other, Config{Name: "south"})
+		done <- err
+	}()
+	if _, err := Challenge(a, game.NewEngine(), Config{Name: "north"}); !errors.Is(err, ErrDeclined) {
+		t.Fatalf("challenger: expected decline, got %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrDeclined) {
+		t.Fatalf("responder: expected decline, got %v", err)
+	}
+}
+
+func TestAcceptDetectsHashMismatch(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := Accept(b, game.NewEngine(), Config{Name: "south", Element: game.ElementShadow})
+		done <- err
+	}()
+	enc, dec := json.NewEncoder(a), json.NewDecoder(a)
+	var reply Message
+	steps := []Message{
+		{Type: msgChallenge, Version: Version, Name: "liar", Color: "white", Hash: game.NewEngine().State().Hash},
+		{Type: msgLoadout, Element: "Light"},
+	}
+	for i, msg := range steps {
+		if err := enc.Encode(msg); err != nil {
+			t.Fatalf("send %s: %v", msg.Type, err)
+		}
+		if err := dec.Decode(&reply); err != nil {
+			t.Fatalf("reply %d: %v", i, err)
+		}
+	}
+	if err := enc.Encode(Message{Type: msgMove, From: "e2", To: "e4", Ply: 1, Hash: 42}); err != nil {
+		t.Fatalf("send move: %v", err)
+	}
+	if err := dec.Decode(&reply); err != nil || reply.Type != msgError {
+		t.Fatalf("expected error message, got %+v (%v)", reply, err)
+	}
+	if err := <-done; !errors.Is(err, ErrDesync) {
+		t.Fatalf("expected desync, got %v", err)
+	}
+}
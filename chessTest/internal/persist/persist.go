@@ -1,3 +1,336 @@
+// path: chessTest/internal/persist/persist.go
+// Package persist keeps in-progress games recoverable across crashes. Each
+// game has a snapshot file and a write-ahead log of the move requests made
+// since that snapshot:
+//
+//	<dir>/<id>.snap  JSON game.Snapshot, replaced atomically on checkpoint
+//	<dir>/<id>.wal   one entry per line: crc32 (8 hex digits), space, JSON
+//
+// A move is appended to the log before the engine sees it, so a process that
+// dies mid-move recovers by restoring the snapshot and replaying the log.
+// Replay repeats every logged request, including ones the engine rejected,
+// each at the clock reading it was logged at, so a timed game is not charged
+// for the downtime and time-based rejections repeat. The sequence number the
+// next entry was logged at says whether a request was accepted; replay stops
+// with ErrReplayDiverged when its own outcome disagrees.
 package persist
 
-// TODO: implement persistence layer.
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// SyncPolicy says when log appends reach stable storage.
+type SyncPolicy int
+
+const (
+	// SyncEveryEntry fsyncs each append before the move is applied.
+	SyncEveryEntry SyncPolicy = iota
+	// SyncOnCheckpoint leaves appends to the OS and fsyncs at checkpoints.
+	SyncOnCheckpoint
+	// SyncNever never fsyncs; a machine crash may lose recent moves.
+	SyncNever
+)
+
+var syncPolicyNames = [...]string{
+	SyncEveryEntry:   "entry",
+	SyncOnCheckpoint: "checkpoint",
+	SyncNever:        "never",
+}
+
+func (p SyncPolicy) String() string {
+	if int(p) < len(syncPolicyNames) {
+		return syncPolicyNames[p]
+	}
+	return "unknown"
+}
+
+// ParseSyncPolicy reads the names String returns.
+func ParseSyncPolicy(s string) (SyncPolicy, bool) {
+	for p, name := range syncPolicyNames {
+		if strings.EqualFold(strings.TrimSpace(s), name) {
+			return SyncPolicy(p), true
+		}
+	}
+	return SyncEveryEntry, false
+}
+
+var (
+	ErrInvalidGameID  = errors.New("invalid game id")
+	ErrNoGame         = errors.New("no persisted game")
+	ErrLogGap         = errors.New("write-ahead log skips moves")
+	ErrReplayDiverged = errors.New("replayed move did not repeat its logged outcome")
+)
+
+// DefaultCheckpointEvery is how many log entries accumulate before Append
+// asks for a checkpoint.
+const DefaultCheckpointEvery = 32
+
+// Options configures a Store.
+type Options struct {
+	Sync SyncPolicy
+	// CheckpointEvery is the log length at which Append reports that a
+	// checkpoint is due; zero selects DefaultCheckpointEvery.
+	CheckpointEvery int
+}
+
+// Store persists games under one directory.
+type Store struct {
+	dir  string
+	opts Options
+
+	mu   sync.Mutex
+	logs map[string]*gameLog
+}
+
+type gameLog struct {
+	file    *os.File
+	entries int
+}
+
+// Open prepares dir, creating it if needed.
+func Open(dir string, opts Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = DefaultCheckpointEvery
+	}
+	return &Store{dir: dir, opts: opts, logs: make(map[string]*gameLog)}, nil
+}
+
+// Append logs req for game id ahead of applying it to eng, which must be the
+// engine the request is about to run against. It reports whether the log has
+// grown enough that the caller should Checkpoint once the move is applied.
+func (s *Store) Append(id string, eng *game.Engine, req game.MoveRequest) (bool, error) {
+	if req.CheckSeq && req.Seq != eng.Seq() {
+		// The engine rejects a stale request without touching the game, and
+		// replay would not know to, so it is not logged.
+		return false, nil
+	}
+	line, err := encodeEntry(newEntry(eng.Seq(), eng.Now(), req))
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lg, err := s.logLocked(id)
+	if err != nil {
+		return false, err
+	}
+	if _, err := lg.file.Write(line); err != nil {
+		return false, err
+	}
+	if s.opts.Sync == SyncEveryEntry {
+		if err := lg.file.Sync(); err != nil {
+			return false, err
+		}
+	}
+	lg.entries++
+	return lg.entries >= s.opts.CheckpointEvery, nil
+}
+
+// Checkpoint writes eng's snapshot for id and empties its log. The snapshot
+// replaces the old one by rename, so a crash leaves one or the other intact;
+// entries the new snapshot already covers are skipped on replay.
+func (s *Store) Checkpoint(id string, eng *game.Engine) error {
+	if err := checkID(id); err != nil {
+		return err
+	}
+	data, err := json.Marshal(eng.Snapshot())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lg, ok := s.logs[id]; ok && s.opts.Sync != SyncNever {
+		if err := lg.file.Sync(); err != nil {
+			return err
+		}
+	}
+	if err := s.writeFileLocked(s.path(id, ".snap"), data); err != nil {
+		return err
+	}
+	lg, err := s.logLocked(id)
+	if err != nil {
+		return err
+	}
+	if err := lg.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := lg.file.Seek(0, 0); err != nil {
+		return err
+	}
+	lg.entries = 0
+	return nil
+}
+
+// Recover rebuilds game id from its snapshot and log. It returns the number
+// of log entries replayed. A torn final entry, left by a crash mid-append, is
+// dropped along with anything after it.
+func (s *Store) Recover(id string) (*game.Engine, int, error) {
+	if err := checkID(id); err != nil {
+		return nil, 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	eng := game.NewEngine()
+	data, err := os.ReadFile(s.path(id, ".snap"))
+	switch {
+	case err == nil:
+		var snap game.Snapshot
+		if err := json.Unmarshal(data, &snap); err != nil {
+			return nil, 0, fmt.Errorf("snapshot %s: %w", id, err)
+		}
+		if err := eng.Restore(snap); err != nil {
+			return nil, 0, fmt.Errorf("snapshot %s: %w", id, err)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if _, statErr := os.Stat(s.path(id, ".wal")); statErr != nil {
+			return nil, 0, ErrNoGame
+		}
+	default:
+		return nil, 0, err
+	}
+	raw, err := os.ReadFile(s.path(id, ".wal"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, 0, err
+	}
+	entries, valid := decodeEntries(raw)
+	replayed := 0
+	for _, ent := range entries {
+		seq := eng.Seq()
+		if ent.Seq != seq && replayed > 0 {
+			// The previous entry was accepted, or refused, the first time
+			// and not this time.
+			return nil, replayed, fmt.Errorf("%w: entry for seq %d at seq %d", ErrReplayDiverged, ent.Seq, seq)
+		}
+		if ent.Seq < seq {
+			continue
+		}
+		if ent.Seq > seq {
+			return nil, replayed, fmt.Errorf("%w: entry for seq %d at seq %d", ErrLogGap, ent.Seq, seq)
+		}
+		req, err := ent.request()
+		if err != nil {
+			return nil, replayed, err
+		}
+		// A refusal is part of the outcome being repeated; the next entry's
+		// seq checks it. The last entry has no such check, but the process
+		// may have died before the engine saw it, so either outcome stands.
+		if ent.At != 0 {
+			_ = eng.MoveAt(time.Unix(0, ent.At), req)
+		} else {
+			_ = eng.Move(req)
+		}
+		replayed++
+	}
+	if valid < len(raw) {
+		// Drop the torn tail so later appends follow the last good entry.
+		if err := os.Truncate(s.path(id, ".wal"), int64(valid)); err != nil {
+			return nil, replayed, err
+		}
+	}
+	if lg, ok := s.logs[id]; ok {
+		lg.file.Close()
+		delete(s.logs, id)
+	}
+	return eng, replayed, nil
+}
+
+// Games lists the ids with persisted state.
+func (s *Store) Games() ([]string, error) {
+	names, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, de := range names {
+		name := de.Name()
+		id := strings.TrimSuffix(strings.TrimSuffix(name, ".snap"), ".wal")
+		if id == name || checkID(id) != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// Close closes every open log.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var first error
+	for id, lg := range s.logs {
+		if err := lg.file.Close(); err != nil && first == nil {
+			first = err
+		}
+		delete(s.logs, id)
+	}
+	return first
+}
+
+func (s *Store) logLocked(id string) (*gameLog, error) {
+	if lg, ok := s.logs[id]; ok {
+		return lg, nil
+	}
+	if err := checkID(id); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(s.path(id, ".wal"), os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	lg := &gameLog{file: f}
+	s.logs[id] = lg
+	return lg, nil
+}
+
+func (s *Store) writeFileLocked(path string, data []byte) error {
+	tmp, err := os.CreateTemp(s.dir, filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if s.opts.Sync != SyncNever {
+		if err := tmp.Sync(); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *Store) path(id, ext string) string {
+	return filepath.Join(s.dir, id+ext)
+}
+
+// checkID keeps ids to a safe file-name alphabet.
+func checkID(id string) error {
+	if id == "" || len(id) > 64 {
+		return ErrInvalidGameID
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return ErrInvalidGameID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,325 @@
+// path: chessTest/internal/persist/persist_test.go
+package persist
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+const crashDirEnv = "BCHESS_PERSIST_CRASH_DIR"
+
+func configured(t *testing.T) *game.Engine {
+	t.Helper()
+	eng := game.NewEngine()
+	if err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityBlockPath}, game.ElementLight); err != nil {
+		t.Fatalf("config white: %v", err)
+	}
+	if err := eng.SetSideConfig(game.Black, game.AbilityList{game.AbilityBlockPath}, game.ElementShadow); err != nil {
+		t.Fatalf("config black: %v", err)
+	}
+	return eng
+}
+
+func mv(t *testing.T, from, to string) game.MoveRequest {
+	t.Helper()
+	f, okFrom := game.CoordToSquare(from)
+	s, okTo := game.CoordToSquare(to)
+	if !okFrom || !okTo {
+		t.Fatalf("bad move %s%s", from, to)
+	}
+	return game.MoveRequest{From: f, To: s}
+}
+
+// play logs and applies each move the way the HTTP handlers do.
+func play(t *testing.T, store *Store, id string, eng *game.Engine, moves ...game.MoveRequest) {
+	t.Helper()
+	for _, req := range moves {
+		if _, err := store.Append(id, eng, req); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+		if err := eng.Move(req); err != nil {
+			t.Fatalf("move %v: %v", req, err)
+		}
+	}
+}
+
+func reference(t *testing.T, moves ...game.MoveRequest) *game.Engine {
+	t.Helper()
+	eng := configured(t)
+	for _, req := range moves {
+		if err := eng.Move(req); err != nil {
+			t.Fatalf("reference move %v: %v", req, err)
+		}
+	}
+	return eng
+}
+
+// crashMidMove runs in a child process: it logs d7d5 and dies before the
+// engine applies it.
+func crashMidMove(t *testing.T, dir string) {
+	store, err := Open(dir, Options{Sync: SyncEveryEntry})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	eng := configured(t)
+	if err := store.Checkpoint("g1", eng); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	play(t, store, "g1", eng, mv(t, "e2", "e4"))
+	if _, err := store.Append("g1", eng, mv(t, "d7", "d5")); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	os.Exit(3)
+}
+
+func TestRecoverAfterCrashMidMove(t *testing.T) {
+	if dir := os.Getenv(crashDirEnv); dir != "" {
+		crashMidMove(t, dir)
+		return
+	}
+	dir := t.TempDir()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRecoverAfterCrashMidMove$")
+	cmd.Env = append(os.Environ(), crashDirEnv+"="+dir)
+	var exit *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exit) || exit.ExitCode() != 3 {
+		t.Fatalf("child should die mid-move, got %v", err)
+	}
+
+	store, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	eng, replayed, err := store.Recover("g1")
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if replayed != 2 {
+		t.Fatalf("expected 2 replayed entries, got %d", replayed)
+	}
+	want := reference(t, mv(t, "e2", "e4"), mv(t, "d7", "d5"))
+	if got := eng.State(); got.Hash != want.State().Hash || eng.Seq() != want.Seq() {
+		t.Fatalf("recovered game differs: seq %d vs %d", eng.Seq(), want.Seq())
+	}
+
+	// The recovered game keeps logging where it left off.
+	play(t, store, "g1", eng, mv(t, "e4", "d5"))
+	again, _, err := store.Recover("g1")
+	if err != nil {
+		t.Fatalf("second recover: %v", err)
+	}
+	if again.State().Hash != eng.State().Hash {
+		t.Fatalf("second recovery lost the capture")
+	}
+}
+
+func TestRecoverDropsTornTail(t *testing.T) {
+	dir := t.TempDir()
+	store, err := Open(dir, Options{Sync: SyncNever})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	eng := configured(t)
+	if err := store.Checkpoint("g1", eng); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	play(t, store, "g1", eng, mv(t, "e2", "e4"))
+	store.Close()
+
+	wal := filepath.Join(dir, "g1.wal")
+	good, err := os.ReadFile(wal)
+	if err != nil {
+		t.Fatalf("read wal: %v", err)
+	}
+	line, _ := encodeEntry(newEntry(eng.Seq(), eng.Now(), mv(t, "d7", "d5")))
+	torn := append(append([]byte{}, good...), line[:len(line)/2]...)
+	if err := os.WriteFile(wal, torn, 0o644); err != nil {
+		t.Fatalf("write wal: %v", err)
+	}
+
+	got, replayed, err := store.Recover("g1")
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+	if replayed != 1 || got.State().Hash != eng.State().Hash {
+		t.Fatalf("expected only e2e4 replayed, got %d entries", replayed)
+	}
+	if after, _ := os.ReadFile(wal); len(after) != len(good) {
+		t.Fatalf("torn tail not truncated: %d bytes, want %d", len(after), len(good))
+	}
+}
+
+func TestRecoverAfterCheckpoint(t *testing.T) {
+	cases := []struct {
+		name     string
+		prepare  func(t *testing.T, dir string, store *Store, eng *game.Engine)
+		replayed int
+		err      error
+	}{
+		{
+			name: "checkpoint empties the log",
+			prepare: func(t *testing.T, dir string, store *Store, eng *game.Engine) {
+				play(t, store, "g1", eng, mv(t, "e2", "e4"))
+				if err := store.Checkpoint("g1", eng); err != nil {
+					t.Fatalf("checkpoint: %v", err)
+				}
+				play(t, store, "g1", eng, mv(t, "d7", "d5"))
+			},
+			replayed: 1,
+		},
+		{
+			name: "entries the snapshot covers are skipped",
+			prepare: func(t *testing.T, dir string, store *Store, eng *game.Engine) {
+				play(t, store, "g1", eng, mv(t, "e2", "e4"), mv(t, "d7", "d5"))
+				// A crash between the snapshot rename and the log truncation.
+				data, _ := os.ReadFile(filepath.Join(dir, "g1.wal"))
+				if err := store.Checkpoint("g1", eng); err != nil {
+					t.Fatalf("checkpoint: %v", err)
+				}
+				store.Close()
+				if err := os.WriteFile(filepath.Join(dir, "g1.wal"), data, 0o644); err != nil {
+					t.Fatalf("write wal: %v", err)
+				}
+			},
+		},
+		{
+			name: "stale requests are not logged",
+			prepare: func(t *testing.T, dir string, store *Store, eng *game.Engine) {
+				req := mv(t, "e2", "e4")
+				req.CheckSeq, req.Seq = true, eng.Seq()+1
+				if _, err := store.Append("g1", eng, req); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+				play(t, store, "g1", eng, mv(t, "d2", "d4"))
+			},
+			replayed: 1,
+		},
+		{
+			name: "missing entries are a gap",
+			prepare: func(t *testing.T, dir string, store *Store, eng *game.Engine) {
+				ahead := configured(t)
+				if err := ahead.Move(mv(t, "e2", "e4")); err != nil {
+					t.Fatalf("move: %v", err)
+				}
+				if _, err := store.Append("g1", ahead, mv(t, "d7", "d5")); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+			},
+			err: ErrLogGap,
+		},
+		{
+			name: "an outcome that does not repeat diverges",
+			prepare: func(t *testing.T, dir string, store *Store, eng *game.Engine) {
+				play(t, store, "g1", eng, mv(t, "e2", "e4"))
+				// Logged as though e2e4 had been refused.
+				if _, err := store.Append("g1", configured(t), mv(t, "d2", "d4")); err != nil {
+					t.Fatalf("append: %v", err)
+				}
+			},
+			err: ErrReplayDiverged,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := Open(dir, Options{CheckpointEvery: 8})
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			defer store.Close()
+			eng := configured(t)
+			if err := store.Checkpoint("g1", eng); err != nil {
+				t.Fatalf("checkpoint: %v", err)
+			}
+			tc.prepare(t, dir, store, eng)
+			got, replayed, err := store.Recover("g1")
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if err != nil {
+				return
+			}
+			if replayed != tc.replayed {
+				t.Fatalf("expected %d replayed entries, got %d", tc.replayed, replayed)
+			}
+			if got.State().Hash != eng.State().Hash {
+				t.Fatalf("recovered game differs from the live one")
+			}
+		})
+	}
+}
+
+func TestRecoverTimedGameAtLoggedTimes(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	eng := configured(t)
+	rules := game.DefaultRules()
+	rules.TimeControl = game.TimeControl{Initial: 5 * time.Minute}
+	if err := eng.SetRules(rules); err != nil {
+		t.Fatalf("set rules: %v", err)
+	}
+	eng.SetClock(func() time.Time { return now })
+
+	dir := t.TempDir()
+	store, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	if err := store.Checkpoint("g1", eng); err != nil {
+		t.Fatalf("checkpoint: %v", err)
+	}
+	now = start.Add(10 * time.Second)
+	play(t, store, "g1", eng, mv(t, "e2", "e4"))
+
+	// Recovery runs on the real clock, long after the logged move.
+	got, replayed, err := store.Recover("g1")
+	if err != nil || replayed != 1 {
+		t.Fatalf("recover: %d replayed, %v", replayed, err)
+	}
+	if st := got.State(); st.Status != game.StatusActive {
+		t.Fatalf("recovered game ended: %s (%s)", st.Status, st.StatusReason)
+	}
+	if clocks, want := got.Snapshot().Clocks, eng.Snapshot().Clocks; clocks != want {
+		t.Fatalf("clocks = %v want %v", clocks, want)
+	}
+	if log := got.MoveLog(); len(log) != 1 || !log[0].SegmentAt.Equal(now) || !got.TurnStarted().Equal(now) {
+		t.Fatalf("move log %+v, turn started %v; want the move at %v", log, got.TurnStarted(), now)
+	}
+	if got.Now().Before(now.Add(time.Hour)) {
+		t.Fatalf("recovered engine still reads the replay clock: %v", got.Now())
+	}
+}
+
+func TestStoreGamesAndIDs(t *testing.T) {
+	store, err := Open(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer store.Close()
+	if _, _, err := store.Recover("nope"); !errors.Is(err, ErrNoGame) {
+		t.Fatalf("expected ErrNoGame, got %v", err)
+	}
+	if err := store.Checkpoint("../escape", game.NewEngine()); !errors.Is(err, ErrInvalidGameID) {
+		t.Fatalf("expected ErrInvalidGameID, got %v", err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if err := store.Checkpoint(id, game.NewEngine()); err != nil {
+			t.Fatalf("checkpoint %s: %v", id, err)
+		}
+	}
+	ids, err := store.Games()
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("expected two games, got %v (%v)", ids, err)
+	}
+	if due, err := store.Append("a", game.NewEngine(), game.MoveRequest{From: game.SquareE2, To: game.SquareE4}); err != nil || due {
+		t.Fatalf("first append should not ask for a checkpoint: %v %v", due, err)
+	}
+}
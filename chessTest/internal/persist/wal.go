@@ -0,0 +1,106 @@
+// path: chessTest/internal/persist/wal.go
+package persist
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"time"
+
+	"battle_chess_poc/internal/game"
+)
+
+// entry is one logged move request. Seq is the engine's sequence number when
+// the request was logged, which lets replay skip entries a later snapshot
+// already covers. At is the engine's clock then, in Unix nanoseconds; zero in
+// logs written before it was recorded.
+type entry struct {
+	Seq       uint64 `json:"seq"`
+	At        int64  `json:"at,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Dir       string `json:"dir,omitempty"`
+	Promotion string `json:"promotion,omitempty"`
+	Shove     bool   `json:"shove,omitempty"`
+	Drift     string `json:"drift,omitempty"`
+}
+
+func newEntry(seq uint64, at time.Time, req game.MoveRequest) entry {
+	ent := entry{
+		Seq:   seq,
+		At:    at.UnixNano(),
+		From:  game.SquareToCoord(req.From),
+		To:    game.SquareToCoord(req.To),
+		Dir:   req.Dir.String(),
+		Shove: req.Shove,
+		Drift: req.Drift.String(),
+	}
+	if req.HasPromotion {
+		ent.Promotion = req.Promotion.String()
+	}
+	return ent
+}
+
+func (ent entry) request() (game.MoveRequest, error) {
+	from, okFrom := game.CoordToSquare(ent.From)
+	to, okTo := game.CoordToSquare(ent.To)
+	if !okFrom || !okTo {
+		return game.MoveRequest{}, fmt.Errorf("log entry has bad squares %q %q", ent.From, ent.To)
+	}
+	req := game.MoveRequest{From: from, To: to, Dir: game.ParseDirection(ent.Dir), Shove: ent.Shove, Drift: game.ParseDirection(ent.Drift)}
+	if ent.Promotion != "" {
+		pt, ok := game.ParsePromotionPiece(ent.Promotion)
+		if !ok {
+			return game.MoveRequest{}, fmt.Errorf("log entry has bad promotion %q", ent.Promotion)
+		}
+		req.Promotion, req.HasPromotion = pt, true
+	}
+	return req, nil
+}
+
+// encodeEntry renders one log line: the CRC-32 of the JSON body, a space, the
+// body and a newline.
+func encodeEntry(ent entry) ([]byte, error) {
+	body, err := json.Marshal(ent)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, 0, 10+len(body))
+	line = fmt.Appendf(line, "%08x ", crc32.ChecksumIEEE(body))
+	line = append(line, body...)
+	return append(line, '\n'), nil
+}
+
+// decodeEntries parses log lines up to the first one that is incomplete or
+// fails its checksum, returning the entries and the byte length they span.
+func decodeEntries(raw []byte) ([]entry, int) {
+	var out []entry
+	valid := 0
+	for valid < len(raw) {
+		end := bytes.IndexByte(raw[valid:], '\n')
+		if end < 0 {
+			break
+		}
+		line := raw[valid : valid+end]
+		if len(line) < 10 || line[8] != ' ' {
+			break
+		}
+		var sum [4]byte
+		if _, err := hex.Decode(sum[:], line[:8]); err != nil {
+			break
+		}
+		body := line[9:]
+		if crc32.ChecksumIEEE(body) != uint32(sum[0])<<24|uint32(sum[1])<<16|uint32(sum[2])<<8|uint32(sum[3]) {
+			break
+		}
+		var ent entry
+		if err := json.Unmarshal(body, &ent); err != nil {
+			break
+		}
+		out = append(out, ent)
+		valid += end + 1
+	}
+	return out, valid
+}
@@ -1,3 +1,282 @@
+// path: chessTest/internal/protocol/protocol.go
+// Package protocol defines the compact binary encoding of board states used
+// when a client negotiates it instead of JSON.
+//
+// Layout (all integers are unsigned varints unless noted):
+//
+//	magic 'B' 'C', version byte, flags byte (turn, locked, status<<2, phase<<4)
+//	hash (8 bytes, little endian), move sequence
+//	pieces: count, then per piece id, kind byte (color<<3 | type), square byte, ability bitmask
+//	loadouts: per side count, then ability id bytes in loadout order
+//	block facing: count, then id, direction byte
+//	charges: present byte, white, black
+//	clocks (ms): present byte, white, black
+//	last note, status reason, engine version, rules fingerprint: length-prefixed strings
+//
+// Ability bitmasks set bit n for game.Ability(n).
 package protocol
 
-// TODO: define binary protocol structures.
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strings"
+
+	"battle_chess_poc/internal/game"
+)
+
+// Version is bumped whenever the layout changes.
+const Version byte = 4
+
+// ContentType is the media type clients send in Accept to request the binary
+// encoding.
+const ContentType = "application/vnd.battlechess.state"
+
+const (
+	flagBlack  = 1 << 0
+	flagLocked = 1 << 1
+)
+
+var ErrMalformed = errors.New("malformed binary state")
+
+// EncodeState packs st into the binary layout.
+func EncodeState(st game.BoardState) []byte {
+	buf := make([]byte, 0, 16+len(st.Pieces)*4+len(st.LastNote))
+	flags := byte(st.Status)<<2 | byte(st.Phase)<<4
+	if st.Turn == game.Black {
+		flags |= flagBlack
+	}
+	if st.Locked {
+		flags |= flagLocked
+	}
+	buf = append(buf, 'B', 'C', Version, flags)
+	buf = binary.LittleEndian.AppendUint64(buf, st.Hash)
+	buf = binary.AppendUvarint(buf, st.Seq)
+
+	buf = binary.AppendUvarint(buf, uint64(len(st.Pieces)))
+	for _, pc := range st.Pieces {
+		buf = binary.AppendUvarint(buf, uint64(pc.ID))
+		buf = append(buf, byte(pc.Color)<<3|byte(pc.Type), byte(pc.Square))
+		buf = binary.AppendUvarint(buf, abilityMask(pc.Abilities))
+	}
+
+	buf = appendLoadout(buf, st.Abilities[game.White.String()])
+	buf = appendLoadout(buf, st.Abilities[game.Black.String()])
+
+	ids := make([]int, 0, len(st.BlockFacing))
+	for id := range st.BlockFacing {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	buf = binary.AppendUvarint(buf, uint64(len(ids)))
+	for _, id := range ids {
+		buf = binary.AppendUvarint(buf, uint64(id))
+		buf = append(buf, byte(st.BlockFacing[id]))
+	}
+
+	buf = appendSides(buf, st.Charges != nil, int64(st.Charges[game.White.String()]), int64(st.Charges[game.Black.String()]))
+	buf = appendSides(buf, st.Clocks != nil, st.Clocks[game.White.String()], st.Clocks[game.Black.String()])
+
+	buf = appendString(buf, st.LastNote)
+	buf = appendString(buf, st.StatusReason)
+	buf = appendString(buf, st.EngineVersion)
+	return appendString(buf, st.RulesFingerprint)
+}
+
+// DecodeState reverses EncodeState.
+func DecodeState(data []byte) (game.BoardState, error) {
+	var st game.BoardState
+	if len(data) < 12 || data[0] != 'B' || data[1] != 'C' || data[2] != Version {
+		return st, ErrMalformed
+	}
+	flags := data[3]
+	if flags&flagBlack != 0 {
+		st.Turn = game.Black
+	}
+	st.Locked = flags&flagLocked != 0
+	st.Status = game.GameStatus(flags >> 2 & 3)
+	st.Phase = game.TurnPhase(flags >> 4)
+	st.Hash = binary.LittleEndian.Uint64(data[4:12])
+	r := reader{buf: data[12:]}
+	st.Seq = r.uvarint()
+
+	n := r.uvarint()
+	if n > 64 {
+		return st, ErrMalformed
+	}
+	for i := uint64(0); i < n; i++ {
+		id := r.uvarint()
+		kind := r.byte()
+		sq := r.byte()
+		mask := r.uvarint()
+		st.Pieces = append(st.Pieces, game.PieceState{
+			ID:           int(id),
+			Color:        game.Color(kind >> 3),
+			Type:         game.PieceType(kind & 7),
+			Square:       game.Square(sq),
+			Abilities:    abilityNames(mask),
+			Capabilities: game.CapabilitiesOf(game.AbilitySet(mask)),
+		})
+	}
+
+	st.Abilities = map[string][]string{
+		game.White.String(): r.loadout(),
+		game.Black.String(): r.loadout(),
+	}
+
+	n = r.uvarint()
+	if n > 64 {
+		return st, ErrMalformed
+	}
+	st.BlockFacing = make(map[int]game.Direction, n)
+	for i := uint64(0); i < n; i++ {
+		id := r.uvarint()
+		st.BlockFacing[int(id)] = game.Direction(r.byte())
+	}
+
+	if ok, w, b := r.sides(); ok {
+		st.Charges = map[string]int{game.White.String(): int(w), game.Black.String(): int(b)}
+	}
+	if ok, w, b := r.sides(); ok {
+		st.Clocks = map[string]int64{game.White.String(): w, game.Black.String(): b}
+	}
+	st.LastNote = r.string()
+	st.StatusReason = r.string()
+	st.EngineVersion = r.string()
+	st.RulesFingerprint = r.string()
+	if r.err {
+		return game.BoardState{}, ErrMalformed
+	}
+	return st, nil
+}
+
+// Accepts reports whether an Accept header asks for the binary encoding.
+func Accepts(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		media, _, _ := strings.Cut(part, ";")
+		if strings.EqualFold(strings.TrimSpace(media), ContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+func abilityMask(names []string) uint64 {
+	var mask uint64
+	for _, name := range names {
+		if id, ok := game.ParseAbility(name); ok && id < 64 {
+			mask |= 1 << uint(id)
+		}
+	}
+	return mask
+}
+
+func abilityNames(mask uint64) []string {
+	if mask == 0 {
+		return nil
+	}
+	var out []string
+	for _, id := range game.AllAbilities {
+		if id < 64 && mask&(1<<uint(id)) != 0 {
+			out = append(out, id.String())
+		}
+	}
+	return out
+}
+
+func appendLoadout(buf []byte, names []string) []byte {
+	ids := make([]byte, 0, len(names))
+	for _, name := range names {
+		if id, ok := game.ParseAbility(name); ok {
+			ids = append(ids, byte(id))
+		}
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(ids)))
+	return append(buf, ids...)
+}
+
+func appendSides(buf []byte, present bool, white, black int64) []byte {
+	if !present {
+		return append(buf, 0)
+	}
+	buf = append(buf, 1)
+	buf = binary.AppendVarint(buf, white)
+	return binary.AppendVarint(buf, black)
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// reader consumes the layout, latching err on the first short read.
+type reader struct {
+	buf []byte
+	err bool
+}
+
+func (r *reader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.err = true
+		r.buf = nil
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *reader) varint() int64 {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		r.err = true
+		r.buf = nil
+		return 0
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *reader) byte() byte {
+	if len(r.buf) == 0 {
+		r.err = true
+		return 0
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *reader) loadout() []string {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.err = true
+		r.buf = nil
+		return nil
+	}
+	out := make([]string, 0, n)
+	for _, id := range r.buf[:n] {
+		out = append(out, game.Ability(id).String())
+	}
+	r.buf = r.buf[n:]
+	return out
+}
+
+func (r *reader) sides() (bool, int64, int64) {
+	if r.byte() == 0 {
+		return false, 0, 0
+	}
+	return true, r.varint(), r.varint()
+}
+
+func (r *reader) string() string {
+	n := r.uvarint()
+	if n > uint64(len(r.buf)) {
+		r.err = true
+		r.buf = nil
+		return ""
+	}
+	s := string(r.buf[:n])
+	r.buf = r.buf[n:]
+	return s
+}
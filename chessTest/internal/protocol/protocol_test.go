@@ -0,0 +1,115 @@
+// path: chessTest/internal/protocol/protocol_test.go
+package protocol
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func sampleState(tb testing.TB) game.BoardState {
+	tb.Helper()
+	eng := game.NewEngine()
+	if err := eng.SetSideConfig(game.White, game.AbilityList{game.AbilityBlockPath, game.AbilityDoOver}, game.ElementLight); err != nil {
+		tb.Fatalf("config white: %v", err)
+	}
+	if err := eng.SetSideConfig(game.Black, game.AbilityList{game.AbilityScatterShot}, game.ElementFire); err != nil {
+		tb.Fatalf("config black: %v", err)
+	}
+	rules := game.DefaultRules()
+	rules.Charges.Enabled = true
+	if err := eng.SetRules(rules); err != nil {
+		tb.Fatalf("rules: %v", err)
+	}
+	if err := eng.Move(game.MoveRequest{From: game.SquareE2, To: game.SquareE4, Dir: game.DirN}); err != nil {
+		tb.Fatalf("move: %v", err)
+	}
+	return eng.State()
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	want := sampleState(t)
+	got, err := DecodeState(EncodeState(want))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDecodeRejectsTruncatedInput(t *testing.T) {
+	data := EncodeState(sampleState(t))
+	for _, n := range []int{0, 3, 12, len(data) / 2, len(data) - 1} {
+		if _, err := DecodeState(data[:n]); err != ErrMalformed {
+			t.Fatalf("len %d: expected ErrMalformed, got %v", n, err)
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	cases := []struct {
+		accept string
+		want   bool
+	}{
+		{"", false},
+		{"application/json", false},
+		{ContentType, true},
+		{"application/json;q=0.5, " + ContentType + ";q=1", true},
+	}
+	for _, tc := range cases {
+		if got := Accepts(tc.accept); got != tc.want {
+			t.Fatalf("Accepts(%q) = %v want %v", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func gzipped(b []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write(b)
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+func BenchmarkStateJSON(b *testing.B) {
+	st := sampleState(b)
+	var size int
+	for i := 0; i < b.N; i++ {
+		out, _ := json.Marshal(map[string]any{"state": st})
+		size = len(out)
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkStateJSONGzip(b *testing.B) {
+	st := sampleState(b)
+	var size int
+	for i := 0; i < b.N; i++ {
+		out, _ := json.Marshal(map[string]any{"state": st})
+		size = len(gzipped(out))
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkStateBinary(b *testing.B) {
+	st := sampleState(b)
+	var size int
+	for i := 0; i < b.N; i++ {
+		size = len(EncodeState(st))
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
+
+func BenchmarkStateBinaryGzip(b *testing.B) {
+	st := sampleState(b)
+	var size int
+	for i := 0; i < b.N; i++ {
+		size = len(gzipped(EncodeState(st)))
+	}
+	b.ReportMetric(float64(size), "bytes")
+}
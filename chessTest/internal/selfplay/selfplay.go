@@ -0,0 +1,47 @@
+// path: chessTest/internal/selfplay/selfplay.go
+// Package selfplay holds the move choice shared by the offline tools that
+// drive games against themselves, such as cmd/optimize and cmd/minepositions.
+package selfplay
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+
+	"battle_chess_poc/internal/game"
+)
+
+// PickMove returns the move among moves whose resulting position scores best
+// for the side to move in eng, or with probability noise a random one. Moves
+// are tried on scratch, so eng is untouched; ties are broken at random. It
+// reports false when no move could be played.
+func PickMove(eng, scratch *game.Engine, moves []game.MoveRequest, rng *rand.Rand, noise float64) (game.MoveRequest, bool) {
+	if len(moves) == 0 {
+		return game.MoveRequest{}, false
+	}
+	if rng.Float64() < noise {
+		return moves[rng.Intn(len(moves))], true
+	}
+	mover := eng.State(game.StateOptions{Detail: game.StateSummary}).Turn
+	snap := eng.Snapshot()
+	best, bestScore := -1, math.MinInt
+	for i, mv := range moves {
+		if scratch.Restore(snap) != nil {
+			continue
+		}
+		if err := scratch.Move(mv); err != nil && !errors.Is(err, game.ErrDoOverActivated) {
+			continue
+		}
+		score := scratch.Evaluate().Score
+		if mover == game.Black {
+			score = -score
+		}
+		if score > bestScore || (score == bestScore && rng.Intn(2) == 0) {
+			best, bestScore = i, score
+		}
+	}
+	if best < 0 {
+		return game.MoveRequest{}, false
+	}
+	return moves[best], true
+}
@@ -0,0 +1,36 @@
+// path: chessTest/internal/selfplay/selfplay_test.go
+package selfplay
+
+import (
+	"math/rand"
+	"testing"
+
+	"battle_chess_poc/internal/game"
+)
+
+func TestPickMoveLeavesTheGameUntouched(t *testing.T) {
+	eng := game.NewEngine()
+	before := eng.State(game.StateOptions{}).Hash
+	moves := eng.LegalMoves()
+	for _, noise := range []float64{0, 1} {
+		mv, ok := PickMove(eng, game.NewEngine(), moves, rand.New(rand.NewSource(1)), noise)
+		if !ok || !contains(moves, mv) {
+			t.Fatalf("noise %v: picked %v (%v), not a legal move", noise, mv, ok)
+		}
+	}
+	if eng.State(game.StateOptions{}).Hash != before {
+		t.Fatalf("picking moved the game")
+	}
+	if _, ok := PickMove(eng, game.NewEngine(), nil, rand.New(rand.NewSource(1)), 0); ok {
+		t.Fatalf("picked a move from none")
+	}
+}
+
+func contains(moves []game.MoveRequest, mv game.MoveRequest) bool {
+	for _, m := range moves {
+		if m.From == mv.From && m.To == mv.To {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,261 @@
+// path: chessTest/internal/telemetry/telemetry.go
+// Package telemetry reports anonymous gameplay aggregates that help balance
+// abilities. It is off unless the server operator configures it, and each
+// game can stay out of it. Batches hold counters only: ability effects by
+// ability, finished game lengths in buckets and games started per variant.
+// No game ids, players, moves or positions leave the process.
+//
+// Batches are POSTed as JSON to an endpoint, or in local-only mode appended
+// to a file for self-hosted analysis, one JSON batch per line:
+//
+//	<dir>/telemetry.jsonl
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+// DefaultInterval is how often batches are sent when Config.Interval is zero.
+const DefaultInterval = time.Hour
+
+// lengthBucket is the width, in plies, of the game length buckets.
+const lengthBucket = 20
+
+// Config says where batches go. Exactly one of Endpoint and Dir is set.
+type Config struct {
+	// Endpoint is an http or https URL that receives each batch as a POST.
+	Endpoint string
+	// Dir selects local-only mode: batches are appended to
+	// Dir/telemetry.jsonl and nothing is sent anywhere.
+	Dir string
+	// Interval is the time between batches; zero means DefaultInterval.
+	Interval time.Duration
+	// Client sends batches to Endpoint; nil uses a client with a 10 second
+	// timeout.
+	Client *http.Client
+}
+
+// Batch is one reporting window's aggregates.
+type Batch struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	// GamesStarted counts tracked games by variant.
+	GamesStarted map[string]uint64 `json:"gamesStarted,omitempty"`
+	// Lengths counts finished games by length in plies, bucketed as "0-19",
+	// "20-39" and so on.
+	Lengths map[string]uint64 `json:"lengths,omitempty"`
+	// Abilities counts events an ability caused, such as removals and
+	// DoOver rewinds, by ability.
+	Abilities map[string]uint64 `json:"abilities,omitempty"`
+}
+
+func (b *Batch) empty() bool {
+	return len(b.GamesStarted) == 0 && len(b.Lengths) == 0 && len(b.Abilities) == 0
+}
+
+// merge adds other's counters into b and widens b's window to cover it.
+func (b *Batch) merge(other Batch) {
+	if other.Start.Before(b.Start) {
+		b.Start = other.Start
+	}
+	add := func(dst *map[string]uint64, src map[string]uint64) {
+		for k, n := range src {
+			if *dst == nil {
+				*dst = make(map[string]uint64)
+			}
+			(*dst)[k] += n
+		}
+	}
+	add(&b.GamesStarted, other.GamesStarted)
+	add(&b.Lengths, other.Lengths)
+	add(&b.Abilities, other.Abilities)
+}
+
+// Reporter aggregates the events of tracked games and sends a batch every
+// interval. Events of games that were never tracked are ignored.
+type Reporter struct {
+	cfg  Config
+	sub  *bus.Subscription
+	done chan struct{}
+	stop chan struct{}
+	once sync.Once
+
+	mu      sync.Mutex
+	tracked map[string]struct{}
+	batch   Batch
+}
+
+// Start validates cfg and begins aggregating the events published on b. The
+// reporter stops when b is closed or on Close.
+func Start(b *bus.Bus, cfg Config) (*Reporter, error) {
+	switch {
+	case cfg.Endpoint != "" && cfg.Dir != "":
+		return nil, errors.New("telemetry: set either an endpoint or a directory, not both")
+	case cfg.Endpoint != "":
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("telemetry: endpoint %q is not an http(s) URL", cfg.Endpoint)
+		}
+	case cfg.Dir != "":
+		if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("telemetry: %w", err)
+		}
+	default:
+		return nil, errors.New("telemetry: no endpoint or directory")
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	r := &Reporter{
+		cfg:     cfg,
+		sub:     b.Subscribe("", 256),
+		done:    make(chan struct{}),
+		stop:    make(chan struct{}),
+		tracked: make(map[string]struct{}),
+		batch:   Batch{Start: time.Now().UTC()},
+	}
+	go r.run()
+	return r, nil
+}
+
+// Track counts game id's events from now on and records that a game of
+// variant started. The id itself is never reported.
+func (r *Reporter) Track(id, variant string) {
+	if variant == "" {
+		variant = game.VariantBattle
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tracked[id]; ok {
+		return
+	}
+	r.tracked[id] = struct{}{}
+	bump(&r.batch.GamesStarted, variant)
+}
+
+func (r *Reporter) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg, ok := <-r.sub.C:
+			if !ok {
+				r.flushAndLog()
+				return
+			}
+			r.observe(msg)
+		case <-ticker.C:
+			r.flushAndLog()
+		case <-r.stop:
+			r.flushAndLog()
+			return
+		}
+	}
+}
+
+func (r *Reporter) observe(msg bus.Message) {
+	if msg.Seat != nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.tracked[msg.Game]; !ok {
+		return
+	}
+	ev := msg.Event
+	if ev.Ability != game.AbilityNone {
+		bump(&r.batch.Abilities, ev.Ability.String())
+	}
+	if ev.Kind == game.EventGameOver {
+		low := int(ev.Ply) / lengthBucket * lengthBucket
+		bump(&r.batch.Lengths, fmt.Sprintf("%d-%d", low, low+lengthBucket-1))
+	}
+}
+
+// bump adds one to key in *m.
+func bump(m *map[string]uint64, key string) {
+	if *m == nil {
+		*m = make(map[string]uint64)
+	}
+	(*m)[key]++
+}
+
+func (r *Reporter) flushAndLog() {
+	if err := r.Flush(); err != nil {
+		log.Printf("telemetry: %v", err)
+	}
+}
+
+// Flush sends the current batch now and starts a new one. An empty batch
+// is not sent. A batch that cannot be delivered is folded back into the
+// next one.
+func (r *Reporter) Flush() error {
+	r.mu.Lock()
+	batch := r.batch
+	batch.End = time.Now().UTC()
+	r.batch = Batch{Start: batch.End}
+	r.mu.Unlock()
+	if batch.empty() {
+		return nil
+	}
+	err := r.deliver(batch)
+	if err != nil {
+		r.mu.Lock()
+		r.batch.merge(batch)
+		r.mu.Unlock()
+	}
+	return err
+}
+
+func (r *Reporter) deliver(batch Batch) error {
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	if r.cfg.Dir != "" {
+		f, err := os.OpenFile(filepath.Join(r.cfg.Dir, "telemetry.jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(append(data, '\n')); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	resp, err := r.cfg.Client.Post(r.cfg.Endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return nil
+}
+
+// Close sends what is left and stops the reporter.
+func (r *Reporter) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+		r.sub.Close()
+	})
+}
@@ -0,0 +1,183 @@
+// path: chessTest/internal/telemetry/telemetry_test.go
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"battle_chess_poc/internal/bus"
+	"battle_chess_poc/internal/game"
+)
+
+func TestStartValidatesConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "nothing set", wantErr: true},
+		{name: "both set", cfg: Config{Endpoint: "https://example.test/t", Dir: t.TempDir()}, wantErr: true},
+		{name: "not a URL", cfg: Config{Endpoint: "ftp://example.test"}, wantErr: true},
+		{name: "endpoint", cfg: Config{Endpoint: "https://example.test/t"}},
+		{name: "local only", cfg: Config{Dir: filepath.Join(t.TempDir(), "stats")}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := bus.New()
+			defer b.Close()
+			r, err := Start(b, tc.cfg)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tc.wantErr)
+			}
+			if r != nil {
+				r.Close()
+			}
+		})
+	}
+}
+
+// publish sends a short game to b: one ability removal and a game over.
+func publish(b *bus.Bus, id string) {
+	b.Publish(bus.Message{Game: id, Event: game.Event{Seq: 1, Kind: game.EventMove}})
+	b.Publish(bus.Message{Game: id, Event: game.Event{Seq: 2, Kind: game.EventAbilityRemoval, Ability: game.AbilityScatterShot}})
+	b.Publish(bus.Message{Game: id, Event: game.Event{Seq: 3, Ply: 23, Kind: game.EventGameOver}})
+	b.Publish(bus.Message{Game: id, Seat: &bus.SeatStatus{Status: "idle"}})
+}
+
+// waitBatch flushes until the reporter has taken in the published events.
+func waitBatch(t *testing.T, r *Reporter, got func() []Batch) Batch {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	var merged Batch
+	for time.Now().Before(deadline) {
+		if err := r.Flush(); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		merged = Batch{}
+		for _, b := range got() {
+			merged.merge(b)
+		}
+		if len(merged.Lengths) > 0 {
+			return merged
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("no game length reported; got %+v", merged)
+	return merged
+}
+
+func checkBatch(t *testing.T, b Batch) {
+	t.Helper()
+	if b.GamesStarted["battle"] != 1 || len(b.GamesStarted) != 1 {
+		t.Fatalf("games started = %v", b.GamesStarted)
+	}
+	if b.Abilities["ScatterShot"] != 1 || len(b.Abilities) != 1 {
+		t.Fatalf("abilities = %v, want only the tracked game's ScatterShot", b.Abilities)
+	}
+	if b.Lengths["20-39"] != 1 || len(b.Lengths) != 1 {
+		t.Fatalf("lengths = %v", b.Lengths)
+	}
+}
+
+func TestReporterPostsTrackedGamesOnly(t *testing.T) {
+	received := make(chan Batch, 16)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b Batch
+		if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+			t.Errorf("decode batch: %v", err)
+		}
+		received <- b
+	}))
+	defer srv.Close()
+
+	b := bus.New()
+	defer b.Close()
+	r, err := Start(b, Config{Endpoint: srv.URL, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer r.Close()
+	r.Track("tracked", "")
+	publish(b, "untracked")
+	publish(b, "tracked")
+
+	var all []Batch
+	checkBatch(t, waitBatch(t, r, func() []Batch {
+		for {
+			select {
+			case batch := <-received:
+				all = append(all, batch)
+			default:
+				return all
+			}
+		}
+	}))
+}
+
+func TestReporterLocalOnly(t *testing.T) {
+	dir := t.TempDir()
+	b := bus.New()
+	defer b.Close()
+	r, err := Start(b, Config{Dir: dir, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer r.Close()
+	r.Track("g1", game.VariantBattle)
+	publish(b, "g1")
+
+	checkBatch(t, waitBatch(t, r, func() []Batch {
+		f, err := os.Open(filepath.Join(dir, "telemetry.jsonl"))
+		if err != nil {
+			return nil
+		}
+		defer f.Close()
+		var out []Batch
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			var batch Batch
+			if err := json.Unmarshal(sc.Bytes(), &batch); err != nil {
+				t.Fatalf("line %q: %v", sc.Text(), err)
+			}
+			out = append(out, batch)
+		}
+		return out
+	}))
+}
+
+func TestFailedDeliveryIsKept(t *testing.T) {
+	fail := true
+	var got Batch
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+	b := bus.New()
+	defer b.Close()
+	r, err := Start(b, Config{Endpoint: srv.URL, Interval: time.Hour})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer r.Close()
+	r.Track("g1", "")
+	if err := r.Flush(); err == nil {
+		t.Fatal("flush to a failing endpoint succeeded")
+	}
+	fail = false
+	if err := r.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if got.GamesStarted["battle"] != 1 {
+		t.Fatalf("retried batch = %+v", got)
+	}
+}
@@ -15,6 +15,11 @@
     lastNote: "",
     locked: false
   };
+  // A page opened from a share link (/g/{token}) plays that game; init.role
+  // is the seat the link was made for, or "spectator", and init.token goes
+  // back with every POST as the page's credential.
+  const apiBase = init.game ? "/api/games/" + encodeURIComponent(init.game) : "/api";
+  const spectating = init.role === "spectator";
   let state = Object.assign({}, defaultState, init.state || {});
   state.locked = !!state.locked;
   let selectedSquare = null;   // 0..63
@@ -369,6 +374,10 @@
   });
 
   async function submitMove(from, to, dir) {
+    if (spectating) {
+      showToast("Spectating", "This link is for watching the game.");
+      return;
+    }
     clearBlockDirOverlay();
     pendingMove = null;
     pendingBlockDir = "";
@@ -376,7 +385,7 @@
     moveForm.classList.add("loading");
     try {
       const payloadDir = typeof dir === "string" ? dir.toUpperCase() : String(dir || "");
-      const result = await fetchJSON("/api/move", { from, to, dir: payloadDir });
+      const result = await fetchJSON(apiBase + "/move", { from, to, dir: payloadDir });
       // Optional client animation
       await animateMove(algToSq(from), algToSq(to));
       updateState(result);
@@ -407,6 +416,8 @@
     }
   }
 
+  // /api/reset only resets the default game.
+  if (init.game) resetBtn.hidden = true;
   resetBtn.addEventListener("click", async () => {
     if (isAnimating) return;
     if (!confirm("🏰 Reset the entire battle? This will clear all progress!")) return;
@@ -463,7 +474,7 @@
       isAnimating = true;
       form.classList.add("loading");
       try {
-        const result = await fetchJSON("/api/config", {
+        const result = await fetchJSON(apiBase + "/config", {
           color,
           abilities: [ability],
           element
@@ -693,6 +704,7 @@
       headers: { "Content-Type": "application/json" },
       body: JSON.stringify(body)
     } : { method: "GET" };
+    if (body && init.token) opts.headers["X-Share-Token"] = init.token;
     const res = await fetch(url, opts);
     let payload;
     try { payload = await res.json(); } catch { payload = {}; }